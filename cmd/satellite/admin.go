@@ -29,6 +29,10 @@ func cmdAdminRun(cmd *cobra.Command, args []string) (err error) {
 	db, err := satellitedb.Open(ctx, log.Named("db"), runCfg.Database, satellitedb.Options{
 		ApplicationName:   "satellite-admin",
 		APIKeysLRUOptions: runCfg.APIKeysLRUOptions(),
+
+		ExpectedTallyInterval: runCfg.Tally.ExpectedInterval,
+		MaxTallyGap:           runCfg.Tally.MaxGap,
+		StrictAccountingReads: runCfg.Tally.StrictAccountingReads,
 	})
 	if err != nil {
 		return errs.New("Error starting master database on satellite api: %+v", err)
@@ -33,9 +33,18 @@ func cmdAPIRun(cmd *cobra.Command, args []string) (err error) {
 	}
 
 	db, err := satellitedb.Open(ctx, log.Named("db"), runCfg.Database, satellitedb.Options{
-		ApplicationName:      "satellite-api",
-		APIKeysLRUOptions:    runCfg.APIKeysLRUOptions(),
-		RevocationLRUOptions: runCfg.RevocationLRUOptions(),
+		ApplicationName:         "satellite-api",
+		APIKeysLRUOptions:       runCfg.APIKeysLRUOptions(),
+		RevocationLRUOptions:    runCfg.RevocationLRUOptions(),
+		ProjectLimitsLRUOptions: runCfg.ProjectLimitsLRUOptions(),
+
+		MaxBucketSearchMatches:   runCfg.Console.MaxBucketSearchMatches,
+		MaxAccountingScanResults: runCfg.Console.MaxUsageScanResults,
+		BucketTotalsParallelism:  runCfg.Console.BucketTotalsParallelism,
+
+		ExpectedTallyInterval: runCfg.Tally.ExpectedInterval,
+		MaxTallyGap:           runCfg.Tally.MaxGap,
+		StrictAccountingReads: runCfg.Tally.StrictAccountingReads,
 	})
 	if err != nil {
 		return errs.New("Error starting master database on satellite api: %+v", err)
@@ -24,7 +24,13 @@ import (
 func runBillingCmd(ctx context.Context, cmdFunc func(context.Context, *stripecoinpayments.Service, satellite.DB) error) error {
 	// Open SatelliteDB for the Payment Service
 	logger := zap.L()
-	db, err := satellitedb.Open(ctx, logger.Named("db"), runCfg.Database, satellitedb.Options{ApplicationName: "satellite-billing"})
+	db, err := satellitedb.Open(ctx, logger.Named("db"), runCfg.Database, satellitedb.Options{
+		ApplicationName: "satellite-billing",
+
+		ExpectedTallyInterval: runCfg.Tally.ExpectedInterval,
+		MaxTallyGap:           runCfg.Tally.MaxGap,
+		StrictAccountingReads: runCfg.Tally.StrictAccountingReads,
+	})
 	if err != nil {
 		return errs.New("error connecting to master database on satellite: %+v", err)
 	}
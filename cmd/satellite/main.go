@@ -57,6 +57,11 @@ type Satellite struct {
 			Expiration time.Duration `help:"macaroon revocation cache expiration" default:"5m"`
 			Capacity   int           `help:"macaroon revocation cache capacity" default:"10000"`
 		}
+		ProjectLimitsCache struct {
+			Enabled    bool          `help:"cache project limits read by the usage service" default:"false"`
+			Expiration time.Duration `help:"project limits cache expiration" default:"1m"`
+			Capacity   int           `help:"project limits cache capacity" default:"10000"`
+		}
 	}
 
 	satellite.Config
@@ -78,6 +83,18 @@ func (s *Satellite) RevocationLRUOptions() lrucache.Options {
 	}
 }
 
+// ProjectLimitsLRUOptions returns a cache.Options based on the project limits LRU config.
+// The options are empty when the cache is disabled.
+func (s *Satellite) ProjectLimitsLRUOptions() lrucache.Options {
+	if !s.DatabaseOptions.ProjectLimitsCache.Enabled {
+		return lrucache.Options{}
+	}
+	return lrucache.Options{
+		Expiration: s.DatabaseOptions.ProjectLimitsCache.Expiration,
+		Capacity:   s.DatabaseOptions.ProjectLimitsCache.Capacity,
+	}
+}
+
 var (
 	rootCmd = &cobra.Command{
 		Use:   "satellite",
@@ -372,9 +389,14 @@ func cmdRun(cmd *cobra.Command, args []string) (err error) {
 	}
 
 	db, err := satellitedb.Open(ctx, log.Named("db"), runCfg.Database, satellitedb.Options{
-		ApplicationName:     "satellite-core",
-		SaveRollupBatchSize: runCfg.Tally.SaveRollupBatchSize,
-		ReadRollupBatchSize: runCfg.Tally.ReadRollupBatchSize,
+		ApplicationName:      "satellite-core",
+		SaveRollupBatchSize:  runCfg.Tally.SaveRollupBatchSize,
+		ReadRollupBatchSize:  runCfg.Tally.ReadRollupBatchSize,
+		SaveTalliesBatchSize: runCfg.Tally.SaveTalliesBatchSize,
+
+		ExpectedTallyInterval: runCfg.Tally.ExpectedInterval,
+		MaxTallyGap:           runCfg.Tally.MaxGap,
+		StrictAccountingReads: runCfg.Tally.StrictAccountingReads,
 	})
 	if err != nil {
 		return errs.New("Error starting master database on satellite: %+v", err)
@@ -5,7 +5,9 @@ package multinodedbtest
 
 import (
 	"context"
+	"crypto/rand"
 	"fmt"
+	"os"
 	"strconv"
 	"strings"
 	"testing"
@@ -17,7 +19,6 @@ import (
 	"storj.io/common/testcontext"
 	"storj.io/private/dbutil"
 	"storj.io/private/dbutil/pgtest"
-	"storj.io/private/dbutil/pgutil"
 	"storj.io/private/dbutil/tempdb"
 	"storj.io/storj/multinode"
 	"storj.io/storj/multinode/multinodedb"
@@ -51,15 +52,26 @@ type ignoreSkip struct{}
 
 func (ignoreSkip) Skip(...interface{}) {}
 
-// SchemaSuffix returns a suffix for schemas.
+// schemaSuffixAlphabet contains the characters of random schema suffixes, schema names are lower cased.
+const schemaSuffixAlphabet = "abcdefghijklmnopqrstuvwxyz0123456789"
+
+// SchemaSuffix returns a suffix for schemas. It's unique across test processes started at the
+// same time, since it combines the process id with crypto random characters.
 func SchemaSuffix() string {
-	return pgutil.CreateRandomTestingSchemaName(6)
+	random := make([]byte, 6)
+	if _, err := rand.Read(random); err != nil {
+		panic(err)
+	}
+	for i, b := range random {
+		random[i] = schemaSuffixAlphabet[int(b)%len(schemaSuffixAlphabet)]
+	}
+	return strconv.FormatInt(int64(os.Getpid()), 36) + "-" + string(random)
 }
 
 // SchemaName returns a properly formatted schema string.
 func SchemaName(testname, category string, index int, schemaSuffix string) string {
 	// postgres has a maximum schema length of 64
-	// we need additional 6 bytes for the random suffix
+	// we need additional bytes for the random suffix
 	// and 4 bytes for the index "/S0/""
 
 	indexStr := strconv.Itoa(index)
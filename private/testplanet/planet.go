@@ -24,7 +24,6 @@ import (
 	"storj.io/common/identity/testidentity"
 	"storj.io/common/pb"
 	"storj.io/common/storj"
-	"storj.io/private/dbutil/pgutil"
 	"storj.io/storj/satellite/overlay"
 	"storj.io/storj/satellite/satellitedb/satellitedbtest"
 	"storj.io/storj/versioncontrol"
@@ -128,7 +127,7 @@ func NewCustom(ctx context.Context, log *zap.Logger, config Config, satelliteDat
 
 	planet := &Planet{
 		log:    log,
-		id:     config.Name + "/" + pgutil.CreateRandomTestingSchemaName(6),
+		id:     config.Name + "/" + satellitedbtest.SchemaSuffix(),
 		config: config,
 	}
 
@@ -51,6 +51,8 @@ import (
 	"storj.io/storj/satellite/metabase/segmentloop"
 	"storj.io/storj/satellite/metainfo"
 	"storj.io/storj/satellite/metainfo/expireddeletion"
+	"storj.io/storj/satellite/metainfo/piecesbackfill"
+	"storj.io/storj/satellite/metainfo/tombstonedeletion"
 	"storj.io/storj/satellite/metrics"
 	"storj.io/storj/satellite/nodestats"
 	"storj.io/storj/satellite/orders"
@@ -137,6 +139,14 @@ type Satellite struct {
 		Chore *expireddeletion.Chore
 	}
 
+	TombstoneDeletion struct {
+		Chore *tombstonedeletion.Chore
+	}
+
+	PiecesBackfill struct {
+		Chore *piecesbackfill.Chore
+	}
+
 	Accounting struct {
 		Tally            *tally.Service
 		NodeTally        *nodetally.Service
@@ -581,6 +591,8 @@ func createNewSystem(name string, log *zap.Logger, config satellite.Config, peer
 	system.GarbageCollection.Service = gcPeer.GarbageCollection.Service
 
 	system.ExpiredDeletion.Chore = peer.ExpiredDeletion.Chore
+	system.TombstoneDeletion.Chore = peer.TombstoneDeletion.Chore
+	system.PiecesBackfill.Chore = peer.PiecesBackfill.Chore
 
 	system.Accounting.Tally = peer.Accounting.Tally
 	system.Accounting.NodeTally = peer.Accounting.NodeTally
@@ -0,0 +1,52 @@
+// Copyright (C) 2021 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package accounting_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"storj.io/common/testcontext"
+	"storj.io/common/testrand"
+	"storj.io/common/uuid"
+	"storj.io/storj/satellite"
+	"storj.io/storj/satellite/satellitedb/satellitedbtest"
+)
+
+func BenchmarkGetProjectTotals(b *testing.B) {
+	satellitedbtest.Bench(b, func(b *testing.B, db satellite.DB) {
+		const projectCount = 100
+
+		ctx := testcontext.New(b)
+		defer ctx.Cleanup()
+
+		now := time.Now().UTC().Truncate(time.Hour)
+		since, before := now.Add(-24*time.Hour), now
+
+		var projectIDs []uuid.UUID
+		for i := 0; i < projectCount; i++ {
+			projectID := testrand.UUID()
+			projectIDs = append(projectIDs, projectID)
+			createProjectUsage(ctx, b, db, projectID, 3, since, before)
+		}
+
+		b.Run("GetProjectTotal", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				for _, projectID := range projectIDs {
+					_, err := db.ProjectAccounting().GetProjectTotal(ctx, projectID, since, before, 0)
+					require.NoError(b, err)
+				}
+			}
+		})
+
+		b.Run("GetProjectTotals", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				_, err := db.ProjectAccounting().GetProjectTotals(ctx, projectIDs, since, before)
+				require.NoError(b, err)
+			}
+		})
+	})
+}
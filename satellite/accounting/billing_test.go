@@ -453,7 +453,7 @@ func getProjectTotalFromStorageNodes(
 	// flush rollups write cache
 	sat.Orders.Chore.Loop.TriggerWait()
 
-	usage, err := sat.DB.ProjectAccounting().GetProjectTotal(ctx, projectID, since, time.Now())
+	usage, err := sat.DB.ProjectAccounting().GetProjectTotal(ctx, projectID, since, time.Now(), 0)
 	require.NoError(t, err)
 
 	return usage
@@ -0,0 +1,35 @@
+// Copyright (C) 2021 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package accounting
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// NormalizeBucketName returns the form of a bucket name stored in the accounting tables.
+// Names are converted to Unicode NFC and surrounding white space is removed, so that usage
+// recorded for a bucket is found under the name of the bucket regardless of its encoding.
+//
+// Empty names, names which aren't valid UTF-8 and names containing control characters
+// are rejected with ErrInvalidBucketName.
+func NormalizeBucketName(name string) (string, error) {
+	if !utf8.ValidString(name) {
+		return "", ErrInvalidArgument.Wrap(fmt.Errorf("%w: %q is not valid UTF-8", ErrInvalidBucketName, name))
+	}
+
+	normalized := norm.NFC.String(strings.TrimSpace(name))
+	if normalized == "" {
+		return "", ErrInvalidArgument.Wrap(fmt.Errorf("%w: name is empty", ErrInvalidBucketName))
+	}
+	if strings.IndexFunc(normalized, unicode.IsControl) >= 0 {
+		return "", ErrInvalidArgument.Wrap(fmt.Errorf("%w: %q contains control characters", ErrInvalidBucketName, name))
+	}
+
+	return normalized, nil
+}
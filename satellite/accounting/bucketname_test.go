@@ -0,0 +1,36 @@
+// Copyright (C) 2021 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package accounting_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"storj.io/storj/satellite/accounting"
+)
+
+func TestNormalizeBucketName(t *testing.T) {
+	for _, tt := range []struct {
+		name       string
+		normalized string
+	}{
+		{"bucket", "bucket"},
+		{"bucket ", "bucket"},
+		{"\tbucket\n", "bucket"},
+		{"caf\u00e9", "caf\u00e9"},
+		{"cafe\u0301", "caf\u00e9"},
+	} {
+		normalized, err := accounting.NormalizeBucketName(tt.name)
+		require.NoError(t, err, tt.name)
+		require.Equal(t, tt.normalized, normalized, tt.name)
+	}
+
+	for _, name := range []string{"", "  ", "\xff\xfe", "bu\x00cket", "bu\ncket"} {
+		_, err := accounting.NormalizeBucketName(name)
+		require.True(t, accounting.ErrInvalidArgument.Has(err), name)
+		require.True(t, errors.Is(err, accounting.ErrInvalidBucketName), name)
+	}
+}
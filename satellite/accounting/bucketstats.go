@@ -37,3 +37,16 @@ func (s *BucketTally) Segments() int64 {
 func (s *BucketTally) Bytes() int64 {
 	return s.TotalBytes
 }
+
+// Verify checks whether the tally can be saved.
+func (s *BucketTally) Verify() error {
+	switch {
+	case s.BucketName == "":
+		return ErrInvalidArgument.New("bucket name is empty")
+	case s.ProjectID.IsZero():
+		return ErrInvalidArgument.New("project ID is zero")
+	case s.ObjectCount < 0, s.TotalSegments < 0, s.TotalBytes < 0, s.MetadataSize < 0:
+		return ErrInvalidArgument.New("negative counter")
+	}
+	return nil
+}
@@ -20,6 +20,11 @@ type BucketStorageTally struct {
 	TotalSegmentCount int64
 	TotalBytes        int64
 
+	// InlineBytes and RemoteBytes are only set for tallies stored before
+	// total bytes were introduced, new tallies use only TotalBytes.
+	InlineBytes int64
+	RemoteBytes int64
+
 	MetadataSize int64
 }
 
@@ -4,6 +4,7 @@
 package accounting
 
 import (
+	"errors"
 	"time"
 
 	"github.com/zeebo/errs"
@@ -33,6 +34,26 @@ var (
 	// ErrUnexpectedValue is returned when an unexpected value according the
 	// business domain is in the cache.
 	ErrUnexpectedValue = errs.Class("unexpected value")
+	// ErrSearchTooBroad is returned when a search matches too many buckets
+	// to be served without degrading the database.
+	ErrSearchTooBroad = errs.Class("search too broad")
+	// ErrTooManyResults is returned when a usage query would process too many
+	// buckets or rows to be served without degrading the database.
+	ErrTooManyResults = errs.Class("too many results")
+)
+
+// Sentinel errors returned by ProjectAccounting, which callers detect with errors.Is.
+// ErrInvalidCursor, ErrPageOutOfRange and ErrInvalidBucketName are additionally wrapped in ErrInvalidArgument.
+var (
+	// ErrInvalidCursor is returned when a cursor or continuation token of a paged
+	// listing doesn't refer to a page.
+	ErrInvalidCursor = errors.New("invalid cursor")
+	// ErrPageOutOfRange is returned when a page past the last one is requested.
+	ErrPageOutOfRange = errors.New("page is out of range")
+	// ErrProjectNotFound is returned when the project doesn't exist.
+	ErrProjectNotFound = errors.New("project not found")
+	// ErrInvalidBucketName is returned when a bucket name can't be normalized.
+	ErrInvalidBucketName = errors.New("invalid bucket name")
 )
 
 // CSVRow represents data from QueryPaymentInfo without exposing dbx.
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"storj.io/common/memory"
+	"storj.io/common/pb"
 	"storj.io/common/storj"
 	"storj.io/common/uuid"
 	"storj.io/storj/satellite/compensation"
@@ -15,6 +16,10 @@ import (
 	"storj.io/storj/satellite/orders"
 )
 
+// OtherPieceActions is the key under which egress of piece actions unknown to
+// the satellite is reported by GetProjectEgressByAction and GetBucketEgressTotals.
+const OtherPieceActions = pb.PieceAction_INVALID
+
 // RollupStats is a convenience alias.
 type RollupStats map[time.Time]map[storj.NodeID]*Rollup
 
@@ -68,11 +73,23 @@ type StorageNodeUsage struct {
 
 // ProjectUsage consist of period total storage, egress
 // and objects count per hour for certain Project in bytes.
+// StorageGBMonths, EgressGB and ObjectMonths convert them to the units used for billing.
 type ProjectUsage struct {
 	Storage     float64 `json:"storage"`
 	Egress      int64   `json:"egress"`
 	ObjectCount float64 `json:"objectCount"`
 
+	// GetEgress, AuditEgress and RepairEgress are the egress of the GET,
+	// GET_AUDIT and GET_REPAIR actions. Egress matches GetEgress.
+	GetEgress    int64 `json:"getEgress"`
+	AuditEgress  int64 `json:"auditEgress"`
+	RepairEgress int64 `json:"repairEgress"`
+
+	// InlineStorage and RemoteStorage split Storage for tallies which were stored
+	// with separate inline and remote bytes. Storage of newer tallies is not split.
+	InlineStorage float64 `json:"inlineStorage,omitempty"`
+	RemoteStorage float64 `json:"remoteStorage,omitempty"`
+
 	Since  time.Time `json:"since"`
 	Before time.Time `json:"before"`
 }
@@ -83,6 +100,93 @@ type ProjectLimits struct {
 	Bandwidth *int64
 }
 
+// EffectiveProjectLimits contains the storage and bandwidth limits with the satellite defaults
+// applied for limits which aren't set for the project.
+type EffectiveProjectLimits struct {
+	Usage     int64
+	Bandwidth int64
+
+	// UsageDefault is true when Usage is the default limit.
+	UsageDefault bool
+	// BandwidthDefault is true when Bandwidth is the default limit.
+	BandwidthDefault bool
+}
+
+// ProjectUsageAlert is the usage of a project which reached one of its usage alert thresholds.
+// Fractions are the usage divided by the limit.
+type ProjectUsageAlert struct {
+	ProjectID uuid.UUID
+
+	StorageUsage     int64
+	StorageLimit     int64
+	StorageFraction  float64
+	StorageThreshold int
+	StorageExceeded  bool
+
+	BandwidthUsage     int64
+	BandwidthLimit     int64
+	BandwidthFraction  float64
+	BandwidthThreshold int
+	BandwidthExceeded  bool
+}
+
+// ProjectAccountingDeletions contains the number of deleted accounting rows of a project per table.
+type ProjectAccountingDeletions struct {
+	StorageTallies          int64
+	StorageTallyArchives    int64
+	BandwidthRollups        int64
+	BandwidthRollupArchives int64
+	DailyBandwidthRollups   int64
+}
+
+// BandwidthInconsistency is a day on which the project bandwidth daily rollup differs from
+// the sum of the bucket bandwidth rollups of the project.
+type BandwidthInconsistency struct {
+	Day           time.Time
+	DailyRollup   int64
+	BucketRollups int64
+}
+
+// ProjectBandwidthAdjustment is a manual adjustment of the project bandwidth of a day.
+// Applied deltas differ from the requested ones when the adjusted bandwidth was clamped at zero.
+type ProjectBandwidthAdjustment struct {
+	ProjectID uuid.UUID
+	Day       time.Time
+
+	RequestedAllocated int64
+	RequestedSettled   int64
+	AppliedAllocated   int64
+	AppliedSettled     int64
+
+	Reason    string
+	CreatedAt time.Time
+}
+
+// ProjectAccountingStatus describes the state of the accounting data of a project, so that
+// missing usage can be told apart from an empty project. Zero times mean no data.
+type ProjectAccountingStatus struct {
+	// LatestTally is the interval start of the most recent tally of the project.
+	LatestTally time.Time
+	// LatestTallyBuckets is the number of buckets in the most recent tally of the project.
+	LatestTallyBuckets int64
+	// TalliedBuckets is the number of buckets with any tally which wasn't archived.
+	TalliedBuckets int64
+	// Buckets is the number of buckets of the project.
+	Buckets int64
+
+	// LatestBandwidthRollup is the interval start of the most recent bandwidth rollup of the project.
+	LatestBandwidthRollup time.Time
+
+	// BandwidthArchiveCutoff and TallyArchiveCutoff are the interval starts of the most
+	// recent archived bandwidth rollup and tally of the project.
+	BandwidthArchiveCutoff time.Time
+	TallyArchiveCutoff     time.Time
+}
+
+// ClearLimit is the limit value which makes UpdateProjectLimits clear the limit,
+// so that the satellite default limit is used for the project.
+const ClearLimit int64 = -1
+
 // BucketUsage consist of total bucket usage for period.
 type BucketUsage struct {
 	ProjectID  uuid.UUID
@@ -91,6 +195,12 @@ type BucketUsage struct {
 	Storage     float64
 	Egress      float64
 	ObjectCount int64
+	// MetadataSize is the metadata of the bucket in bytes as of the latest tally within the period.
+	MetadataSize int64
+
+	// HasData is true when there is at least one storage tally or bandwidth rollup
+	// of the bucket within the period, which tells zero usage apart from no data.
+	HasData bool
 
 	Since  time.Time
 	Before time.Time
@@ -102,6 +212,11 @@ type BucketUsageCursor struct {
 	Search string
 	Limit  uint
 	Page   uint
+
+	// StartAfter switches to keyset pagination, buckets with names after StartAfter are
+	// listed instead of the Page. Unlike pages, it doesn't skip buckets when buckets are
+	// created or deleted while paging.
+	StartAfter string
 }
 
 // BucketUsagePage represents bucket usage page result.
@@ -115,6 +230,43 @@ type BucketUsagePage struct {
 	PageCount   uint
 	CurrentPage uint
 	TotalCount  uint64
+
+	// NextCursor is the name of the last bucket of the page, when more buckets follow.
+	// It's meant to be used as StartAfter of the cursor for the next page.
+	NextCursor string
+}
+
+// RollupArchiveStats holds the number of bucket bandwidth rollups of an action archived
+// by ArchiveRollupsBeforeWithStats and the time it took, when it's known.
+type RollupArchiveStats struct {
+	Count   int
+	Elapsed time.Duration
+}
+
+// ArchivedRollupsCursor holds info for archived bandwidth rollups pagination.
+type ArchivedRollupsCursor struct {
+	Since time.Time
+	// ProjectID and Action optionally restrict the listing to a single project or action.
+	ProjectID *uuid.UUID
+	Action    *pb.PieceAction
+	// Limit is the maximum number of rollups in the page, it's capped by the implementation.
+	Limit int
+	// Next is the continuation token returned with the previous page, empty for the first page.
+	Next string
+}
+
+// ArchivedBandwidthRollup is an archived bucket bandwidth rollup with the start of its interval.
+type ArchivedBandwidthRollup struct {
+	IntervalStart time.Time
+	orders.BucketBandwidthRollup
+}
+
+// ArchivedRollupsPage represents a page of archived bandwidth rollups ordered by
+// interval start, project ID, bucket name and action.
+type ArchivedRollupsPage struct {
+	Rollups []ArchivedBandwidthRollup
+	// Next is the continuation token for the next page, it's empty when there are no more rollups.
+	Next string
 }
 
 // BucketUsageRollup is total bucket usage info
@@ -124,6 +276,10 @@ type BucketUsageRollup struct {
 	BucketName []byte
 
 	TotalStoredData float64
+	// InlineStoredData and RemoteStoredData split TotalStoredData for tallies
+	// which were stored with separate inline and remote bytes.
+	InlineStoredData float64
+	RemoteStoredData float64
 
 	TotalSegments float64
 	ObjectCount   float64
@@ -133,10 +289,65 @@ type BucketUsageRollup struct {
 	GetEgress    float64
 	AuditEgress  float64
 
+	// HasData is true when there is at least one storage tally or bandwidth rollup
+	// of the bucket within the period.
+	HasData bool
+
 	Since  time.Time
 	Before time.Time
 }
 
+// ProjectBandwidthBreakdown contains the project bandwidth of a period split by its settlement state.
+type ProjectBandwidthBreakdown struct {
+	AllocatedTotal int64
+	SettledTotal   int64
+	DeadTotal      int64
+	// Used is the settled bandwidth for days where allocations have expired
+	// and the allocated bandwidth otherwise.
+	Used int64
+}
+
+// BucketBandwidthBreakdownCursor holds info for bucket bandwidth breakdown
+// pagination by bucket name.
+type BucketBandwidthBreakdownCursor struct {
+	// StartAfter is the bucket name after which the listing starts.
+	StartAfter string
+	Limit      int
+}
+
+// BucketBandwidthBreakdown compares allocated and settled GET bandwidth of a bucket.
+type BucketBandwidthBreakdown struct {
+	BucketName string `json:"bucketName"`
+
+	Allocated int64 `json:"allocated"`
+	Settled   int64 `json:"settled"`
+	// Difference is allocated minus settled bandwidth. It's negative when
+	// orders allocated before the period were settled within it.
+	Difference int64 `json:"difference"`
+}
+
+// BucketBandwidthBreakdownPage represents a page of bucket bandwidth breakdowns.
+type BucketBandwidthBreakdownPage struct {
+	Breakdowns []BucketBandwidthBreakdown `json:"breakdowns"`
+	More       bool                       `json:"more"`
+}
+
+// BucketObjectCountPoint is a point of bucket object count history.
+type BucketObjectCountPoint struct {
+	IntervalStart time.Time `json:"intervalStart"`
+	ObjectCount   int64     `json:"objectCount"`
+	TotalBytes    int64     `json:"totalBytes"`
+}
+
+// ProjectHourlyBandwidth is the bandwidth of a project within an hour, summed up over all
+// buckets and actions.
+type ProjectHourlyBandwidth struct {
+	Hour      time.Time
+	Allocated int64
+	Settled   int64
+	Inline    int64
+}
+
 // StoragenodeAccounting stores information about bandwidth and storage usage for storage nodes.
 //
 // architecture: Database
@@ -163,6 +374,8 @@ type StoragenodeAccounting interface {
 	DeleteTalliesBefore(ctx context.Context, latestRollup time.Time) error
 	// ArchiveRollupsBefore archives rollups older than a given time and returns num storagenode and bucket bandwidth rollups archived.
 	ArchiveRollupsBefore(ctx context.Context, before time.Time, batchSize int) (numArchivedNodeBW int, err error)
+	// ArchiveRollupsBeforeDryRun returns the number of storagenode bandwidth rollups ArchiveRollupsBefore would archive.
+	ArchiveRollupsBeforeDryRun(ctx context.Context, before time.Time, batchSize int) (int, error)
 	// GetRollupsSince retrieves all archived bandwidth rollup records since a given time. A hard limit batch size is used for results.
 	GetRollupsSince(ctx context.Context, since time.Time) ([]StoragenodeBandwidthRollup, error)
 	// GetArchivedRollupsSince retrieves all archived bandwidth rollup records since a given time. A hard limit batch size is used for results.
@@ -171,45 +384,213 @@ type StoragenodeAccounting interface {
 
 // ProjectAccounting stores information about bandwidth and storage usage for projects.
 //
+// Usage periods given by since and before are half-open: data at since is included,
+// data at before belongs to the next period.
+//
 // architecture: Database
 type ProjectAccounting interface {
-	// SaveTallies saves the latest project info
-	SaveTallies(ctx context.Context, intervalStart time.Time, bucketTallies map[metabase.BucketLocation]*BucketTally) error
+	// SaveTallies saves the latest project info and returns the number of saved tallies.
+	// Tallies which fail verification are skipped and returned with their errors in invalid.
+	// Bucket names are stored normalized by NormalizeBucketName.
+	SaveTallies(ctx context.Context, intervalStart time.Time, bucketTallies map[metabase.BucketLocation]*BucketTally) (saved int, invalid map[metabase.BucketLocation]error, err error)
 	// GetTallies retrieves all tallies
 	GetTallies(ctx context.Context) ([]BucketTally, error)
-	// CreateStorageTally creates a record for BucketStorageTally in the accounting DB table
+	// GetTalliesSince retrieves the tallies with interval start at or after since. Zero since retrieves all tallies.
+	GetTalliesSince(ctx context.Context, since time.Time) ([]BucketTally, error)
+	// CreateStorageTally creates a record for BucketStorageTally in the accounting DB table.
+	// The bucket name is stored normalized by NormalizeBucketName.
 	CreateStorageTally(ctx context.Context, tally BucketStorageTally) error
+	// CreateBandwidthRollup adds the amounts of rollup to the bucket bandwidth rollup of the interval.
+	CreateBandwidthRollup(ctx context.Context, rollup orders.BucketBandwidthRollup, intervalStart time.Time, intervalSeconds int) error
+	// CreateBandwidthRollups adds the amounts of rollups to the bucket bandwidth rollups of the interval.
+	CreateBandwidthRollups(ctx context.Context, rollups []orders.BucketBandwidthRollup, intervalStart time.Time, intervalSeconds int) error
 	// GetAllocatedBandwidthTotal returns the sum of GET bandwidth usage allocated for a projectID in the past time frame
 	GetAllocatedBandwidthTotal(ctx context.Context, projectID uuid.UUID, from time.Time) (int64, error)
 	// GetProjectBandwidth returns project allocated bandwidth for the specified year, month and day.
 	GetProjectBandwidth(ctx context.Context, projectID uuid.UUID, year int, month time.Month, day int, asOfSystemInterval time.Duration) (int64, error)
+	// GetProjectBandwidthBreakdown returns project allocated, settled and dead bandwidth for the month of the specified
+	// year, month and day, together with the used bandwidth as returned by GetProjectBandwidth.
+	GetProjectBandwidthBreakdown(ctx context.Context, projectID uuid.UUID, year int, month time.Month, day int, asOfSystemInterval time.Duration) (ProjectBandwidthBreakdown, error)
+	// GetProjectCycleBandwidth returns project allocated bandwidth for the specified year, month and day within
+	// a billing cycle starting on cycleStartDay, between 1 and 28, of a month.
+	GetProjectCycleBandwidth(ctx context.Context, projectID uuid.UUID, year int, month time.Month, day, cycleStartDay int, asOfSystemInterval time.Duration) (int64, error)
+	// GetProjectBandwidthUsageAndLimit returns project bandwidth usage the same way as GetProjectBandwidth
+	// together with the project bandwidth limit in a single query. Nil limit means the default limit is used.
+	GetProjectBandwidthUsageAndLimit(ctx context.Context, projectID uuid.UUID, year int, month time.Month, day int, asOfSystemInterval time.Duration) (used int64, limit *int64, err error)
+	// VerifyProjectBandwidthConsistency compares the project bandwidth daily rollups of a month with the
+	// bucket bandwidth rollups and returns the days on which they differ by more than tolerance bytes.
+	VerifyProjectBandwidthConsistency(ctx context.Context, projectID uuid.UUID, year int, month time.Month, tolerance int64) ([]BandwidthInconsistency, error)
 	// GetProjectDailyBandwidth returns bandwidth (allocated and settled) for the specified day.
 	GetProjectDailyBandwidth(ctx context.Context, projectID uuid.UUID, year int, month time.Month, day int) (int64, int64, error)
-	// DeleteProjectBandwidthBefore deletes project bandwidth rollups before the given time
-	DeleteProjectBandwidthBefore(ctx context.Context, before time.Time) error
+	// ApplyProjectBandwidthAdjustment adds the deltas to the project bandwidth of the day, clamping it at zero,
+	// and records the adjustment together with reason.
+	ApplyProjectBandwidthAdjustment(ctx context.Context, projectID uuid.UUID, day time.Time, deltaAllocated, deltaSettled int64, reason string) (ProjectBandwidthAdjustment, error)
+	// GetProjectBandwidthAdjustments returns the recorded bandwidth adjustments of a project since a given time, ordered by time.
+	GetProjectBandwidthAdjustments(ctx context.Context, projectID uuid.UUID, since time.Time) ([]ProjectBandwidthAdjustment, error)
+	// DeleteProjectBandwidthBefore deletes project bandwidth rollups before the given time in batches
+	// of batchSize and returns the number of deleted rollups. A zero or negative batchSize deletes them with a single statement.
+	DeleteProjectBandwidthBefore(ctx context.Context, before time.Time, batchSize int) (deletedCount int64, err error)
+	// DeleteProjectBandwidthBeforeDryRun returns the number of project bandwidth rollups DeleteProjectBandwidthBefore would delete.
+	DeleteProjectBandwidthBeforeDryRun(ctx context.Context, before time.Time) (int64, error)
+	// DeleteTalliesBefore deletes bucket storage tallies before the given time in batches of batchSize
+	// and returns the number of deleted tallies. A zero or negative batchSize deletes them with a single statement.
+	DeleteTalliesBefore(ctx context.Context, before time.Time, batchSize int) (deleted int64, err error)
+	// DeleteTalliesBeforeDryRun returns the number of bucket storage tallies DeleteTalliesBefore would delete.
+	DeleteTalliesBeforeDryRun(ctx context.Context, before time.Time) (int64, error)
+	// DeleteProjectAccountingData deletes all storage and bandwidth accounting of a project in batches of
+	// batchSize and returns the number of deleted rows per table. It can be called again to resume
+	// after a failure.
+	DeleteProjectAccountingData(ctx context.Context, projectID uuid.UUID, batchSize int) (ProjectAccountingDeletions, error)
+	// DeleteBucketAccountingData deletes storage tallies and bandwidth rollups of a bucket before the given time,
+	// so that a bucket recreated with the same name starts without usage. It returns the number of deleted rows per table.
+	DeleteBucketAccountingData(ctx context.Context, projectID uuid.UUID, bucketName string, before time.Time) (ProjectAccountingDeletions, error)
+	// ApplyRetention archives or deletes the accounting rows past the retention configured for their table at now
+	// and returns the outcome per table.
+	ApplyRetention(ctx context.Context, config RetentionConfig, now time.Time) (RetentionSummary, error)
 
 	// UpdateProjectUsageLimit updates project usage limit.
 	UpdateProjectUsageLimit(ctx context.Context, projectID uuid.UUID, limit memory.Size) error
 	// UpdateProjectBandwidthLimit updates project bandwidth limit.
 	UpdateProjectBandwidthLimit(ctx context.Context, projectID uuid.UUID, limit memory.Size) error
-	// GetProjectStorageLimit returns project storage usage limit.
+	// ClearProjectUsageLimit clears project usage limit, so that the satellite default is used.
+	ClearProjectUsageLimit(ctx context.Context, projectID uuid.UUID) error
+	// ClearProjectBandwidthLimit clears project bandwidth limit, so that the satellite default is used.
+	ClearProjectBandwidthLimit(ctx context.Context, projectID uuid.UUID) error
+	// UpdateProjectLimits updates project storage and bandwidth limits at once. Nil limits are left unchanged,
+	// limits set to ClearLimit are cleared.
+	UpdateProjectLimits(ctx context.Context, projectID uuid.UUID, limits ProjectLimits) error
+	// UpdateProjectLimitsBulk updates storage and bandwidth limits of all given projects at once, same as
+	// UpdateProjectLimits. It returns the number of updated projects.
+	UpdateProjectLimitsBulk(ctx context.Context, projectIDs []uuid.UUID, limits ProjectLimits) (updated int64, err error)
+	// GetProjectStorageLimit returns project storage usage limit or ErrProjectNotFound.
 	GetProjectStorageLimit(ctx context.Context, projectID uuid.UUID) (*int64, error)
-	// GetProjectBandwidthLimit returns project bandwidth usage limit.
+	// GetProjectBandwidthLimit returns project bandwidth usage limit or ErrProjectNotFound.
 	GetProjectBandwidthLimit(ctx context.Context, projectID uuid.UUID) (*int64, error)
-	// GetProjectLimits returns current project limit for both storage and bandwidth.
+	// GetProjectLimits returns current project limit for both storage and bandwidth or ErrProjectNotFound.
 	GetProjectLimits(ctx context.Context, projectID uuid.UUID) (ProjectLimits, error)
+	// GetEffectiveProjectLimits returns current project limits for both storage and bandwidth,
+	// using the given defaults for limits which aren't set. Both defaults must be set.
+	GetEffectiveProjectLimits(ctx context.Context, projectID uuid.UUID, defaults ProjectLimits) (EffectiveProjectLimits, error)
+	// SetProjectUsageAlertThresholds sets the percentages of the storage and bandwidth limits at which the project
+	// is reported by GetProjectsOverThreshold. Zero disables the alert, setting both to zero removes the thresholds.
+	SetProjectUsageAlertThresholds(ctx context.Context, projectID uuid.UUID, storagePercent, bandwidthPercent int) error
+	// GetProjectsOverThreshold returns the projects whose storage, taken from the latest tallies, or bandwidth of the
+	// month containing asOf reached their usage alert thresholds. The defaults are used for limits which aren't set.
+	GetProjectsOverThreshold(ctx context.Context, asOf time.Time, defaults ProjectLimits) ([]ProjectUsageAlert, error)
+	// UpdateBucketBandwidthLimit updates bucket bandwidth limit.
+	UpdateBucketBandwidthLimit(ctx context.Context, projectID uuid.UUID, bucketName string, limit memory.Size) error
+	// ClearBucketBandwidthLimit clears bucket bandwidth limit, so that the bucket is limited only by the project limit.
+	ClearBucketBandwidthLimit(ctx context.Context, projectID uuid.UUID, bucketName string) error
+	// GetBucketBandwidthLimit returns bucket bandwidth limit, nil means unlimited.
+	GetBucketBandwidthLimit(ctx context.Context, projectID uuid.UUID, bucketName string) (*int64, error)
+	// GetBucketBandwidthUsage returns the sum of GET bandwidth allocated for a bucket since from.
+	GetBucketBandwidthUsage(ctx context.Context, projectID uuid.UUID, bucketName string, from time.Time) (int64, error)
+	// UpdateBucketStorageLimit updates bucket storage limit.
+	UpdateBucketStorageLimit(ctx context.Context, projectID uuid.UUID, bucketName string, limit memory.Size) error
+	// ClearBucketStorageLimit clears bucket storage limit, so that the bucket is limited only by the project limit.
+	ClearBucketStorageLimit(ctx context.Context, projectID uuid.UUID, bucketName string) error
+	// GetBucketStorageLimit returns bucket storage limit, nil means unlimited.
+	GetBucketStorageLimit(ctx context.Context, projectID uuid.UUID, bucketName string) (*int64, error)
+	// GetBucketStorageUsage returns the stored bytes of a bucket from the most recent tally.
+	GetBucketStorageUsage(ctx context.Context, projectID uuid.UUID, bucketName string) (int64, error)
+	// GetProjectLimitHistory returns limit changes of a project since a given time, ordered by time.
+	// Only changes made with a context having a limit change actor are recorded, see WithLimitChangeActor.
+	GetProjectLimitHistory(ctx context.Context, projectID uuid.UUID, since time.Time) ([]ProjectLimitChange, error)
+	// GetProjectLimitsBatch returns current limits of all given projects. Projects which don't exist are missing from the result.
+	GetProjectLimitsBatch(ctx context.Context, projectIDs []uuid.UUID) (map[uuid.UUID]ProjectLimits, error)
+	// GetProjectExemptFromBilling returns whether the project is excluded from satellite-wide aggregates and invoicing.
+	GetProjectExemptFromBilling(ctx context.Context, projectID uuid.UUID) (bool, error)
+	// UpdateProjectExemptFromBilling sets whether the project is excluded from satellite-wide aggregates and invoicing.
+	UpdateProjectExemptFromBilling(ctx context.Context, projectID uuid.UUID, exempt bool) error
 	// GetProjectTotal returns project usage summary for specified period of time.
-	GetProjectTotal(ctx context.Context, projectID uuid.UUID, since, before time.Time) (*ProjectUsage, error)
+	// Egress includes archived bandwidth rollups.
+	GetProjectTotal(ctx context.Context, projectID uuid.UUID, since, before time.Time, asOfSystemInterval time.Duration) (*ProjectUsage, error)
+	// GetProjectHourlyBandwidth returns the bandwidth of the project per hour within [from, to), ordered by hour.
+	// The range may span at most 14 days. When includeArchives is set, archived rollups are included.
+	GetProjectHourlyBandwidth(ctx context.Context, projectID uuid.UUID, from, to time.Time, includeArchives bool) ([]ProjectHourlyBandwidth, error)
+	// GetProjectEgressByAction returns egress of the project per piece action for specified period of time.
+	// Egress of unknown actions is summed up under OtherPieceActions.
+	GetProjectEgressByAction(ctx context.Context, projectID uuid.UUID, since, before time.Time) (map[pb.PieceAction]int64, error)
+	// GetBucketEgressTotals returns egress of every bucket of the project per piece action for specified period of time,
+	// including archived rollups. Only the given actions are included, nil actions include all of them.
+	GetBucketEgressTotals(ctx context.Context, projectID uuid.UUID, since, before time.Time, actions []pb.PieceAction) (map[string]map[pb.PieceAction]int64, error)
+	// SaveProjectMonthlyUsage stores the usage of a project for the month of period, replacing the previously stored usage.
+	SaveProjectMonthlyUsage(ctx context.Context, projectID uuid.UUID, period time.Time, usage ProjectUsage) error
+	// GetProjectMonthlyUsage returns the stored usage of a project for the month of period.
+	GetProjectMonthlyUsage(ctx context.Context, projectID uuid.UUID, period time.Time) (*ProjectUsage, error)
+	// SaveClosedProjectMonthlyUsage computes the usage of a project for the month of period, which has to be over,
+	// the same way as GetProjectTotal and stores it.
+	SaveClosedProjectMonthlyUsage(ctx context.Context, projectID uuid.UUID, period time.Time) (*ProjectUsage, error)
+	// GetProjectTotalByPartner returns project usage summary for specified period of time split by the partner
+	// the buckets are attributed to. Usage of buckets not attributed to any of partnerNames is returned under the empty name.
+	GetProjectTotalByPartner(ctx context.Context, projectID uuid.UUID, partnerNames []string, since, before time.Time) (map[string]*ProjectUsage, error)
+	// GetProjectTotals returns project usage summaries for multiple projects for specified period of time.
+	GetProjectTotals(ctx context.Context, projectIDs []uuid.UUID, since, before time.Time) (map[uuid.UUID]*ProjectUsage, error)
+	// GetActiveProjects returns IDs of projects with nonzero storage or egress in [since, before), ordered by project ID.
+	GetActiveProjects(ctx context.Context, since, before time.Time) ([]uuid.UUID, error)
 	// GetBucketUsageRollups returns usage rollup per each bucket for specified period of time.
-	GetBucketUsageRollups(ctx context.Context, projectID uuid.UUID, since, before time.Time) ([]BucketUsageRollup, error)
+	// Egress includes archived bandwidth rollups.
+	GetBucketUsageRollups(ctx context.Context, projectID uuid.UUID, since, before time.Time, asOfSystemInterval time.Duration) ([]BucketUsageRollup, error)
+	// IterateBucketUsageRollups calls fn with usage rollup of each bucket for specified period of time.
+	IterateBucketUsageRollups(ctx context.Context, projectID uuid.UUID, since, before time.Time, asOfSystemInterval time.Duration, fn func(context.Context, BucketUsageRollup) error) error
+	// GetSingleBucketUsageRollup returns usage rollup of a single bucket for specified period of time.
+	GetSingleBucketUsageRollup(ctx context.Context, projectID uuid.UUID, bucketName string, since, before time.Time) (BucketUsageRollup, error)
+	// GetBucketBandwidthBreakdown returns allocated and settled GET bandwidth per bucket for specified period of time.
+	GetBucketBandwidthBreakdown(ctx context.Context, projectID uuid.UUID, cursor BucketBandwidthBreakdownCursor, since, before time.Time) (BucketBandwidthBreakdownPage, error)
+	// GetLatestBucketTallies returns the most recent tally of every bucket of the project.
+	GetLatestBucketTallies(ctx context.Context, projectID uuid.UUID) ([]BucketTally, error)
+	// GetProjectAccountingStatus returns the state of the accounting data of the project for diagnostics.
+	GetProjectAccountingStatus(ctx context.Context, projectID uuid.UUID) (ProjectAccountingStatus, error)
+	// GetProjectStorageTotals returns the storage of the project summed over the most recent tally of every bucket.
+	GetProjectStorageTotals(ctx context.Context, projectID uuid.UUID) (bytes, segments, objects int64, err error)
+	// GetProjectMetadataTotals returns the metadata bytes of every bucket of the project from its most recent tally,
+	// along with the total over all buckets.
+	GetProjectMetadataTotals(ctx context.Context, projectID uuid.UUID) (total int64, buckets map[string]int64, err error)
+	// GetBucketObjectCountSeries returns object count history of a bucket for specified period of time,
+	// ordered by interval start. When maxPoints is positive, tallies are downsampled to at most maxPoints points.
+	GetBucketObjectCountSeries(ctx context.Context, projectID uuid.UUID, bucketName string, since, before time.Time, maxPoints int) ([]BucketObjectCountPoint, error)
+	// GetBucketCount returns the number of buckets in the project.
+	GetBucketCount(ctx context.Context, projectID uuid.UUID) (int64, error)
+	// GetBucketCounts returns the number of buckets for each of the given projects.
+	GetBucketCounts(ctx context.Context, projectIDs []uuid.UUID) (map[uuid.UUID]int64, error)
 	// GetBucketTotals returns per bucket usage summary for specified period of time.
-	GetBucketTotals(ctx context.Context, projectID uuid.UUID, cursor BucketUsageCursor, since, before time.Time) (*BucketUsagePage, error)
+	GetBucketTotals(ctx context.Context, projectID uuid.UUID, cursor BucketUsageCursor, since, before time.Time, asOfSystemInterval time.Duration) (*BucketUsagePage, error)
+	// GetBucketTotalsGrouped returns bucket usage summary for specified period of time aggregated per group of buckets,
+	// which share the first component of the bucket name split on delimiter. BucketName of the usage is the group.
+	GetBucketTotalsGrouped(ctx context.Context, projectID uuid.UUID, delimiter string, since, before time.Time) ([]BucketUsage, error)
 	// ArchiveRollupsBefore archives rollups older than a given time and returns number of bucket bandwidth rollups archived.
 	ArchiveRollupsBefore(ctx context.Context, before time.Time, batchSize int) (numArchivedBucketBW int, err error)
+	// ArchiveRollupsBeforeWithStats archives rollups older than a given time and returns the number of bucket bandwidth
+	// rollups archived and the time it took per action.
+	ArchiveRollupsBeforeWithStats(ctx context.Context, before time.Time, batchSize int) (map[pb.PieceAction]RollupArchiveStats, error)
+	// ArchiveRollupsBeforeDryRun returns the number of bucket bandwidth rollups per action ArchiveRollupsBefore would archive.
+	ArchiveRollupsBeforeDryRun(ctx context.Context, before time.Time, batchSize int) (map[pb.PieceAction]int, error)
+	// RestoreRollupsSince moves archived bucket bandwidth rollups since a given time back and returns number of rollups restored.
+	// Amounts of archived rollups are added to already existing rollups.
+	RestoreRollupsSince(ctx context.Context, since time.Time) (restoredCount int, err error)
+	// ArchiveStorageTalliesBefore archives bucket storage tallies older than a given time and returns number of tallies archived.
+	ArchiveStorageTalliesBefore(ctx context.Context, before time.Time, batchSize int) (numArchivedTallies int, err error)
+	// ArchiveStorageTalliesBeforeDryRun returns the number of bucket storage tallies ArchiveStorageTalliesBefore would archive.
+	ArchiveStorageTalliesBeforeDryRun(ctx context.Context, before time.Time, batchSize int) (int, error)
+	// GetArchivedTalliesSince retrieves all archived bucket storage tallies since a given time. A hard limit batch size is used for results.
+	GetArchivedTalliesSince(ctx context.Context, since time.Time) ([]BucketStorageTally, error)
 	// GetRollupsSince retrieves all archived bandwidth rollup records since a given time. A hard limit batch size is used for results.
 	GetRollupsSince(ctx context.Context, since time.Time) ([]orders.BucketBandwidthRollup, error)
 	// GetArchivedRollupsSince retrieves all archived bandwidth rollup records since a given time. A hard limit batch size is used for results.
 	GetArchivedRollupsSince(ctx context.Context, since time.Time) ([]orders.BucketBandwidthRollup, error)
+	// GetArchivedRollupsPage returns a page of archived bandwidth rollup records since a given time,
+	// optionally filtered by project and action.
+	GetArchivedRollupsPage(ctx context.Context, cursor ArchivedRollupsCursor) (*ArchivedRollupsPage, error)
+	// ForEachRollupSince calls fn for every bandwidth rollup record since a given time. Records are read in batches
+	// and iteration stops when fn returns an error.
+	ForEachRollupSince(ctx context.Context, since time.Time, fn func(context.Context, orders.BucketBandwidthRollup) error) error
+	// ForEachArchivedRollupSince calls fn for every archived bandwidth rollup record since a given time. Records are
+	// read in batches and iteration stops when fn returns an error.
+	ForEachArchivedRollupSince(ctx context.Context, since time.Time, fn func(context.Context, orders.BucketBandwidthRollup) error) error
+	// GetRollupsSinceByProject retrieves all bandwidth rollup records of a project since a given time. A hard limit batch size is used for results.
+	GetRollupsSinceByProject(ctx context.Context, since time.Time, projectID uuid.UUID) ([]orders.BucketBandwidthRollup, error)
+	// GetArchivedRollupsSinceByProject retrieves all archived bandwidth rollup records of a project since a given time. A hard limit batch size is used for results.
+	GetArchivedRollupsSinceByProject(ctx context.Context, since time.Time, projectID uuid.UUID) ([]orders.BucketBandwidthRollup, error)
 }
 
 // Cache stores live information about project storage which has not yet been synced to ProjectAccounting.
@@ -4,8 +4,13 @@
 package accounting_test
 
 import (
+	"bytes"
+	"database/sql"
+	"errors"
 	"fmt"
 	"math"
+	"sort"
+	"strings"
 	"testing"
 	"time"
 
@@ -13,14 +18,18 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"storj.io/common/memory"
+	"storj.io/common/pb"
 	"storj.io/common/storj"
 	"storj.io/common/testcontext"
 	"storj.io/common/testrand"
 	"storj.io/common/uuid"
 	"storj.io/storj/satellite"
 	"storj.io/storj/satellite/accounting"
+	"storj.io/storj/satellite/attribution"
 	"storj.io/storj/satellite/console"
 	"storj.io/storj/satellite/metabase"
+	"storj.io/storj/satellite/orders"
+	"storj.io/storj/satellite/rewards"
 	"storj.io/storj/satellite/satellitedb/satellitedbtest"
 )
 
@@ -36,7 +45,7 @@ func TestSaveBucketTallies(t *testing.T) {
 		intervalStart := time.Now()
 		pdb := db.ProjectAccounting()
 
-		err = pdb.SaveTallies(ctx, intervalStart, bucketTallies)
+		_, _, err = pdb.SaveTallies(ctx, intervalStart, bucketTallies)
 		require.NoError(t, err)
 
 		tallies, err := pdb.GetTallies(ctx)
@@ -47,6 +56,421 @@ func TestSaveBucketTallies(t *testing.T) {
 	})
 }
 
+func TestSaveBucketTalliesTwice(t *testing.T) {
+	satellitedbtest.Run(t, func(ctx *testcontext.Context, t *testing.T, db satellite.DB) {
+		projectID := testrand.UUID()
+		intervalStart := time.Now()
+		pdb := db.ProjectAccounting()
+
+		talliesOf := func(first, last int, bytes int64) map[metabase.BucketLocation]*accounting.BucketTally {
+			tallies := make(map[metabase.BucketLocation]*accounting.BucketTally)
+			for i := first; i <= last; i++ {
+				location := metabase.BucketLocation{ProjectID: projectID, BucketName: fmt.Sprintf("testbucket%d", i)}
+				tallies[location] = &accounting.BucketTally{
+					BucketLocation: location,
+					ObjectCount:    bytes / 10,
+					TotalSegments:  bytes / 5,
+					TotalBytes:     bytes,
+					MetadataSize:   bytes / 100,
+				}
+			}
+			return tallies
+		}
+
+		// retried save of the same interval with overlapping buckets.
+		_, _, err := pdb.SaveTallies(ctx, intervalStart, talliesOf(0, 3, 1000))
+		require.NoError(t, err)
+		_, _, err = pdb.SaveTallies(ctx, intervalStart, talliesOf(2, 5, 2000))
+		require.NoError(t, err)
+
+		expected := talliesOf(0, 1, 1000)
+		for location, tally := range talliesOf(2, 5, 2000) {
+			expected[location] = tally
+		}
+
+		tallies, err := pdb.GetTallies(ctx)
+		require.NoError(t, err)
+		require.Len(t, tallies, len(expected))
+		for _, tally := range tallies {
+			require.Equal(t, *expected[tally.BucketLocation], tally)
+		}
+	})
+}
+
+func TestSaveBucketTalliesInvalid(t *testing.T) {
+	satellitedbtest.Run(t, func(ctx *testcontext.Context, t *testing.T, db satellite.DB) {
+		projectID := testrand.UUID()
+		pdb := db.ProjectAccounting()
+
+		bucketTallies, expectedTallies, err := createBucketStorageTallies(projectID)
+		require.NoError(t, err)
+
+		emptyName := metabase.BucketLocation{ProjectID: projectID}
+		zeroProject := metabase.BucketLocation{BucketName: "zero"}
+		negative := metabase.BucketLocation{ProjectID: projectID, BucketName: "negative"}
+		bucketTallies[emptyName] = &accounting.BucketTally{BucketLocation: emptyName, TotalBytes: 1}
+		bucketTallies[zeroProject] = &accounting.BucketTally{BucketLocation: zeroProject, TotalBytes: 1}
+		bucketTallies[negative] = &accounting.BucketTally{BucketLocation: negative, TotalBytes: -1}
+
+		saved, invalid, err := pdb.SaveTallies(ctx, time.Now(), bucketTallies)
+		require.NoError(t, err)
+		require.Equal(t, len(expectedTallies), saved)
+		require.Len(t, invalid, 3)
+		for _, location := range []metabase.BucketLocation{emptyName, zeroProject, negative} {
+			require.True(t, accounting.ErrInvalidArgument.Has(invalid[location]))
+		}
+
+		tallies, err := pdb.GetTallies(ctx)
+		require.NoError(t, err)
+		require.ElementsMatch(t, expectedTallies, tallies)
+	})
+}
+
+func TestGetTalliesSince(t *testing.T) {
+	satellitedbtest.Run(t, func(ctx *testcontext.Context, t *testing.T, db satellite.DB) {
+		now := time.Now().UTC().Truncate(time.Hour)
+		projectID := testrand.UUID()
+		pdb := db.ProjectAccounting()
+
+		bucketTallies, expectedTallies, err := createBucketStorageTallies(projectID)
+		require.NoError(t, err)
+
+		for _, intervalStart := range []time.Time{now.Add(-2 * time.Hour), now.Add(-time.Hour), now} {
+			_, _, err = pdb.SaveTallies(ctx, intervalStart, bucketTallies)
+			require.NoError(t, err)
+		}
+
+		all, err := pdb.GetTallies(ctx)
+		require.NoError(t, err)
+		require.Len(t, all, 3*len(expectedTallies))
+
+		tallies, err := pdb.GetTalliesSince(ctx, time.Time{})
+		require.NoError(t, err)
+		require.ElementsMatch(t, all, tallies)
+
+		tallies, err = pdb.GetTalliesSince(ctx, now.Add(-time.Hour))
+		require.NoError(t, err)
+		require.ElementsMatch(t, append(expectedTallies, expectedTallies...), tallies)
+
+		tallies, err = pdb.GetTalliesSince(ctx, now.Add(time.Minute))
+		require.NoError(t, err)
+		require.Empty(t, tallies)
+	})
+}
+
+func TestCreateBandwidthRollups(t *testing.T) {
+	satellitedbtest.Run(t, func(ctx *testcontext.Context, t *testing.T, db satellite.DB) {
+		now := time.Now().UTC().Truncate(time.Hour)
+		projectID := testrand.UUID()
+		pdb := db.ProjectAccounting()
+
+		get := orders.BucketBandwidthRollup{
+			ProjectID:  projectID,
+			BucketName: "bucket",
+			Action:     pb.PieceAction_GET,
+			Inline:     1,
+			Allocated:  10,
+			Settled:    5,
+		}
+		put := orders.BucketBandwidthRollup{
+			ProjectID:  projectID,
+			BucketName: "bucket",
+			Action:     pb.PieceAction_PUT,
+			Allocated:  20,
+			Settled:    20,
+		}
+
+		err := pdb.CreateBandwidthRollup(ctx, get, now, 0)
+		require.True(t, accounting.ErrInvalidArgument.Has(err))
+
+		err = pdb.CreateBandwidthRollup(ctx, get, now, 3600)
+		require.NoError(t, err)
+
+		rollups, err := pdb.GetRollupsSince(ctx, now)
+		require.NoError(t, err)
+		require.Equal(t, []orders.BucketBandwidthRollup{get}, rollups)
+
+		// duplicates within the batch and existing rollups are summed up.
+		err = pdb.CreateBandwidthRollups(ctx, []orders.BucketBandwidthRollup{get, put, get}, now, 3600)
+		require.NoError(t, err)
+
+		// rollups of another interval are kept separately.
+		err = pdb.CreateBandwidthRollups(ctx, []orders.BucketBandwidthRollup{put}, now.Add(-time.Hour), 3600)
+		require.NoError(t, err)
+
+		rollups, err = pdb.GetRollupsSince(ctx, now)
+		require.NoError(t, err)
+		require.ElementsMatch(t, []orders.BucketBandwidthRollup{
+			{
+				ProjectID:  projectID,
+				BucketName: "bucket",
+				Action:     pb.PieceAction_GET,
+				Inline:     3,
+				Allocated:  30,
+				Settled:    15,
+			},
+			put,
+		}, rollups)
+
+		rollups, err = pdb.GetRollupsSince(ctx, now.Add(-time.Hour))
+		require.NoError(t, err)
+		require.Len(t, rollups, 3)
+
+		require.NoError(t, pdb.CreateBandwidthRollups(ctx, nil, now, 3600))
+	})
+}
+
+func TestGetArchivedRollupsPage(t *testing.T) {
+	satellitedbtest.Run(t, func(ctx *testcontext.Context, t *testing.T, db satellite.DB) {
+		now := time.Now().UTC().Truncate(time.Hour)
+		first, second := now.Add(-2*time.Hour), now.Add(-time.Hour)
+		projectA, projectB := uuid.UUID{1}, uuid.UUID{2}
+		pdb := db.ProjectAccounting()
+
+		rollup := func(intervalStart time.Time, projectID uuid.UUID, bucketName string, action pb.PieceAction) accounting.ArchivedBandwidthRollup {
+			return accounting.ArchivedBandwidthRollup{
+				IntervalStart: intervalStart,
+				BucketBandwidthRollup: orders.BucketBandwidthRollup{
+					ProjectID:  projectID,
+					BucketName: bucketName,
+					Action:     action,
+					Allocated:  100,
+					Settled:    50,
+				},
+			}
+		}
+		// expected listing order.
+		all := []accounting.ArchivedBandwidthRollup{
+			rollup(first, projectA, "a", pb.PieceAction_PUT),
+			rollup(first, projectA, "a", pb.PieceAction_GET),
+			rollup(first, projectA, "b", pb.PieceAction_GET),
+			rollup(first, projectB, "a", pb.PieceAction_GET),
+			rollup(second, projectA, "a", pb.PieceAction_GET),
+			rollup(second, projectB, "a", pb.PieceAction_PUT),
+		}
+		for i := len(all) - 1; i >= 0; i-- {
+			err := pdb.CreateBandwidthRollup(ctx, all[i].BucketBandwidthRollup, all[i].IntervalStart, 3600)
+			require.NoError(t, err)
+		}
+		archived, err := pdb.ArchiveRollupsBefore(ctx, now, 100)
+		require.NoError(t, err)
+		require.Equal(t, len(all), archived)
+
+		listAll := func(cursor accounting.ArchivedRollupsCursor) (rollups []accounting.ArchivedBandwidthRollup, pages int) {
+			for {
+				page, err := pdb.GetArchivedRollupsPage(ctx, cursor)
+				require.NoError(t, err)
+				for _, rollup := range page.Rollups {
+					rollup.IntervalStart = rollup.IntervalStart.UTC()
+					rollups = append(rollups, rollup)
+				}
+				pages++
+				if page.Next == "" {
+					return rollups, pages
+				}
+				cursor.Next = page.Next
+			}
+		}
+
+		rollups, pages := listAll(accounting.ArchivedRollupsCursor{Limit: 2})
+		require.Equal(t, all, rollups)
+		require.Equal(t, 3, pages)
+
+		rollups, _ = listAll(accounting.ArchivedRollupsCursor{Since: second})
+		require.Equal(t, all[4:], rollups)
+
+		rollups, _ = listAll(accounting.ArchivedRollupsCursor{ProjectID: &projectB, Limit: 1})
+		require.Equal(t, []accounting.ArchivedBandwidthRollup{all[3], all[5]}, rollups)
+
+		get := pb.PieceAction_GET
+		rollups, _ = listAll(accounting.ArchivedRollupsCursor{ProjectID: &projectA, Action: &get, Limit: 2})
+		require.Equal(t, []accounting.ArchivedBandwidthRollup{all[1], all[2], all[4]}, rollups)
+
+		_, err = pdb.GetArchivedRollupsPage(ctx, accounting.ArchivedRollupsCursor{Next: "not a token"})
+		require.True(t, accounting.ErrInvalidArgument.Has(err))
+	})
+}
+
+func TestArchiveRollupsBeforeWithStats(t *testing.T) {
+	satellitedbtest.Run(t, func(ctx *testcontext.Context, t *testing.T, db satellite.DB) {
+		now := time.Now().UTC().Truncate(time.Hour)
+		projectID := testrand.UUID()
+		pdb := db.ProjectAccounting()
+
+		create := func(action pb.PieceAction, count int) {
+			for i := 0; i < count; i++ {
+				err := pdb.CreateBandwidthRollup(ctx, orders.BucketBandwidthRollup{
+					ProjectID:  projectID,
+					BucketName: fmt.Sprintf("bucket%d", i),
+					Action:     action,
+					Allocated:  100,
+				}, now.Add(-time.Hour), 3600)
+				require.NoError(t, err)
+			}
+		}
+		create(pb.PieceAction_GET, 2)
+		create(pb.PieceAction_GET_REPAIR, 3)
+
+		stats, err := pdb.ArchiveRollupsBeforeWithStats(ctx, now, 0)
+		require.NoError(t, err)
+		require.Empty(t, stats)
+
+		stats, err = pdb.ArchiveRollupsBeforeWithStats(ctx, now, 2)
+		require.NoError(t, err)
+		require.Len(t, stats, 2)
+		require.Equal(t, 2, stats[pb.PieceAction_GET].Count)
+		require.Equal(t, 3, stats[pb.PieceAction_GET_REPAIR].Count)
+
+		create(pb.PieceAction_PUT, 1)
+		archived, err := pdb.ArchiveRollupsBefore(ctx, now, 2)
+		require.NoError(t, err)
+		require.Equal(t, 1, archived)
+	})
+}
+
+func TestMaintenanceDryRun(t *testing.T) {
+	satellitedbtest.Run(t, func(ctx *testcontext.Context, t *testing.T, db satellite.DB) {
+		now := time.Now().UTC().Truncate(time.Hour)
+		cutoff := now.Add(-time.Hour)
+		yesterday := time.Date(now.Year(), now.Month(), now.Day()-1, 0, 0, 0, 0, time.UTC)
+		projectID := testrand.UUID()
+		pdb := db.ProjectAccounting()
+		sdb := db.StoragenodeAccounting()
+
+		for _, interval := range []time.Time{now.Add(-2 * time.Hour), cutoff, now} {
+			err := db.Orders().UpdateBucketBandwidthSettle(ctx, projectID, []byte("bucket"), pb.PieceAction_GET, 100, interval)
+			require.NoError(t, err)
+			err = db.Orders().UpdateStoragenodeBandwidthSettle(ctx, testrand.NodeID(), pb.PieceAction_GET, 100, interval)
+			require.NoError(t, err)
+			err = pdb.CreateStorageTally(ctx, accounting.BucketStorageTally{
+				BucketName:    "bucket",
+				ProjectID:     projectID,
+				IntervalStart: interval,
+				TotalBytes:    100,
+			})
+			require.NoError(t, err)
+		}
+		err := db.Orders().UpdateBucketBandwidthSettle(ctx, projectID, []byte("bucket"), pb.PieceAction_GET, 100, now.AddDate(0, 0, -2))
+		require.NoError(t, err)
+
+		// dry runs don't modify the data, hence they can be repeated.
+		for i := 0; i < 2; i++ {
+			bucketRollups, err := pdb.ArchiveRollupsBeforeDryRun(ctx, cutoff, 10)
+			require.NoError(t, err)
+			require.Equal(t, map[pb.PieceAction]int{pb.PieceAction_GET: 3}, bucketRollups)
+
+			nodeRollups, err := sdb.ArchiveRollupsBeforeDryRun(ctx, cutoff, 10)
+			require.NoError(t, err)
+			require.Equal(t, 2, nodeRollups)
+
+			// tallies are archived including the cutoff, but deleted excluding it.
+			tallies, err := pdb.ArchiveStorageTalliesBeforeDryRun(ctx, cutoff, 10)
+			require.NoError(t, err)
+			require.Equal(t, 2, tallies)
+
+			deletedTallies, err := pdb.DeleteTalliesBeforeDryRun(ctx, cutoff)
+			require.NoError(t, err)
+			require.EqualValues(t, 1, deletedTallies)
+
+			dailyRollups, err := pdb.DeleteProjectBandwidthBeforeDryRun(ctx, yesterday)
+			require.NoError(t, err)
+			require.EqualValues(t, 1, dailyRollups)
+		}
+
+		// nothing is archived with a non-positive batch size.
+		bucketRollups, err := pdb.ArchiveRollupsBeforeDryRun(ctx, cutoff, 0)
+		require.NoError(t, err)
+		require.Empty(t, bucketRollups)
+
+		archived, err := pdb.ArchiveRollupsBefore(ctx, cutoff, 10)
+		require.NoError(t, err)
+		require.Equal(t, 3, archived)
+
+		archived, err = sdb.ArchiveRollupsBefore(ctx, cutoff, 10)
+		require.NoError(t, err)
+		require.Equal(t, 2, archived)
+
+		deleted, err := pdb.DeleteTalliesBefore(ctx, cutoff, 10)
+		require.NoError(t, err)
+		require.EqualValues(t, 1, deleted)
+
+		deleted, err = pdb.DeleteProjectBandwidthBefore(ctx, yesterday, 10)
+		require.NoError(t, err)
+		require.EqualValues(t, 1, deleted)
+	})
+}
+
+func TestApplyRetention(t *testing.T) {
+	satellitedbtest.Run(t, func(ctx *testcontext.Context, t *testing.T, db satellite.DB) {
+		now := time.Date(2021, time.June, 15, 12, 0, 0, 0, time.UTC)
+		projectID := testrand.UUID()
+		pdb := db.ProjectAccounting()
+
+		// settling creates a daily rollup for each of the three days too.
+		for _, interval := range []time.Time{now.Add(-50 * time.Hour), now.Add(-30 * time.Hour), now.Add(-time.Hour)} {
+			err := db.Orders().UpdateBucketBandwidthSettle(ctx, projectID, []byte("bucket"), pb.PieceAction_GET, 100, interval)
+			require.NoError(t, err)
+			err = pdb.CreateStorageTally(ctx, accounting.BucketStorageTally{
+				BucketName:    "bucket",
+				ProjectID:     projectID,
+				IntervalStart: interval,
+				TotalBytes:    100,
+			})
+			require.NoError(t, err)
+		}
+
+		config := accounting.RetentionConfig{
+			BandwidthRollups:      accounting.TableRetention{Retention: 24 * time.Hour, Mode: accounting.RetentionArchive},
+			StorageTallies:        accounting.TableRetention{Retention: 24 * time.Hour, Mode: accounting.RetentionDelete},
+			DailyBandwidthRollups: accounting.TableRetention{Retention: 36 * time.Hour, Mode: accounting.RetentionDelete},
+			BatchSize:             10,
+		}
+
+		summary, err := pdb.ApplyRetention(ctx, config, now)
+		require.NoError(t, err)
+		require.Equal(t, accounting.RetentionSummary{
+			BandwidthRollups:      accounting.RetentionResult{Mode: accounting.RetentionArchive, Cutoff: now.Add(-24 * time.Hour), Rows: 2},
+			StorageTallies:        accounting.RetentionResult{Mode: accounting.RetentionDelete, Cutoff: now.Add(-24 * time.Hour), Rows: 2},
+			DailyBandwidthRollups: accounting.RetentionResult{Mode: accounting.RetentionDelete, Cutoff: time.Date(2021, time.June, 14, 0, 0, 0, 0, time.UTC), Rows: 1},
+		}, summary)
+
+		archived, err := pdb.GetArchivedRollupsSince(ctx, time.Time{})
+		require.NoError(t, err)
+		require.Len(t, archived, 2)
+
+		// applying the retention again finds nothing to do.
+		summary, err = pdb.ApplyRetention(ctx, config, now)
+		require.NoError(t, err)
+		require.Zero(t, summary.BandwidthRollups.Rows)
+		require.Zero(t, summary.StorageTallies.Rows)
+		require.Zero(t, summary.DailyBandwidthRollups.Rows)
+
+		// tables without retention are skipped.
+		summary, err = pdb.ApplyRetention(ctx, accounting.RetentionConfig{
+			BandwidthRollups: accounting.TableRetention{Retention: 30 * time.Minute, Mode: accounting.RetentionDelete},
+			BatchSize:        10,
+		}, now)
+		require.NoError(t, err)
+		require.Equal(t, accounting.RetentionSummary{
+			BandwidthRollups: accounting.RetentionResult{Mode: accounting.RetentionDelete, Cutoff: now.Add(-30 * time.Minute), Rows: 1},
+		}, summary)
+
+		rollups, err := pdb.GetRollupsSince(ctx, time.Time{})
+		require.NoError(t, err)
+		require.Empty(t, rollups)
+
+		for _, invalid := range []accounting.RetentionConfig{
+			{BatchSize: 0},
+			{BatchSize: 10, StorageTallies: accounting.TableRetention{Retention: -time.Hour}},
+			{BatchSize: 10, StorageTallies: accounting.TableRetention{Retention: time.Hour}},
+			{BatchSize: 10, DailyBandwidthRollups: accounting.TableRetention{Retention: time.Hour, Mode: accounting.RetentionArchive}},
+		} {
+			_, err := pdb.ApplyRetention(ctx, invalid, now)
+			require.True(t, accounting.ErrInvalidArgument.Has(err), err)
+		}
+	})
+}
+
 func TestStorageNodeUsage(t *testing.T) {
 	satellitedbtest.Run(t, func(ctx *testcontext.Context, t *testing.T, db satellite.DB) {
 		const days = 30
@@ -211,64 +635,1659 @@ func TestProjectLimits(t *testing.T) {
 			assert.NoError(t, err)
 			assert.Equal(t, memory.Size(3).Int64(), *bandwidthLimit)
 		})
+
+		t.Run("clear", func(t *testing.T) {
+			err = db.ProjectAccounting().ClearProjectUsageLimit(ctx, proj.ID)
+			require.NoError(t, err)
+
+			storageLimit, err := db.ProjectAccounting().GetProjectStorageLimit(ctx, proj.ID)
+			require.NoError(t, err)
+			require.Nil(t, storageLimit)
+
+			bandwidthLimit, err := db.ProjectAccounting().GetProjectBandwidthLimit(ctx, proj.ID)
+			require.NoError(t, err)
+			require.NotNil(t, bandwidthLimit)
+			require.Equal(t, memory.Size(3).Int64(), *bandwidthLimit)
+
+			err = db.ProjectAccounting().ClearProjectBandwidthLimit(ctx, proj.ID)
+			require.NoError(t, err)
+
+			bandwidthLimit, err = db.ProjectAccounting().GetProjectBandwidthLimit(ctx, proj.ID)
+			require.NoError(t, err)
+			require.Nil(t, bandwidthLimit)
+
+			// a cleared limit can be set again.
+			err = db.ProjectAccounting().UpdateProjectUsageLimit(ctx, proj.ID, 5)
+			require.NoError(t, err)
+
+			storageLimit, err = db.ProjectAccounting().GetProjectStorageLimit(ctx, proj.ID)
+			require.NoError(t, err)
+			require.NotNil(t, storageLimit)
+			require.Equal(t, memory.Size(5).Int64(), *storageLimit)
+		})
 	})
 }
 
-func createBucketStorageTallies(projectID uuid.UUID) (map[metabase.BucketLocation]*accounting.BucketTally, []accounting.BucketTally, error) {
-	bucketTallies := make(map[metabase.BucketLocation]*accounting.BucketTally)
-	var expectedTallies []accounting.BucketTally
+func TestUpdateProjectLimits(t *testing.T) {
+	satellitedbtest.Run(t, func(ctx *testcontext.Context, t *testing.T, db satellite.DB) {
+		proj, err := db.Console().Projects().Insert(ctx, &console.Project{Name: "test", OwnerID: testrand.UUID()})
+		require.NoError(t, err)
 
-	for i := 0; i < 4; i++ {
-		bucketName := fmt.Sprintf("%s%d", "testbucket", i)
-		bucketLocation := metabase.BucketLocation{
-			ProjectID:  projectID,
-			BucketName: bucketName,
-		}
+		limit := func(v int64) *int64 { return &v }
 
-		// Setup: The data in this tally should match the pointer that the uplink.upload created
-		tally := accounting.BucketTally{
-			BucketLocation: metabase.BucketLocation{
-				ProjectID:  projectID,
-				BucketName: bucketName,
+		for _, tt := range []struct {
+			name     string
+			update   accounting.ProjectLimits
+			expected accounting.ProjectLimits
+		}{
+			{
+				name:     "set both",
+				update:   accounting.ProjectLimits{Usage: limit(1), Bandwidth: limit(2)},
+				expected: accounting.ProjectLimits{Usage: limit(1), Bandwidth: limit(2)},
 			},
-			ObjectCount:   int64(1),
-			TotalSegments: int64(2),
-			TotalBytes:    int64(2),
-			MetadataSize:  int64(1),
-		}
-		bucketTallies[bucketLocation] = &tally
-		expectedTallies = append(expectedTallies, tally)
+			{
+				name:     "set usage only",
+				update:   accounting.ProjectLimits{Usage: limit(3)},
+				expected: accounting.ProjectLimits{Usage: limit(3), Bandwidth: limit(2)},
+			},
+			{
+				name:     "set bandwidth only",
+				update:   accounting.ProjectLimits{Bandwidth: limit(0)},
+				expected: accounting.ProjectLimits{Usage: limit(3), Bandwidth: limit(0)},
+			},
+			{
+				name:     "no changes",
+				update:   accounting.ProjectLimits{},
+				expected: accounting.ProjectLimits{Usage: limit(3), Bandwidth: limit(0)},
+			},
+			{
+				name:     "clear usage",
+				update:   accounting.ProjectLimits{Usage: limit(accounting.ClearLimit), Bandwidth: limit(4)},
+				expected: accounting.ProjectLimits{Bandwidth: limit(4)},
+			},
+			{
+				name:     "clear bandwidth",
+				update:   accounting.ProjectLimits{Bandwidth: limit(accounting.ClearLimit)},
+				expected: accounting.ProjectLimits{},
+			},
+		} {
+			err := db.ProjectAccounting().UpdateProjectLimits(ctx, proj.ID, tt.update)
+			require.NoError(t, err, tt.name)
 
-	}
-	return bucketTallies, expectedTallies, nil
+			limits, err := db.ProjectAccounting().GetProjectLimits(ctx, proj.ID)
+			require.NoError(t, err, tt.name)
+			require.Equal(t, tt.expected, limits, tt.name)
+		}
+	})
 }
 
-// make rollups and tallies for specified nodes and date range.
-func makeRollupsAndStorageNodeStorageTallies(nodes []storj.NodeID, start time.Time, days int) (accounting.RollupStats, map[time.Time]map[storj.NodeID]float64, time.Time) {
-	rollups := make(accounting.RollupStats)
-	tallies := make(map[time.Time]map[storj.NodeID]float64)
+func TestUpdateProjectLimitsBulk(t *testing.T) {
+	satellitedbtest.Run(t, func(ctx *testcontext.Context, t *testing.T, db satellite.DB) {
+		limit := func(v int64) *int64 { return &v }
 
-	const (
-		hours = 12
-	)
+		updated, err := db.ProjectAccounting().UpdateProjectLimitsBulk(ctx, nil, accounting.ProjectLimits{Usage: limit(1)})
+		require.NoError(t, err)
+		require.Zero(t, updated)
 
-	for i := 0; i < days; i++ {
-		startDay := time.Date(start.Year(), start.Month(), start.Day()+i, 0, 0, 0, 0, start.Location())
-		if rollups[startDay] == nil {
-			rollups[startDay] = make(map[storj.NodeID]*accounting.Rollup)
+		// only some of the project ids exist.
+		var existing, projectIDs []uuid.UUID
+		for i := 0; i < 1000; i++ {
+			if i%10 != 0 {
+				projectIDs = append(projectIDs, testrand.UUID())
+				continue
+			}
+			proj, err := db.Console().Projects().Insert(ctx, &console.Project{Name: fmt.Sprintf("test%d", i), OwnerID: testrand.UUID()})
+			require.NoError(t, err)
+			existing = append(existing, proj.ID)
+			projectIDs = append(projectIDs, proj.ID)
 		}
 
-		for _, node := range nodes {
-			rollup := &accounting.Rollup{
-				NodeID:    node,
-				StartTime: startDay,
-			}
+		other, err := db.Console().Projects().Insert(ctx, &console.Project{Name: "other", OwnerID: testrand.UUID()})
+		require.NoError(t, err)
+		err = db.ProjectAccounting().UpdateProjectLimits(ctx, other.ID, accounting.ProjectLimits{Usage: limit(5), Bandwidth: limit(6)})
+		require.NoError(t, err)
 
-			for h := 0; h < hours; h++ {
-				startTime := startDay.Add(time.Hour * time.Duration(h))
-				if tallies[startTime] == nil {
-					tallies[startTime] = make(map[storj.NodeID]float64)
-				}
+		updated, err = db.ProjectAccounting().UpdateProjectLimitsBulk(ctx, projectIDs, accounting.ProjectLimits{Usage: limit(1), Bandwidth: limit(2)})
+		require.NoError(t, err)
+		require.EqualValues(t, len(existing), updated)
+
+		updated, err = db.ProjectAccounting().UpdateProjectLimitsBulk(ctx, projectIDs, accounting.ProjectLimits{Usage: limit(accounting.ClearLimit)})
+		require.NoError(t, err)
+		require.EqualValues(t, len(existing), updated)
+
+		for _, projectID := range existing {
+			limits, err := db.ProjectAccounting().GetProjectLimits(ctx, projectID)
+			require.NoError(t, err)
+			require.Equal(t, accounting.ProjectLimits{Bandwidth: limit(2)}, limits)
+		}
+
+		limits, err := db.ProjectAccounting().GetProjectLimits(ctx, other.ID)
+		require.NoError(t, err)
+		require.Equal(t, accounting.ProjectLimits{Usage: limit(5), Bandwidth: limit(6)}, limits)
+	})
+}
+
+func TestGetProjectLimitsBatch(t *testing.T) {
+	satellitedbtest.Run(t, func(ctx *testcontext.Context, t *testing.T, db satellite.DB) {
+		limit := func(v int64) *int64 { return &v }
+
+		limited, err := db.Console().Projects().Insert(ctx, &console.Project{Name: "limited", OwnerID: testrand.UUID()})
+		require.NoError(t, err)
+		err = db.ProjectAccounting().UpdateProjectLimits(ctx, limited.ID, accounting.ProjectLimits{Usage: limit(1), Bandwidth: limit(2)})
+		require.NoError(t, err)
+
+		partial, err := db.Console().Projects().Insert(ctx, &console.Project{Name: "partial", OwnerID: testrand.UUID()})
+		require.NoError(t, err)
+		err = db.ProjectAccounting().UpdateProjectLimits(ctx, partial.ID, accounting.ProjectLimits{Usage: limit(3), Bandwidth: limit(accounting.ClearLimit)})
+		require.NoError(t, err)
+
+		unlimited, err := db.Console().Projects().Insert(ctx, &console.Project{Name: "unlimited", OwnerID: testrand.UUID()})
+		require.NoError(t, err)
+		err = db.ProjectAccounting().UpdateProjectLimits(ctx, unlimited.ID, accounting.ProjectLimits{Usage: limit(accounting.ClearLimit), Bandwidth: limit(accounting.ClearLimit)})
+		require.NoError(t, err)
+
+		limits, err := db.ProjectAccounting().GetProjectLimitsBatch(ctx, []uuid.UUID{limited.ID, partial.ID, unlimited.ID, testrand.UUID()})
+		require.NoError(t, err)
+		require.Equal(t, map[uuid.UUID]accounting.ProjectLimits{
+			limited.ID:   {Usage: limit(1), Bandwidth: limit(2)},
+			partial.ID:   {Usage: limit(3)},
+			unlimited.ID: {},
+		}, limits)
+
+		for projectID, expected := range limits {
+			single, err := db.ProjectAccounting().GetProjectLimits(ctx, projectID)
+			require.NoError(t, err)
+			require.Equal(t, expected, single)
+		}
+
+		limits, err = db.ProjectAccounting().GetProjectLimitsBatch(ctx, nil)
+		require.NoError(t, err)
+		require.Empty(t, limits)
+	})
+}
+
+func TestGetEffectiveProjectLimits(t *testing.T) {
+	satellitedbtest.Run(t, func(ctx *testcontext.Context, t *testing.T, db satellite.DB) {
+		limit := func(v int64) *int64 { return &v }
+		defaults := accounting.ProjectLimits{Usage: limit(10), Bandwidth: limit(20)}
+
+		proj, err := db.Console().Projects().Insert(ctx, &console.Project{Name: "test", OwnerID: testrand.UUID()})
+		require.NoError(t, err)
+
+		_, err = db.ProjectAccounting().GetEffectiveProjectLimits(ctx, proj.ID, accounting.ProjectLimits{Usage: limit(10)})
+		require.Error(t, err)
+
+		_, err = db.ProjectAccounting().GetEffectiveProjectLimits(ctx, testrand.UUID(), defaults)
+		require.Error(t, err)
+
+		limits, err := db.ProjectAccounting().GetEffectiveProjectLimits(ctx, proj.ID, defaults)
+		require.NoError(t, err)
+		require.Equal(t, accounting.EffectiveProjectLimits{
+			Usage: 10, Bandwidth: 20,
+			UsageDefault: true, BandwidthDefault: true,
+		}, limits)
+
+		err = db.ProjectAccounting().UpdateProjectLimits(ctx, proj.ID, accounting.ProjectLimits{Bandwidth: limit(0)})
+		require.NoError(t, err)
+
+		limits, err = db.ProjectAccounting().GetEffectiveProjectLimits(ctx, proj.ID, defaults)
+		require.NoError(t, err)
+		require.Equal(t, accounting.EffectiveProjectLimits{
+			Usage: 10, Bandwidth: 0,
+			UsageDefault: true, BandwidthDefault: false,
+		}, limits)
+
+		err = db.ProjectAccounting().UpdateProjectLimits(ctx, proj.ID, accounting.ProjectLimits{Usage: limit(5)})
+		require.NoError(t, err)
+
+		limits, err = db.ProjectAccounting().GetEffectiveProjectLimits(ctx, proj.ID, defaults)
+		require.NoError(t, err)
+		require.Equal(t, accounting.EffectiveProjectLimits{Usage: 5, Bandwidth: 0}, limits)
+
+		// the raw limits are unchanged.
+		raw, err := db.ProjectAccounting().GetProjectLimits(ctx, proj.ID)
+		require.NoError(t, err)
+		require.Equal(t, accounting.ProjectLimits{Usage: limit(5), Bandwidth: limit(0)}, raw)
+	})
+}
+
+func TestGetProjectBandwidthUsageAndLimit(t *testing.T) {
+	satellitedbtest.Run(t, func(ctx *testcontext.Context, t *testing.T, db satellite.DB) {
+		now := time.Now().UTC()
+		limit := func(v int64) *int64 { return &v }
+
+		proj, err := db.Console().Projects().Insert(ctx, &console.Project{Name: "test", OwnerID: testrand.UUID()})
+		require.NoError(t, err)
+
+		used, bandwidthLimit, err := db.ProjectAccounting().GetProjectBandwidthUsageAndLimit(ctx, proj.ID, now.Year(), now.Month(), now.Day(), 0)
+		require.NoError(t, err)
+		require.Zero(t, used)
+		require.Nil(t, bandwidthLimit)
+
+		err = db.Orders().UpdateBucketBandwidthAllocation(ctx, proj.ID, []byte("bucket"), pb.PieceAction_GET, 1000, now)
+		require.NoError(t, err)
+		err = db.Orders().UpdateBucketBandwidthSettle(ctx, proj.ID, []byte("bucket"), pb.PieceAction_GET, 500, now)
+		require.NoError(t, err)
+		err = db.ProjectAccounting().UpdateProjectBandwidthLimit(ctx, proj.ID, 5*memory.KB)
+		require.NoError(t, err)
+
+		expected, err := db.ProjectAccounting().GetProjectBandwidth(ctx, proj.ID, now.Year(), now.Month(), now.Day(), 0)
+		require.NoError(t, err)
+		require.EqualValues(t, 1000, expected)
+
+		used, bandwidthLimit, err = db.ProjectAccounting().GetProjectBandwidthUsageAndLimit(ctx, proj.ID, now.Year(), now.Month(), now.Day(), 0)
+		require.NoError(t, err)
+		require.Equal(t, expected, used)
+		require.Equal(t, limit(5*memory.KB.Int64()), bandwidthLimit)
+
+		_, _, err = db.ProjectAccounting().GetProjectBandwidthUsageAndLimit(ctx, testrand.UUID(), now.Year(), now.Month(), now.Day(), 0)
+		require.Error(t, err)
+	})
+}
+
+func TestGetProjectCycleBandwidth(t *testing.T) {
+	satellitedbtest.Run(t, func(ctx *testcontext.Context, t *testing.T, db satellite.DB) {
+		date := func(year int, month time.Month, day int) time.Time {
+			return time.Date(year, month, day, 12, 0, 0, 0, time.UTC)
+		}
+
+		// every day of usage allocates 1000 bytes and settles 500 bytes.
+		createUsage := func(days ...time.Time) uuid.UUID {
+			projectID := testrand.UUID()
+			for _, day := range days {
+				err := db.Orders().UpdateBucketBandwidthAllocation(ctx, projectID, []byte("bucket"), pb.PieceAction_GET, 1000, day)
+				require.NoError(t, err)
+				err = db.Orders().UpdateBucketBandwidthSettle(ctx, projectID, []byte("bucket"), pb.PieceAction_GET, 500, day)
+				require.NoError(t, err)
+			}
+			return projectID
+		}
+
+		for _, tt := range []struct {
+			name          string
+			usage         []time.Time
+			now           time.Time
+			cycleStartDay int
+			expected      int64
+		}{
+			{
+				name:          "month boundary",
+				usage:         []time.Time{date(2021, 2, 14), date(2021, 2, 15), date(2021, 3, 4), date(2021, 3, 15)},
+				now:           date(2021, 3, 5),
+				cycleStartDay: 15,
+				expected:      500 + 1000,
+			},
+			{
+				name:          "february",
+				usage:         []time.Time{date(2021, 1, 27), date(2021, 1, 28), date(2021, 2, 27), date(2021, 2, 28)},
+				now:           date(2021, 2, 27),
+				cycleStartDay: 28,
+				expected:      500 + 1000,
+			},
+			{
+				name:          "start of cycle in february",
+				usage:         []time.Time{date(2021, 2, 27), date(2021, 2, 28), date(2021, 3, 1)},
+				now:           date(2021, 3, 1),
+				cycleStartDay: 28,
+				expected:      1000 + 1000,
+			},
+			{
+				name:          "first day of month",
+				usage:         []time.Time{date(2021, 1, 31), date(2021, 2, 1), date(2021, 2, 10), date(2021, 3, 1)},
+				now:           date(2021, 2, 10),
+				cycleStartDay: 1,
+				expected:      500 + 1000,
+			},
+		} {
+			projectID := createUsage(tt.usage...)
+
+			bandwidth, err := db.ProjectAccounting().GetProjectCycleBandwidth(ctx, projectID, tt.now.Year(), tt.now.Month(), tt.now.Day(), tt.cycleStartDay, 0)
+			require.NoError(t, err, tt.name)
+			require.Equal(t, tt.expected, bandwidth, tt.name)
+
+			if tt.cycleStartDay == 1 {
+				bandwidth, err = db.ProjectAccounting().GetProjectBandwidth(ctx, projectID, tt.now.Year(), tt.now.Month(), tt.now.Day(), 0)
+				require.NoError(t, err, tt.name)
+				require.Equal(t, tt.expected, bandwidth, tt.name)
+			}
+		}
+
+		for _, cycleStartDay := range []int{-1, 0, 29, 31} {
+			_, err := db.ProjectAccounting().GetProjectCycleBandwidth(ctx, testrand.UUID(), 2021, 2, 1, cycleStartDay, 0)
+			require.True(t, accounting.ErrInvalidArgument.Has(err), cycleStartDay)
+		}
+	})
+}
+
+func TestGetProjectBandwidthBreakdown(t *testing.T) {
+	satellitedbtest.Run(t, func(ctx *testcontext.Context, t *testing.T, db satellite.DB) {
+		projectID := testrand.UUID()
+		now := time.Date(2021, 3, 10, 12, 0, 0, 0, time.UTC)
+
+		// every day of usage allocates 1000 bytes and settles 500 bytes.
+		for _, day := range []time.Time{
+			now.AddDate(0, 0, -10), // previous month
+			now.AddDate(0, 0, -9),
+			now.AddDate(0, 0, -5),
+			now.AddDate(0, 0, -2), // within the expiration window
+			now.AddDate(0, 0, -1),
+		} {
+			err := db.Orders().UpdateBucketBandwidthAllocation(ctx, projectID, []byte("bucket"), pb.PieceAction_GET, 1000, day)
+			require.NoError(t, err)
+			err = db.Orders().UpdateBucketBandwidthSettle(ctx, projectID, []byte("bucket"), pb.PieceAction_GET, 500, day)
+			require.NoError(t, err)
+		}
+
+		breakdown, err := db.ProjectAccounting().GetProjectBandwidthBreakdown(ctx, projectID, now.Year(), now.Month(), now.Day(), 0)
+		require.NoError(t, err)
+		require.Equal(t, accounting.ProjectBandwidthBreakdown{
+			AllocatedTotal: 4 * 1000,
+			SettledTotal:   4 * 500,
+			Used:           2*500 + 2*1000,
+		}, breakdown)
+
+		used, err := db.ProjectAccounting().GetProjectBandwidth(ctx, projectID, now.Year(), now.Month(), now.Day(), 0)
+		require.NoError(t, err)
+		require.Equal(t, breakdown.Used, used)
+
+		breakdown, err = db.ProjectAccounting().GetProjectBandwidthBreakdown(ctx, testrand.UUID(), now.Year(), now.Month(), now.Day(), 0)
+		require.NoError(t, err)
+		require.Zero(t, breakdown)
+	})
+}
+
+func TestApplyProjectBandwidthAdjustment(t *testing.T) {
+	satellitedbtest.Run(t, func(ctx *testcontext.Context, t *testing.T, db satellite.DB) {
+		pdb := db.ProjectAccounting()
+		projectID := testrand.UUID()
+		now := time.Now().UTC()
+		day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+		since := now.Add(-time.Minute)
+
+		err := db.Orders().UpdateBucketBandwidthAllocation(ctx, projectID, []byte("bucket"), pb.PieceAction_GET, 1000, now)
+		require.NoError(t, err)
+		err = db.Orders().UpdateBucketBandwidthSettle(ctx, projectID, []byte("bucket"), pb.PieceAction_GET, 500, now)
+		require.NoError(t, err)
+
+		adjustment, err := pdb.ApplyProjectBandwidthAdjustment(ctx, projectID, now, -300, -200, "accidental egress")
+		require.NoError(t, err)
+		require.Equal(t, day, adjustment.Day)
+		require.EqualValues(t, -300, adjustment.AppliedAllocated)
+		require.EqualValues(t, -200, adjustment.AppliedSettled)
+
+		allocated, settled, err := pdb.GetProjectDailyBandwidth(ctx, projectID, now.Year(), now.Month(), now.Day())
+		require.NoError(t, err)
+		require.EqualValues(t, 700, allocated)
+		require.EqualValues(t, 300, settled)
+
+		used, err := pdb.GetProjectBandwidth(ctx, projectID, now.Year(), now.Month(), now.Day(), 0)
+		require.NoError(t, err)
+		require.EqualValues(t, 700, used)
+
+		// the bandwidth is clamped at zero and the clamped part is recorded.
+		adjustment, err = pdb.ApplyProjectBandwidthAdjustment(ctx, projectID, now, -1000, -100, "support credit")
+		require.NoError(t, err)
+		require.EqualValues(t, -700, adjustment.AppliedAllocated)
+		require.EqualValues(t, -100, adjustment.AppliedSettled)
+
+		used, err = pdb.GetProjectBandwidth(ctx, projectID, now.Year(), now.Month(), now.Day(), 0)
+		require.NoError(t, err)
+		require.Zero(t, used)
+
+		// days without bandwidth get a rollup.
+		previousDay := day.AddDate(0, 0, -1)
+		_, err = pdb.ApplyProjectBandwidthAdjustment(ctx, projectID, previousDay, 100, 50, "missing rollup")
+		require.NoError(t, err)
+		allocated, settled, err = pdb.GetProjectDailyBandwidth(ctx, projectID, previousDay.Year(), previousDay.Month(), previousDay.Day())
+		require.NoError(t, err)
+		require.EqualValues(t, 100, allocated)
+		require.EqualValues(t, 50, settled)
+
+		adjustments, err := pdb.GetProjectBandwidthAdjustments(ctx, projectID, since)
+		require.NoError(t, err)
+		require.Len(t, adjustments, 3)
+		for i, expected := range []struct {
+			day                                  time.Time
+			requestedAllocated, requestedSettled int64
+			appliedAllocated, appliedSettled     int64
+			reason                               string
+		}{
+			{day, -300, -200, -300, -200, "accidental egress"},
+			{day, -1000, -100, -700, -100, "support credit"},
+			{previousDay, 100, 50, 100, 50, "missing rollup"},
+		} {
+			assert.Equal(t, projectID, adjustments[i].ProjectID)
+			assert.Equal(t, expected.day, adjustments[i].Day.UTC())
+			assert.Equal(t, expected.requestedAllocated, adjustments[i].RequestedAllocated)
+			assert.Equal(t, expected.requestedSettled, adjustments[i].RequestedSettled)
+			assert.Equal(t, expected.appliedAllocated, adjustments[i].AppliedAllocated)
+			assert.Equal(t, expected.appliedSettled, adjustments[i].AppliedSettled)
+			assert.Equal(t, expected.reason, adjustments[i].Reason)
+		}
+
+		_, err = pdb.ApplyProjectBandwidthAdjustment(ctx, projectID, now, -1, 0, "")
+		require.True(t, accounting.ErrInvalidArgument.Has(err))
+		_, err = pdb.ApplyProjectBandwidthAdjustment(ctx, projectID, now, 0, 0, "nothing")
+		require.True(t, accounting.ErrInvalidArgument.Has(err))
+	})
+}
+
+func TestProjectLimitHistory(t *testing.T) {
+	satellitedbtest.Run(t, func(ctx *testcontext.Context, t *testing.T, db satellite.DB) {
+		limit := func(v int64) *int64 { return &v }
+		since := time.Now().Add(-time.Minute)
+
+		proj, err := db.Console().Projects().Insert(ctx, &console.Project{Name: "test", OwnerID: testrand.UUID()})
+		require.NoError(t, err)
+		other, err := db.Console().Projects().Insert(ctx, &console.Project{Name: "other", OwnerID: testrand.UUID()})
+		require.NoError(t, err)
+
+		// changes without an actor are not recorded.
+		err = db.ProjectAccounting().UpdateProjectUsageLimit(ctx, proj.ID, memory.GB)
+		require.NoError(t, err)
+
+		history, err := db.ProjectAccounting().GetProjectLimitHistory(ctx, proj.ID, since)
+		require.NoError(t, err)
+		require.Empty(t, history)
+
+		adminCtx := accounting.WithLimitChangeActor(ctx, "admin")
+		err = db.ProjectAccounting().UpdateProjectLimits(adminCtx, proj.ID, accounting.ProjectLimits{Usage: limit(memory.GB.Int64()), Bandwidth: limit(2)})
+		require.NoError(t, err)
+		err = db.ProjectAccounting().ClearProjectUsageLimit(adminCtx, proj.ID)
+		require.NoError(t, err)
+
+		updated, err := db.ProjectAccounting().UpdateProjectLimitsBulk(accounting.WithLimitChangeActor(ctx, "bulk"),
+			[]uuid.UUID{proj.ID, other.ID}, accounting.ProjectLimits{Bandwidth: limit(3)})
+		require.NoError(t, err)
+		require.EqualValues(t, 2, updated)
+
+		history, err = db.ProjectAccounting().GetProjectLimitHistory(ctx, proj.ID, since)
+		require.NoError(t, err)
+		require.Len(t, history, 3)
+
+		for _, change := range history {
+			require.Equal(t, proj.ID, change.ProjectID)
+			require.False(t, change.ChangedAt.Before(since))
+		}
+
+		// unchanged usage limit is not recorded.
+		require.Equal(t, accounting.LimitFieldBandwidth, history[0].Field)
+		require.Nil(t, history[0].OldValue)
+		require.Equal(t, limit(2), history[0].NewValue)
+		require.Equal(t, "admin", history[0].Actor)
+
+		require.Equal(t, accounting.LimitFieldUsage, history[1].Field)
+		require.Equal(t, limit(memory.GB.Int64()), history[1].OldValue)
+		require.Nil(t, history[1].NewValue)
+		require.Equal(t, "admin", history[1].Actor)
+
+		require.Equal(t, accounting.LimitFieldBandwidth, history[2].Field)
+		require.Equal(t, limit(2), history[2].OldValue)
+		require.Equal(t, limit(3), history[2].NewValue)
+		require.Equal(t, "bulk", history[2].Actor)
+
+		history, err = db.ProjectAccounting().GetProjectLimitHistory(ctx, other.ID, since)
+		require.NoError(t, err)
+		require.Len(t, history, 1)
+
+		history, err = db.ProjectAccounting().GetProjectLimitHistory(ctx, proj.ID, time.Now().Add(time.Minute))
+		require.NoError(t, err)
+		require.Empty(t, history)
+	})
+}
+
+func TestBucketBandwidthLimit(t *testing.T) {
+	satellitedbtest.Run(t, func(ctx *testcontext.Context, t *testing.T, db satellite.DB) {
+		proj, err := db.Console().Projects().Insert(ctx, &console.Project{Name: "test", OwnerID: testrand.UUID()})
+		require.NoError(t, err)
+
+		_, err = db.Buckets().CreateBucket(ctx, storj.Bucket{
+			ID:        testrand.UUID(),
+			Name:      "public",
+			ProjectID: proj.ID,
+		})
+		require.NoError(t, err)
+
+		limit, err := db.ProjectAccounting().GetBucketBandwidthLimit(ctx, proj.ID, "public")
+		require.NoError(t, err)
+		require.Nil(t, limit)
+
+		err = db.ProjectAccounting().UpdateBucketBandwidthLimit(ctx, proj.ID, "public", 10*memory.GB)
+		require.NoError(t, err)
+
+		limit, err = db.ProjectAccounting().GetBucketBandwidthLimit(ctx, proj.ID, "public")
+		require.NoError(t, err)
+		require.NotNil(t, limit)
+		require.Equal(t, (10 * memory.GB).Int64(), *limit)
+
+		err = db.ProjectAccounting().ClearBucketBandwidthLimit(ctx, proj.ID, "public")
+		require.NoError(t, err)
+
+		limit, err = db.ProjectAccounting().GetBucketBandwidthLimit(ctx, proj.ID, "public")
+		require.NoError(t, err)
+		require.Nil(t, limit)
+
+		err = db.ProjectAccounting().UpdateBucketBandwidthLimit(ctx, proj.ID, "missing", memory.GB)
+		require.True(t, storj.ErrBucketNotFound.Has(err))
+		_, err = db.ProjectAccounting().GetBucketBandwidthLimit(ctx, proj.ID, "missing")
+		require.True(t, storj.ErrBucketNotFound.Has(err))
+
+		now := time.Now().UTC()
+		from := now.Add(-24 * time.Hour)
+		for _, bucket := range []string{"public", "other"} {
+			err = db.Orders().UpdateBucketBandwidthAllocation(ctx, proj.ID, []byte(bucket), pb.PieceAction_GET, 100, now)
+			require.NoError(t, err)
+		}
+		err = db.Orders().UpdateBucketBandwidthAllocation(ctx, proj.ID, []byte("public"), pb.PieceAction_PUT, 100, now)
+		require.NoError(t, err)
+		err = db.Orders().UpdateBucketBandwidthAllocation(ctx, proj.ID, []byte("public"), pb.PieceAction_GET, 100, from.Add(-time.Hour))
+		require.NoError(t, err)
+
+		usage, err := db.ProjectAccounting().GetBucketBandwidthUsage(ctx, proj.ID, "public", from)
+		require.NoError(t, err)
+		require.EqualValues(t, 100, usage)
+
+		usage, err = db.ProjectAccounting().GetBucketBandwidthUsage(ctx, proj.ID, "unused", from)
+		require.NoError(t, err)
+		require.Zero(t, usage)
+	})
+}
+
+func TestBucketStorageLimit(t *testing.T) {
+	satellitedbtest.Run(t, func(ctx *testcontext.Context, t *testing.T, db satellite.DB) {
+		proj, err := db.Console().Projects().Insert(ctx, &console.Project{Name: "test", OwnerID: testrand.UUID()})
+		require.NoError(t, err)
+
+		_, err = db.Buckets().CreateBucket(ctx, storj.Bucket{
+			ID:        testrand.UUID(),
+			Name:      "uploads",
+			ProjectID: proj.ID,
+		})
+		require.NoError(t, err)
+
+		limit, err := db.ProjectAccounting().GetBucketStorageLimit(ctx, proj.ID, "uploads")
+		require.NoError(t, err)
+		require.Nil(t, limit)
+
+		err = db.ProjectAccounting().UpdateBucketStorageLimit(ctx, proj.ID, "uploads", 100*memory.GB)
+		require.NoError(t, err)
+
+		limit, err = db.ProjectAccounting().GetBucketStorageLimit(ctx, proj.ID, "uploads")
+		require.NoError(t, err)
+		require.NotNil(t, limit)
+		require.Equal(t, (100 * memory.GB).Int64(), *limit)
+
+		// the bandwidth limit is independent.
+		bandwidthLimit, err := db.ProjectAccounting().GetBucketBandwidthLimit(ctx, proj.ID, "uploads")
+		require.NoError(t, err)
+		require.Nil(t, bandwidthLimit)
+
+		err = db.ProjectAccounting().ClearBucketStorageLimit(ctx, proj.ID, "uploads")
+		require.NoError(t, err)
+
+		limit, err = db.ProjectAccounting().GetBucketStorageLimit(ctx, proj.ID, "uploads")
+		require.NoError(t, err)
+		require.Nil(t, limit)
+
+		err = db.ProjectAccounting().UpdateBucketStorageLimit(ctx, proj.ID, "missing", memory.GB)
+		require.True(t, storj.ErrBucketNotFound.Has(err))
+
+		usage, err := db.ProjectAccounting().GetBucketStorageUsage(ctx, proj.ID, "uploads")
+		require.NoError(t, err)
+		require.Zero(t, usage)
+
+		now := time.Now().UTC().Truncate(time.Hour)
+		for i, totalBytes := range []int64{3000, 2000, 1000} {
+			err := db.ProjectAccounting().CreateStorageTally(ctx, accounting.BucketStorageTally{
+				BucketName:    "uploads",
+				ProjectID:     proj.ID,
+				IntervalStart: now.Add(-time.Duration(i) * time.Hour),
+				TotalBytes:    totalBytes,
+			})
+			require.NoError(t, err)
+		}
+
+		usage, err = db.ProjectAccounting().GetBucketStorageUsage(ctx, proj.ID, "uploads")
+		require.NoError(t, err)
+		require.EqualValues(t, 3000, usage)
+	})
+}
+
+func TestProjectExemptFromBilling(t *testing.T) {
+	satellitedbtest.Run(t, func(ctx *testcontext.Context, t *testing.T, db satellite.DB) {
+		proj, err := db.Console().Projects().Insert(ctx, &console.Project{Name: "test", OwnerID: testrand.UUID()})
+		require.NoError(t, err)
+
+		exempt, err := db.ProjectAccounting().GetProjectExemptFromBilling(ctx, proj.ID)
+		require.NoError(t, err)
+		require.False(t, exempt)
+
+		err = db.ProjectAccounting().UpdateProjectExemptFromBilling(ctx, proj.ID, true)
+		require.NoError(t, err)
+
+		exempt, err = db.ProjectAccounting().GetProjectExemptFromBilling(ctx, proj.ID)
+		require.NoError(t, err)
+		require.True(t, exempt)
+
+		// per-project usage is still available for exempt projects.
+		now := time.Now()
+		_, err = db.ProjectAccounting().GetProjectTotal(ctx, proj.ID, now.Add(-time.Hour), now, 0)
+		require.NoError(t, err)
+
+		_, err = db.ProjectAccounting().GetProjectExemptFromBilling(ctx, testrand.UUID())
+		require.True(t, errors.Is(err, accounting.ErrProjectNotFound), err)
+
+		err = db.ProjectAccounting().UpdateProjectExemptFromBilling(ctx, testrand.UUID(), true)
+		require.True(t, errors.Is(err, accounting.ErrProjectNotFound), err)
+	})
+}
+
+func TestGetBucketCounts(t *testing.T) {
+	satellitedbtest.Run(t, func(ctx *testcontext.Context, t *testing.T, db satellite.DB) {
+		var projects []uuid.UUID
+		for i := 0; i < 3; i++ {
+			proj, err := db.Console().Projects().Insert(ctx, &console.Project{Name: "test", OwnerID: testrand.UUID()})
+			require.NoError(t, err)
+			projects = append(projects, proj.ID)
+		}
+		projectA, projectB, projectEmpty := projects[0], projects[1], projects[2]
+
+		for i := 0; i < 3; i++ {
+			_, err := db.Buckets().CreateBucket(ctx, storj.Bucket{
+				ID:        testrand.UUID(),
+				Name:      fmt.Sprintf("bucket%d", i),
+				ProjectID: projectA,
+			})
+			require.NoError(t, err)
+		}
+		_, err := db.Buckets().CreateBucket(ctx, storj.Bucket{
+			ID:        testrand.UUID(),
+			Name:      "bucket",
+			ProjectID: projectB,
+		})
+		require.NoError(t, err)
+
+		count, err := db.ProjectAccounting().GetBucketCount(ctx, projectA)
+		require.NoError(t, err)
+		require.EqualValues(t, 3, count)
+
+		count, err = db.ProjectAccounting().GetBucketCount(ctx, projectEmpty)
+		require.NoError(t, err)
+		require.Zero(t, count)
+
+		counts, err := db.ProjectAccounting().GetBucketCounts(ctx, []uuid.UUID{projectA, projectB, projectEmpty})
+		require.NoError(t, err)
+		require.Equal(t, map[uuid.UUID]int64{
+			projectA:     3,
+			projectB:     1,
+			projectEmpty: 0,
+		}, counts)
+
+		counts, err = db.ProjectAccounting().GetBucketCounts(ctx, nil)
+		require.NoError(t, err)
+		require.Empty(t, counts)
+	})
+}
+
+func TestGetProjectTotals(t *testing.T) {
+	satellitedbtest.Run(t, func(ctx *testcontext.Context, t *testing.T, db satellite.DB) {
+		now := time.Now().UTC().Truncate(time.Hour)
+		since, before := now.Add(-10*time.Hour), now
+
+		projectIDs := []uuid.UUID{testrand.UUID(), testrand.UUID(), testrand.UUID()}
+		for i, projectID := range projectIDs[:2] {
+			createProjectUsage(ctx, t, db, projectID, 2+i, since, before)
+		}
+
+		totals, err := db.ProjectAccounting().GetProjectTotals(ctx, projectIDs, since, before)
+		require.NoError(t, err)
+		require.Len(t, totals, len(projectIDs))
+
+		for _, projectID := range projectIDs {
+			expected, err := db.ProjectAccounting().GetProjectTotal(ctx, projectID, since, before, 0)
+			require.NoError(t, err)
+
+			actual := totals[projectID]
+			require.NotNil(t, actual)
+			require.Equal(t, expected.Egress, actual.Egress)
+			require.InDelta(t, expected.Storage, actual.Storage, 1e-6)
+			require.InDelta(t, expected.ObjectCount, actual.ObjectCount, 1e-6)
+			require.Equal(t, expected.Since, actual.Since)
+			require.Equal(t, expected.Before, actual.Before)
+		}
+
+		// the project without any usage is reported as zero.
+		require.Zero(t, totals[projectIDs[2]].Storage)
+		require.Zero(t, totals[projectIDs[2]].Egress)
+	})
+}
+
+func TestGetProjectEgressByAction(t *testing.T) {
+	satellitedbtest.Run(t, func(ctx *testcontext.Context, t *testing.T, db satellite.DB) {
+		now := time.Now().UTC().Truncate(time.Hour)
+		since, before := now.Add(-time.Hour), now
+		projectID := testrand.UUID()
+		pdb := db.ProjectAccounting()
+
+		rollup := func(action pb.PieceAction, settled int64) orders.BucketBandwidthRollup {
+			return orders.BucketBandwidthRollup{
+				ProjectID:  projectID,
+				BucketName: "bucket",
+				Action:     action,
+				Inline:     1,
+				Allocated:  2 * settled,
+				Settled:    settled,
+			}
+		}
+
+		err := pdb.CreateBandwidthRollups(ctx, []orders.BucketBandwidthRollup{
+			rollup(pb.PieceAction_GET, 100),
+			rollup(pb.PieceAction_GET_AUDIT, 200),
+			rollup(pb.PieceAction_GET_REPAIR, 300),
+			rollup(pb.PieceAction_PUT, 400),
+			rollup(pb.PieceAction(100), 500),
+			rollup(pb.PieceAction(101), 600),
+		}, since, 3600)
+		require.NoError(t, err)
+
+		// outside of the period.
+		err = pdb.CreateBandwidthRollup(ctx, rollup(pb.PieceAction_GET, 1000), since.Add(-time.Hour), 3600)
+		require.NoError(t, err)
+
+		egress, err := pdb.GetProjectEgressByAction(ctx, projectID, since, before)
+		require.NoError(t, err)
+		require.Equal(t, map[pb.PieceAction]int64{
+			pb.PieceAction_GET:           101,
+			pb.PieceAction_GET_AUDIT:     201,
+			pb.PieceAction_GET_REPAIR:    301,
+			pb.PieceAction_PUT:           401,
+			accounting.OtherPieceActions: 1102,
+		}, egress)
+
+		usage, err := pdb.GetProjectTotal(ctx, projectID, since, before, 0)
+		require.NoError(t, err)
+		require.EqualValues(t, 101, usage.Egress)
+		require.EqualValues(t, 101, usage.GetEgress)
+		require.EqualValues(t, 201, usage.AuditEgress)
+		require.EqualValues(t, 301, usage.RepairEgress)
+
+		egress, err = pdb.GetProjectEgressByAction(ctx, testrand.UUID(), since, before)
+		require.NoError(t, err)
+		require.Empty(t, egress)
+	})
+}
+
+func TestGetProjectHourlyBandwidth(t *testing.T) {
+	satellitedbtest.Run(t, func(ctx *testcontext.Context, t *testing.T, db satellite.DB) {
+		now := time.Now().UTC().Truncate(time.Hour)
+		from := now.Add(-3 * time.Hour)
+		projectID := testrand.UUID()
+		pdb := db.ProjectAccounting()
+
+		rollup := func(bucket string, action pb.PieceAction, settled int64) orders.BucketBandwidthRollup {
+			return orders.BucketBandwidthRollup{
+				ProjectID:  projectID,
+				BucketName: bucket,
+				Action:     action,
+				Inline:     1,
+				Allocated:  2 * settled,
+				Settled:    settled,
+			}
+		}
+
+		// the oldest hour gets archived.
+		require.NoError(t, pdb.CreateBandwidthRollup(ctx, rollup("a", pb.PieceAction_GET, 10), from, 3600))
+		require.NoError(t, pdb.CreateBandwidthRollup(ctx, rollup("a", pb.PieceAction_GET, 20), from.Add(time.Hour), 3600))
+		require.NoError(t, pdb.CreateBandwidthRollup(ctx, rollup("b", pb.PieceAction_PUT, 30), from.Add(time.Hour), 3600))
+		require.NoError(t, pdb.CreateBandwidthRollup(ctx, rollup("a", pb.PieceAction_GET_REPAIR, 40), from.Add(2*time.Hour), 3600))
+		// outside of the range.
+		require.NoError(t, pdb.CreateBandwidthRollup(ctx, rollup("a", pb.PieceAction_GET, 1000), now, 3600))
+		require.NoError(t, pdb.CreateBandwidthRollup(ctx, orders.BucketBandwidthRollup{
+			ProjectID: testrand.UUID(), BucketName: "a", Action: pb.PieceAction_GET, Settled: 1000,
+		}, from.Add(time.Hour), 3600))
+
+		_, err := pdb.ArchiveRollupsBefore(ctx, from.Add(time.Hour), 10)
+		require.NoError(t, err)
+
+		hourly, err := pdb.GetProjectHourlyBandwidth(ctx, projectID, from, now, false)
+		require.NoError(t, err)
+		require.Equal(t, []accounting.ProjectHourlyBandwidth{
+			{Hour: from.Add(time.Hour), Allocated: 100, Settled: 50, Inline: 2},
+			{Hour: from.Add(2 * time.Hour), Allocated: 80, Settled: 40, Inline: 1},
+		}, hourly)
+
+		hourly, err = pdb.GetProjectHourlyBandwidth(ctx, projectID, from, now, true)
+		require.NoError(t, err)
+		require.Equal(t, []accounting.ProjectHourlyBandwidth{
+			{Hour: from, Allocated: 20, Settled: 10, Inline: 1},
+			{Hour: from.Add(time.Hour), Allocated: 100, Settled: 50, Inline: 2},
+			{Hour: from.Add(2 * time.Hour), Allocated: 80, Settled: 40, Inline: 1},
+		}, hourly)
+
+		hourly, err = pdb.GetProjectHourlyBandwidth(ctx, testrand.UUID(), from, now, true)
+		require.NoError(t, err)
+		require.Empty(t, hourly)
+
+		_, err = pdb.GetProjectHourlyBandwidth(ctx, projectID, now, from, false)
+		require.True(t, accounting.ErrInvalidArgument.Has(err))
+
+		_, err = pdb.GetProjectHourlyBandwidth(ctx, projectID, now.Add(-15*24*time.Hour), now, false)
+		require.True(t, accounting.ErrInvalidArgument.Has(err))
+	})
+}
+
+func TestGetActiveProjects(t *testing.T) {
+	satellitedbtest.Run(t, func(ctx *testcontext.Context, t *testing.T, db satellite.DB) {
+		before := time.Now().UTC().Truncate(time.Hour)
+		since := before.Add(-24 * time.Hour)
+		pdb := db.ProjectAccounting()
+
+		tally := func(projectID uuid.UUID, intervalStart time.Time, totalBytes int64) {
+			require.NoError(t, pdb.CreateStorageTally(ctx, accounting.BucketStorageTally{
+				BucketName:    "bucket",
+				ProjectID:     projectID,
+				IntervalStart: intervalStart,
+				TotalBytes:    totalBytes,
+				ObjectCount:   1,
+			}))
+		}
+		rollup := func(projectID uuid.UUID, intervalStart time.Time, action pb.PieceAction, settled int64) {
+			require.NoError(t, pdb.CreateBandwidthRollup(ctx, orders.BucketBandwidthRollup{
+				ProjectID:  projectID,
+				BucketName: "bucket",
+				Action:     action,
+				Allocated:  settled,
+				Settled:    settled,
+			}, intervalStart, 3600))
+		}
+
+		storageOnly := testrand.UUID()
+		tally(storageOnly, since, 100)
+
+		egressOnly := testrand.UUID()
+		rollup(egressOnly, since.Add(time.Hour), pb.PieceAction_GET, 100)
+
+		archivedEgressOnly := testrand.UUID()
+		rollup(archivedEgressOnly, since, pb.PieceAction_GET, 100)
+		_, err := pdb.ArchiveRollupsBefore(ctx, since.Add(time.Hour), 10)
+		require.NoError(t, err)
+
+		both := testrand.UUID()
+		tally(both, since.Add(time.Hour), 100)
+		rollup(both, since.Add(time.Hour), pb.PieceAction_GET, 100)
+
+		// projects without storage or egress.
+		idle := testrand.UUID()
+		tally(idle, since, 0)
+		rollup(idle, since.Add(time.Hour), pb.PieceAction_PUT, 100)
+
+		outsideOfPeriod := testrand.UUID()
+		tally(outsideOfPeriod, since.Add(-time.Hour), 100)
+		tally(outsideOfPeriod, before, 100)
+		rollup(outsideOfPeriod, before, pb.PieceAction_GET, 100)
+
+		expected := []uuid.UUID{storageOnly, egressOnly, archivedEgressOnly, both}
+		sort.Slice(expected, func(i, k int) bool {
+			return bytes.Compare(expected[i][:], expected[k][:]) < 0
+		})
+
+		active, err := pdb.GetActiveProjects(ctx, since, before)
+		require.NoError(t, err)
+		require.Equal(t, expected, active)
+
+		active, err = pdb.GetActiveProjects(ctx, before, before.Add(time.Hour))
+		require.NoError(t, err)
+		require.Equal(t, []uuid.UUID{outsideOfPeriod}, active)
+	})
+}
+
+func TestGetBucketEgressTotals(t *testing.T) {
+	satellitedbtest.Run(t, func(ctx *testcontext.Context, t *testing.T, db satellite.DB) {
+		before := time.Now().UTC().Truncate(time.Hour)
+		since := before.Add(-24 * time.Hour)
+		projectID := testrand.UUID()
+		pdb := db.ProjectAccounting()
+
+		rollup := func(bucket string, action pb.PieceAction, intervalStart time.Time) {
+			require.NoError(t, pdb.CreateBandwidthRollup(ctx, orders.BucketBandwidthRollup{
+				ProjectID:  projectID,
+				BucketName: bucket,
+				Action:     action,
+				Inline:     1,
+				Allocated:  1000,
+				Settled:    10,
+			}, intervalStart, 3600))
+		}
+
+		rollup("a", pb.PieceAction_GET, since)
+		rollup("a", pb.PieceAction_GET, since.Add(time.Hour))
+		rollup("a", pb.PieceAction_GET_REPAIR, since.Add(time.Hour))
+		rollup("b", pb.PieceAction_PUT, since.Add(time.Hour))
+		rollup("b", pb.PieceAction(1000), since.Add(time.Hour))
+		// outside of the period.
+		rollup("a", pb.PieceAction_GET, before)
+		rollup("c", pb.PieceAction_GET, since.Add(-time.Hour))
+		require.NoError(t, pdb.CreateBandwidthRollup(ctx, orders.BucketBandwidthRollup{
+			ProjectID: testrand.UUID(), BucketName: "a", Action: pb.PieceAction_GET, Settled: 1000,
+		}, since, 3600))
+
+		_, err := pdb.ArchiveRollupsBefore(ctx, since.Add(time.Hour), 10)
+		require.NoError(t, err)
+
+		egress, err := pdb.GetBucketEgressTotals(ctx, projectID, since, before, nil)
+		require.NoError(t, err)
+		require.Equal(t, map[string]map[pb.PieceAction]int64{
+			"a": {pb.PieceAction_GET: 22, pb.PieceAction_GET_REPAIR: 11},
+			"b": {pb.PieceAction_PUT: 11, accounting.OtherPieceActions: 11},
+		}, egress)
+
+		egress, err = pdb.GetBucketEgressTotals(ctx, projectID, since, before, []pb.PieceAction{pb.PieceAction_GET, pb.PieceAction_PUT})
+		require.NoError(t, err)
+		require.Equal(t, map[string]map[pb.PieceAction]int64{
+			"a": {pb.PieceAction_GET: 22},
+			"b": {pb.PieceAction_PUT: 11},
+		}, egress)
+
+		egress, err = pdb.GetBucketEgressTotals(ctx, projectID, since, before, []pb.PieceAction{})
+		require.NoError(t, err)
+		require.Empty(t, egress)
+	})
+}
+
+func TestProjectUsageInlineRemoteSplit(t *testing.T) {
+	satellitedbtest.Run(t, func(ctx *testcontext.Context, t *testing.T, db satellite.DB) {
+		now := time.Now().UTC().Truncate(time.Hour)
+		since, before := now.Add(-3*time.Hour), now
+		projectID := testrand.UUID()
+
+		for interval := since; !interval.After(before); interval = interval.Add(time.Hour) {
+			// tally stored before total bytes were introduced.
+			err := db.ProjectAccounting().CreateStorageTally(ctx, accounting.BucketStorageTally{
+				BucketName:    "legacy",
+				ProjectID:     projectID,
+				IntervalStart: interval,
+				ObjectCount:   1,
+				InlineBytes:   memory.KiB.Int64(),
+				RemoteBytes:   3 * memory.KiB.Int64(),
+			})
+			require.NoError(t, err)
+
+			err = db.ProjectAccounting().CreateStorageTally(ctx, accounting.BucketStorageTally{
+				BucketName:    "current",
+				ProjectID:     projectID,
+				IntervalStart: interval,
+				ObjectCount:   1,
+				TotalBytes:    10 * memory.KiB.Int64(),
+			})
+			require.NoError(t, err)
+		}
+
+		usage, err := db.ProjectAccounting().GetProjectTotal(ctx, projectID, since, before, 0)
+		require.NoError(t, err)
+		require.NotZero(t, usage.InlineStorage)
+		require.InDelta(t, 3*usage.InlineStorage, usage.RemoteStorage, 1e-6)
+		// the current tallies aren't split, so they are accounted only in the total.
+		require.InDelta(t, 14*usage.InlineStorage, usage.Storage, 1e-6)
+
+		rollups, err := db.ProjectAccounting().GetBucketUsageRollups(ctx, projectID, since, before, 0)
+		require.NoError(t, err)
+		require.Len(t, rollups, 2)
+
+		byBucket := make(map[string]accounting.BucketUsageRollup)
+		for _, rollup := range rollups {
+			byBucket[string(rollup.BucketName)] = rollup
+		}
+
+		legacy := byBucket["legacy"]
+		require.NotZero(t, legacy.InlineStoredData)
+		require.InDelta(t, 3*legacy.InlineStoredData, legacy.RemoteStoredData, 1e-6)
+		require.InDelta(t, legacy.InlineStoredData+legacy.RemoteStoredData, legacy.TotalStoredData, 1e-6)
+
+		current := byBucket["current"]
+		require.Zero(t, current.InlineStoredData)
+		require.Zero(t, current.RemoteStoredData)
+		require.InDelta(t, 10*legacy.InlineStoredData, current.TotalStoredData, 1e-6)
+	})
+}
+
+func TestGetProjectsOverThreshold(t *testing.T) {
+	satellitedbtest.Run(t, func(ctx *testcontext.Context, t *testing.T, db satellite.DB) {
+		now := time.Now().UTC()
+		pdb := db.ProjectAccounting()
+		defaultUsage, defaultBandwidth := 10*memory.GB.Int64(), 10*memory.GB.Int64()
+		defaults := accounting.ProjectLimits{Usage: &defaultUsage, Bandwidth: &defaultBandwidth}
+
+		newProject := func(storage, bandwidth int64) uuid.UUID {
+			project, err := db.Console().Projects().Insert(ctx, &console.Project{Name: "test", OwnerID: testrand.UUID()})
+			require.NoError(t, err)
+
+			err = pdb.UpdateProjectUsageLimit(ctx, project.ID, memory.GB)
+			require.NoError(t, err)
+			err = pdb.UpdateProjectBandwidthLimit(ctx, project.ID, memory.GB)
+			require.NoError(t, err)
+
+			// only the latest tally is accounted.
+			for i, bytes := range []int64{2 * storage, storage} {
+				err = pdb.CreateStorageTally(ctx, accounting.BucketStorageTally{
+					BucketName:    "bucket",
+					ProjectID:     project.ID,
+					IntervalStart: now.Add(-time.Duration(2-i) * time.Hour),
+					TotalBytes:    bytes,
+				})
+				require.NoError(t, err)
+			}
+			err = db.Orders().UpdateBucketBandwidthAllocation(ctx, project.ID, []byte("bucket"), pb.PieceAction_GET, bandwidth, now)
+			require.NoError(t, err)
+			return project.ID
+		}
+
+		storageOver := newProject(850*memory.MB.Int64(), 0)
+		bandwidthOver := newProject(0, 950*memory.MB.Int64())
+		under := newProject(500*memory.MB.Int64(), 500*memory.MB.Int64())
+		disabled := newProject(2*memory.GB.Int64(), 2*memory.GB.Int64())
+		noThresholds := newProject(2*memory.GB.Int64(), 2*memory.GB.Int64())
+
+		require.NoError(t, pdb.SetProjectUsageAlertThresholds(ctx, storageOver, 80, 80))
+		require.NoError(t, pdb.SetProjectUsageAlertThresholds(ctx, bandwidthOver, 80, 90))
+		require.NoError(t, pdb.SetProjectUsageAlertThresholds(ctx, under, 80, 80))
+		require.NoError(t, pdb.SetProjectUsageAlertThresholds(ctx, disabled, 80, 80))
+		require.NoError(t, pdb.SetProjectUsageAlertThresholds(ctx, disabled, 0, 0))
+
+		err := pdb.SetProjectUsageAlertThresholds(ctx, noThresholds, 101, 80)
+		require.True(t, accounting.ErrInvalidArgument.Has(err))
+
+		alerts, err := pdb.GetProjectsOverThreshold(ctx, now, defaults)
+		require.NoError(t, err)
+		require.Len(t, alerts, 2)
+
+		byProject := make(map[uuid.UUID]accounting.ProjectUsageAlert)
+		for _, alert := range alerts {
+			byProject[alert.ProjectID] = alert
+		}
+
+		alert := byProject[storageOver]
+		require.True(t, alert.StorageExceeded)
+		require.False(t, alert.BandwidthExceeded)
+		require.Equal(t, 80, alert.StorageThreshold)
+		require.Equal(t, 850*memory.MB.Int64(), alert.StorageUsage)
+		require.Equal(t, memory.GB.Int64(), alert.StorageLimit)
+		require.InDelta(t, 0.85, alert.StorageFraction, 1e-9)
+
+		alert = byProject[bandwidthOver]
+		require.False(t, alert.StorageExceeded)
+		require.True(t, alert.BandwidthExceeded)
+		require.Equal(t, 950*memory.MB.Int64(), alert.BandwidthUsage)
+		require.InDelta(t, 0.95, alert.BandwidthFraction, 1e-9)
+
+		// the bandwidth of the next month doesn't count.
+		alerts, err = pdb.GetProjectsOverThreshold(ctx, now.AddDate(0, 1, 0), defaults)
+		require.NoError(t, err)
+		require.Len(t, alerts, 1)
+		require.Equal(t, storageOver, alerts[0].ProjectID)
+	})
+}
+
+func TestGetBucketTotalsHasData(t *testing.T) {
+	satellitedbtest.Run(t, func(ctx *testcontext.Context, t *testing.T, db satellite.DB) {
+		now := time.Now().UTC().Truncate(time.Hour)
+		since, before := now.Add(-3*time.Hour), now
+
+		project, err := db.Console().Projects().Insert(ctx, &console.Project{Name: "test", OwnerID: testrand.UUID()})
+		require.NoError(t, err)
+
+		for _, bucketName := range []string{"egress", "empty", "nodata", "outside"} {
+			_, err := db.Buckets().CreateBucket(ctx, storj.Bucket{
+				ID:        testrand.UUID(),
+				Name:      bucketName,
+				ProjectID: project.ID,
+			})
+			require.NoError(t, err)
+		}
+
+		err = db.Orders().UpdateBucketBandwidthSettle(ctx, project.ID, []byte("egress"), pb.PieceAction_GET, 0, now.Add(-time.Hour))
+		require.NoError(t, err)
+		// a tally with zero usage is still data.
+		err = db.ProjectAccounting().CreateStorageTally(ctx, accounting.BucketStorageTally{
+			BucketName:    "empty",
+			ProjectID:     project.ID,
+			IntervalStart: now.Add(-time.Hour),
+		})
+		require.NoError(t, err)
+		err = db.ProjectAccounting().CreateStorageTally(ctx, accounting.BucketStorageTally{
+			BucketName:    "outside",
+			ProjectID:     project.ID,
+			IntervalStart: now.Add(-5 * time.Hour),
+			TotalBytes:    memory.GB.Int64(),
+		})
+		require.NoError(t, err)
+
+		page, err := db.ProjectAccounting().GetBucketTotals(ctx, project.ID, accounting.BucketUsageCursor{Limit: 10, Page: 1}, since, before, 0)
+		require.NoError(t, err)
+		require.Len(t, page.BucketUsages, 4)
+
+		hasData := make(map[string]bool)
+		for _, usage := range page.BucketUsages {
+			require.Zero(t, usage.Storage)
+			require.Zero(t, usage.Egress)
+			hasData[usage.BucketName] = usage.HasData
+		}
+		require.Equal(t, map[string]bool{"egress": true, "empty": true, "nodata": false, "outside": false}, hasData)
+
+		for bucketName, expected := range hasData {
+			rollup, err := db.ProjectAccounting().GetSingleBucketUsageRollup(ctx, project.ID, bucketName, since, before)
+			require.NoError(t, err)
+			require.Equal(t, expected, rollup.HasData, bucketName)
+		}
+	})
+}
+
+func TestTallyBucketNameNormalization(t *testing.T) {
+	satellitedbtest.Run(t, func(ctx *testcontext.Context, t *testing.T, db satellite.DB) {
+		now := time.Now().UTC().Truncate(time.Hour)
+		since, before := now.Add(-3*time.Hour), now
+		pdb := db.ProjectAccounting()
+
+		project, err := db.Console().Projects().Insert(ctx, &console.Project{Name: "test", OwnerID: testrand.UUID()})
+		require.NoError(t, err)
+
+		// the bucket is stored decomposed, while the tally uses the composed form.
+		decomposed, composed := "cafe\u0301", "caf\u00e9"
+		_, err = db.Buckets().CreateBucket(ctx, storj.Bucket{
+			ID:        testrand.UUID(),
+			Name:      decomposed,
+			ProjectID: project.ID,
+		})
+		require.NoError(t, err)
+
+		location := func(bucketName string) metabase.BucketLocation {
+			return metabase.BucketLocation{ProjectID: project.ID, BucketName: bucketName}
+		}
+		tally := func(bucketName string, totalBytes int64) *accounting.BucketTally {
+			return &accounting.BucketTally{BucketLocation: location(bucketName), TotalBytes: totalBytes, ObjectCount: 1}
+		}
+
+		saved, invalid, err := pdb.SaveTallies(ctx, now.Add(-time.Hour), map[metabase.BucketLocation]*accounting.BucketTally{
+			location(composed + " "): tally(composed+" ", memory.GB.Int64()),
+			location("bu\x00cket"):   tally("bu\x00cket", 1),
+			// same bucket as the composed name with a trailing space, the tally using the normalized name wins.
+			location(composed): tally(composed, 2*memory.GB.Int64()),
+		})
+		require.NoError(t, err)
+		require.Equal(t, 1, saved)
+		require.Len(t, invalid, 2)
+		for _, name := range []string{"bu\x00cket", composed + " "} {
+			require.True(t, errors.Is(invalid[location(name)], accounting.ErrInvalidBucketName), name)
+		}
+
+		page, err := pdb.GetBucketTotals(ctx, project.ID, accounting.BucketUsageCursor{Limit: 10, Page: 1}, since, before, 0)
+		require.NoError(t, err)
+		require.Len(t, page.BucketUsages, 1)
+		require.Equal(t, decomposed, page.BucketUsages[0].BucketName)
+		require.True(t, page.BucketUsages[0].HasData)
+		require.EqualValues(t, 2, page.BucketUsages[0].Storage)
+
+		err = pdb.CreateStorageTally(ctx, accounting.BucketStorageTally{
+			BucketName:    " ",
+			ProjectID:     project.ID,
+			IntervalStart: now.Add(-time.Hour),
+		})
+		require.True(t, accounting.ErrInvalidArgument.Has(err))
+		require.True(t, errors.Is(err, accounting.ErrInvalidBucketName))
+	})
+}
+
+func TestGetProjectAccountingStatus(t *testing.T) {
+	satellitedbtest.Run(t, func(ctx *testcontext.Context, t *testing.T, db satellite.DB) {
+		now := time.Now().UTC().Truncate(time.Hour)
+		pdb := db.ProjectAccounting()
+
+		project, err := db.Console().Projects().Insert(ctx, &console.Project{Name: "test", OwnerID: testrand.UUID()})
+		require.NoError(t, err)
+
+		// a project without any data.
+		status, err := pdb.GetProjectAccountingStatus(ctx, project.ID)
+		require.NoError(t, err)
+		require.Zero(t, status)
+
+		for _, bucketName := range []string{"a", "b", "c"} {
+			_, err := db.Buckets().CreateBucket(ctx, storj.Bucket{
+				ID:        testrand.UUID(),
+				Name:      bucketName,
+				ProjectID: project.ID,
+			})
+			require.NoError(t, err)
+		}
+
+		tally := func(bucketName string, intervalStart time.Time) {
+			require.NoError(t, pdb.CreateStorageTally(ctx, accounting.BucketStorageTally{
+				BucketName:    bucketName,
+				ProjectID:     project.ID,
+				IntervalStart: intervalStart,
+			}))
+		}
+		rollup := func(intervalStart time.Time) {
+			require.NoError(t, pdb.CreateBandwidthRollup(ctx, orders.BucketBandwidthRollup{
+				ProjectID:  project.ID,
+				BucketName: "a",
+				Action:     pb.PieceAction_GET,
+				Settled:    1,
+			}, intervalStart, 3600))
+		}
+
+		tally("a", now.Add(-48*time.Hour))
+		tally("a", now.Add(-2*time.Hour))
+		tally("b", now.Add(-2*time.Hour))
+		tally("a", now.Add(-time.Hour))
+		tally("c", now.Add(-time.Hour))
+		rollup(now.Add(-48 * time.Hour))
+		rollup(now.Add(-3 * time.Hour))
+
+		_, err = pdb.ArchiveRollupsBefore(ctx, now.Add(-24*time.Hour), 10)
+		require.NoError(t, err)
+		_, err = pdb.ArchiveStorageTalliesBefore(ctx, now.Add(-24*time.Hour), 10)
+		require.NoError(t, err)
+
+		status, err = pdb.GetProjectAccountingStatus(ctx, project.ID)
+		require.NoError(t, err)
+		require.Equal(t, accounting.ProjectAccountingStatus{
+			LatestTally:            now.Add(-time.Hour),
+			LatestTallyBuckets:     2,
+			TalliedBuckets:         3,
+			Buckets:                3,
+			LatestBandwidthRollup:  now.Add(-3 * time.Hour),
+			BandwidthArchiveCutoff: now.Add(-48 * time.Hour),
+			TallyArchiveCutoff:     now.Add(-48 * time.Hour),
+		}, status)
+
+		// data of other projects isn't included.
+		status, err = pdb.GetProjectAccountingStatus(ctx, testrand.UUID())
+		require.NoError(t, err)
+		require.Zero(t, status)
+	})
+}
+
+func TestProjectAccountingErrors(t *testing.T) {
+	satellitedbtest.Run(t, func(ctx *testcontext.Context, t *testing.T, db satellite.DB) {
+		now := time.Now().UTC()
+		pdb := db.ProjectAccounting()
+
+		project, err := db.Console().Projects().Insert(ctx, &console.Project{Name: "test", OwnerID: testrand.UUID()})
+		require.NoError(t, err)
+		_, err = db.Buckets().CreateBucket(ctx, storj.Bucket{
+			ID:        testrand.UUID(),
+			Name:      "bucket",
+			ProjectID: project.ID,
+		})
+		require.NoError(t, err)
+
+		_, err = pdb.GetBucketTotals(ctx, project.ID, accounting.BucketUsageCursor{Limit: 10, Page: 0}, now.Add(-time.Hour), now, 0)
+		require.True(t, errors.Is(err, accounting.ErrInvalidCursor), err)
+		require.True(t, accounting.ErrInvalidArgument.Has(err), err)
+
+		_, err = pdb.GetBucketTotals(ctx, project.ID, accounting.BucketUsageCursor{Limit: 10, Page: 2}, now.Add(-time.Hour), now, 0)
+		require.True(t, errors.Is(err, accounting.ErrPageOutOfRange), err)
+		require.True(t, accounting.ErrInvalidArgument.Has(err), err)
+
+		_, err = pdb.GetArchivedRollupsPage(ctx, accounting.ArchivedRollupsCursor{Next: "not a token"})
+		require.True(t, errors.Is(err, accounting.ErrInvalidCursor), err)
+		require.True(t, accounting.ErrInvalidArgument.Has(err), err)
+
+		missing := testrand.UUID()
+		_, err = pdb.GetProjectLimits(ctx, missing)
+		require.True(t, errors.Is(err, accounting.ErrProjectNotFound), err)
+		_, err = pdb.GetProjectStorageLimit(ctx, missing)
+		require.True(t, errors.Is(err, accounting.ErrProjectNotFound), err)
+		_, err = pdb.GetProjectBandwidthLimit(ctx, missing)
+		require.True(t, errors.Is(err, accounting.ErrProjectNotFound), err)
+
+		// the error survives the wrapping of the limit cache.
+		cache := accounting.NewProjectLimitCache(pdb, 0, 0, accounting.ProjectLimitConfig{CacheCapacity: 10})
+		_, err = cache.GetProjectStorageLimit(ctx, missing)
+		require.True(t, errors.Is(err, accounting.ErrProjectNotFound), err)
+
+		_, err = pdb.GetProjectLimits(ctx, project.ID)
+		require.NoError(t, err)
+	})
+}
+
+func TestGetBucketTotalsGrouped(t *testing.T) {
+	satellitedbtest.Run(t, func(ctx *testcontext.Context, t *testing.T, db satellite.DB) {
+		now := time.Now().UTC().Truncate(time.Hour)
+		since, before := now.Add(-3*time.Hour), now
+
+		project, err := db.Console().Projects().Insert(ctx, &console.Project{Name: "test", OwnerID: testrand.UUID()})
+		require.NoError(t, err)
+
+		for i, bucketName := range []string{"analytics-a", "analytics-b", "backups-x", "misc"} {
+			_, err := db.Buckets().CreateBucket(ctx, storj.Bucket{
+				ID:        testrand.UUID(),
+				Name:      bucketName,
+				ProjectID: project.ID,
+			})
+			require.NoError(t, err)
+
+			// only the latest tally is accounted.
+			for hour := 2; hour >= 1; hour-- {
+				err = db.ProjectAccounting().CreateStorageTally(ctx, accounting.BucketStorageTally{
+					BucketName:    bucketName,
+					ProjectID:     project.ID,
+					IntervalStart: now.Add(-time.Duration(hour) * time.Hour),
+					ObjectCount:   int64(hour * (i + 1)),
+					TotalBytes:    int64(hour*(i+1)) * memory.GB.Int64(),
+				})
+				require.NoError(t, err)
+			}
+
+			err = db.Orders().UpdateBucketBandwidthSettle(ctx, project.ID, []byte(bucketName),
+				pb.PieceAction_GET, int64(i+1)*memory.GB.Int64(), now.Add(-time.Hour))
+			require.NoError(t, err)
+		}
+
+		_, err = db.ProjectAccounting().GetBucketTotalsGrouped(ctx, project.ID, "", since, before)
+		require.True(t, accounting.ErrInvalidArgument.Has(err))
+
+		groups, err := db.ProjectAccounting().GetBucketTotalsGrouped(ctx, project.ID, "-", since, before)
+		require.NoError(t, err)
+		require.Len(t, groups, 3)
+
+		// the groups match the sum of the usage of their buckets.
+		page, err := db.ProjectAccounting().GetBucketTotals(ctx, project.ID, accounting.BucketUsageCursor{Limit: 10, Page: 1}, since, before, 0)
+		require.NoError(t, err)
+		expected := make(map[string]accounting.BucketUsage)
+		for _, usage := range page.BucketUsages {
+			group := strings.SplitN(usage.BucketName, "-", 2)[0]
+			total := expected[group]
+			total.Storage += usage.Storage
+			total.Egress += usage.Egress
+			total.ObjectCount += usage.ObjectCount
+			expected[group] = total
+		}
+
+		var names []string
+		for _, group := range groups {
+			names = append(names, group.BucketName)
+			require.Equal(t, project.ID, group.ProjectID)
+			require.InDelta(t, expected[group.BucketName].Storage, group.Storage, 1e-6, group.BucketName)
+			require.InDelta(t, expected[group.BucketName].Egress, group.Egress, 1e-6, group.BucketName)
+			require.Equal(t, expected[group.BucketName].ObjectCount, group.ObjectCount, group.BucketName)
+		}
+		require.Equal(t, []string{"analytics", "backups", "misc"}, names)
+		require.InDelta(t, 3, groups[0].Storage, 1e-6)
+		require.InDelta(t, 3, groups[0].Egress, 1e-6)
+
+		groups, err = db.ProjectAccounting().GetBucketTotalsGrouped(ctx, testrand.UUID(), "-", since, before)
+		require.NoError(t, err)
+		require.Empty(t, groups)
+	})
+}
+
+func TestProjectMonthlyUsage(t *testing.T) {
+	satellitedbtest.Run(t, func(ctx *testcontext.Context, t *testing.T, db satellite.DB) {
+		now := time.Now().UTC()
+		period := time.Date(now.Year(), now.Month()-1, 1, 0, 0, 0, 0, time.UTC)
+		projectID := testrand.UUID()
+		pdb := db.ProjectAccounting()
+
+		createProjectUsage(ctx, t, db, projectID, 2, period.Add(24*time.Hour), period.Add(34*time.Hour))
+
+		_, err := pdb.GetProjectMonthlyUsage(ctx, projectID, period)
+		require.True(t, errors.Is(err, sql.ErrNoRows))
+
+		_, err = pdb.SaveClosedProjectMonthlyUsage(ctx, projectID, now)
+		require.True(t, accounting.ErrInvalidArgument.Has(err))
+
+		expected, err := pdb.GetProjectTotal(ctx, projectID, period, period.AddDate(0, 1, 0), 0)
+		require.NoError(t, err)
+		require.NotZero(t, expected.Storage)
+		require.NotZero(t, expected.Egress)
+
+		// any time within the month refers to the month.
+		saved, err := pdb.SaveClosedProjectMonthlyUsage(ctx, projectID, period.Add(48*time.Hour))
+		require.NoError(t, err)
+		require.Equal(t, expected, saved)
+
+		usage, err := pdb.GetProjectMonthlyUsage(ctx, projectID, period.Add(time.Hour))
+		require.NoError(t, err)
+		require.Equal(t, expected.Egress, usage.Egress)
+		require.InDelta(t, expected.Storage, usage.Storage, 1e-6)
+		require.InDelta(t, expected.ObjectCount, usage.ObjectCount, 1e-6)
+		require.Equal(t, period, usage.Since)
+		require.Equal(t, period.AddDate(0, 1, 0), usage.Before)
+
+		// saving again replaces the usage.
+		err = pdb.SaveProjectMonthlyUsage(ctx, projectID, period, accounting.ProjectUsage{Storage: 1, Egress: 2, ObjectCount: 3})
+		require.NoError(t, err)
+
+		usage, err = pdb.GetProjectMonthlyUsage(ctx, projectID, period)
+		require.NoError(t, err)
+		require.Equal(t, &accounting.ProjectUsage{
+			Storage: 1, Egress: 2, ObjectCount: 3,
+			Since: period, Before: period.AddDate(0, 1, 0),
+		}, usage)
+
+		_, err = pdb.GetProjectMonthlyUsage(ctx, testrand.UUID(), period)
+		require.True(t, errors.Is(err, sql.ErrNoRows))
+	})
+}
+
+func TestGetProjectTotalByPartner(t *testing.T) {
+	satellitedbtest.Run(t, func(ctx *testcontext.Context, t *testing.T, db satellite.DB) {
+		now := time.Now().UTC().Truncate(time.Hour)
+		since, before := now.Add(-10*time.Hour), now
+
+		projectID := testrand.UUID()
+		createProjectUsage(ctx, t, db, projectID, 4, since, before)
+
+		// project with usage identical to bucket0 of projectID.
+		singleBucketProjectID := testrand.UUID()
+		createProjectUsage(ctx, t, db, singleBucketProjectID, 1, since, before)
+
+		partnerUUID := func(name string) uuid.UUID {
+			partner, err := rewards.DefaultPartnersDB.ByName(ctx, name)
+			require.NoError(t, err)
+			return partner.UUID
+		}
+
+		partnerA, partnerB, partnerC, partnerD := "Blocknify", "Breaker", "CloudBloq", "Confluent"
+		for bucket, partnerName := range map[string]string{
+			"bucket0": partnerA,
+			"bucket1": partnerB,
+			"bucket2": partnerC,
+		} {
+			_, err := db.Attribution().Insert(ctx, &attribution.Info{
+				ProjectID:  projectID,
+				BucketName: []byte(bucket),
+				PartnerID:  partnerUUID(partnerName),
+			})
+			require.NoError(t, err)
+		}
+
+		_, err := db.ProjectAccounting().GetProjectTotalByPartner(ctx, projectID, []string{"unknown partner"}, since, before)
+		require.True(t, accounting.ErrInvalidArgument.Has(err))
+
+		// partnerB is not requested, so bucket1 lands in the remainder.
+		usages, err := db.ProjectAccounting().GetProjectTotalByPartner(ctx, projectID, []string{partnerA, partnerC, partnerD}, since, before)
+		require.NoError(t, err)
+		require.Len(t, usages, 4)
+		require.NotContains(t, usages, partnerB)
+
+		expectedA, err := db.ProjectAccounting().GetProjectTotal(ctx, singleBucketProjectID, since, before, 0)
+		require.NoError(t, err)
+		require.Equal(t, expectedA.Egress, usages[partnerA].Egress)
+		require.InDelta(t, expectedA.Storage, usages[partnerA].Storage, 1e-6)
+		require.InDelta(t, expectedA.ObjectCount, usages[partnerA].ObjectCount, 1e-6)
+
+		require.NotZero(t, usages[partnerC].Storage)
+		require.NotZero(t, usages[""].Storage)
+		require.Zero(t, usages[partnerD].Storage)
+		require.Zero(t, usages[partnerD].Egress)
+
+		// usages across partners sum up to the project total.
+		total, err := db.ProjectAccounting().GetProjectTotal(ctx, projectID, since, before, 0)
+		require.NoError(t, err)
+
+		var sum accounting.ProjectUsage
+		for _, usage := range usages {
+			require.Equal(t, total.Since, usage.Since)
+			require.Equal(t, total.Before, usage.Before)
+			sum.Egress += usage.Egress
+			sum.Storage += usage.Storage
+			sum.ObjectCount += usage.ObjectCount
+		}
+		require.Equal(t, total.Egress, sum.Egress)
+		require.InDelta(t, total.Storage, sum.Storage, 1e-6)
+		require.InDelta(t, total.ObjectCount, sum.ObjectCount, 1e-6)
+	})
+}
+
+func TestGetBucketBandwidthBreakdown(t *testing.T) {
+	satellitedbtest.Run(t, func(ctx *testcontext.Context, t *testing.T, db satellite.DB) {
+		now := time.Now().UTC().Truncate(time.Hour)
+		since, before := now.Add(-24*time.Hour), now
+		projectID := testrand.UUID()
+
+		type rollup struct {
+			bucket    string
+			action    pb.PieceAction
+			allocated int64
+			settled   int64
+			interval  time.Time
+		}
+		for _, r := range []rollup{
+			// allocation exceeds settlement.
+			{bucket: "alpha", action: pb.PieceAction_GET, allocated: 1000, settled: 600, interval: since},
+			{bucket: "alpha", action: pb.PieceAction_GET, allocated: 500, settled: 100, interval: now.Add(-time.Hour)},
+			// late settlement of orders allocated in a previous period.
+			{bucket: "beta", action: pb.PieceAction_GET, allocated: 100, settled: 700, interval: since.Add(time.Hour)},
+			{bucket: "beta", action: pb.PieceAction_GET, allocated: 900, interval: since.Add(-time.Hour)},
+			// other actions are not included.
+			{bucket: "beta", action: pb.PieceAction_GET_REPAIR, allocated: 300, settled: 300, interval: now.Add(-time.Hour)},
+			{bucket: "gamma", action: pb.PieceAction_GET_AUDIT, allocated: 300, settled: 300, interval: now.Add(-time.Hour)},
+			{bucket: "delta", action: pb.PieceAction_GET, allocated: 10, settled: 10, interval: now.Add(-time.Hour)},
+			// the end of the period is exclusive.
+			{bucket: "epsilon", action: pb.PieceAction_GET, allocated: 10, settled: 10, interval: before},
+		} {
+			if r.allocated > 0 {
+				err := db.Orders().UpdateBucketBandwidthAllocation(ctx, projectID, []byte(r.bucket), r.action, r.allocated, r.interval)
+				require.NoError(t, err)
+			}
+			if r.settled > 0 {
+				err := db.Orders().UpdateBucketBandwidthSettle(ctx, projectID, []byte(r.bucket), r.action, r.settled, r.interval)
+				require.NoError(t, err)
+			}
+		}
+
+		expected := []accounting.BucketBandwidthBreakdown{
+			{BucketName: "alpha", Allocated: 1500, Settled: 700, Difference: 800},
+			{BucketName: "beta", Allocated: 100, Settled: 700, Difference: -600},
+			{BucketName: "delta", Allocated: 10, Settled: 10, Difference: 0},
+		}
+
+		page, err := db.ProjectAccounting().GetBucketBandwidthBreakdown(ctx, projectID, accounting.BucketBandwidthBreakdownCursor{}, since, before)
+		require.NoError(t, err)
+		require.False(t, page.More)
+		require.Equal(t, expected, page.Breakdowns)
+
+		// paging by bucket name.
+		var breakdowns []accounting.BucketBandwidthBreakdown
+		cursor := accounting.BucketBandwidthBreakdownCursor{Limit: 2}
+		for {
+			page, err := db.ProjectAccounting().GetBucketBandwidthBreakdown(ctx, projectID, cursor, since, before)
+			require.NoError(t, err)
+			require.LessOrEqual(t, len(page.Breakdowns), cursor.Limit)
+			breakdowns = append(breakdowns, page.Breakdowns...)
+			if !page.More {
+				break
+			}
+			cursor.StartAfter = page.Breakdowns[len(page.Breakdowns)-1].BucketName
+		}
+		require.Equal(t, expected, breakdowns)
+
+		// project without bandwidth.
+		page, err = db.ProjectAccounting().GetBucketBandwidthBreakdown(ctx, testrand.UUID(), accounting.BucketBandwidthBreakdownCursor{}, since, before)
+		require.NoError(t, err)
+		require.False(t, page.More)
+		require.Empty(t, page.Breakdowns)
+	})
+}
+
+// createProjectUsage creates hourly tallies and egress for the given number of buckets of a project.
+func createProjectUsage(ctx *testcontext.Context, t testing.TB, db satellite.DB, projectID uuid.UUID, buckets int, since, before time.Time) {
+	for b := 0; b < buckets; b++ {
+		bucketName := fmt.Sprintf("bucket%d", b)
+		for interval := since; !interval.After(before); interval = interval.Add(time.Hour) {
+			err := db.ProjectAccounting().CreateStorageTally(ctx, accounting.BucketStorageTally{
+				BucketName:        bucketName,
+				ProjectID:         projectID,
+				IntervalStart:     interval,
+				ObjectCount:       int64(b + 1),
+				TotalSegmentCount: int64(b + 2),
+				TotalBytes:        int64(interval.Hour()+1) * memory.KiB.Int64(),
+			})
+			require.NoError(t, err)
+
+			err = db.Orders().UpdateBucketBandwidthSettle(ctx, projectID, []byte(bucketName),
+				pb.PieceAction_GET, memory.MiB.Int64(), interval)
+			require.NoError(t, err)
+		}
+	}
+}
+
+func createBucketStorageTallies(projectID uuid.UUID) (map[metabase.BucketLocation]*accounting.BucketTally, []accounting.BucketTally, error) {
+	bucketTallies := make(map[metabase.BucketLocation]*accounting.BucketTally)
+	var expectedTallies []accounting.BucketTally
+
+	for i := 0; i < 4; i++ {
+		bucketName := fmt.Sprintf("%s%d", "testbucket", i)
+		bucketLocation := metabase.BucketLocation{
+			ProjectID:  projectID,
+			BucketName: bucketName,
+		}
+
+		// Setup: The data in this tally should match the pointer that the uplink.upload created
+		tally := accounting.BucketTally{
+			BucketLocation: metabase.BucketLocation{
+				ProjectID:  projectID,
+				BucketName: bucketName,
+			},
+			ObjectCount:   int64(1),
+			TotalSegments: int64(2),
+			TotalBytes:    int64(2),
+			MetadataSize:  int64(1),
+		}
+		bucketTallies[bucketLocation] = &tally
+		expectedTallies = append(expectedTallies, tally)
+
+	}
+	return bucketTallies, expectedTallies, nil
+}
+
+// make rollups and tallies for specified nodes and date range.
+func makeRollupsAndStorageNodeStorageTallies(nodes []storj.NodeID, start time.Time, days int) (accounting.RollupStats, map[time.Time]map[storj.NodeID]float64, time.Time) {
+	rollups := make(accounting.RollupStats)
+	tallies := make(map[time.Time]map[storj.NodeID]float64)
+
+	const (
+		hours = 12
+	)
+
+	for i := 0; i < days; i++ {
+		startDay := time.Date(start.Year(), start.Month(), start.Day()+i, 0, 0, 0, 0, start.Location())
+		if rollups[startDay] == nil {
+			rollups[startDay] = make(map[storj.NodeID]*accounting.Rollup)
+		}
+
+		for _, node := range nodes {
+			rollup := &accounting.Rollup{
+				NodeID:    node,
+				StartTime: startDay,
+			}
+
+			for h := 0; h < hours; h++ {
+				startTime := startDay.Add(time.Hour * time.Duration(h))
+				if tallies[startTime] == nil {
+					tallies[startTime] = make(map[storj.NodeID]float64)
+				}
 
 				tallieAtRest := math.Round(testrand.Float64n(1000))
 				tallies[startTime][node] = tallieAtRest
@@ -281,3 +2300,226 @@ func makeRollupsAndStorageNodeStorageTallies(nodes []storj.NodeID, start time.Ti
 
 	return rollups, tallies, time.Date(start.Year(), start.Month(), start.Day()+days-1, 0, 0, 0, 0, start.Location())
 }
+
+func TestGetLatestBucketTallies(t *testing.T) {
+	satellitedbtest.Run(t, func(ctx *testcontext.Context, t *testing.T, db satellite.DB) {
+		now := time.Now().UTC().Truncate(time.Hour)
+		projectID := testrand.UUID()
+
+		tallies, err := db.ProjectAccounting().GetLatestBucketTallies(ctx, projectID)
+		require.NoError(t, err)
+		require.Empty(t, tallies)
+
+		for _, tally := range []accounting.BucketStorageTally{
+			{BucketName: "b", ProjectID: projectID, IntervalStart: now.Add(-2 * time.Hour), ObjectCount: 1, TotalBytes: 100},
+			{BucketName: "b", ProjectID: projectID, IntervalStart: now, ObjectCount: 3, TotalBytes: 300, TotalSegmentCount: 3},
+			{BucketName: "b", ProjectID: projectID, IntervalStart: now.Add(-time.Hour), ObjectCount: 2, TotalBytes: 200},
+			{BucketName: "a", ProjectID: projectID, IntervalStart: now.Add(-time.Hour), ObjectCount: 5, TotalBytes: 30, TotalSegmentCount: 5},
+			{BucketName: "b", ProjectID: testrand.UUID(), IntervalStart: now.Add(time.Hour), ObjectCount: 10},
+		} {
+			require.NoError(t, db.ProjectAccounting().CreateStorageTally(ctx, tally))
+		}
+
+		tallies, err = db.ProjectAccounting().GetLatestBucketTallies(ctx, projectID)
+		require.NoError(t, err)
+		require.Equal(t, []accounting.BucketTally{
+			{
+				BucketLocation: metabase.BucketLocation{ProjectID: projectID, BucketName: "a"},
+				ObjectCount:    5,
+				TotalSegments:  5,
+				TotalBytes:     30,
+			},
+			{
+				BucketLocation: metabase.BucketLocation{ProjectID: projectID, BucketName: "b"},
+				ObjectCount:    3,
+				TotalSegments:  3,
+				TotalBytes:     300,
+			},
+		}, tallies)
+	})
+}
+
+func TestGetProjectStorageTotals(t *testing.T) {
+	satellitedbtest.Run(t, func(ctx *testcontext.Context, t *testing.T, db satellite.DB) {
+		now := time.Now().UTC().Truncate(time.Hour)
+		projectID := testrand.UUID()
+
+		bytes, segments, objects, err := db.ProjectAccounting().GetProjectStorageTotals(ctx, projectID)
+		require.NoError(t, err)
+		require.Zero(t, bytes)
+		require.Zero(t, segments)
+		require.Zero(t, objects)
+
+		for _, tally := range []accounting.BucketStorageTally{
+			{BucketName: "a", ProjectID: projectID, IntervalStart: now.Add(-2 * time.Hour), ObjectCount: 1, TotalBytes: 100, TotalSegmentCount: 1},
+			{BucketName: "a", ProjectID: projectID, IntervalStart: now, ObjectCount: 3, TotalBytes: 300, TotalSegmentCount: 6},
+			{BucketName: "a", ProjectID: projectID, IntervalStart: now.Add(-time.Hour), ObjectCount: 2, TotalBytes: 200, TotalSegmentCount: 4},
+			{BucketName: "b", ProjectID: projectID, IntervalStart: now.Add(-3 * time.Hour), ObjectCount: 5, TotalBytes: 50, TotalSegmentCount: 5},
+			{BucketName: "b", ProjectID: projectID, IntervalStart: now.Add(-4 * time.Hour), ObjectCount: 7, TotalBytes: 70, TotalSegmentCount: 7},
+			{BucketName: "c", ProjectID: projectID, IntervalStart: now.Add(-time.Hour), ObjectCount: 1, TotalBytes: 10, TotalSegmentCount: 1},
+			{BucketName: "a", ProjectID: testrand.UUID(), IntervalStart: now.Add(time.Hour), ObjectCount: 10, TotalBytes: 1000, TotalSegmentCount: 10},
+		} {
+			require.NoError(t, db.ProjectAccounting().CreateStorageTally(ctx, tally))
+		}
+
+		bytes, segments, objects, err = db.ProjectAccounting().GetProjectStorageTotals(ctx, projectID)
+		require.NoError(t, err)
+		require.EqualValues(t, 300+50+10, bytes)
+		require.EqualValues(t, 6+5+1, segments)
+		require.EqualValues(t, 3+5+1, objects)
+	})
+}
+
+func TestGetProjectMetadataTotals(t *testing.T) {
+	satellitedbtest.Run(t, func(ctx *testcontext.Context, t *testing.T, db satellite.DB) {
+		now := time.Now().UTC().Truncate(time.Hour)
+		pdb := db.ProjectAccounting()
+
+		project, err := db.Console().Projects().Insert(ctx, &console.Project{Name: "test", OwnerID: testrand.UUID()})
+		require.NoError(t, err)
+
+		total, buckets, err := pdb.GetProjectMetadataTotals(ctx, project.ID)
+		require.NoError(t, err)
+		require.Zero(t, total)
+		require.Empty(t, buckets)
+
+		for _, bucketName := range []string{"a", "b"} {
+			_, err := db.Buckets().CreateBucket(ctx, storj.Bucket{
+				ID:        testrand.UUID(),
+				Name:      bucketName,
+				ProjectID: project.ID,
+			})
+			require.NoError(t, err)
+		}
+
+		for _, tally := range []accounting.BucketStorageTally{
+			{BucketName: "a", ProjectID: project.ID, IntervalStart: now.Add(-2 * time.Hour), MetadataSize: 100},
+			{BucketName: "a", ProjectID: project.ID, IntervalStart: now.Add(-time.Hour), MetadataSize: 300},
+			{BucketName: "b", ProjectID: project.ID, IntervalStart: now.Add(-3 * time.Hour), MetadataSize: 50},
+			{BucketName: "b", ProjectID: project.ID, IntervalStart: now.Add(-4 * time.Hour), MetadataSize: 70},
+			{BucketName: "a", ProjectID: testrand.UUID(), IntervalStart: now, MetadataSize: 1000},
+		} {
+			require.NoError(t, pdb.CreateStorageTally(ctx, tally))
+		}
+
+		total, buckets, err = pdb.GetProjectMetadataTotals(ctx, project.ID)
+		require.NoError(t, err)
+		require.EqualValues(t, 300+50, total)
+		require.Equal(t, map[string]int64{"a": 300, "b": 50}, buckets)
+
+		page, err := pdb.GetBucketTotals(ctx, project.ID, accounting.BucketUsageCursor{Limit: 10, Page: 1}, now.Add(-5*time.Hour), now, 0)
+		require.NoError(t, err)
+		require.Len(t, page.BucketUsages, 2)
+		for _, usage := range page.BucketUsages {
+			require.Equal(t, buckets[usage.BucketName], usage.MetadataSize, usage.BucketName)
+		}
+	})
+}
+
+func TestDeleteBucketAccountingData(t *testing.T) {
+	satellitedbtest.Run(t, func(ctx *testcontext.Context, t *testing.T, db satellite.DB) {
+		now := time.Now().UTC().Truncate(time.Hour)
+		since := now.Add(-24 * time.Hour)
+		projectID := testrand.UUID()
+		pdb := db.ProjectAccounting()
+
+		for _, bucketName := range []string{"bucket", "other"} {
+			for _, interval := range []time.Time{now.Add(-4 * time.Hour), now.Add(-3 * time.Hour)} {
+				err := pdb.CreateStorageTally(ctx, accounting.BucketStorageTally{
+					BucketName:    bucketName,
+					ProjectID:     projectID,
+					IntervalStart: interval,
+					TotalBytes:    1000,
+				})
+				require.NoError(t, err)
+				err = db.Orders().UpdateBucketBandwidthAllocation(ctx, projectID, []byte(bucketName), pb.PieceAction_GET, 100, interval)
+				require.NoError(t, err)
+			}
+		}
+
+		// the bucket is deleted and recreated with the same name.
+		deletedAt := now.Add(-2 * time.Hour)
+		deletions, err := pdb.DeleteBucketAccountingData(ctx, projectID, "bucket", deletedAt)
+		require.NoError(t, err)
+		require.Equal(t, accounting.ProjectAccountingDeletions{StorageTallies: 2, BandwidthRollups: 2}, deletions)
+
+		storage, err := pdb.GetBucketStorageUsage(ctx, projectID, "bucket")
+		require.NoError(t, err)
+		require.Zero(t, storage)
+		bandwidth, err := pdb.GetBucketBandwidthUsage(ctx, projectID, "bucket", since)
+		require.NoError(t, err)
+		require.Zero(t, bandwidth)
+
+		// usage of the recreated bucket is kept.
+		err = pdb.CreateStorageTally(ctx, accounting.BucketStorageTally{
+			BucketName:    "bucket",
+			ProjectID:     projectID,
+			IntervalStart: now,
+			TotalBytes:    10,
+		})
+		require.NoError(t, err)
+
+		deletions, err = pdb.DeleteBucketAccountingData(ctx, projectID, "bucket", deletedAt)
+		require.NoError(t, err)
+		require.Zero(t, deletions)
+
+		storage, err = pdb.GetBucketStorageUsage(ctx, projectID, "bucket")
+		require.NoError(t, err)
+		require.EqualValues(t, 10, storage)
+
+		// other buckets are untouched.
+		storage, err = pdb.GetBucketStorageUsage(ctx, projectID, "other")
+		require.NoError(t, err)
+		require.EqualValues(t, 1000, storage)
+		bandwidth, err = pdb.GetBucketBandwidthUsage(ctx, projectID, "other", since)
+		require.NoError(t, err)
+		require.EqualValues(t, 200, bandwidth)
+	})
+}
+
+func TestGetBucketObjectCountSeries(t *testing.T) {
+	satellitedbtest.Run(t, func(ctx *testcontext.Context, t *testing.T, db satellite.DB) {
+		since := time.Now().UTC().Truncate(time.Hour).Add(-24 * time.Hour)
+		projectID := testrand.UUID()
+
+		// insert tallies out of order to verify the ordering.
+		for _, i := range []int{3, 0, 5, 1, 4, 2} {
+			err := db.ProjectAccounting().CreateStorageTally(ctx, accounting.BucketStorageTally{
+				BucketName:    "bucket",
+				ProjectID:     projectID,
+				IntervalStart: since.Add(time.Duration(i) * time.Hour),
+				ObjectCount:   int64(i),
+				TotalBytes:    int64(i) * 100,
+			})
+			require.NoError(t, err)
+		}
+		// other buckets and tallies outside of the period are not included.
+		for _, tally := range []accounting.BucketStorageTally{
+			{BucketName: "other", ProjectID: projectID, IntervalStart: since, ObjectCount: 10},
+			{BucketName: "bucket", ProjectID: projectID, IntervalStart: since.Add(-time.Hour), ObjectCount: 10},
+		} {
+			require.NoError(t, db.ProjectAccounting().CreateStorageTally(ctx, tally))
+		}
+
+		check := func(maxPoints int, expected ...int) {
+			points, err := db.ProjectAccounting().GetBucketObjectCountSeries(ctx, projectID, "bucket", since, since.Add(6*time.Hour), maxPoints)
+			require.NoError(t, err)
+			require.Len(t, points, len(expected))
+			for k, i := range expected {
+				require.True(t, since.Add(time.Duration(i)*time.Hour).Equal(points[k].IntervalStart))
+				require.EqualValues(t, i, points[k].ObjectCount)
+				require.EqualValues(t, i*100, points[k].TotalBytes)
+			}
+		}
+
+		check(0, 0, 1, 2, 3, 4, 5)
+		check(6, 0, 1, 2, 3, 4, 5)
+		check(3, 0, 2, 4)
+		check(4, 0, 2, 4)
+		check(2, 0, 3)
+		check(1, 0)
+
+		_, err := db.ProjectAccounting().GetBucketObjectCountSeries(ctx, projectID, "bucket", since, since.Add(6*time.Hour), -1)
+		require.True(t, accounting.ErrInvalidArgument.Has(err))
+	})
+}
@@ -0,0 +1,44 @@
+// Copyright (C) 2021 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package accounting
+
+import (
+	"context"
+	"time"
+
+	"storj.io/common/uuid"
+)
+
+const (
+	// LimitFieldUsage is the ProjectLimitChange field of storage usage limit changes.
+	LimitFieldUsage = "usage_limit"
+	// LimitFieldBandwidth is the ProjectLimitChange field of bandwidth limit changes.
+	LimitFieldBandwidth = "bandwidth_limit"
+)
+
+// ProjectLimitChange is a recorded change of a project limit.
+// Nil values mean the limit wasn't set.
+type ProjectLimitChange struct {
+	ProjectID uuid.UUID
+	Field     string
+	OldValue  *int64
+	NewValue  *int64
+	ChangedAt time.Time
+	Actor     string
+}
+
+// limitChangeActorKey is context key for the limit change actor.
+type limitChangeActorKey struct{}
+
+// WithLimitChangeActor creates new context with the actor of project limit changes.
+// Project limit updates using the context are recorded with the actor.
+func WithLimitChangeActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, limitChangeActorKey{}, actor)
+}
+
+// GetLimitChangeActor gets the actor of project limit changes from context.
+func GetLimitChangeActor(ctx context.Context) (actor string, ok bool) {
+	actor, ok = ctx.Value(limitChangeActorKey{}).(string)
+	return actor, ok
+}
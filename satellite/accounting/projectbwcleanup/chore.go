@@ -21,6 +21,8 @@ var mon = monkit.Package()
 type Config struct {
 	Interval     time.Duration `help:"how often to remove unused project bandwidth rollups" default:"168h" testDefault:"$TESTINTERVAL"`
 	RetainMonths int           `help:"number of months of project bandwidth rollups to retain, not including the current month" default:"2"`
+	BatchSize    int           `help:"number of project bandwidth rollups to delete per statement, zero or negative deletes all with a single statement" default:"1000" testDefault:"100"`
+	DryRun       bool          `help:"only log the number of project bandwidth rollups which would be removed, without removing them" default:"false"`
 }
 
 // Chore to remove unused project bandwidth rollups.
@@ -69,7 +71,22 @@ func (chore *Chore) RunOnce(ctx context.Context) (err error) {
 	now := time.Now().UTC()
 	beforeMonth := time.Date(now.Year(), now.Month()-time.Month(chore.config.RetainMonths), 1, 0, 0, 0, 0, time.UTC)
 
-	return chore.db.DeleteProjectBandwidthBefore(ctx, beforeMonth)
+	if chore.config.DryRun {
+		count, err := chore.db.DeleteProjectBandwidthBeforeDryRun(ctx, beforeMonth)
+		if err != nil {
+			return err
+		}
+		chore.log.Info("dry run: project bandwidth rollups to remove", zap.Time("before", beforeMonth), zap.Int64("count", count))
+		return nil
+	}
+
+	deleted, err := chore.db.DeleteProjectBandwidthBefore(ctx, beforeMonth, chore.config.BatchSize)
+	if err != nil {
+		return err
+	}
+	chore.log.Info("removed project bandwidth rollups", zap.Time("before", beforeMonth), zap.Int64("count", deleted))
+
+	return nil
 }
 
 // Close stops the chore.
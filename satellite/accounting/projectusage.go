@@ -5,12 +5,15 @@ package accounting
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/spacemonkeygo/monkit/v3"
 	"github.com/zeebo/errs"
 	"golang.org/x/sync/errgroup"
+	"golang.org/x/sync/singleflight"
 
+	"storj.io/common/context2"
 	"storj.io/common/memory"
 	"storj.io/common/uuid"
 )
@@ -32,6 +35,11 @@ type Service struct {
 	bandwidthCacheTTL   time.Duration
 	nowFn               func() time.Time
 	asOfSystemInterval  time.Duration
+
+	// usageQueries coalesces concurrent identical usage queries.
+	usageQueries singleflight.Group
+	// usageQueryJoined is called when a caller joined a coalesced usage query, it's set by tests.
+	usageQueryJoined func()
 }
 
 // NewService created new instance of project usage service.
@@ -172,6 +180,70 @@ func (usage *Service) GetProjectBandwidth(ctx context.Context, projectID uuid.UU
 	return total, ErrProjectUsage.Wrap(err)
 }
 
+// GetProjectTotal returns project usage for a given period.
+//
+// Concurrent calls for the same project and period share a single database
+// query. Results aren't cached.
+func (usage *Service) GetProjectTotal(ctx context.Context, projectID uuid.UUID, since, before time.Time, asOfSystemInterval time.Duration) (_ *ProjectUsage, err error) {
+	defer mon.Task()(&ctx, projectID)(&err)
+
+	key := usageQueryKey("project-total", projectID, since, before, asOfSystemInterval)
+	result, err := usage.coalesceUsageQuery(ctx, key, func(ctx context.Context) (interface{}, error) {
+		return usage.projectAccountingDB.GetProjectTotal(ctx, projectID, since, before, asOfSystemInterval)
+	})
+	if err != nil {
+		return nil, ErrProjectUsage.Wrap(err)
+	}
+
+	// every caller gets its own copy, since the result is shared.
+	total := *result.(*ProjectUsage)
+	return &total, nil
+}
+
+// GetBucketUsageRollups returns usage rollups of every bucket of the project for a given period.
+//
+// Concurrent calls are coalesced the same way as for GetProjectTotal.
+func (usage *Service) GetBucketUsageRollups(ctx context.Context, projectID uuid.UUID, since, before time.Time, asOfSystemInterval time.Duration) (_ []BucketUsageRollup, err error) {
+	defer mon.Task()(&ctx, projectID)(&err)
+
+	key := usageQueryKey("bucket-usage-rollups", projectID, since, before, asOfSystemInterval)
+	result, err := usage.coalesceUsageQuery(ctx, key, func(ctx context.Context) (interface{}, error) {
+		return usage.projectAccountingDB.GetBucketUsageRollups(ctx, projectID, since, before, asOfSystemInterval)
+	})
+	if err != nil {
+		return nil, ErrProjectUsage.Wrap(err)
+	}
+
+	rollups := result.([]BucketUsageRollup)
+	return append([]BucketUsageRollup(nil), rollups...), nil
+}
+
+// coalesceUsageQuery runs query once for all concurrent callers with the same key.
+//
+// The shared query isn't canceled together with the caller which started it,
+// instead every caller stops waiting for the result when its own ctx is canceled.
+func (usage *Service) coalesceUsageQuery(ctx context.Context, key string, query func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	queryCtx := context2.WithoutCancellation(ctx)
+	results := usage.usageQueries.DoChan(key, func() (interface{}, error) {
+		return query(queryCtx)
+	})
+	if usage.usageQueryJoined != nil {
+		usage.usageQueryJoined()
+	}
+
+	select {
+	case result := <-results:
+		return result.Val, result.Err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// usageQueryKey returns the key used to coalesce usage queries of a project.
+func usageQueryKey(query string, projectID uuid.UUID, since, before time.Time, asOfSystemInterval time.Duration) string {
+	return fmt.Sprintf("%s/%s/%d/%d/%d", query, projectID, since.UnixNano(), before.UnixNano(), asOfSystemInterval)
+}
+
 // GetProjectStorageLimit returns current project storage limit.
 func (usage *Service) GetProjectStorageLimit(ctx context.Context, projectID uuid.UUID) (_ memory.Size, err error) {
 	defer mon.Task()(&ctx, projectID)(&err)
@@ -225,3 +297,9 @@ func (usage *Service) AddProjectStorageUsage(ctx context.Context, projectID uuid
 func (usage *Service) SetNow(now func() time.Time) {
 	usage.nowFn = now
 }
+
+// TestingOnUsageQueryJoined sets a callback, which is called when a caller joined a coalesced
+// usage query, i.e. once it's guaranteed to get the result of the query in flight.
+func (usage *Service) TestingOnUsageQueryJoined(fn func()) {
+	usage.usageQueryJoined = fn
+}
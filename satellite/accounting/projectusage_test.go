@@ -8,6 +8,7 @@ import (
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -484,50 +485,130 @@ func TestUsageRollups(t *testing.T) {
 				bucketTallies[bucketLoc2] = tally2
 			}
 
-			err := db.ProjectAccounting().SaveTallies(ctx, interval, bucketTallies)
+			_, _, err := db.ProjectAccounting().SaveTallies(ctx, interval, bucketTallies)
 			require.NoError(t, err)
 		}
 
 		usageRollups := db.ProjectAccounting()
 
 		t.Run("test project total", func(t *testing.T) {
-			projTotal1, err := usageRollups.GetProjectTotal(ctx, project1, start, now)
+			projTotal1, err := usageRollups.GetProjectTotal(ctx, project1, start, now, 0)
 			require.NoError(t, err)
 			require.NotNil(t, projTotal1)
 
-			projTotal2, err := usageRollups.GetProjectTotal(ctx, project2, start, now)
+			projTotal2, err := usageRollups.GetProjectTotal(ctx, project2, start, now, 0)
 			require.NoError(t, err)
 			require.NotNil(t, projTotal2)
 		})
 
 		t.Run("test bucket usage rollups", func(t *testing.T) {
-			rollups1, err := usageRollups.GetBucketUsageRollups(ctx, project1, start, now)
+			rollups1, err := usageRollups.GetBucketUsageRollups(ctx, project1, start, now, 0)
 			require.NoError(t, err)
 			require.NotNil(t, rollups1)
 
-			rollups2, err := usageRollups.GetBucketUsageRollups(ctx, project2, start, now)
+			rollups2, err := usageRollups.GetBucketUsageRollups(ctx, project2, start, now, 0)
 			require.NoError(t, err)
 			require.NotNil(t, rollups2)
 		})
 
+		t.Run("test single bucket usage rollup", func(t *testing.T) {
+			rollups, err := usageRollups.GetBucketUsageRollups(ctx, project1, start, now, 0)
+			require.NoError(t, err)
+			require.NotEmpty(t, rollups)
+
+			for _, expected := range rollups {
+				rollup, err := usageRollups.GetSingleBucketUsageRollup(ctx, project1, string(expected.BucketName), start, now)
+				require.NoError(t, err)
+				require.Equal(t, expected, rollup)
+				require.True(t, rollup.HasData)
+			}
+
+			// a bucket without usage results in an empty rollup.
+			rollup, err := usageRollups.GetSingleBucketUsageRollup(ctx, project1, "no-usage", start, now)
+			require.NoError(t, err)
+			require.Equal(t, accounting.BucketUsageRollup{
+				ProjectID:  project1,
+				BucketName: []byte("no-usage"),
+				Since:      rollups[0].Since,
+				Before:     rollups[0].Before,
+			}, rollup)
+		})
+
 		t.Run("test bucket totals", func(t *testing.T) {
 			cursor := accounting.BucketUsageCursor{
 				Limit: 20,
 				Page:  1,
 			}
 
-			totals1, err := usageRollups.GetBucketTotals(ctx, project1, cursor, start, now)
+			totals1, err := usageRollups.GetBucketTotals(ctx, project1, cursor, start, now, 0)
 			require.NoError(t, err)
 			require.NotNil(t, totals1)
 
-			totals2, err := usageRollups.GetBucketTotals(ctx, project2, cursor, start, now)
+			totals2, err := usageRollups.GetBucketTotals(ctx, project2, cursor, start, now, 0)
 			require.NoError(t, err)
 			require.NotNil(t, totals2)
 		})
 
+		t.Run("as of system time", func(t *testing.T) {
+			// the interval is only applied on cockroach, make sure the
+			// queries are valid and return the same data as live reads.
+			cursor := accounting.BucketUsageCursor{
+				Limit: 20,
+				Page:  1,
+			}
+
+			expectedTotal, err := usageRollups.GetProjectTotal(ctx, project1, start, now, 0)
+			require.NoError(t, err)
+			projTotal, err := usageRollups.GetProjectTotal(ctx, project1, start, now, -time.Microsecond)
+			require.NoError(t, err)
+			require.Equal(t, expectedTotal, projTotal)
+
+			expectedRollups, err := usageRollups.GetBucketUsageRollups(ctx, project1, start, now, 0)
+			require.NoError(t, err)
+			rollups, err := usageRollups.GetBucketUsageRollups(ctx, project1, start, now, -time.Microsecond)
+			require.NoError(t, err)
+			require.Equal(t, expectedRollups, rollups)
+
+			expectedTotals, err := usageRollups.GetBucketTotals(ctx, project1, cursor, start, now, 0)
+			require.NoError(t, err)
+			totals, err := usageRollups.GetBucketTotals(ctx, project1, cursor, start, now, -time.Microsecond)
+			require.NoError(t, err)
+			require.Equal(t, expectedTotals, totals)
+		})
+
+		t.Run("non-UTC time zone", func(t *testing.T) {
+			// tallies are stored with UTC interval starts, truncating in a zone
+			// with a non-hour offset would shift the window by 45 minutes.
+			zone := time.FixedZone("UTC+13:45", (13*60+45)*60)
+			zonedStart, zonedNow := start.In(zone), now.In(zone)
+
+			cursor := accounting.BucketUsageCursor{
+				Limit: 20,
+				Page:  1,
+			}
+
+			expectedTotal, err := usageRollups.GetProjectTotal(ctx, project1, start.UTC(), now.UTC(), 0)
+			require.NoError(t, err)
+			projTotal, err := usageRollups.GetProjectTotal(ctx, project1, zonedStart, zonedNow, 0)
+			require.NoError(t, err)
+			require.Equal(t, expectedTotal, projTotal)
+
+			expectedRollups, err := usageRollups.GetBucketUsageRollups(ctx, project1, start.UTC(), now.UTC(), 0)
+			require.NoError(t, err)
+			rollups, err := usageRollups.GetBucketUsageRollups(ctx, project1, zonedStart, zonedNow, 0)
+			require.NoError(t, err)
+			require.Equal(t, expectedRollups, rollups)
+
+			expectedTotals, err := usageRollups.GetBucketTotals(ctx, project1, cursor, start.UTC(), now.UTC(), 0)
+			require.NoError(t, err)
+			totals, err := usageRollups.GetBucketTotals(ctx, project1, cursor, zonedStart, zonedNow, 0)
+			require.NoError(t, err)
+			require.Equal(t, expectedTotals, totals)
+		})
+
 		t.Run("Get paged", func(t *testing.T) {
 			// sql injection test. F.E '%SomeText%' = > ''%SomeText%' OR 'x' != '%'' will be true
-			bucketsPage, err := usageRollups.GetBucketTotals(ctx, project1, accounting.BucketUsageCursor{Limit: 5, Search: "buck%' OR 'x' != '", Page: 1}, start, now)
+			bucketsPage, err := usageRollups.GetBucketTotals(ctx, project1, accounting.BucketUsageCursor{Limit: 5, Search: "buck%' OR 'x' != '", Page: 1}, start, now, 0)
 			require.NoError(t, err)
 			require.NotNil(t, bucketsPage)
 			assert.Equal(t, uint64(0), bucketsPage.TotalCount)
@@ -535,7 +616,7 @@ func TestUsageRollups(t *testing.T) {
 			assert.Equal(t, uint(0), bucketsPage.PageCount)
 			assert.Equal(t, 0, len(bucketsPage.BucketUsages))
 
-			bucketsPage, err = usageRollups.GetBucketTotals(ctx, project1, accounting.BucketUsageCursor{Limit: 3, Search: "", Page: 1}, start, now)
+			bucketsPage, err = usageRollups.GetBucketTotals(ctx, project1, accounting.BucketUsageCursor{Limit: 3, Search: "", Page: 1}, start, now, 0)
 			require.NoError(t, err)
 			require.NotNil(t, bucketsPage)
 			assert.Equal(t, uint64(5), bucketsPage.TotalCount)
@@ -543,7 +624,7 @@ func TestUsageRollups(t *testing.T) {
 			assert.Equal(t, uint(2), bucketsPage.PageCount)
 			assert.Equal(t, 3, len(bucketsPage.BucketUsages))
 
-			bucketsPage, err = usageRollups.GetBucketTotals(ctx, project1, accounting.BucketUsageCursor{Limit: 5, Search: "buck", Page: 1}, start, now)
+			bucketsPage, err = usageRollups.GetBucketTotals(ctx, project1, accounting.BucketUsageCursor{Limit: 5, Search: "buck", Page: 1}, start, now, 0)
 			require.NoError(t, err)
 			require.NotNil(t, bucketsPage)
 			assert.Equal(t, uint64(5), bucketsPage.TotalCount)
@@ -551,7 +632,7 @@ func TestUsageRollups(t *testing.T) {
 			assert.Equal(t, uint(1), bucketsPage.PageCount)
 			assert.Equal(t, 5, len(bucketsPage.BucketUsages))
 
-			bucketsPage, err = usageRollups.GetBucketTotals(ctx, project1, accounting.BucketUsageCursor{Limit: 5, Search: "bucket-0", Page: 1}, start, now)
+			bucketsPage, err = usageRollups.GetBucketTotals(ctx, project1, accounting.BucketUsageCursor{Limit: 5, Search: "bucket-0", Page: 1}, start, now, 0)
 			require.NoError(t, err)
 			require.NotNil(t, bucketsPage)
 			assert.Equal(t, uint64(1), bucketsPage.TotalCount)
@@ -559,7 +640,7 @@ func TestUsageRollups(t *testing.T) {
 			assert.Equal(t, uint(1), bucketsPage.PageCount)
 			assert.Equal(t, 1, len(bucketsPage.BucketUsages))
 
-			bucketsPage, err = usageRollups.GetBucketTotals(ctx, project1, accounting.BucketUsageCursor{Limit: 5, Search: "buck\xff", Page: 1}, start, now)
+			bucketsPage, err = usageRollups.GetBucketTotals(ctx, project1, accounting.BucketUsageCursor{Limit: 5, Search: "buck\xff", Page: 1}, start, now, 0)
 			require.NoError(t, err)
 			require.NotNil(t, bucketsPage)
 			assert.Equal(t, uint64(0), bucketsPage.TotalCount)
@@ -570,6 +651,82 @@ func TestUsageRollups(t *testing.T) {
 	})
 }
 
+func TestGetBucketTotalsConcurrentBucketCreation(t *testing.T) {
+	testplanet.Run(t, testplanet.Config{
+		SatelliteCount: 1, StorageNodeCount: 0, UplinkCount: 1,
+	}, func(t *testing.T, ctx *testcontext.Context, planet *testplanet.Planet) {
+		satelliteSys := planet.Satellites[0]
+		uplinkPeer := planet.Uplinks[0]
+		projectID := uplinkPeer.Projects[0].ID
+		projectAccounting := satelliteSys.DB.ProjectAccounting()
+
+		const existingBuckets = 10
+		var expected []string
+		for i := 0; i < existingBuckets; i++ {
+			bucketName := fmt.Sprintf("bucket-%02d", 2*i+1)
+			require.NoError(t, uplinkPeer.CreateBucket(ctx, satelliteSys, bucketName))
+			expected = append(expected, bucketName)
+		}
+
+		now := time.Now()
+		start := now.Add(-time.Hour)
+
+		var group errgroup.Group
+		group.Go(func() error {
+			// new buckets sort in between the existing ones.
+			for i := 0; i <= existingBuckets; i++ {
+				if err := uplinkPeer.CreateBucket(ctx, satelliteSys, fmt.Sprintf("bucket-%02d", 2*i)); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+
+		var listed []string
+		cursor := accounting.BucketUsageCursor{Limit: 2, Page: 1}
+		for {
+			page, err := projectAccounting.GetBucketTotals(ctx, projectID, cursor, start, now, 0)
+			require.NoError(t, err)
+			require.LessOrEqual(t, uint64(len(page.BucketUsages)), page.TotalCount)
+
+			for _, usage := range page.BucketUsages {
+				listed = append(listed, usage.BucketName)
+			}
+			if page.NextCursor == "" {
+				break
+			}
+			require.Equal(t, listed[len(listed)-1], page.NextCursor)
+			cursor.StartAfter = page.NextCursor
+			cursor.Page++
+		}
+		require.NoError(t, group.Wait())
+
+		// buckets are listed in order, each one once and none of the existing ones is skipped.
+		for i := 1; i < len(listed); i++ {
+			require.Less(t, listed[i-1], listed[i])
+		}
+		require.Subset(t, listed, expected)
+
+		// once the creation finished, paging lists all the buckets.
+		listed = listed[:0]
+		cursor = accounting.BucketUsageCursor{Limit: 3, Page: 1}
+		for {
+			page, err := projectAccounting.GetBucketTotals(ctx, projectID, cursor, start, now, 0)
+			require.NoError(t, err)
+			require.EqualValues(t, 2*existingBuckets+1, page.TotalCount)
+
+			for _, usage := range page.BucketUsages {
+				listed = append(listed, usage.BucketName)
+			}
+			if page.NextCursor == "" {
+				break
+			}
+			cursor.StartAfter = page.NextCursor
+		}
+		require.Len(t, listed, 2*existingBuckets+1)
+	})
+}
+
 func TestProjectUsage_FreeUsedStorageSpace(t *testing.T) {
 	testplanet.Run(t, testplanet.Config{
 		SatelliteCount: 1, StorageNodeCount: 4, UplinkCount: 1,
@@ -814,3 +971,157 @@ func TestProjectUsage_BandwidthDownloadLimit(t *testing.T) {
 		require.NoError(t, err)
 	})
 }
+
+// blockingProjectAccounting counts GetProjectTotal calls and blocks them until released.
+type blockingProjectAccounting struct {
+	accounting.ProjectAccounting
+
+	calls   int64
+	entered chan struct{}
+	release chan struct{}
+	usage   *accounting.ProjectUsage
+	err     error
+	ctxErr  error
+}
+
+func (db *blockingProjectAccounting) GetProjectTotal(ctx context.Context, projectID uuid.UUID, since, before time.Time, asOfSystemInterval time.Duration) (*accounting.ProjectUsage, error) {
+	atomic.AddInt64(&db.calls, 1)
+	db.entered <- struct{}{}
+	<-db.release
+	db.ctxErr = ctx.Err()
+	if db.err != nil {
+		return nil, db.err
+	}
+	usage := *db.usage
+	return &usage, nil
+}
+
+func TestProjectUsage_CoalesceGetProjectTotal(t *testing.T) {
+	const callers = 10
+
+	ctx := testcontext.New(t)
+	defer ctx.Cleanup()
+
+	projectID := testrand.UUID()
+	since := time.Date(2021, 5, 1, 10, 15, 0, 0, time.UTC)
+	before := time.Date(2021, 5, 20, 8, 30, 0, 0, time.UTC)
+
+	run := func(t *testing.T, db *blockingProjectAccounting) ([]*accounting.ProjectUsage, []error) {
+		service := accounting.NewService(db, nil, nil, 0, 0)
+
+		var joined sync.WaitGroup
+		joined.Add(callers)
+		service.TestingOnUsageQueryJoined(joined.Done)
+
+		usages := make([]*accounting.ProjectUsage, callers)
+		callErrs := make([]error, callers)
+
+		var group errgroup.Group
+		for i := 0; i < callers; i++ {
+			i := i
+			group.Go(func() error {
+				usages[i], callErrs[i] = service.GetProjectTotal(ctx, projectID, since, before, 0)
+				return nil
+			})
+		}
+
+		<-db.entered
+		// the query is blocked, so all callers join the query in flight.
+		joined.Wait()
+		close(db.release)
+
+		require.NoError(t, group.Wait())
+		require.EqualValues(t, 1, atomic.LoadInt64(&db.calls))
+		return usages, callErrs
+	}
+
+	t.Run("result", func(t *testing.T) {
+		db := &blockingProjectAccounting{
+			entered: make(chan struct{}, callers),
+			release: make(chan struct{}),
+			usage:   &accounting.ProjectUsage{Storage: 10, Egress: 20, ObjectCount: 30},
+		}
+
+		usages, callErrs := run(t, db)
+		for i := range usages {
+			require.NoError(t, callErrs[i])
+			require.Equal(t, db.usage, usages[i])
+		}
+		// callers get their own copies of the result.
+		usages[0].Storage = 0
+		require.EqualValues(t, 10, usages[1].Storage)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		db := &blockingProjectAccounting{
+			entered: make(chan struct{}, callers),
+			release: make(chan struct{}),
+			err:     errs.New("failure"),
+		}
+
+		usages, callErrs := run(t, db)
+		for i := range usages {
+			require.Error(t, callErrs[i])
+			require.True(t, accounting.ErrProjectUsage.Has(callErrs[i]))
+			require.Nil(t, usages[i])
+		}
+	})
+
+	t.Run("different periods", func(t *testing.T) {
+		db := &blockingProjectAccounting{
+			entered: make(chan struct{}, 2),
+			release: make(chan struct{}),
+			usage:   &accounting.ProjectUsage{},
+		}
+		close(db.release)
+		service := accounting.NewService(db, nil, nil, 0, 0)
+
+		_, err := service.GetProjectTotal(ctx, projectID, since, before, 0)
+		require.NoError(t, err)
+		// periods within the same hour are queried separately.
+		_, err = service.GetProjectTotal(ctx, projectID, since.Add(time.Minute), before, 0)
+		require.NoError(t, err)
+		require.EqualValues(t, 2, atomic.LoadInt64(&db.calls))
+	})
+
+	t.Run("canceled caller", func(t *testing.T) {
+		db := &blockingProjectAccounting{
+			entered: make(chan struct{}, 1),
+			release: make(chan struct{}),
+			usage:   &accounting.ProjectUsage{Storage: 10},
+		}
+		service := accounting.NewService(db, nil, nil, 0, 0)
+
+		var joined sync.WaitGroup
+		joined.Add(2)
+		service.TestingOnUsageQueryJoined(joined.Done)
+
+		// the caller which started the query stops waiting when it's canceled.
+		callerCtx, cancel := context.WithCancel(ctx)
+		canceled := make(chan error, 1)
+		go func() {
+			_, err := service.GetProjectTotal(callerCtx, projectID, since, before, 0)
+			canceled <- err
+		}()
+		<-db.entered
+
+		var usage *accounting.ProjectUsage
+		var group errgroup.Group
+		group.Go(func() (err error) {
+			usage, err = service.GetProjectTotal(ctx, projectID, since, before, 0)
+			return err
+		})
+		joined.Wait()
+
+		cancel()
+		err := <-canceled
+		require.True(t, errors.Is(err, context.Canceled))
+
+		// the shared query isn't canceled and other callers get its result.
+		close(db.release)
+		require.NoError(t, group.Wait())
+		require.Equal(t, db.usage, usage)
+		require.NoError(t, db.ctxErr)
+		require.EqualValues(t, 1, atomic.LoadInt64(&db.calls))
+	})
+}
@@ -0,0 +1,127 @@
+// Copyright (C) 2021 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package accounting
+
+import (
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// RetentionMode decides what happens with accounting rows past their retention.
+type RetentionMode string
+
+const (
+	// RetentionArchive moves rows past the retention to the archive table.
+	RetentionArchive RetentionMode = "archive"
+	// RetentionDelete deletes rows past the retention.
+	RetentionDelete RetentionMode = "delete"
+)
+
+// TableRetention is the retention of a single accounting table.
+// A zero Retention keeps the rows of the table forever.
+type TableRetention struct {
+	Retention time.Duration
+	Mode      RetentionMode
+}
+
+// Enabled returns whether any rows of the table are past the retention.
+func (retention TableRetention) Enabled() bool { return retention.Retention > 0 }
+
+// Cutoff returns the time before which rows are past the retention at now.
+func (retention TableRetention) Cutoff(now time.Time) time.Time {
+	return now.UTC().Add(-retention.Retention)
+}
+
+// RetentionConfig configures ProjectAccounting.ApplyRetention.
+type RetentionConfig struct {
+	// BandwidthRollups is the retention of bucket bandwidth rollups.
+	BandwidthRollups TableRetention
+	// StorageTallies is the retention of bucket storage tallies.
+	StorageTallies TableRetention
+	// DailyBandwidthRollups is the retention of project bandwidth daily rollups.
+	// They don't have an archive, so only RetentionDelete is supported.
+	DailyBandwidthRollups TableRetention
+
+	// BatchSize is the number of rows archived or deleted at once.
+	BatchSize int
+}
+
+// Validate checks that the retention of every table can be applied.
+func (config RetentionConfig) Validate() error {
+	if config.BatchSize <= 0 {
+		return ErrInvalidArgument.New("batch size must be positive, got %d", config.BatchSize)
+	}
+
+	for _, table := range []struct {
+		name      string
+		retention TableRetention
+		modes     []RetentionMode
+	}{
+		{"bandwidth rollups", config.BandwidthRollups, []RetentionMode{RetentionArchive, RetentionDelete}},
+		{"storage tallies", config.StorageTallies, []RetentionMode{RetentionArchive, RetentionDelete}},
+		{"daily bandwidth rollups", config.DailyBandwidthRollups, []RetentionMode{RetentionDelete}},
+	} {
+		if table.retention.Retention < 0 {
+			return ErrInvalidArgument.New("%s: retention can not be negative", table.name)
+		}
+		if !table.retention.Enabled() {
+			continue
+		}
+
+		supported := false
+		for _, mode := range table.modes {
+			supported = supported || table.retention.Mode == mode
+		}
+		if !supported {
+			return ErrInvalidArgument.New("%s: unsupported retention mode %q", table.name, table.retention.Mode)
+		}
+	}
+
+	return nil
+}
+
+// RetentionResult is the outcome of applying the retention to a single table.
+type RetentionResult struct {
+	Mode   RetentionMode
+	Cutoff time.Time
+	// Rows is the number of archived or deleted rows.
+	Rows int64
+}
+
+// MarshalLogObject implements zapcore.ObjectMarshaler.
+func (result RetentionResult) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("mode", string(result.Mode))
+	enc.AddTime("cutoff", result.Cutoff)
+	enc.AddInt64("rows", result.Rows)
+	return nil
+}
+
+// RetentionSummary contains the outcome of ProjectAccounting.ApplyRetention per table.
+// Tables without retention are left zero.
+type RetentionSummary struct {
+	BandwidthRollups      RetentionResult
+	StorageTallies        RetentionResult
+	DailyBandwidthRollups RetentionResult
+}
+
+// MarshalLogObject implements zapcore.ObjectMarshaler, so that the summary can be logged with zap.Object.
+func (summary RetentionSummary) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	for _, table := range []struct {
+		name   string
+		result RetentionResult
+	}{
+		{"bucket_bandwidth_rollups", summary.BandwidthRollups},
+		{"bucket_storage_tallies", summary.StorageTallies},
+		{"project_bandwidth_daily_rollups", summary.DailyBandwidthRollups},
+	} {
+		if table.result.Mode == "" {
+			continue
+		}
+		if err := enc.AddObject(table.name, table.result); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -25,11 +25,12 @@ var (
 type Config struct {
 	Interval   time.Duration `help:"how frequently rollup archiver should run" releaseDefault:"24h" devDefault:"120s" testDefault:"$TESTINTERVAL"`
 	ArchiveAge time.Duration `help:"age at which a rollup is archived" default:"2160h" testDefault:"24h"`
-	BatchSize  int           `help:"number of records to delete per delete execution. Used only for crdb which is slow without limit." default:"500" testDefault:"1000"`
+	BatchSize  int           `help:"number of records to move to the archive tables per delete execution." default:"500" testDefault:"1000"`
 	Enabled    bool          `help:"whether or not the rollup archive is enabled." default:"true"`
+	DryRun     bool          `help:"only log the number of rollups and tallies which would be archived, without archiving them." default:"false"`
 }
 
-// Chore archives bucket and storagenode rollups at a given interval.
+// Chore archives bucket and storagenode rollups and bucket storage tallies at a given interval.
 //
 // architecture: Chore
 type Chore struct {
@@ -37,6 +38,7 @@ type Chore struct {
 	Loop              *sync2.Cycle
 	archiveAge        time.Duration
 	batchSize         int
+	dryRun            bool
 	nodeAccounting    accounting.StoragenodeAccounting
 	projectAccounting accounting.ProjectAccounting
 }
@@ -48,6 +50,7 @@ func New(log *zap.Logger, sdb accounting.StoragenodeAccounting, pdb accounting.P
 		Loop:              sync2.NewCycle(config.Interval),
 		archiveAge:        config.ArchiveAge,
 		batchSize:         config.BatchSize,
+		dryRun:            config.DryRun,
 		nodeAccounting:    sdb,
 		projectAccounting: pdb,
 	}
@@ -61,9 +64,13 @@ func (chore *Chore) Run(ctx context.Context) (err error) {
 	}
 	return chore.Loop.Run(ctx, func(ctx context.Context) error {
 		cutoff := time.Now().UTC().Add(-chore.archiveAge)
-		err := chore.ArchiveRollups(ctx, cutoff, chore.batchSize)
+		archive := chore.ArchiveRollups
+		if chore.dryRun {
+			archive = chore.ArchiveRollupsDryRun
+		}
+		err := archive(ctx, cutoff, chore.batchSize)
 		if err != nil {
-			chore.log.Error("error archiving SN and bucket bandwidth rollups", zap.Error(err))
+			chore.log.Error("error archiving SN and bucket bandwidth rollups and bucket tallies", zap.Error(err))
 		}
 		return nil
 	})
@@ -75,7 +82,7 @@ func (chore *Chore) Close() error {
 	return nil
 }
 
-// ArchiveRollups will remove old rollups from active rollup tables.
+// ArchiveRollups will remove old rollups and bucket storage tallies from active tables.
 func (chore *Chore) ArchiveRollups(ctx context.Context, cutoff time.Time, batchSize int) (err error) {
 	defer mon.Task()(&ctx)(&err)
 	nodeRollupsArchived, err := chore.nodeAccounting.ArchiveRollupsBefore(ctx, cutoff, batchSize)
@@ -83,10 +90,48 @@ func (chore *Chore) ArchiveRollups(ctx context.Context, cutoff time.Time, batchS
 		chore.log.Error("archiving bandwidth rollups", zap.Int("node rollups archived", nodeRollupsArchived), zap.Error(err))
 		return Error.Wrap(err)
 	}
-	bucketRollupsArchived, err := chore.projectAccounting.ArchiveRollupsBefore(ctx, cutoff, batchSize)
+	bucketRollupStats, err := chore.projectAccounting.ArchiveRollupsBeforeWithStats(ctx, cutoff, batchSize)
+	bucketRollupsArchived := 0
+	for action, stats := range bucketRollupStats {
+		bucketRollupsArchived += stats.Count
+		chore.log.Info("archived bucket bandwidth rollups",
+			zap.Stringer("action", action),
+			zap.Int("count", stats.Count),
+			zap.Duration("elapsed", stats.Elapsed))
+	}
 	if err != nil {
 		chore.log.Error("archiving bandwidth rollups", zap.Int("bucket rollups archived", bucketRollupsArchived), zap.Error(err))
 		return Error.Wrap(err)
 	}
+	talliesArchived, err := chore.projectAccounting.ArchiveStorageTalliesBefore(ctx, cutoff, batchSize)
+	if err != nil {
+		chore.log.Error("archiving bucket storage tallies", zap.Int("bucket tallies archived", talliesArchived), zap.Error(err))
+		return Error.Wrap(err)
+	}
+	return nil
+}
+
+// ArchiveRollupsDryRun logs the number of rollups and bucket storage tallies ArchiveRollups
+// would archive, without archiving them.
+func (chore *Chore) ArchiveRollupsDryRun(ctx context.Context, cutoff time.Time, batchSize int) (err error) {
+	defer mon.Task()(&ctx)(&err)
+	nodeRollups, err := chore.nodeAccounting.ArchiveRollupsBeforeDryRun(ctx, cutoff, batchSize)
+	if err != nil {
+		return Error.Wrap(err)
+	}
+	bucketRollups, err := chore.projectAccounting.ArchiveRollupsBeforeDryRun(ctx, cutoff, batchSize)
+	if err != nil {
+		return Error.Wrap(err)
+	}
+	tallies, err := chore.projectAccounting.ArchiveStorageTalliesBeforeDryRun(ctx, cutoff, batchSize)
+	if err != nil {
+		return Error.Wrap(err)
+	}
+
+	chore.log.Info("dry run: node rollups to archive", zap.Time("cutoff", cutoff), zap.Int("count", nodeRollups))
+	for action, count := range bucketRollups {
+		chore.log.Info("dry run: bucket bandwidth rollups to archive", zap.Time("cutoff", cutoff), zap.Stringer("action", action), zap.Int("count", count))
+	}
+	chore.log.Info("dry run: bucket storage tallies to archive", zap.Time("cutoff", cutoff), zap.Int("count", tallies))
 	return nil
 }
@@ -16,6 +16,7 @@ import (
 	"storj.io/common/testrand"
 	"storj.io/storj/private/testplanet"
 	"storj.io/storj/satellite"
+	"storj.io/storj/satellite/accounting"
 )
 
 func TestRollupArchiveChore(t *testing.T) {
@@ -30,11 +31,12 @@ func TestRollupArchiveChore(t *testing.T) {
 	},
 		func(t *testing.T, ctx *testcontext.Context, planet *testplanet.Planet) {
 			// The purpose of this test is to ensure that the archive chore deletes
-			// entries in the storagenode_bandwidth_rollups and bucket_bandwidth_rollups tables
-			// and inserts those entries into new archive tables.
+			// entries in the storagenode_bandwidth_rollups, bucket_bandwidth_rollups and
+			// bucket_storage_tallies tables and inserts those entries into new archive tables.
 
 			satellite := planet.Satellites[0]
 			satellite.Accounting.Rollup.Loop.Pause()
+			satellite.Accounting.Tally.Loop.Pause()
 
 			days := 6
 
@@ -61,6 +63,15 @@ func TestRollupArchiveChore(t *testing.T) {
 					nodeID, pb.PieceAction_GET, bwAmount, timestamp)
 				require.NoError(t, err)
 
+				err = satellite.DB.ProjectAccounting().CreateStorageTally(ctx, accounting.BucketStorageTally{
+					BucketName:    bucketName,
+					ProjectID:     projectID,
+					IntervalStart: timestamp,
+					ObjectCount:   1,
+					TotalBytes:    bwAmount,
+				})
+				require.NoError(t, err)
+
 				// Advance time by 24 hours
 				timestamp = timestamp.Add(time.Hour * 24)
 			}
@@ -74,6 +85,10 @@ func TestRollupArchiveChore(t *testing.T) {
 			require.NoError(t, err)
 			require.Len(t, bucketRollups, days)
 
+			tallies, err := satellite.DB.ProjectAccounting().GetTallies(ctx)
+			require.NoError(t, err)
+			require.Len(t, tallies, days)
+
 			// We take off a millisecond so the before isn't exactly the same as one of the interval starts.
 			before := currentTime.AddDate(0, 0, -days/2).Add(-time.Millisecond)
 			batchSize := 1000
@@ -95,5 +110,32 @@ func TestRollupArchiveChore(t *testing.T) {
 			bucketRollups, err = satellite.DB.ProjectAccounting().GetArchivedRollupsSince(ctx, lastWeek)
 			require.NoError(t, err)
 			require.Len(t, bucketRollups, days/2)
+
+			tallies, err = satellite.DB.ProjectAccounting().GetTallies(ctx)
+			require.NoError(t, err)
+			require.Len(t, tallies, days-days/2)
+
+			archivedTallies, err := satellite.DB.ProjectAccounting().GetArchivedTalliesSince(ctx, lastWeek)
+			require.NoError(t, err)
+			require.Len(t, archivedTallies, days/2)
+			for i, tally := range archivedTallies {
+				require.Equal(t, fmt.Sprintf("%s%d", "testbucket", i), tally.BucketName)
+				require.Equal(t, projectID, tally.ProjectID)
+				require.EqualValues(t, 1000, tally.TotalBytes)
+				require.True(t, tally.IntervalStart.Before(before))
+			}
+
+			// archiving the remaining tallies in batches of one.
+			archived, err := satellite.DB.ProjectAccounting().ArchiveStorageTalliesBefore(ctx, currentTime, 1)
+			require.NoError(t, err)
+			require.Equal(t, days-days/2, archived)
+
+			tallies, err = satellite.DB.ProjectAccounting().GetTallies(ctx)
+			require.NoError(t, err)
+			require.Empty(t, tallies)
+
+			archivedTallies, err = satellite.DB.ProjectAccounting().GetArchivedTalliesSince(ctx, lastWeek)
+			require.NoError(t, err)
+			require.Len(t, archivedTallies, days)
 		})
 }
@@ -25,12 +25,18 @@ var (
 
 // Config contains configurable values for the tally service.
 type Config struct {
-	Interval            time.Duration `help:"how frequently the tally service should run" releaseDefault:"1h" devDefault:"30s" testDefault:"$TESTINTERVAL"`
-	SaveRollupBatchSize int           `help:"how large of batches SaveRollup should process at a time" default:"1000"`
-	ReadRollupBatchSize int           `help:"how large of batches GetBandwidthSince should process at a time" default:"10000"`
+	Interval             time.Duration `help:"how frequently the tally service should run" releaseDefault:"1h" devDefault:"30s" testDefault:"$TESTINTERVAL"`
+	SaveRollupBatchSize  int           `help:"how large of batches SaveRollup should process at a time" default:"1000"`
+	ReadRollupBatchSize  int           `help:"how large of batches GetBandwidthSince should process at a time" default:"10000"`
+	SaveTalliesBatchSize int           `help:"how large of batches SaveTallies should process at a time" default:"10000"`
 
 	ListLimit          int           `help:"how many objects to query in a batch" default:"2500"`
 	AsOfSystemInterval time.Duration `help:"as of system interval" releaseDefault:"-5m" devDefault:"-1us" testDefault:"-1us"`
+
+	ExpectedInterval time.Duration `help:"how often tallies are expected to be taken, the most recent tally of a usage period is accounted for this duration (0 = the most recent tally isn't accounted)" default:"0"`
+	MaxGap           time.Duration `help:"maximum duration a single tally is accounted for in storage usage, so that missed tally runs don't inflate usage (0 = unlimited)" default:"0"`
+
+	StrictAccountingReads bool `help:"fail accounting listings on rows with a corrupt project id instead of skipping them" default:"false"`
 }
 
 // Service is the tally service for data stored on each storage node.
@@ -180,10 +186,17 @@ func (service *Service) Tally(ctx context.Context) (err error) {
 	var errAtRest error
 	if len(collector.Bucket) > 0 {
 		// record bucket tallies to DB
-		err = service.projectAccountingDB.SaveTallies(ctx, finishTime, collector.Bucket)
+		_, invalid, err := service.projectAccountingDB.SaveTallies(ctx, finishTime, collector.Bucket)
 		if err != nil {
 			errAtRest = Error.New("ProjectAccounting.SaveTallies failed: %v", err)
 		}
+		for location, err := range invalid {
+			service.log.Warn("skipped invalid bucket tally",
+				zap.Error(err),
+				zap.String("projectID", location.ProjectID.String()),
+				zap.String("bucket", location.BucketName),
+			)
+		}
 
 		updateLiveAccountingTotals(projectTotalsFromBuckets(collector.Bucket))
 	}
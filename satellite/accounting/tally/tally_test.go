@@ -101,7 +101,7 @@ func TestOnlyInline(t *testing.T) {
 			require.NoError(t, err)
 
 			now := time.Now().Add(time.Duration(i) * time.Second)
-			err = planet.Satellites[0].DB.ProjectAccounting().SaveTallies(ctx, now, collector.Bucket)
+			_, _, err = planet.Satellites[0].DB.ProjectAccounting().SaveTallies(ctx, now, collector.Bucket)
 			require.NoError(t, err)
 
 			assert.Equal(t, 1, len(collector.Bucket))
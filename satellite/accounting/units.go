@@ -0,0 +1,27 @@
+// Copyright (C) 2021 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package accounting
+
+import (
+	"storj.io/common/memory"
+)
+
+// HoursPerMonth is the number of hours in a billing month. For the purpose of billing,
+// the billing month is always 30 days.
+const HoursPerMonth = 24 * 30
+
+// StorageGBMonths returns Storage converted from byte-hours to GB-months.
+func (usage *ProjectUsage) StorageGBMonths() float64 {
+	return usage.Storage / memory.GB.Float64() / HoursPerMonth
+}
+
+// EgressGB returns Egress converted from bytes to GB.
+func (usage *ProjectUsage) EgressGB() float64 {
+	return memory.Size(usage.Egress).GB()
+}
+
+// ObjectMonths returns ObjectCount converted from object-hours to object-months.
+func (usage *ProjectUsage) ObjectMonths() float64 {
+	return usage.ObjectCount / HoursPerMonth
+}
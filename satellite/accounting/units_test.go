@@ -0,0 +1,52 @@
+// Copyright (C) 2021 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package accounting_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"storj.io/storj/satellite/accounting"
+)
+
+func TestProjectUsageUnits(t *testing.T) {
+	require.EqualValues(t, 720, accounting.HoursPerMonth)
+
+	for _, tt := range []struct {
+		usage           accounting.ProjectUsage
+		storageGBMonths float64
+		egressGB        float64
+		objectMonths    float64
+	}{
+		{
+			usage: accounting.ProjectUsage{},
+		},
+		{
+			// 1.5GB and 10 objects stored for the whole month.
+			usage: accounting.ProjectUsage{
+				Storage:     1.5e9 * 720,
+				Egress:      2.5e9,
+				ObjectCount: 10 * 720,
+			},
+			storageGBMonths: 1.5,
+			egressGB:        2.5,
+			objectMonths:    10,
+		},
+		{
+			usage: accounting.ProjectUsage{
+				Storage:     123456789012345,
+				Egress:      987654321,
+				ObjectCount: 54321,
+			},
+			storageGBMonths: 171.46776251714584,
+			egressGB:        0.987654321,
+			objectMonths:    75.44583333333334,
+		},
+	} {
+		require.Equal(t, tt.storageGBMonths, tt.usage.StorageGBMonths())
+		require.Equal(t, tt.egressGB, tt.usage.EgressGB())
+		require.Equal(t, tt.objectMonths, tt.usage.ObjectMonths())
+	}
+}
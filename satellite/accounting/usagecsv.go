@@ -0,0 +1,64 @@
+// Copyright (C) 2021 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package accounting
+
+import (
+	"context"
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+
+	"storj.io/common/uuid"
+)
+
+// BucketUsageCSVHeader is the header of bucket usage CSV exports.
+var BucketUsageCSVHeader = []string{
+	"bucket",
+	"stored_gb_hours",
+	"segment_hours",
+	"object_hours",
+	"metadata_gb_hours",
+	"get_egress_gb",
+	"audit_egress_gb",
+	"repair_egress_gb",
+}
+
+// ExportBucketUsageCSV writes usage rollups of every bucket of the project for a given
+// period as CSV to w. Rows are written as soon as the usage of a bucket is computed.
+func (usage *Service) ExportBucketUsageCSV(ctx context.Context, projectID uuid.UUID, since, before time.Time, w io.Writer) (err error) {
+	defer mon.Task()(&ctx, projectID)(&err)
+
+	out := csv.NewWriter(w)
+	writeRow := func(row []string) error {
+		if err := out.Write(row); err != nil {
+			return err
+		}
+		out.Flush()
+		return out.Error()
+	}
+
+	if err := writeRow(BucketUsageCSVHeader); err != nil {
+		return ErrProjectUsage.Wrap(err)
+	}
+
+	formatFloat := func(v float64) string {
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+
+	err = usage.projectAccountingDB.IterateBucketUsageRollups(ctx, projectID, since, before, usage.asOfSystemInterval,
+		func(ctx context.Context, rollup BucketUsageRollup) error {
+			return writeRow([]string{
+				string(rollup.BucketName),
+				formatFloat(rollup.TotalStoredData),
+				formatFloat(rollup.TotalSegments),
+				formatFloat(rollup.ObjectCount),
+				formatFloat(rollup.MetadataSize),
+				formatFloat(rollup.GetEgress),
+				formatFloat(rollup.AuditEgress),
+				formatFloat(rollup.RepairEgress),
+			})
+		})
+	return ErrProjectUsage.Wrap(err)
+}
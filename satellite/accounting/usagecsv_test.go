@@ -0,0 +1,124 @@
+// Copyright (C) 2021 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package accounting_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"storj.io/common/memory"
+	"storj.io/common/pb"
+	"storj.io/common/testcontext"
+	"storj.io/common/testrand"
+	"storj.io/common/uuid"
+	"storj.io/storj/satellite"
+	"storj.io/storj/satellite/accounting"
+	"storj.io/storj/satellite/satellitedb/satellitedbtest"
+)
+
+func TestExportBucketUsageCSV(t *testing.T) {
+	satellitedbtest.Run(t, func(ctx *testcontext.Context, t *testing.T, db satellite.DB) {
+		projectID := testrand.UUID()
+		now := time.Now().UTC().Truncate(time.Hour)
+		since, before := now.Add(-10*time.Hour), now
+
+		buckets := []string{"plain", `with,comma "and quotes"`, "multi\nline"}
+		for i, bucket := range buckets {
+			for hour := 0; hour < 5; hour++ {
+				err := db.ProjectAccounting().CreateStorageTally(ctx, accounting.BucketStorageTally{
+					BucketName:        bucket,
+					ProjectID:         projectID,
+					IntervalStart:     since.Add(time.Duration(hour) * time.Hour),
+					ObjectCount:       int64(i + hour),
+					TotalSegmentCount: int64(2 * (i + hour)),
+					TotalBytes:        int64(i+1) * memory.GB.Int64(),
+					MetadataSize:      int64(i+1) * memory.KB.Int64(),
+				})
+				require.NoError(t, err)
+			}
+
+			for _, action := range []pb.PieceAction{pb.PieceAction_GET, pb.PieceAction_GET_AUDIT, pb.PieceAction_GET_REPAIR} {
+				err := db.Orders().UpdateBucketBandwidthSettle(ctx, projectID, []byte(bucket), action, int64(i+1)*int64(action)*memory.MB.Int64(), since.Add(time.Hour))
+				require.NoError(t, err)
+			}
+		}
+
+		service := accounting.NewService(db.ProjectAccounting(), nil, nil, 0, 0)
+
+		var buf bytes.Buffer
+		require.NoError(t, service.ExportBucketUsageCSV(ctx, projectID, since, before, &buf))
+
+		records, err := csv.NewReader(&buf).ReadAll()
+		require.NoError(t, err)
+		require.Equal(t, accounting.BucketUsageCSVHeader, records[0])
+
+		rollups, err := db.ProjectAccounting().GetBucketUsageRollups(ctx, projectID, since, before, 0)
+		require.NoError(t, err)
+		require.Len(t, rollups, len(buckets))
+		require.Len(t, records, len(rollups)+1)
+
+		parse := func(s string) float64 {
+			v, err := strconv.ParseFloat(s, 64)
+			require.NoError(t, err)
+			return v
+		}
+
+		for i, rollup := range rollups {
+			record := records[i+1]
+			require.Equal(t, string(rollup.BucketName), record[0])
+			require.Equal(t, rollup.TotalStoredData, parse(record[1]))
+			require.Equal(t, rollup.TotalSegments, parse(record[2]))
+			require.Equal(t, rollup.ObjectCount, parse(record[3]))
+			require.Equal(t, rollup.MetadataSize, parse(record[4]))
+			require.Equal(t, rollup.GetEgress, parse(record[5]))
+			require.Equal(t, rollup.AuditEgress, parse(record[6]))
+			require.Equal(t, rollup.RepairEgress, parse(record[7]))
+		}
+	})
+}
+
+func TestExportBucketUsageCSV_Streaming(t *testing.T) {
+	ctx := testcontext.New(t)
+	defer ctx.Cleanup()
+
+	db := &iteratingProjectAccounting{
+		rollups: []accounting.BucketUsageRollup{
+			{BucketName: []byte("first"), TotalStoredData: 1},
+			{BucketName: []byte("second"), TotalStoredData: 2},
+		},
+	}
+	service := accounting.NewService(db, nil, nil, 0, 0)
+
+	var buf bytes.Buffer
+	db.written = func() int { return bytes.Count(buf.Bytes(), []byte("\n")) }
+
+	require.NoError(t, service.ExportBucketUsageCSV(ctx, testrand.UUID(), time.Now(), time.Now(), &buf))
+	// header and every previous row are written before the next rollup is computed.
+	require.Equal(t, []int{1, 2}, db.writtenBefore)
+}
+
+// iteratingProjectAccounting records how many lines were written before each rollup is produced.
+type iteratingProjectAccounting struct {
+	accounting.ProjectAccounting
+
+	rollups       []accounting.BucketUsageRollup
+	written       func() int
+	writtenBefore []int
+}
+
+func (db *iteratingProjectAccounting) IterateBucketUsageRollups(ctx context.Context, projectID uuid.UUID, since, before time.Time, asOfSystemInterval time.Duration, fn func(context.Context, accounting.BucketUsageRollup) error) error {
+	for _, rollup := range db.rollups {
+		db.writtenBefore = append(db.writtenBefore, db.written())
+		if err := fn(ctx, rollup); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+// Copyright (C) 2021 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package accounting
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"storj.io/common/uuid"
+)
+
+// OtherBucketsLabel is the bucket label of usage aggregated over buckets,
+// which don't get their own label. It's not a valid bucket name.
+const OtherBucketsLabel = "(other)"
+
+// RenderProjectUsageMetrics writes current usage and limits of the project in the
+// Prometheus text exposition format to w.
+//
+// Storage is taken from the latest tallies of the largest maxBucketLabels buckets,
+// usage of the remaining buckets is aggregated under OtherBucketsLabel, so that the
+// number of series is bounded.
+func (usage *Service) RenderProjectUsageMetrics(ctx context.Context, projectID uuid.UUID, maxBucketLabels int, w io.Writer) (err error) {
+	defer mon.Task()(&ctx, projectID)(&err)
+
+	tallies, err := usage.projectAccountingDB.GetLatestBucketTallies(ctx, projectID)
+	if err != nil {
+		return ErrProjectUsage.Wrap(err)
+	}
+
+	now := usage.nowFn()
+	egress, err := usage.GetProjectBandwidth(ctx, projectID, now.Year(), now.Month(), now.Day())
+	if err != nil {
+		return err
+	}
+
+	storageLimit, err := usage.GetProjectStorageLimit(ctx, projectID)
+	if err != nil {
+		return ErrProjectUsage.Wrap(err)
+	}
+	bandwidthLimit, err := usage.GetProjectBandwidthLimit(ctx, projectID)
+	if err != nil {
+		return ErrProjectUsage.Wrap(err)
+	}
+
+	sort.Slice(tallies, func(i, k int) bool {
+		if tallies[i].TotalBytes != tallies[k].TotalBytes {
+			return tallies[i].TotalBytes > tallies[k].TotalBytes
+		}
+		return tallies[i].BucketName < tallies[k].BucketName
+	})
+
+	type bucketStorage struct {
+		label string
+		bytes int64
+	}
+	var buckets []bucketStorage
+	var totalStorage int64
+	for i, tally := range tallies {
+		totalStorage += tally.TotalBytes
+		switch {
+		case i < maxBucketLabels:
+			buckets = append(buckets, bucketStorage{label: tally.BucketName, bytes: tally.TotalBytes})
+		case i == maxBucketLabels:
+			buckets = append(buckets, bucketStorage{label: OtherBucketsLabel, bytes: tally.TotalBytes})
+		default:
+			buckets[len(buckets)-1].bytes += tally.TotalBytes
+		}
+	}
+
+	out := bufio.NewWriter(w)
+	project := `project_id="` + projectID.String() + `"`
+	metric := func(name, help string) {
+		_, _ = out.WriteString("# HELP " + name + " " + help + "\n")
+		_, _ = out.WriteString("# TYPE " + name + " gauge\n")
+	}
+	sample := func(name, labels string, value int64) {
+		_, _ = out.WriteString(name + "{" + labels + "} " + strconv.FormatInt(value, 10) + "\n")
+	}
+	remaining := func(limit, used int64) int64 {
+		if used > limit {
+			return 0
+		}
+		return limit - used
+	}
+
+	metric("storj_project_storage_bytes", "Bytes stored in the project according to the latest tallies.")
+	for _, bucket := range buckets {
+		sample("storj_project_storage_bytes", project+`,bucket="`+escapeLabelValue(bucket.label)+`"`, bucket.bytes)
+	}
+
+	metric("storj_project_month_to_date_egress_bytes", "Egress of the project since the beginning of the month.")
+	sample("storj_project_month_to_date_egress_bytes", project, egress)
+
+	metric("storj_project_storage_limit_bytes", "Storage limit of the project.")
+	sample("storj_project_storage_limit_bytes", project, storageLimit.Int64())
+
+	metric("storj_project_bandwidth_limit_bytes", "Monthly bandwidth limit of the project.")
+	sample("storj_project_bandwidth_limit_bytes", project, bandwidthLimit.Int64())
+
+	metric("storj_project_storage_remaining_bytes", "Storage remaining until the project reaches its limit.")
+	sample("storj_project_storage_remaining_bytes", project, remaining(storageLimit.Int64(), totalStorage))
+
+	metric("storj_project_bandwidth_remaining_bytes", "Bandwidth remaining until the project reaches its monthly limit.")
+	sample("storj_project_bandwidth_remaining_bytes", project, remaining(bandwidthLimit.Int64(), egress))
+
+	return ErrProjectUsage.Wrap(out.Flush())
+}
+
+// escapeLabelValue escapes a Prometheus label value.
+func escapeLabelValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
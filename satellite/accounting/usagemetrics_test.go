@@ -0,0 +1,107 @@
+// Copyright (C) 2021 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package accounting_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"storj.io/common/memory"
+	"storj.io/common/testcontext"
+	"storj.io/common/uuid"
+	"storj.io/storj/satellite/accounting"
+	"storj.io/storj/satellite/metabase"
+)
+
+// staticProjectAccounting returns fixed tallies, bandwidth and limits.
+type staticProjectAccounting struct {
+	accounting.ProjectAccounting
+
+	tallies   []accounting.BucketTally
+	bandwidth int64
+	limits    accounting.ProjectLimits
+
+	bandwidthDay time.Time
+}
+
+func (db *staticProjectAccounting) GetLatestBucketTallies(ctx context.Context, projectID uuid.UUID) ([]accounting.BucketTally, error) {
+	return append([]accounting.BucketTally(nil), db.tallies...), nil
+}
+
+func (db *staticProjectAccounting) GetProjectBandwidth(ctx context.Context, projectID uuid.UUID, year int, month time.Month, day int, asOfSystemInterval time.Duration) (int64, error) {
+	db.bandwidthDay = time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	return db.bandwidth, nil
+}
+
+func (db *staticProjectAccounting) GetProjectLimits(ctx context.Context, projectID uuid.UUID) (accounting.ProjectLimits, error) {
+	return db.limits, nil
+}
+
+func TestRenderProjectUsageMetrics(t *testing.T) {
+	ctx := testcontext.New(t)
+	defer ctx.Cleanup()
+
+	projectID, err := uuid.FromString("0e6d5c4b-3a29-4817-a6f5-e4d3c2b1a098")
+	require.NoError(t, err)
+
+	tally := func(bucket string, bytes int64) accounting.BucketTally {
+		return accounting.BucketTally{
+			BucketLocation: metabase.BucketLocation{ProjectID: projectID, BucketName: bucket},
+			TotalBytes:     bytes,
+		}
+	}
+
+	storageLimit := 1 * memory.KB
+	db := &staticProjectAccounting{
+		tallies: []accounting.BucketTally{
+			tally("alpha", 100),
+			tally("beta", 400),
+			tally(`quoted "name"`, 300),
+			tally("gamma", 50),
+			tally("delta", 80),
+		},
+		bandwidth: 3000,
+		limits:    accounting.ProjectLimits{Usage: (*int64)(&storageLimit)},
+	}
+
+	limitCache := accounting.NewProjectLimitCache(db, 0, 2*memory.KB, accounting.ProjectLimitConfig{CacheCapacity: 10})
+	service := accounting.NewService(db, nil, limitCache, 0, 0)
+	service.SetNow(func() time.Time {
+		return time.Date(2021, time.March, 14, 15, 9, 26, 0, time.UTC)
+	})
+
+	var out bytes.Buffer
+	require.NoError(t, service.RenderProjectUsageMetrics(ctx, projectID, 3, &out))
+	require.Equal(t, time.Date(2021, time.March, 14, 0, 0, 0, 0, time.UTC), db.bandwidthDay)
+
+	expected := strings.ReplaceAll(`# HELP storj_project_storage_bytes Bytes stored in the project according to the latest tallies.
+# TYPE storj_project_storage_bytes gauge
+storj_project_storage_bytes{project_id="PROJECT",bucket="beta"} 400
+storj_project_storage_bytes{project_id="PROJECT",bucket="quoted \"name\""} 300
+storj_project_storage_bytes{project_id="PROJECT",bucket="alpha"} 100
+storj_project_storage_bytes{project_id="PROJECT",bucket="(other)"} 130
+# HELP storj_project_month_to_date_egress_bytes Egress of the project since the beginning of the month.
+# TYPE storj_project_month_to_date_egress_bytes gauge
+storj_project_month_to_date_egress_bytes{project_id="PROJECT"} 3000
+# HELP storj_project_storage_limit_bytes Storage limit of the project.
+# TYPE storj_project_storage_limit_bytes gauge
+storj_project_storage_limit_bytes{project_id="PROJECT"} 1000
+# HELP storj_project_bandwidth_limit_bytes Monthly bandwidth limit of the project.
+# TYPE storj_project_bandwidth_limit_bytes gauge
+storj_project_bandwidth_limit_bytes{project_id="PROJECT"} 2000
+# HELP storj_project_storage_remaining_bytes Storage remaining until the project reaches its limit.
+# TYPE storj_project_storage_remaining_bytes gauge
+storj_project_storage_remaining_bytes{project_id="PROJECT"} 70
+# HELP storj_project_bandwidth_remaining_bytes Bandwidth remaining until the project reaches its monthly limit.
+# TYPE storj_project_bandwidth_remaining_bytes gauge
+storj_project_bandwidth_remaining_bytes{project_id="PROJECT"} 0
+`, "PROJECT", projectID.String())
+
+	require.Equal(t, expected, out.String())
+}
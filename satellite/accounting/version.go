@@ -0,0 +1,41 @@
+// Copyright (C) 2021 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package accounting
+
+import (
+	"github.com/zeebo/errs"
+)
+
+// AlgorithmVersion identifies the algorithm used to compute usage totals.
+//
+// It must be bumped with every change which affects the computed numbers
+// (e.g. how tallies are integrated or how usage windows are truncated),
+// so that stored values can be attributed to the algorithm that produced them.
+//
+// History:
+//   1 - initial version.
+//   2 - usage windows are normalized to UTC before truncation.
+const AlgorithmVersion = 2
+
+// ErrAlgorithmVersionMismatch is returned when comparing values computed by
+// different accounting algorithms.
+var ErrAlgorithmVersionMismatch = errs.Class("accounting algorithm version mismatch")
+
+// CheckAlgorithmVersions verifies that values produced by accounting algorithms a and b
+// can be compared. Comparing values across versions is only allowed with allowMismatch.
+func CheckAlgorithmVersions(a, b int, allowMismatch bool) error {
+	if a <= 0 || b <= 0 {
+		return ErrInvalidArgument.New("invalid algorithm version: %d, %d", a, b)
+	}
+	if a != b && !allowMismatch {
+		return ErrAlgorithmVersionMismatch.New("%d != %d", a, b)
+	}
+	return nil
+}
+
+// GetAlgorithmVersion returns the version of the algorithm used by the service
+// to compute usage totals.
+func (usage *Service) GetAlgorithmVersion() int {
+	return AlgorithmVersion
+}
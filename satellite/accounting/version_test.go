@@ -0,0 +1,27 @@
+// Copyright (C) 2021 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package accounting_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"storj.io/storj/satellite/accounting"
+)
+
+func TestCheckAlgorithmVersions(t *testing.T) {
+	service := accounting.NewService(nil, nil, nil, 0, 0)
+	require.Equal(t, accounting.AlgorithmVersion, service.GetAlgorithmVersion())
+
+	require.NoError(t, accounting.CheckAlgorithmVersions(accounting.AlgorithmVersion, accounting.AlgorithmVersion, false))
+
+	err := accounting.CheckAlgorithmVersions(accounting.AlgorithmVersion-1, accounting.AlgorithmVersion, false)
+	require.True(t, accounting.ErrAlgorithmVersionMismatch.Has(err))
+
+	require.NoError(t, accounting.CheckAlgorithmVersions(accounting.AlgorithmVersion-1, accounting.AlgorithmVersion, true))
+
+	err = accounting.CheckAlgorithmVersions(0, accounting.AlgorithmVersion, true)
+	require.True(t, accounting.ErrInvalidArgument.Has(err))
+}
@@ -20,6 +20,7 @@ import (
 	"storj.io/common/memory"
 	"storj.io/common/storj"
 	"storj.io/common/uuid"
+	"storj.io/storj/satellite/accounting"
 	"storj.io/storj/satellite/console"
 	"storj.io/storj/satellite/payments/stripecoinpayments"
 )
@@ -157,7 +158,7 @@ func (server *Server) getProjectLimit(w http.ResponseWriter, r *http.Request) {
 }
 
 func (server *Server) putProjectLimit(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
+	ctx := accounting.WithLimitChangeActor(r.Context(), "admin")
 
 	vars := mux.Vars(r)
 	projectUUIDString, ok := vars["project"]
@@ -456,7 +457,7 @@ func (server *Server) checkUsage(ctx context.Context, w http.ResponseWriter, pro
 	year, month, _ := server.nowFn().UTC().Date()
 	firstOfMonth := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
 
-	currentUsage, err := server.db.ProjectAccounting().GetProjectTotal(ctx, projectID, firstOfMonth, server.nowFn())
+	currentUsage, err := server.db.ProjectAccounting().GetProjectTotal(ctx, projectID, firstOfMonth, server.nowFn(), 0)
 	if err != nil {
 		httpJSONError(w, "unable to list project usage", err.Error(), http.StatusInternalServerError)
 		return true
@@ -467,7 +468,7 @@ func (server *Server) checkUsage(ctx context.Context, w http.ResponseWriter, pro
 	}
 
 	// if usage of last month exist, make sure to look for billing records
-	lastMonthUsage, err := server.db.ProjectAccounting().GetProjectTotal(ctx, projectID, firstOfMonth.AddDate(0, -1, 0), firstOfMonth.AddDate(0, 0, -1))
+	lastMonthUsage, err := server.db.ProjectAccounting().GetProjectTotal(ctx, projectID, firstOfMonth.AddDate(0, -1, 0), firstOfMonth.AddDate(0, 0, -1), 0)
 	if err != nil {
 		httpJSONError(w, "error getting project totals",
 			"", http.StatusInternalServerError)
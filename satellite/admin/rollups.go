@@ -0,0 +1,111 @@
+// Copyright (C) 2021 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package admin
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"storj.io/common/pb"
+	"storj.io/common/uuid"
+	"storj.io/storj/satellite/accounting"
+)
+
+func (server *Server) getArchivedRollups(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	query := r.URL.Query()
+
+	var cursor accounting.ArchivedRollupsCursor
+	if since := query.Get("since"); since != "" {
+		var err error
+		cursor.Since, err = time.Parse(time.RFC3339, since)
+		if err != nil {
+			httpJSONError(w, "invalid since",
+				err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	if project := query.Get("project"); project != "" {
+		projectUUID, err := uuid.FromString(project)
+		if err != nil {
+			httpJSONError(w, "invalid project-uuid",
+				err.Error(), http.StatusBadRequest)
+			return
+		}
+		cursor.ProjectID = &projectUUID
+	}
+	if action := query.Get("action"); action != "" {
+		value, ok := pb.PieceAction_value[action]
+		if !ok {
+			httpJSONError(w, "invalid action",
+				fmt.Sprintf("unknown piece action %q", action), http.StatusBadRequest)
+			return
+		}
+		pieceAction := pb.PieceAction(value)
+		cursor.Action = &pieceAction
+	}
+	if limit := query.Get("limit"); limit != "" {
+		var err error
+		cursor.Limit, err = strconv.Atoi(limit)
+		if err != nil || cursor.Limit < 0 {
+			httpJSONError(w, "invalid limit",
+				fmt.Sprintf("%q is not a non-negative number", limit), http.StatusBadRequest)
+			return
+		}
+	}
+	cursor.Next = query.Get("next")
+
+	page, err := server.db.ProjectAccounting().GetArchivedRollupsPage(ctx, cursor)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if accounting.ErrInvalidArgument.Has(err) {
+			status = http.StatusBadRequest
+		}
+		httpJSONError(w, "failed to get archived rollups",
+			err.Error(), status)
+		return
+	}
+
+	type rollup struct {
+		IntervalStart time.Time `json:"intervalStart"`
+		ProjectID     uuid.UUID `json:"projectId"`
+		BucketName    string    `json:"bucketName"`
+		Action        string    `json:"action"`
+		Inline        int64     `json:"inline"`
+		Allocated     int64     `json:"allocated"`
+		Settled       int64     `json:"settled"`
+	}
+	output := struct {
+		Rollups []rollup `json:"rollups"`
+		Next    string   `json:"next,omitempty"`
+	}{
+		Rollups: make([]rollup, 0, len(page.Rollups)),
+		Next:    page.Next,
+	}
+	for _, archived := range page.Rollups {
+		output.Rollups = append(output.Rollups, rollup{
+			IntervalStart: archived.IntervalStart.UTC(),
+			ProjectID:     archived.ProjectID,
+			BucketName:    archived.BucketName,
+			Action:        archived.Action.String(),
+			Inline:        archived.Inline,
+			Allocated:     archived.Allocated,
+			Settled:       archived.Settled,
+		})
+	}
+
+	data, err := json.Marshal(output)
+	if err != nil {
+		httpJSONError(w, "json encoding failed",
+			err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(data) // nothing to do with the error response, probably the client requesting disappeared
+}
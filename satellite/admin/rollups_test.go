@@ -0,0 +1,67 @@
+// Copyright (C) 2021 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package admin_test
+
+import (
+	"fmt"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"storj.io/common/pb"
+	"storj.io/common/testcontext"
+	"storj.io/common/testrand"
+	"storj.io/storj/private/testplanet"
+	"storj.io/storj/satellite"
+	"storj.io/storj/satellite/orders"
+)
+
+func TestGetArchivedRollups(t *testing.T) {
+	testplanet.Run(t, testplanet.Config{
+		SatelliteCount:   1,
+		StorageNodeCount: 0,
+		UplinkCount:      0,
+		Reconfigure: testplanet.Reconfigure{
+			Satellite: func(log *zap.Logger, index int, config *satellite.Config) {
+				config.Admin.Address = "127.0.0.1:0"
+			},
+		},
+	}, func(t *testing.T, ctx *testcontext.Context, planet *testplanet.Planet) {
+		sat := planet.Satellites[0]
+		address := sat.Admin.Admin.Listener.Addr()
+		authToken := sat.Config.Console.AuthToken
+		pdb := sat.DB.ProjectAccounting()
+
+		intervalStart := time.Now().UTC().Truncate(time.Hour).Add(-2 * time.Hour)
+		projectID := testrand.UUID()
+		for _, action := range []pb.PieceAction{pb.PieceAction_GET, pb.PieceAction_PUT} {
+			err := pdb.CreateBandwidthRollup(ctx, orders.BucketBandwidthRollup{
+				ProjectID:  projectID,
+				BucketName: "bucket",
+				Action:     action,
+				Allocated:  100,
+				Settled:    50,
+			}, intervalStart, 3600)
+			require.NoError(t, err)
+		}
+		_, err := pdb.ArchiveRollupsBefore(ctx, intervalStart.Add(time.Hour), 100)
+		require.NoError(t, err)
+
+		query := url.Values{
+			"since":   {intervalStart.Format(time.RFC3339)},
+			"project": {projectID.String()},
+			"action":  {"GET"},
+		}
+		link := "http://" + address.String() + "/api/rollups/archived?" + query.Encode()
+		expected := fmt.Sprintf(
+			`{"rollups":[{"intervalStart":"%s","projectId":"%s","bucketName":"bucket","action":"GET","inline":0,"allocated":100,"settled":50}]}`,
+			intervalStart.Format(time.RFC3339Nano),
+			projectID.String(),
+		)
+		assertGet(ctx, t, link, expected, authToken)
+	})
+}
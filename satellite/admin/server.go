@@ -94,6 +94,7 @@ func NewServer(log *zap.Logger, listener net.Listener, db DB, accounts payments.
 	server.mux.HandleFunc("/api/project/{project}/apikey", server.addAPIKey).Methods("POST")
 	server.mux.HandleFunc("/api/project/{project}/apikey/{name}", server.deleteAPIKeyByName).Methods("DELETE")
 	server.mux.HandleFunc("/api/apikey/{apikey}", server.deleteAPIKey).Methods("DELETE")
+	server.mux.HandleFunc("/api/rollups/archived", server.getArchivedRollups).Methods("GET")
 
 	return server
 }
@@ -587,7 +587,7 @@ func TestReverifyModifiedSegment(t *testing.T) {
 
 		// remove a piece from the file (a piece that the contained node isn't holding)
 		audits.Verifier.OnTestingCheckSegmentAlteredHook = func() {
-			err = satellite.Metainfo.Metabase.UpdateSegmentPieces(ctx, metabase.UpdateSegmentPieces{
+			_, err = satellite.Metainfo.Metabase.UpdateSegmentPieces(ctx, metabase.UpdateSegmentPieces{
 				StreamID:      queueSegment.StreamID,
 				Position:      queueSegment.Position,
 				OldPieces:     segment.Pieces,
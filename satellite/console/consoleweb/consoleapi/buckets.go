@@ -6,11 +6,14 @@ package consoleapi
 import (
 	"encoding/json"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/zeebo/errs"
 	"go.uber.org/zap"
 
 	"storj.io/common/uuid"
+	"storj.io/storj/satellite/accounting"
 	"storj.io/storj/satellite/console"
 )
 
@@ -66,6 +69,65 @@ func (b *Buckets) AllBucketNames(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// BandwidthBreakdown returns allocated and settled bandwidth per bucket of a specific project.
+// The period is given by since and before unix timestamps, buckets are paged by name
+// using the cursor and limit parameters.
+func (b *Buckets) BandwidthBreakdown(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var err error
+	defer mon.Task()(&ctx)(&err)
+
+	w.Header().Set("Content-Type", "application/json")
+
+	query := r.URL.Query()
+
+	projectID, err := uuid.FromString(query.Get("projectID"))
+	if err != nil {
+		b.serveJSONError(w, http.StatusBadRequest, errs.New("invalid project id: %v", err))
+		return
+	}
+	sinceStamp, err := strconv.ParseInt(query.Get("since"), 10, 64)
+	if err != nil {
+		b.serveJSONError(w, http.StatusBadRequest, errs.New("invalid since: %v", err))
+		return
+	}
+	beforeStamp, err := strconv.ParseInt(query.Get("before"), 10, 64)
+	if err != nil {
+		b.serveJSONError(w, http.StatusBadRequest, errs.New("invalid before: %v", err))
+		return
+	}
+
+	cursor := accounting.BucketBandwidthBreakdownCursor{
+		StartAfter: query.Get("cursor"),
+	}
+	if limit := query.Get("limit"); limit != "" {
+		cursor.Limit, err = strconv.Atoi(limit)
+		if err != nil {
+			b.serveJSONError(w, http.StatusBadRequest, errs.New("invalid limit: %v", err))
+			return
+		}
+	}
+
+	since := time.Unix(sinceStamp, 0).UTC()
+	before := time.Unix(beforeStamp, 0).UTC()
+
+	page, err := b.service.GetBucketBandwidthBreakdown(ctx, projectID, cursor, since, before)
+	if err != nil {
+		if console.ErrUnauthorized.Has(err) {
+			b.serveJSONError(w, http.StatusUnauthorized, err)
+			return
+		}
+
+		b.serveJSONError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	err = json.NewEncoder(w).Encode(page)
+	if err != nil {
+		b.log.Error("failed to write json bandwidth breakdown response", zap.Error(ErrBucketsAPI.Wrap(err)))
+	}
+}
+
 // serveJSONError writes JSON error to response output stream.
 func (b *Buckets) serveJSONError(w http.ResponseWriter, status int, err error) {
 	serveJSONError(b.log, w, status, err)
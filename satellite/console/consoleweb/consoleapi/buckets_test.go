@@ -5,6 +5,7 @@ package consoleapi_test
 
 import (
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"net/http"
 	"testing"
@@ -13,11 +14,13 @@ import (
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 
+	"storj.io/common/pb"
 	"storj.io/common/storj"
 	"storj.io/common/testcontext"
 	"storj.io/common/testrand"
 	"storj.io/storj/private/testplanet"
 	"storj.io/storj/satellite"
+	"storj.io/storj/satellite/accounting"
 	"storj.io/storj/satellite/console"
 )
 
@@ -103,3 +106,64 @@ func Test_AllBucketNames(t *testing.T) {
 		}()
 	})
 }
+
+func Test_BandwidthBreakdown(t *testing.T) {
+	testplanet.Run(t, testplanet.Config{
+		SatelliteCount: 1, StorageNodeCount: 0, UplinkCount: 1,
+		Reconfigure: testplanet.Reconfigure{
+			Satellite: func(log *zap.Logger, index int, config *satellite.Config) {
+				config.Console.OpenRegistrationEnabled = true
+				config.Console.RateLimit.Burst = 10
+			},
+		},
+	}, func(t *testing.T, ctx *testcontext.Context, planet *testplanet.Planet) {
+		sat := planet.Satellites[0]
+
+		user, err := sat.AddUser(ctx, console.CreateUser{
+			FullName: "Jack-bandwidth",
+			Email:    "bandwidthtest@test.test",
+		}, 1)
+		require.NoError(t, err)
+
+		project, err := sat.AddProject(ctx, user.ID, "bandwidthtest")
+		require.NoError(t, err)
+
+		now := time.Now().UTC()
+		err = sat.DB.Orders().UpdateBucketBandwidthAllocation(ctx, project.ID, []byte("testbucket"), pb.PieceAction_GET, 300, now)
+		require.NoError(t, err)
+		err = sat.DB.Orders().UpdateBucketBandwidthSettle(ctx, project.ID, []byte("testbucket"), pb.PieceAction_GET, 100, now)
+		require.NoError(t, err)
+
+		// we are using full name as a password
+		token, err := sat.API.Console.Service.Token(ctx, console.AuthUser{Email: user.Email, Password: user.FullName})
+		require.NoError(t, err)
+
+		url := fmt.Sprintf("http://%s/api/v0/buckets/bandwidth-breakdown?projectID=%s&since=%d&before=%d",
+			sat.API.Console.Listener.Addr().String(), project.ID, now.Add(-time.Hour).Unix(), now.Add(time.Hour).Unix())
+		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+		require.NoError(t, err)
+
+		req.AddCookie(&http.Cookie{
+			Name:    "_tokenKey",
+			Path:    "/",
+			Value:   token,
+			Expires: time.Now().AddDate(0, 0, 1),
+		})
+
+		result, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer ctx.Check(result.Body.Close)
+		require.Equal(t, http.StatusOK, result.StatusCode)
+
+		body, err := ioutil.ReadAll(result.Body)
+		require.NoError(t, err)
+
+		var page accounting.BucketBandwidthBreakdownPage
+		require.NoError(t, json.Unmarshal(body, &page))
+		require.Equal(t, accounting.BucketBandwidthBreakdownPage{
+			Breakdowns: []accounting.BucketBandwidthBreakdown{
+				{BucketName: "testbucket", Allocated: 300, Settled: 100, Difference: 200},
+			},
+		}, page)
+	})
+}
@@ -5,6 +5,7 @@ package consoleapi
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 
 	"github.com/gorilla/mux"
@@ -66,6 +67,9 @@ func (ul *UsageLimits) ProjectUsageLimits(w http.ResponseWriter, r *http.Request
 		case accounting.ErrInvalidArgument.Has(err):
 			ul.serveJSONError(w, http.StatusBadRequest, err)
 			return
+		case errors.Is(err, accounting.ErrProjectNotFound):
+			ul.serveJSONError(w, http.StatusNotFound, err)
+			return
 		default:
 			ul.serveJSONError(w, http.StatusInternalServerError, err)
 			return
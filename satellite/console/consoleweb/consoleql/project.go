@@ -60,6 +60,10 @@ const (
 	FieldEgress = "egress"
 	// FieldObjectCount is a field name for objects count.
 	FieldObjectCount = "objectCount"
+	// FieldMetadataSize is a field name for metadata size.
+	FieldMetadataSize = "metadataSize"
+	// FieldHasData is a field name for whether there is usage data.
+	FieldHasData = "hasData"
 	// FieldPageCount is a field name for total page count.
 	FieldPageCount = "pageCount"
 	// FieldCurrentPage is a field name for current page number.
@@ -316,6 +320,12 @@ func graphqlBucketUsage() *graphql.Object {
 			FieldObjectCount: &graphql.Field{
 				Type: graphql.Float,
 			},
+			FieldMetadataSize: &graphql.Field{
+				Type: graphql.Float,
+			},
+			FieldHasData: &graphql.Field{
+				Type: graphql.Boolean,
+			},
 			SinceArg: &graphql.Field{
 				Type: graphql.DateTime,
 			},
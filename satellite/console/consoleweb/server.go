@@ -251,6 +251,7 @@ func NewServer(logger *zap.Logger, config Config, service *console.Service, mail
 	bucketsRouter := router.PathPrefix("/api/v0/buckets").Subrouter()
 	bucketsRouter.Use(server.withAuth)
 	bucketsRouter.HandleFunc("/bucket-names", bucketsController.AllBucketNames).Methods(http.MethodGet)
+	bucketsRouter.HandleFunc("/bandwidth-breakdown", bucketsController.BandwidthBreakdown).Methods(http.MethodGet)
 
 	apiKeysController := consoleapi.NewAPIKeys(logger, service)
 	apiKeysRouter := router.PathPrefix("/api/v0/api-keys").Subrouter()
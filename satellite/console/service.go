@@ -56,8 +56,10 @@ const (
 	teamMemberDoesNotExistErrMsg         = `There is no account on this Satellite for the user(s) you have entered.
 									     Please add team members with active accounts`
 
-	usedRegTokenErrMsg = "This registration token has already been used"
-	projLimitErrMsg    = "Sorry, project creation is limited for your account. Please contact support!"
+	usedRegTokenErrMsg   = "This registration token has already been used"
+	projLimitErrMsg      = "Sorry, project creation is limited for your account. Please contact support!"
+	searchTooBroadErrMsg = "Too many buckets match your search, please narrow your search"
+	tooManyResultsErrMsg = "There is too much usage data for the selected period, please narrow your range"
 )
 
 var (
@@ -124,9 +126,13 @@ func init() {
 
 // Config keeps track of core console service configuration parameters.
 type Config struct {
-	PasswordCost            int  `help:"password hashing cost (0=automatic)" testDefault:"4" default:"0"`
-	OpenRegistrationEnabled bool `help:"enable open registration" default:"false" testDefault:"true"`
-	DefaultProjectLimit     int  `help:"default project limits for users" default:"3" testDefault:"5"`
+	PasswordCost            int           `help:"password hashing cost (0=automatic)" testDefault:"4" default:"0"`
+	OpenRegistrationEnabled bool          `help:"enable open registration" default:"false" testDefault:"true"`
+	DefaultProjectLimit     int           `help:"default project limits for users" default:"3" testDefault:"5"`
+	AsOfSystemTimeInterval  time.Duration `help:"interval for AS OF SYSTEM TIME clause (crdb specific) used when reading project and bucket usage" default:"-10s" testDefault:"-1µs"`
+	MaxBucketSearchMatches  int           `help:"maximum number of buckets a bucket usage search may match, broader searches are rejected" default:"100000"`
+	MaxUsageScanResults     int           `help:"maximum number of buckets or rows a single usage query may process, larger queries are rejected (0 = unlimited)" default:"0"`
+	BucketTotalsParallelism int           `help:"number of buckets of a bucket usage page whose usage is fetched concurrently" default:"4"`
 	UsageLimits             UsageLimitsConfig
 	Recaptcha               RecaptchaConfig
 }
@@ -1492,8 +1498,11 @@ func (s *Service) GetProjectUsage(ctx context.Context, projectID uuid.UUID, sinc
 		return nil, Error.Wrap(err)
 	}
 
-	projectUsage, err := s.projectAccounting.GetProjectTotal(ctx, projectID, since, before)
+	projectUsage, err := s.projectUsage.GetProjectTotal(ctx, projectID, since, before, s.config.AsOfSystemTimeInterval)
 	if err != nil {
+		if accounting.ErrTooManyResults.Has(err) {
+			return nil, ErrValidation.New(tooManyResultsErrMsg)
+		}
 		return nil, Error.Wrap(err)
 	}
 
@@ -1514,14 +1523,42 @@ func (s *Service) GetBucketTotals(ctx context.Context, projectID uuid.UUID, curs
 		return nil, Error.Wrap(err)
 	}
 
-	usage, err := s.projectAccounting.GetBucketTotals(ctx, projectID, cursor, isMember.project.CreatedAt, before)
+	usage, err := s.projectAccounting.GetBucketTotals(ctx, projectID, cursor, isMember.project.CreatedAt, before, s.config.AsOfSystemTimeInterval)
 	if err != nil {
+		if accounting.ErrSearchTooBroad.Has(err) {
+			return nil, ErrValidation.New(searchTooBroadErrMsg)
+		}
+		if errors.Is(err, accounting.ErrInvalidCursor) || errors.Is(err, accounting.ErrPageOutOfRange) {
+			return nil, ErrValidation.Wrap(err)
+		}
 		return nil, Error.Wrap(err)
 	}
 
 	return usage, nil
 }
 
+// GetBucketBandwidthBreakdown retrieves allocated and settled bandwidth per bucket of particular project for a given period.
+func (s *Service) GetBucketBandwidthBreakdown(ctx context.Context, projectID uuid.UUID, cursor accounting.BucketBandwidthBreakdownCursor, since, before time.Time) (_ accounting.BucketBandwidthBreakdownPage, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	auth, err := s.getAuthAndAuditLog(ctx, "get bucket bandwidth breakdown", zap.String("projectID", projectID.String()))
+	if err != nil {
+		return accounting.BucketBandwidthBreakdownPage{}, Error.Wrap(err)
+	}
+
+	_, err = s.isProjectMember(ctx, auth.User.ID, projectID)
+	if err != nil {
+		return accounting.BucketBandwidthBreakdownPage{}, Error.Wrap(err)
+	}
+
+	page, err := s.projectAccounting.GetBucketBandwidthBreakdown(ctx, projectID, cursor, since, before)
+	if err != nil {
+		return accounting.BucketBandwidthBreakdownPage{}, Error.Wrap(err)
+	}
+
+	return page, nil
+}
+
 // GetAllBucketNames retrieves all bucket names of a specific project.
 func (s *Service) GetAllBucketNames(ctx context.Context, projectID uuid.UUID) (_ []string, err error) {
 	defer mon.Task()(&ctx)(&err)
@@ -1571,8 +1608,11 @@ func (s *Service) GetBucketUsageRollups(ctx context.Context, projectID uuid.UUID
 		return nil, Error.Wrap(err)
 	}
 
-	result, err := s.projectAccounting.GetBucketUsageRollups(ctx, projectID, since, before)
+	result, err := s.projectUsage.GetBucketUsageRollups(ctx, projectID, since, before, s.config.AsOfSystemTimeInterval)
 	if err != nil {
+		if accounting.ErrTooManyResults.Has(err) {
+			return nil, ErrValidation.New(tooManyResultsErrMsg)
+		}
 		return nil, Error.Wrap(err)
 	}
 
@@ -5,6 +5,7 @@ package console_test
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -19,6 +20,7 @@ import (
 	"storj.io/common/uuid"
 	"storj.io/storj/private/testplanet"
 	"storj.io/storj/satellite"
+	"storj.io/storj/satellite/accounting"
 	"storj.io/storj/satellite/console"
 )
 
@@ -430,3 +432,73 @@ func TestMFA(t *testing.T) {
 		})
 	})
 }
+
+func TestGetBucketTotalsSearchTooBroad(t *testing.T) {
+	testplanet.Run(t, testplanet.Config{
+		SatelliteCount: 1, StorageNodeCount: 0, UplinkCount: 1,
+		Reconfigure: testplanet.Reconfigure{
+			SatelliteDB: func(log *zap.Logger, index int, db satellite.DB) (satellite.DB, error) {
+				return searchTooBroadDB{DB: db}, nil
+			},
+		},
+	}, func(t *testing.T, ctx *testcontext.Context, planet *testplanet.Planet) {
+		sat := planet.Satellites[0]
+		service := sat.API.Console.Service
+
+		project, err := sat.API.DB.Console().Projects().Get(ctx, planet.Uplinks[0].Projects[0].ID)
+		require.NoError(t, err)
+
+		authCtx, err := sat.AuthenticatedContext(ctx, project.OwnerID)
+		require.NoError(t, err)
+
+		_, err = service.GetBucketTotals(authCtx, project.ID, accounting.BucketUsageCursor{
+			Search: "a",
+			Limit:  10,
+			Page:   1,
+		}, time.Now())
+		require.Error(t, err)
+		require.True(t, console.ErrValidation.Has(err))
+		require.Contains(t, err.Error(), "please narrow your search")
+	})
+}
+
+func TestGetBucketTotalsInvalidPage(t *testing.T) {
+	testplanet.Run(t, testplanet.Config{
+		SatelliteCount: 1, StorageNodeCount: 0, UplinkCount: 1,
+	}, func(t *testing.T, ctx *testcontext.Context, planet *testplanet.Planet) {
+		sat := planet.Satellites[0]
+		service := sat.API.Console.Service
+
+		project, err := sat.API.DB.Console().Projects().Get(ctx, planet.Uplinks[0].Projects[0].ID)
+		require.NoError(t, err)
+		require.NoError(t, planet.Uplinks[0].CreateBucket(ctx, sat, "bucket"))
+
+		authCtx, err := sat.AuthenticatedContext(ctx, project.OwnerID)
+		require.NoError(t, err)
+
+		_, err = service.GetBucketTotals(authCtx, project.ID, accounting.BucketUsageCursor{Limit: 10, Page: 0}, time.Now())
+		require.True(t, console.ErrValidation.Has(err), err)
+		require.True(t, errors.Is(err, accounting.ErrInvalidCursor), err)
+
+		_, err = service.GetBucketTotals(authCtx, project.ID, accounting.BucketUsageCursor{Limit: 10, Page: 2}, time.Now())
+		require.True(t, console.ErrValidation.Has(err), err)
+		require.True(t, errors.Is(err, accounting.ErrPageOutOfRange), err)
+	})
+}
+
+// searchTooBroadDB rejects every bucket usage search as too broad.
+type searchTooBroadDB struct {
+	satellite.DB
+}
+
+func (db searchTooBroadDB) ProjectAccounting() accounting.ProjectAccounting {
+	return searchTooBroadProjectAccounting{ProjectAccounting: db.DB.ProjectAccounting()}
+}
+
+type searchTooBroadProjectAccounting struct {
+	accounting.ProjectAccounting
+}
+
+func (searchTooBroadProjectAccounting) GetBucketTotals(ctx context.Context, projectID uuid.UUID, cursor accounting.BucketUsageCursor, since, before time.Time, asOfSystemInterval time.Duration) (*accounting.BucketUsagePage, error) {
+	return nil, accounting.ErrSearchTooBroad.New("test")
+}
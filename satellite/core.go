@@ -35,6 +35,8 @@ import (
 	"storj.io/storj/satellite/metabase/segmentloop"
 	"storj.io/storj/satellite/metainfo"
 	"storj.io/storj/satellite/metainfo/expireddeletion"
+	"storj.io/storj/satellite/metainfo/piecesbackfill"
+	"storj.io/storj/satellite/metainfo/tombstonedeletion"
 	"storj.io/storj/satellite/metrics"
 	"storj.io/storj/satellite/orders"
 	"storj.io/storj/satellite/overlay"
@@ -108,6 +110,14 @@ type Core struct {
 		Chore *expireddeletion.Chore
 	}
 
+	TombstoneDeletion struct {
+		Chore *tombstonedeletion.Chore
+	}
+
+	PiecesBackfill struct {
+		Chore *piecesbackfill.Chore
+	}
+
 	Accounting struct {
 		Tally                 *tally.Service
 		NodeTally             *nodetally.Service
@@ -363,6 +373,36 @@ func New(log *zap.Logger, full *identity.FullIdentity, db DB,
 			debug.Cycle("Expired Segments Chore", peer.ExpiredDeletion.Chore.Loop))
 	}
 
+	{ // setup segment tombstone cleanup
+		peer.TombstoneDeletion.Chore = tombstonedeletion.NewChore(
+			peer.Log.Named("core-tombstone-deletion"),
+			config.TombstoneDeletion,
+			peer.Metainfo.Metabase,
+		)
+		peer.Services.Add(lifecycle.Item{
+			Name:  "tombstonedeletion:chore",
+			Run:   peer.TombstoneDeletion.Chore.Run,
+			Close: peer.TombstoneDeletion.Chore.Close,
+		})
+		peer.Debug.Server.Panel.Add(
+			debug.Cycle("Segment Tombstones Chore", peer.TombstoneDeletion.Chore.Loop))
+	}
+
+	{ // setup segment pieces backfill
+		peer.PiecesBackfill.Chore = piecesbackfill.NewChore(
+			peer.Log.Named("core-pieces-backfill"),
+			config.PiecesBackfill,
+			peer.Metainfo.Metabase,
+		)
+		peer.Services.Add(lifecycle.Item{
+			Name:  "piecesbackfill:chore",
+			Run:   peer.PiecesBackfill.Chore.Run,
+			Close: peer.PiecesBackfill.Chore.Close,
+		})
+		peer.Debug.Server.Panel.Add(
+			debug.Cycle("Segment Pieces Backfill Chore", peer.PiecesBackfill.Chore.Loop))
+	}
+
 	{ // setup accounting
 		peer.Accounting.Tally = tally.New(peer.Log.Named("accounting:tally"), peer.DB.StoragenodeAccounting(), peer.DB.ProjectAccounting(), peer.LiveAccounting.Cache, peer.Metainfo.Metabase, config.Tally)
 		peer.Services.Add(lifecycle.Item{
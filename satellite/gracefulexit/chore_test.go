@@ -210,7 +210,7 @@ func TestDurabilityRatio(t *testing.T) {
 					idx++
 				}
 			}
-			err = satellite.Metainfo.Metabase.UpdateSegmentPieces(ctx, metabase.UpdateSegmentPieces{
+			_, err = satellite.Metainfo.Metabase.UpdateSegmentPieces(ctx, metabase.UpdateSegmentPieces{
 				StreamID: segment.StreamID,
 				Position: segment.Position,
 
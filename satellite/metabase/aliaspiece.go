@@ -6,6 +6,12 @@ package metabase
 import (
 	"database/sql/driver"
 	"encoding/binary"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgtype"
+
+	"storj.io/private/dbutil/pgutil"
 )
 
 // AliasPieces is a slice of AliasPiece.
@@ -17,6 +23,44 @@ type AliasPiece struct {
 	Alias  NodeAlias
 }
 
+// nodes returns node aliases of the pieces for the remote_alias_nodes column,
+// which indexes segments by the nodes holding their pieces.
+func (aliases AliasPieces) nodes() *pgtype.Int4Array {
+	if len(aliases) == 0 {
+		return &pgtype.Int4Array{Status: pgtype.Null}
+	}
+	nodes := make([]int32, len(aliases))
+	for i, piece := range aliases {
+		nodes[i] = int32(piece.Alias)
+	}
+	return pgutil.Int4Array(nodes)
+}
+
+// nodesLiteral returns node aliases of the pieces as an INT4[] literal, so that
+// remote_alias_nodes of multiple segments can be passed as a TEXT[] parameter.
+func (aliases AliasPieces) nodesLiteral() string {
+	var literal strings.Builder
+	literal.WriteByte('{')
+	for i, piece := range aliases {
+		if i > 0 {
+			literal.WriteByte(',')
+		}
+		literal.WriteString(strconv.Itoa(int(piece.Alias)))
+	}
+	literal.WriteByte('}')
+	return literal.String()
+}
+
+// count returns the number of pieces for the pieces_count column,
+// which is NULL for segments without remote pieces.
+func (aliases AliasPieces) count() *int32 {
+	if len(aliases) == 0 {
+		return nil
+	}
+	count := int32(len(aliases))
+	return &count
+}
+
 const (
 	// aliasPieceEncodingRLE run length encodes the zeros and node ID-s.
 	//
@@ -0,0 +1,253 @@
+// Copyright (C) 2021 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package metabase
+
+import (
+	"context"
+	"time"
+
+	"storj.io/common/storj"
+	"storj.io/common/uuid"
+	"storj.io/private/dbutil"
+	"storj.io/private/tagsql"
+)
+
+// segmentTombstonesInsert returns a statement which records tombstones for
+// segments returned by the fromCTE. The CTE must return stream_id, position,
+// root_piece_id and remote_alias_pieces of deleted segments. Inline segments
+// don't have pieces, hence no tombstones are recorded for them.
+func segmentTombstonesInsert(fromCTE string) string {
+	return `
+		INSERT INTO segment_tombstones (
+			pieces_changed_seq, stream_id, position,
+			root_piece_id, remote_alias_pieces
+		)
+		SELECT
+			nextval('segment_pieces_changed_seq'), stream_id, position,
+			root_piece_id, remote_alias_pieces
+		FROM ` + fromCTE + `
+		WHERE remote_alias_pieces IS NOT NULL
+	`
+}
+
+// segmentTombstonesCTE returns a WITH clause entry, which records tombstones
+// for segments deleted by the fromCTE. See segmentTombstonesInsert.
+func segmentTombstonesCTE(fromCTE string) string {
+	return `inserted_segment_tombstones AS (` +
+		segmentTombstonesInsert(fromCTE) + `
+		RETURNING pieces_changed_seq
+	)`
+}
+
+// SegmentPiecesChange describes a change of segment pieces.
+type SegmentPiecesChange struct {
+	// Seq is the position of the change in the change feed.
+	Seq int64
+
+	StreamID uuid.UUID
+	Position SegmentPosition
+
+	RootPieceID storj.PieceID
+	// Pieces contains current pieces of the segment or, for deleted
+	// segments, the pieces the segment had when it was deleted.
+	Pieces Pieces
+
+	// Deleted is set for tombstone entries of deleted segments.
+	Deleted bool
+}
+
+// GetSegmentsChangedSince contains arguments necessary for listing segment pieces changes.
+type GetSegmentsChangedSince struct {
+	// Seq is the last change already known to the caller.
+	Seq int64
+	// UpToSeq is the last change to list, it must be a settled watermark,
+	// see GetSegmentPiecesChangedWatermark.
+	UpToSeq int64
+	Limit   int
+}
+
+// GetSegmentsChangedSinceResult is the result of GetSegmentsChangedSince.
+type GetSegmentsChangedSinceResult struct {
+	Changes []SegmentPiecesChange
+	More    bool
+}
+
+// GetSegmentPiecesChangedWatermark returns the last change sequence number handed out so far.
+//
+// Sequence numbers are taken when a change is written, however the changes are committed
+// out of order, so a change may become visible only after a change with a higher number
+// was already listed. A watermark is settled, when all changes up to it are committed,
+// i.e. once the longest running change transaction, which started before the watermark
+// was taken, has finished.
+func (db *DB) GetSegmentPiecesChangedWatermark(ctx context.Context) (seq int64, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	err = db.db.QueryRowContext(ctx, `
+		SELECT CASE WHEN is_called THEN last_value ELSE 0 END
+		FROM segment_pieces_changed_seq
+	`).Scan(&seq)
+	if err != nil {
+		return 0, Error.New("unable to get segment changes watermark: %w", err)
+	}
+	return seq, nil
+}
+
+// GetSegmentsChangedSince lists segments which pieces were changed by UpdateSegmentPieces
+// or which were deleted after the specified change and up to the specified watermark.
+// Changes are ordered by Seq and a segment changed multiple times is listed only with its
+// latest change.
+//
+// This allows segment loop observers to apply deltas between full loop passes. Listing only
+// up to a settled watermark ensures that advancing Seq past the listed changes doesn't skip
+// changes which were not committed yet.
+func (db *DB) GetSegmentsChangedSince(ctx context.Context, opts GetSegmentsChangedSince) (result GetSegmentsChangedSinceResult, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	if opts.Seq < 0 {
+		return GetSegmentsChangedSinceResult{}, ErrInvalidRequest.New("Invalid seq: %d", opts.Seq)
+	}
+	if opts.UpToSeq < opts.Seq {
+		return GetSegmentsChangedSinceResult{}, ErrInvalidRequest.New("UpToSeq %d is before Seq %d", opts.UpToSeq, opts.Seq)
+	}
+	if opts.Limit < 0 {
+		return GetSegmentsChangedSinceResult{}, ErrInvalidRequest.New("Invalid limit: %d", opts.Limit)
+	}
+	ListLimit.Ensure(&opts.Limit)
+
+	err = withRows(db.db.QueryContext(ctx, `
+		SELECT pieces_changed_seq, stream_id, position, root_piece_id, remote_alias_pieces, deleted
+		FROM (
+			(
+				SELECT pieces_changed_seq, stream_id, position, root_piece_id, remote_alias_pieces, false AS deleted
+				FROM segments
+				WHERE pieces_changed_seq > $1 AND pieces_changed_seq <= $2
+				ORDER BY pieces_changed_seq
+				LIMIT $3
+			)
+			UNION ALL
+			(
+				SELECT pieces_changed_seq, stream_id, position, root_piece_id, remote_alias_pieces, true AS deleted
+				FROM segment_tombstones
+				WHERE pieces_changed_seq > $1 AND pieces_changed_seq <= $2
+				ORDER BY pieces_changed_seq
+				LIMIT $3
+			)
+		) AS changes
+		ORDER BY pieces_changed_seq
+		LIMIT $3
+	`, opts.Seq, opts.UpToSeq, opts.Limit+1))(func(rows tagsql.Rows) error {
+		for rows.Next() {
+			var change SegmentPiecesChange
+			var aliasPieces AliasPieces
+			err := rows.Scan(
+				&change.Seq, &change.StreamID, &change.Position,
+				&change.RootPieceID, &aliasPieces, &change.Deleted,
+			)
+			if err != nil {
+				return Error.New("failed to scan segment changes: %w", err)
+			}
+
+			change.Pieces, err = db.aliasCache.ConvertAliasesToPieces(ctx, aliasPieces)
+			if err != nil {
+				return Error.New("failed to convert aliases to pieces: %w", err)
+			}
+
+			result.Changes = append(result.Changes, change)
+		}
+		return nil
+	})
+	if err != nil {
+		return GetSegmentsChangedSinceResult{}, Error.New("unable to fetch segment changes: %w", err)
+	}
+
+	if len(result.Changes) > opts.Limit {
+		result.More = true
+		result.Changes = result.Changes[:len(result.Changes)-1]
+	}
+
+	return result, nil
+}
+
+// DeleteSegmentTombstones contains arguments necessary for deleting segment tombstones.
+type DeleteSegmentTombstones struct {
+	// UpToSeq is the last change which is no longer needed by any observer.
+	UpToSeq int64
+	// DeletedBefore removes tombstones older than the retention of the change feed,
+	// regardless whether observers applied them.
+	DeletedBefore time.Time
+	// BatchSize is the number of tombstones deleted by a single statement.
+	BatchSize int
+}
+
+// DeleteSegmentTombstones deletes tombstones of segments, which were deleted
+// at or before the specified change or before the specified time.
+func (db *DB) DeleteSegmentTombstones(ctx context.Context, opts DeleteSegmentTombstones) (deleted int64, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	if opts.BatchSize < 0 {
+		return 0, ErrInvalidRequest.New("Invalid batch size: %d", opts.BatchSize)
+	}
+	batchsizeLimit.Ensure(&opts.BatchSize)
+
+	// the conditions are deleted separately, so that each can use an index.
+	if opts.UpToSeq > 0 {
+		count, err := db.deleteSegmentTombstonesInBatches(ctx, `pieces_changed_seq <= $1`, opts.UpToSeq, opts.BatchSize)
+		deleted += count
+		if err != nil {
+			return deleted, err
+		}
+	}
+	if !opts.DeletedBefore.IsZero() {
+		count, err := db.deleteSegmentTombstonesInBatches(ctx, `deleted_at < $1`, opts.DeletedBefore, opts.BatchSize)
+		deleted += count
+		if err != nil {
+			return deleted, err
+		}
+	}
+
+	return deleted, nil
+}
+
+// deleteSegmentTombstonesInBatches deletes tombstones matching the condition with the
+// argument $1, at most batchSize tombstones per statement.
+func (db *DB) deleteSegmentTombstonesInBatches(ctx context.Context, condition string, arg interface{}, batchSize int) (deleted int64, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	var query string
+	switch db.impl {
+	case dbutil.Cockroach:
+		query = `
+			DELETE FROM segment_tombstones
+			WHERE ` + condition + `
+			LIMIT $2`
+	case dbutil.Postgres:
+		// Postgres doesn't support DELETE ... LIMIT, so the batch is selected by ctid.
+		query = `
+			DELETE FROM segment_tombstones
+			WHERE ctid IN (
+				SELECT ctid FROM segment_tombstones
+				WHERE ` + condition + `
+				LIMIT $2
+			)`
+	default:
+		return 0, Error.New("unsupported database: %v", db.impl)
+	}
+
+	for {
+		result, err := db.db.ExecContext(ctx, query, arg, batchSize)
+		if err != nil {
+			return deleted, Error.New("unable to delete segment tombstones: %w", err)
+		}
+
+		count, err := result.RowsAffected()
+		if err != nil {
+			return deleted, Error.New("unable to delete segment tombstones: %w", err)
+		}
+		deleted += count
+
+		if count < int64(batchSize) {
+			return deleted, nil
+		}
+	}
+}
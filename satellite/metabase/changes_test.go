@@ -0,0 +1,311 @@
+// Copyright (C) 2021 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package metabase_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"storj.io/common/storj"
+	"storj.io/common/testcontext"
+	"storj.io/common/testrand"
+	"storj.io/storj/satellite/metabase"
+	"storj.io/storj/satellite/metabase/metabasetest"
+)
+
+func TestGetSegmentsChangedSince(t *testing.T) {
+	metabasetest.Run(t, func(ctx *testcontext.Context, t *testing.T, db *metabase.DB) {
+		obj := metabasetest.RandObjectStream()
+
+		t.Run("invalid request", func(t *testing.T) {
+			defer metabasetest.DeleteAll{}.Check(ctx, t, db)
+
+			_, err := db.GetSegmentsChangedSince(ctx, metabase.GetSegmentsChangedSince{Seq: -1})
+			require.True(t, metabase.ErrInvalidRequest.Has(err))
+
+			_, err = db.GetSegmentsChangedSince(ctx, metabase.GetSegmentsChangedSince{Limit: -1})
+			require.True(t, metabase.ErrInvalidRequest.Has(err))
+
+			_, err = db.GetSegmentsChangedSince(ctx, metabase.GetSegmentsChangedSince{Seq: 2, UpToSeq: 1})
+			require.True(t, metabase.ErrInvalidRequest.Has(err))
+		})
+
+		t.Run("updates and deletes", func(t *testing.T) {
+			defer metabasetest.DeleteAll{}.Check(ctx, t, db)
+
+			metabasetest.CreateObject(ctx, t, db, obj, 2)
+
+			changes := func(seq int64) []metabase.SegmentPiecesChange {
+				watermark, err := db.GetSegmentPiecesChangedWatermark(ctx)
+				require.NoError(t, err)
+
+				result, err := db.GetSegmentsChangedSince(ctx, metabase.GetSegmentsChangedSince{Seq: seq, UpToSeq: watermark})
+				require.NoError(t, err)
+				require.False(t, result.More)
+				return result.Changes
+			}
+
+			// newly created segments are not reported.
+			require.Empty(t, changes(0))
+
+			oldPieces := metabase.Pieces{{Number: 0, StorageNode: storj.NodeID{2}}}
+			updatePieces := func(index uint32, old, new metabase.Pieces) {
+				_, err := db.UpdateSegmentPieces(ctx, metabase.UpdateSegmentPieces{
+					StreamID:      obj.StreamID,
+					Position:      metabase.SegmentPosition{Index: index},
+					OldPieces:     old,
+					NewRedundancy: metabasetest.DefaultRedundancy,
+					NewPieces:     new,
+				})
+				require.NoError(t, err)
+			}
+
+			pieces0 := metabase.Pieces{{Number: 1, StorageNode: testrand.NodeID()}}
+			updatePieces(0, oldPieces, pieces0)
+			pieces1 := metabase.Pieces{{Number: 1, StorageNode: testrand.NodeID()}}
+			updatePieces(1, oldPieces, pieces1)
+
+			updated := changes(0)
+			require.Len(t, updated, 2)
+			require.Less(t, updated[0].Seq, updated[1].Seq)
+			require.Equal(t, metabase.SegmentPiecesChange{
+				Seq:         updated[0].Seq,
+				StreamID:    obj.StreamID,
+				Position:    metabase.SegmentPosition{Index: 0},
+				RootPieceID: storj.PieceID{1},
+				Pieces:      pieces0,
+			}, updated[0])
+			require.Equal(t, pieces1, updated[1].Pieces)
+
+			// a failed update doesn't change the sequence.
+			_, err := db.UpdateSegmentPieces(ctx, metabase.UpdateSegmentPieces{
+				StreamID:      obj.StreamID,
+				Position:      metabase.SegmentPosition{Index: 0},
+				OldPieces:     oldPieces,
+				NewRedundancy: metabasetest.DefaultRedundancy,
+				NewPieces:     pieces1,
+			})
+			require.Error(t, err)
+			require.Equal(t, updated, changes(0))
+
+			// a segment changed again is listed only once with the latest change.
+			pieces0 = metabase.Pieces{{Number: 2, StorageNode: testrand.NodeID()}}
+			updatePieces(0, updated[0].Pieces, pieces0)
+
+			reupdated := changes(0)
+			require.Len(t, reupdated, 2)
+			require.Equal(t, updated[1], reupdated[0])
+			require.Greater(t, reupdated[1].Seq, updated[1].Seq)
+			require.Equal(t, pieces0, reupdated[1].Pieces)
+
+			require.Equal(t, reupdated[1:], changes(reupdated[0].Seq))
+			lastSeq := reupdated[1].Seq
+			require.Empty(t, changes(lastSeq))
+
+			// deleted segments are reported with a tombstone.
+			metabasetest.DeleteObjectExactVersion{
+				Opts: metabase.DeleteObjectExactVersion{
+					ObjectLocation: obj.Location(),
+					Version:        obj.Version,
+				},
+				Result: metabase.DeleteObjectResult{
+					Objects: []metabase.Object{{
+						ObjectStream: obj,
+					}},
+				},
+			}.Check(ctx, t, db)
+
+			deleted := changes(lastSeq)
+			require.Len(t, deleted, 2)
+			require.Greater(t, deleted[0].Seq, lastSeq)
+			require.Less(t, deleted[0].Seq, deleted[1].Seq)
+			for _, change := range deleted {
+				require.True(t, change.Deleted)
+				require.Equal(t, obj.StreamID, change.StreamID)
+				require.Equal(t, storj.PieceID{1}, change.RootPieceID)
+			}
+			require.ElementsMatch(t,
+				[]metabase.Pieces{pieces0, pieces1},
+				[]metabase.Pieces{deleted[0].Pieces, deleted[1].Pieces})
+
+			// tombstones can be removed once observers applied them.
+			count, err := db.DeleteSegmentTombstones(ctx, metabase.DeleteSegmentTombstones{UpToSeq: deleted[0].Seq})
+			require.NoError(t, err)
+			require.EqualValues(t, 1, count)
+			require.Equal(t, deleted[1:], changes(0))
+
+			// tombstones are removed after the retention, even when observers didn't apply them.
+			count, err = db.DeleteSegmentTombstones(ctx, metabase.DeleteSegmentTombstones{DeletedBefore: time.Now().Add(-time.Hour)})
+			require.NoError(t, err)
+			require.Zero(t, count)
+			require.Equal(t, deleted[1:], changes(0))
+
+			count, err = db.DeleteSegmentTombstones(ctx, metabase.DeleteSegmentTombstones{DeletedBefore: time.Now().Add(time.Hour)})
+			require.NoError(t, err)
+			require.EqualValues(t, 1, count)
+			require.Empty(t, changes(0))
+		})
+
+		t.Run("tombstones", func(t *testing.T) {
+			defer metabasetest.DeleteAll{}.Check(ctx, t, db)
+
+			changes := func() []metabase.SegmentPiecesChange {
+				watermark, err := db.GetSegmentPiecesChangedWatermark(ctx)
+				require.NoError(t, err)
+
+				result, err := db.GetSegmentsChangedSince(ctx, metabase.GetSegmentsChangedSince{UpToSeq: watermark})
+				require.NoError(t, err)
+				return result.Changes
+			}
+
+			// inline segments don't have pieces, hence they don't get tombstones.
+			inline := metabasetest.RandObjectStream()
+			metabasetest.BeginObjectExactVersion{
+				Opts: metabase.BeginObjectExactVersion{
+					ObjectStream: inline,
+					Encryption:   metabasetest.DefaultEncryption,
+				},
+				Version: inline.Version,
+			}.Check(ctx, t, db)
+			metabasetest.CommitInlineSegment{
+				Opts: metabase.CommitInlineSegment{
+					ObjectStream: inline,
+
+					EncryptedKey:      testrand.Bytes(32),
+					EncryptedKeyNonce: testrand.Bytes(32),
+
+					InlineData: testrand.Bytes(1024),
+
+					PlainSize:   512,
+					PlainOffset: 0,
+				},
+			}.Check(ctx, t, db)
+			metabasetest.CommitObject{
+				Opts: metabase.CommitObject{
+					ObjectStream: inline,
+				},
+			}.Check(ctx, t, db)
+
+			_, err := db.DeleteObjectExactVersion(ctx, metabase.DeleteObjectExactVersion{
+				ObjectLocation: inline.Location(),
+				Version:        inline.Version,
+			})
+			require.NoError(t, err)
+			require.Empty(t, changes())
+
+			metabasetest.CreateObject(ctx, t, db, obj, 5)
+			_, err = db.DeleteObjectExactVersion(ctx, metabase.DeleteObjectExactVersion{
+				ObjectLocation: obj.Location(),
+				Version:        obj.Version,
+			})
+			require.NoError(t, err)
+
+			deleted := changes()
+			require.Len(t, deleted, 5)
+
+			// tombstones are deleted in batches.
+			_, err = db.DeleteSegmentTombstones(ctx, metabase.DeleteSegmentTombstones{BatchSize: -1})
+			require.True(t, metabase.ErrInvalidRequest.Has(err))
+
+			count, err := db.DeleteSegmentTombstones(ctx, metabase.DeleteSegmentTombstones{
+				UpToSeq:   deleted[2].Seq,
+				BatchSize: 2,
+			})
+			require.NoError(t, err)
+			require.EqualValues(t, 3, count)
+			require.Equal(t, deleted[3:], changes())
+
+			count, err = db.DeleteSegmentTombstones(ctx, metabase.DeleteSegmentTombstones{
+				DeletedBefore: time.Now().Add(time.Hour),
+				BatchSize:     1,
+			})
+			require.NoError(t, err)
+			require.EqualValues(t, 2, count)
+			require.Empty(t, changes())
+		})
+
+		t.Run("uncommitted changes", func(t *testing.T) {
+			defer metabasetest.DeleteAll{}.Check(ctx, t, db)
+
+			metabasetest.CreateObject(ctx, t, db, obj, 2)
+
+			list := func(seq, upToSeq int64) []metabase.SegmentPiecesChange {
+				result, err := db.GetSegmentsChangedSince(ctx, metabase.GetSegmentsChangedSince{Seq: seq, UpToSeq: upToSeq})
+				require.NoError(t, err)
+				return result.Changes
+			}
+
+			watermark, err := db.GetSegmentPiecesChangedWatermark(ctx)
+			require.NoError(t, err)
+
+			// the change of the first segment takes its sequence number,
+			// but it's committed only after the change of the second segment.
+			tx, err := db.UnderlyingTagSQL().BeginTx(ctx, nil)
+			require.NoError(t, err)
+			defer func() { _ = tx.Rollback() }()
+
+			_, err = tx.ExecContext(ctx, `
+				UPDATE segments SET pieces_changed_seq = nextval('segment_pieces_changed_seq')
+				WHERE stream_id = $1 AND position = 0
+			`, obj.StreamID)
+			require.NoError(t, err)
+
+			_, err = db.UpdateSegmentPieces(ctx, metabase.UpdateSegmentPieces{
+				StreamID:      obj.StreamID,
+				Position:      metabase.SegmentPosition{Index: 1},
+				OldPieces:     metabase.Pieces{{Number: 0, StorageNode: storj.NodeID{2}}},
+				NewRedundancy: metabasetest.DefaultRedundancy,
+				NewPieces:     metabase.Pieces{{Number: 1, StorageNode: testrand.NodeID()}},
+			})
+			require.NoError(t, err)
+
+			// the committed change is above the settled watermark, hence it's not listed yet
+			// and the listing doesn't advance past the uncommitted change.
+			require.Empty(t, list(watermark, watermark))
+
+			require.NoError(t, tx.Commit())
+
+			settled, err := db.GetSegmentPiecesChangedWatermark(ctx)
+			require.NoError(t, err)
+
+			changes := list(watermark, settled)
+			require.Len(t, changes, 2)
+			require.Equal(t, metabase.SegmentPosition{Index: 0}, changes[0].Position)
+			require.Equal(t, metabase.SegmentPosition{Index: 1}, changes[1].Position)
+		})
+
+		t.Run("limit", func(t *testing.T) {
+			defer metabasetest.DeleteAll{}.Check(ctx, t, db)
+
+			metabasetest.CreateObject(ctx, t, db, obj, 3)
+
+			for i := uint32(0); i < 3; i++ {
+				_, err := db.UpdateSegmentPieces(ctx, metabase.UpdateSegmentPieces{
+					StreamID:      obj.StreamID,
+					Position:      metabase.SegmentPosition{Index: i},
+					OldPieces:     metabase.Pieces{{Number: 0, StorageNode: storj.NodeID{2}}},
+					NewRedundancy: metabasetest.DefaultRedundancy,
+					NewPieces:     metabase.Pieces{{Number: 1, StorageNode: testrand.NodeID()}},
+				})
+				require.NoError(t, err)
+			}
+
+			watermark, err := db.GetSegmentPiecesChangedWatermark(ctx)
+			require.NoError(t, err)
+
+			result, err := db.GetSegmentsChangedSince(ctx, metabase.GetSegmentsChangedSince{UpToSeq: watermark, Limit: 2})
+			require.NoError(t, err)
+			require.True(t, result.More)
+			require.Len(t, result.Changes, 2)
+
+			result, err = db.GetSegmentsChangedSince(ctx, metabase.GetSegmentsChangedSince{Seq: result.Changes[1].Seq, UpToSeq: watermark, Limit: 2})
+			require.NoError(t, err)
+			require.False(t, result.More)
+			require.Len(t, result.Changes, 1)
+			require.Equal(t, metabase.SegmentPosition{Index: 2}, result.Changes[0].Position)
+		})
+	})
+}
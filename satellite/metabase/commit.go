@@ -298,7 +298,7 @@ func (db *DB) CommitSegment(ctx context.Context, opts CommitSegment) (err error)
 			root_piece_id, encrypted_key_nonce, encrypted_key,
 			encrypted_size, plain_offset, plain_size, encrypted_etag,
 			redundancy,
-			remote_alias_pieces
+			remote_alias_pieces, remote_alias_nodes, pieces_count
 		) VALUES (
 			(SELECT stream_id
 				FROM objects WHERE
@@ -312,13 +312,14 @@ func (db *DB) CommitSegment(ctx context.Context, opts CommitSegment) (err error)
 			$3, $4, $5,
 			$6, $7, $8, $9,
 			$10,
-			$11
+			$11, $17, $18
 		)`, opts.Position, opts.ExpiresAt,
 		opts.RootPieceID, opts.EncryptedKeyNonce, opts.EncryptedKey,
 		opts.EncryptedSize, opts.PlainOffset, opts.PlainSize, opts.EncryptedETag,
 		redundancyScheme{&opts.Redundancy},
 		aliasPieces,
 		opts.ProjectID, []byte(opts.BucketName), []byte(opts.ObjectKey), opts.Version, opts.StreamID,
+		aliasPieces.nodes(), aliasPieces.count(),
 	)
 	if err != nil {
 		if code := pgerrcode.FromError(err); code == pgxerrcode.NotNullViolation {
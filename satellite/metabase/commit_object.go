@@ -324,9 +324,12 @@ func (db *DB) deleteSegmentsNotInCommit(ctx context.Context, tx tagsql.Tx, strea
 
 	// This potentially could be done together with the previous database call.
 	err = withRows(tx.QueryContext(ctx, `
-			DELETE FROM segments
-			WHERE stream_id = $1 AND position = ANY($2)
-			RETURNING root_piece_id, remote_alias_pieces
+			WITH deleted_segments AS (
+				DELETE FROM segments
+				WHERE stream_id = $1 AND position = ANY($2)
+				RETURNING stream_id, position, root_piece_id, remote_alias_pieces
+			), `+segmentTombstonesCTE("deleted_segments")+`
+			SELECT root_piece_id, remote_alias_pieces FROM deleted_segments
 		`, streamID, pgutil.Int8Array(positions)))(func(rows tagsql.Rows) error {
 		for rows.Next() {
 			var deleted DeletedSegmentInfo
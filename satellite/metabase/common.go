@@ -324,6 +324,18 @@ func (p Pieces) Verify() error {
 	return nil
 }
 
+// verifyStorageNodesUnique verifies that no storage node holds more than one of the pieces.
+func (p Pieces) verifyStorageNodesUnique() error {
+	numbers := make(map[storj.NodeID]uint16, len(p))
+	for _, piece := range p {
+		if number, ok := numbers[piece.StorageNode]; ok {
+			return ErrInvalidRequest.New("piece number %d is stored on the same storage node as piece number %d", piece.Number, number)
+		}
+		numbers[piece.StorageNode] = piece.Number
+	}
+	return nil
+}
+
 // Equal checks if Pieces structures are equal.
 func (p Pieces) Equal(pieces Pieces) bool {
 	if len(p) != len(pieces) {
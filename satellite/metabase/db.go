@@ -35,7 +35,8 @@ type DB struct {
 
 	aliasCache *NodeAliasCache
 
-	testCleanup func() error
+	testCleanup        func() error
+	testWrapRowQuerier func(RowQuerier) RowQuerier
 }
 
 // Open opens a connection to metabase.
@@ -91,6 +92,12 @@ func (db *DB) TestingSetCleanup(cleanup func() error) {
 	db.testCleanup = cleanup
 }
 
+// TestingWrapRowQuerier is used to wrap the querier of segment pieces updates, e.g. to
+// inject database errors. nil removes the wrapper.
+func (db *DB) TestingWrapRowQuerier(wrap func(RowQuerier) RowQuerier) {
+	db.testWrapRowQuerier = wrap
+}
+
 // Close closes the connection to database.
 func (db *DB) Close() error {
 	return errs.Combine(Error.Wrap(db.db.Close()), db.testCleanup())
@@ -302,6 +309,90 @@ func (db *DB) PostgresMigration() *migrate.Migration {
 					`ALTER TABLE segments ADD COLUMN expires_at TIMESTAMPTZ`,
 				},
 			},
+			{
+				DB:          &db.db,
+				Description: "add expiration_updated_at column to segments",
+				Version:     13,
+				Action: migrate.SQL{
+					`ALTER TABLE segments ADD COLUMN expiration_updated_at TIMESTAMPTZ`,
+					`CREATE INDEX segments_expiration_updated_at_index ON segments (expiration_updated_at, stream_id, position) WHERE expiration_updated_at IS NOT NULL`,
+				},
+			},
+			{
+				DB:          &db.db,
+				Description: "add segment pieces change feed",
+				Version:     14,
+				Action: migrate.SQL{
+					`CREATE SEQUENCE segment_pieces_changed_seq
+						INCREMENT BY 1
+						MINVALUE 1
+						START WITH 1
+					`,
+					`ALTER TABLE segments ADD COLUMN pieces_changed_seq INT8`,
+					`CREATE INDEX segments_pieces_changed_seq_index ON segments (pieces_changed_seq)`,
+					`CREATE TABLE segment_tombstones (
+						pieces_changed_seq INT8 NOT NULL PRIMARY KEY,
+
+						stream_id BYTEA NOT NULL,
+						position  INT8  NOT NULL,
+
+						root_piece_id       BYTEA NOT NULL,
+						remote_alias_pieces BYTEA,
+
+						deleted_at TIMESTAMPTZ NOT NULL default now()
+					)`,
+				},
+			},
+			{
+				DB:          &db.db,
+				Description: "add remote_alias_nodes column to segments",
+				Version:     15,
+				Action: migrate.SQL{
+					`ALTER TABLE segments ADD COLUMN remote_alias_nodes INT4[]`,
+					`CREATE INDEX segments_remote_alias_nodes_index ON segments USING GIN (remote_alias_nodes)`,
+				},
+			},
+			{
+				DB:          &db.db,
+				Description: "add pieces_count column to segments",
+				Version:     16,
+				Action: migrate.SQL{
+					`ALTER TABLE segments ADD COLUMN pieces_count INT4`,
+					`CREATE INDEX segments_pieces_count_index ON segments (pieces_count)`,
+				},
+			},
+			{
+				DB:          &db.db,
+				Description: "add repair_count column to segments",
+				Version:     17,
+				Action: migrate.SQL{
+					`ALTER TABLE segments ADD COLUMN repair_count INT4 NOT NULL DEFAULT 0`,
+				},
+			},
+			{
+				DB:          &db.db,
+				Description: "add placement column to segments",
+				Version:     18,
+				Action: migrate.SQL{
+					`ALTER TABLE segments ADD COLUMN placement INT2 NOT NULL DEFAULT 0`,
+				},
+			},
+			{
+				DB:          &db.db,
+				Description: "add stream_id index on objects",
+				Version:     19,
+				Action: migrate.SQL{
+					`CREATE INDEX objects_stream_id_index ON objects (stream_id)`,
+				},
+			},
+			{
+				DB:          &db.db,
+				Description: "add deleted_at index on segment_tombstones",
+				Version:     20,
+				Action: migrate.SQL{
+					`CREATE INDEX segment_tombstones_deleted_at_index ON segment_tombstones (deleted_at)`,
+				},
+			},
 		},
 	}
 }
@@ -117,8 +117,8 @@ func (db *DB) DeleteObjectExactVersion(ctx context.Context, opts DeleteObjectExa
 			), deleted_segments AS (
 				DELETE FROM segments
 				WHERE segments.stream_id in (SELECT deleted_objects.stream_id FROM deleted_objects)
-				RETURNING segments.stream_id,segments.root_piece_id, segments.remote_alias_pieces
-			)
+				RETURNING segments.stream_id, segments.position, segments.root_piece_id, segments.remote_alias_pieces
+			), `+segmentTombstonesCTE("deleted_segments")+`
 			SELECT
 				deleted_objects.version, deleted_objects.stream_id,
 				deleted_objects.created_at, deleted_objects.expires_at,
@@ -188,8 +188,8 @@ func (db *DB) DeletePendingObject(ctx context.Context, opts DeletePendingObject)
 			), deleted_segments AS (
 				DELETE FROM segments
 				WHERE segments.stream_id in (SELECT deleted_objects.stream_id FROM deleted_objects)
-				RETURNING segments.stream_id,segments.root_piece_id, segments.remote_alias_pieces
-			)
+				RETURNING segments.stream_id, segments.position, segments.root_piece_id, segments.remote_alias_pieces
+			), `+segmentTombstonesCTE("deleted_segments")+`
 			SELECT
 				deleted_objects.version, deleted_objects.stream_id,
 				deleted_objects.created_at, deleted_objects.expires_at,
@@ -250,8 +250,8 @@ func (db *DB) DeleteObjectLatestVersion(ctx context.Context, opts DeleteObjectLa
 			), deleted_segments AS (
 				DELETE FROM segments
 				WHERE segments.stream_id in (SELECT deleted_objects.stream_id FROM deleted_objects)
-				RETURNING segments.stream_id,segments.root_piece_id, segments.remote_alias_pieces
-			)
+				RETURNING segments.stream_id, segments.position, segments.root_piece_id, segments.remote_alias_pieces
+			), ` + segmentTombstonesCTE("deleted_segments") + `
 			SELECT
 				deleted_objects.version, deleted_objects.stream_id,
 				deleted_objects.created_at, deleted_objects.expires_at,
@@ -291,8 +291,8 @@ func (db *DB) DeleteObjectLatestVersion(ctx context.Context, opts DeleteObjectLa
 			), deleted_segments AS (
 				DELETE FROM segments
 				WHERE segments.stream_id in (SELECT deleted_objects.stream_id FROM deleted_objects)
-				RETURNING segments.stream_id,segments.root_piece_id, segments.remote_alias_pieces
-			)
+				RETURNING segments.stream_id, segments.position, segments.root_piece_id, segments.remote_alias_pieces
+			), ` + segmentTombstonesCTE("deleted_segments") + `
 			SELECT
 				deleted_objects.version, deleted_objects.stream_id,
 				deleted_objects.created_at, deleted_objects.expires_at,
@@ -351,8 +351,8 @@ func (db *DB) DeleteObjectAnyStatusAllVersions(ctx context.Context, opts DeleteO
 			), deleted_segments AS (
 				DELETE FROM segments
 				WHERE segments.stream_id in (SELECT deleted_objects.stream_id FROM deleted_objects)
-				RETURNING segments.stream_id,segments.root_piece_id, segments.remote_alias_pieces
-			)
+				RETURNING segments.stream_id, segments.position, segments.root_piece_id, segments.remote_alias_pieces
+			), `+segmentTombstonesCTE("deleted_segments")+`
 			SELECT
 				deleted_objects.version, deleted_objects.stream_id,
 				deleted_objects.created_at, deleted_objects.expires_at,
@@ -428,8 +428,8 @@ func (db *DB) DeleteObjectsAllVersions(ctx context.Context, opts DeleteObjectsAl
 				), deleted_segments AS (
 					DELETE FROM segments
 					WHERE segments.stream_id in (SELECT deleted_objects.stream_id FROM deleted_objects)
-					RETURNING segments.stream_id,segments.root_piece_id, segments.remote_alias_pieces
-				)
+					RETURNING segments.stream_id, segments.position, segments.root_piece_id, segments.remote_alias_pieces
+				), `+segmentTombstonesCTE("deleted_segments")+`
 				SELECT
 					deleted_objects.project_id, deleted_objects.bucket_name,
 					deleted_objects.object_key,deleted_objects.version, deleted_objects.stream_id,
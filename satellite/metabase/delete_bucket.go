@@ -50,10 +50,12 @@ func (db *DB) DeleteBucketObjects(ctx context.Context, opts DeleteBucketObjects)
 			DELETE FROM objects
 			WHERE project_id = $1 AND bucket_name = $2 LIMIT $3
 			RETURNING objects.stream_id
-		)
-		DELETE FROM segments
-		WHERE segments.stream_id in (SELECT deleted_objects.stream_id FROM deleted_objects)
-		RETURNING segments.stream_id, segments.root_piece_id, segments.remote_alias_pieces
+		), deleted_segments AS (
+			DELETE FROM segments
+			WHERE segments.stream_id in (SELECT deleted_objects.stream_id FROM deleted_objects)
+			RETURNING segments.stream_id, segments.position, segments.root_piece_id, segments.remote_alias_pieces
+		), ` + segmentTombstonesCTE("deleted_segments") + `
+		SELECT stream_id, root_piece_id, remote_alias_pieces FROM deleted_segments
 	`
 	case dbutil.Postgres:
 		query = `
@@ -65,10 +67,12 @@ func (db *DB) DeleteBucketObjects(ctx context.Context, opts DeleteBucketObjects)
 				LIMIT $3
 			)
 			RETURNING objects.stream_id
-		)
-		DELETE FROM segments
-		WHERE segments.stream_id in (SELECT deleted_objects.stream_id FROM deleted_objects)
-		RETURNING segments.stream_id, segments.root_piece_id, segments.remote_alias_pieces
+		), deleted_segments AS (
+			DELETE FROM segments
+			WHERE segments.stream_id in (SELECT deleted_objects.stream_id FROM deleted_objects)
+			RETURNING segments.stream_id, segments.position, segments.root_piece_id, segments.remote_alias_pieces
+		), ` + segmentTombstonesCTE("deleted_segments") + `
+		SELECT stream_id, root_piece_id, remote_alias_pieces FROM deleted_segments
 	`
 	default:
 		return 0, Error.New("unhandled database: %v", db.impl)
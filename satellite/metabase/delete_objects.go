@@ -185,9 +185,12 @@ func (db *DB) deleteObjectsAndSegments(ctx context.Context, objects []ObjectStre
 					AND stream_id = $5::BYTEA
 			`, obj.ProjectID, []byte(obj.BucketName), []byte(obj.ObjectKey), obj.Version, obj.StreamID)
 			batch.Queue(`
-				DELETE FROM segments
-				WHERE segments.stream_id = $1::BYTEA
-			`, obj.StreamID)
+				WITH deleted_segments AS (
+					DELETE FROM segments
+					WHERE segments.stream_id = $1::BYTEA
+					RETURNING segments.stream_id, segments.position, segments.root_piece_id, segments.remote_alias_pieces
+				)
+				`+segmentTombstonesInsert("deleted_segments"), obj.StreamID)
 			batch.Queue(`COMMIT TRANSACTION`)
 		}
 
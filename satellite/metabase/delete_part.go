@@ -46,10 +46,13 @@ func (db *DB) DeletePart(ctx context.Context, opts DeletePart) (err error) {
 	}
 	deleted := make([]Deleted, 0, 10)
 	err = withRows(db.db.QueryContext(ctx, `
-				DELETE FROM segments WHERE
-					stream_id = $1 AND position BETWEEN $2 AND $3
-				RETURNING
-					root_piece_id, remote_alias_pieces
+				WITH deleted_segments AS (
+					DELETE FROM segments WHERE
+						stream_id = $1 AND position BETWEEN $2 AND $3
+					RETURNING
+						stream_id, position, root_piece_id, remote_alias_pieces
+				), `+segmentTombstonesCTE("deleted_segments")+`
+				SELECT root_piece_id, remote_alias_pieces FROM deleted_segments
 			`, opts.StreamID, minPosition, maxPosition))(func(rows tagsql.Rows) error {
 		for rows.Next() {
 			var rootPieceID storj.PieceID
@@ -0,0 +1,308 @@
+// Copyright (C) 2021 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package metabase
+
+import (
+	"context"
+	"time"
+
+	"storj.io/common/storj"
+	"storj.io/common/uuid"
+	"storj.io/private/dbutil/txutil"
+	"storj.io/private/tagsql"
+)
+
+// UpdateObjectExpiration contains arguments necessary for changing an object expiration.
+type UpdateObjectExpiration struct {
+	ObjectStream
+
+	// ExpiresAt is the new expiration time, nil means the object never expires.
+	ExpiresAt *time.Time
+}
+
+// SegmentExpirationChange contains pieces of a segment which expiration was changed.
+type SegmentExpirationChange struct {
+	StreamID uuid.UUID
+	Position SegmentPosition
+
+	RootPieceID storj.PieceID
+	Pieces      Pieces
+
+	OldExpiresAt *time.Time
+	NewExpiresAt *time.Time
+
+	ExpirationUpdatedAt time.Time
+}
+
+// UpdateObjectExpirationResult is the result of UpdateObjectExpiration.
+type UpdateObjectExpirationResult struct {
+	// Segments contains segments which expiration has actually changed.
+	Segments []SegmentExpirationChange
+}
+
+// UpdateObjectExpiration changes expiration of a committed object and all its segments.
+// It returns pieces of every segment which expiration has changed, so that
+// the storage nodes can be notified about the new piece TTL.
+func (db *DB) UpdateObjectExpiration(ctx context.Context, opts UpdateObjectExpiration) (result UpdateObjectExpirationResult, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	if err := opts.ObjectStream.Verify(); err != nil {
+		return UpdateObjectExpirationResult{}, err
+	}
+
+	if opts.ObjectStream.Version <= 0 {
+		return UpdateObjectExpirationResult{}, ErrInvalidRequest.New("Version invalid: %v", opts.Version)
+	}
+
+	type oldSegment struct {
+		Position    SegmentPosition
+		RootPieceID storj.PieceID
+		AliasPieces AliasPieces
+		ExpiresAt   *time.Time
+	}
+
+	var segments []oldSegment
+	var updatedAt time.Time
+	err = txutil.WithTx(ctx, db.db, nil, func(ctx context.Context, tx tagsql.Tx) (err error) {
+		segments = nil
+
+		updated, err := tx.ExecContext(ctx, `
+			UPDATE objects SET
+				expires_at = $6
+			WHERE
+				project_id   = $1 AND
+				bucket_name  = $2 AND
+				object_key   = $3 AND
+				version      = $4 AND
+				stream_id    = $5 AND
+				status       = `+committedStatus,
+			opts.ProjectID, []byte(opts.BucketName), []byte(opts.ObjectKey), opts.Version, opts.StreamID,
+			opts.ExpiresAt)
+		if err != nil {
+			return Error.New("unable to update object expiration: %w", err)
+		}
+
+		affected, err := updated.RowsAffected()
+		if err != nil {
+			return Error.New("failed to get rows affected: %w", err)
+		}
+		if affected == 0 {
+			return storj.ErrObjectNotFound.Wrap(
+				Error.New("object with specified version and committed status is missing"),
+			)
+		}
+
+		err = withRows(tx.QueryContext(ctx, `
+			SELECT position, root_piece_id, remote_alias_pieces, expires_at
+			FROM segments
+			WHERE
+				stream_id = $1 AND
+				expires_at IS DISTINCT FROM $2
+			ORDER BY position
+		`, opts.StreamID, opts.ExpiresAt))(func(rows tagsql.Rows) error {
+			for rows.Next() {
+				var segment oldSegment
+				if err := rows.Scan(&segment.Position, &segment.RootPieceID, &segment.AliasPieces, &segment.ExpiresAt); err != nil {
+					return Error.New("failed to scan segments: %w", err)
+				}
+				segments = append(segments, segment)
+			}
+			return nil
+		})
+		if err != nil {
+			return Error.New("unable to fetch segments: %w", err)
+		}
+
+		return withRows(tx.QueryContext(ctx, `
+			UPDATE segments SET
+				expires_at            = $2,
+				expiration_updated_at = now()
+			WHERE
+				stream_id = $1 AND
+				expires_at IS DISTINCT FROM $2
+			RETURNING expiration_updated_at
+		`, opts.StreamID, opts.ExpiresAt))(func(rows tagsql.Rows) error {
+			for rows.Next() {
+				if err := rows.Scan(&updatedAt); err != nil {
+					return Error.New("failed to scan segments: %w", err)
+				}
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return UpdateObjectExpirationResult{}, err
+	}
+
+	for _, segment := range segments {
+		pieces, err := db.aliasCache.ConvertAliasesToPieces(ctx, segment.AliasPieces)
+		if err != nil {
+			return UpdateObjectExpirationResult{}, Error.New("failed to convert aliases to pieces: %w", err)
+		}
+
+		result.Segments = append(result.Segments, SegmentExpirationChange{
+			StreamID:            opts.StreamID,
+			Position:            segment.Position,
+			RootPieceID:         segment.RootPieceID,
+			Pieces:              pieces,
+			OldExpiresAt:        segment.ExpiresAt,
+			NewExpiresAt:        opts.ExpiresAt,
+			ExpirationUpdatedAt: updatedAt,
+		})
+	}
+
+	mon.Meter("object_update_expiration").Mark(1)
+
+	return result, nil
+}
+
+// UpdateSegmentsExpiration contains arguments necessary for changing expiration of all segments of a stream.
+type UpdateSegmentsExpiration struct {
+	StreamID uuid.UUID
+
+	// ExpiresAt is the new expiration time, nil means the segments never expire.
+	ExpiresAt *time.Time
+}
+
+// UpdateSegmentsExpiration changes expiration of all segments of a committed object
+// together with the expiration of the object, so that they never disagree.
+// It returns the number of segments which expiration has changed.
+func (db *DB) UpdateSegmentsExpiration(ctx context.Context, opts UpdateSegmentsExpiration) (updated int64, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	if opts.StreamID.IsZero() {
+		return 0, ErrInvalidRequest.New("StreamID missing")
+	}
+
+	err = txutil.WithTx(ctx, db.db, nil, func(ctx context.Context, tx tagsql.Tx) (err error) {
+		result, err := tx.ExecContext(ctx, `
+			UPDATE objects SET
+				expires_at = $2
+			WHERE
+				stream_id = $1 AND
+				status    = `+committedStatus,
+			opts.StreamID, opts.ExpiresAt)
+		if err != nil {
+			return Error.New("unable to update object expiration: %w", err)
+		}
+
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return Error.New("failed to get rows affected: %w", err)
+		}
+		if affected == 0 {
+			return storj.ErrObjectNotFound.Wrap(
+				Error.New("object with specified stream id and committed status is missing"),
+			)
+		}
+
+		result, err = tx.ExecContext(ctx, `
+			UPDATE segments SET
+				expires_at            = $2,
+				expiration_updated_at = now()
+			WHERE
+				stream_id = $1 AND
+				expires_at IS DISTINCT FROM $2
+		`, opts.StreamID, opts.ExpiresAt)
+		if err != nil {
+			return Error.New("unable to update segments expiration: %w", err)
+		}
+
+		updated, err = result.RowsAffected()
+		if err != nil {
+			return Error.New("failed to get rows affected: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	mon.Meter("segments_update_expiration").Mark(1)
+
+	return updated, nil
+}
+
+// SegmentExpirationCursor is a cursor used during iteration over segments with changed expiration.
+type SegmentExpirationCursor struct {
+	ExpirationUpdatedAt time.Time
+	StreamID            uuid.UUID
+	Position            SegmentPosition
+}
+
+// GetSegmentsWithExpirationChangedSince contains arguments necessary for listing
+// segments which expiration was changed.
+type GetSegmentsWithExpirationChangedSince struct {
+	Since  time.Time
+	Cursor SegmentExpirationCursor
+	Limit  int
+}
+
+// GetSegmentsWithExpirationChangedSinceResult is the result of GetSegmentsWithExpirationChangedSince.
+type GetSegmentsWithExpirationChangedSinceResult struct {
+	Segments []SegmentExpirationChange
+	More     bool
+}
+
+// GetSegmentsWithExpirationChangedSince lists segments which expiration was changed
+// at or after the specified time. The listing is ordered by the time of the change,
+// Cursor can be used to continue the listing from the last returned segment.
+//
+// Only the current expiration of the segment is known, so OldExpiresAt is not set.
+func (db *DB) GetSegmentsWithExpirationChangedSince(ctx context.Context, opts GetSegmentsWithExpirationChangedSince) (result GetSegmentsWithExpirationChangedSinceResult, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	if opts.Limit < 0 {
+		return GetSegmentsWithExpirationChangedSinceResult{}, ErrInvalidRequest.New("Invalid limit: %d", opts.Limit)
+	}
+	ListLimit.Ensure(&opts.Limit)
+
+	if opts.Cursor.ExpirationUpdatedAt.Before(opts.Since) {
+		opts.Cursor = SegmentExpirationCursor{ExpirationUpdatedAt: opts.Since}
+	}
+
+	err = withRows(db.db.QueryContext(ctx, `
+		SELECT
+			stream_id, position,
+			expiration_updated_at, expires_at,
+			root_piece_id, remote_alias_pieces
+		FROM segments
+		WHERE
+			expiration_updated_at >= $1 AND
+			(expiration_updated_at, stream_id, position) > ($1, $2, $3)
+		ORDER BY expiration_updated_at, stream_id, position
+		LIMIT $4
+	`, opts.Cursor.ExpirationUpdatedAt, opts.Cursor.StreamID, opts.Cursor.Position, opts.Limit+1))(func(rows tagsql.Rows) error {
+		for rows.Next() {
+			var segment SegmentExpirationChange
+			var aliasPieces AliasPieces
+			err := rows.Scan(
+				&segment.StreamID, &segment.Position,
+				&segment.ExpirationUpdatedAt, &segment.NewExpiresAt,
+				&segment.RootPieceID, &aliasPieces,
+			)
+			if err != nil {
+				return Error.New("failed to scan segments: %w", err)
+			}
+
+			segment.Pieces, err = db.aliasCache.ConvertAliasesToPieces(ctx, aliasPieces)
+			if err != nil {
+				return Error.New("failed to convert aliases to pieces: %w", err)
+			}
+
+			result.Segments = append(result.Segments, segment)
+		}
+		return nil
+	})
+	if err != nil {
+		return GetSegmentsWithExpirationChangedSinceResult{}, Error.New("unable to fetch segments: %w", err)
+	}
+
+	if len(result.Segments) > opts.Limit {
+		result.More = true
+		result.Segments = result.Segments[:len(result.Segments)-1]
+	}
+
+	return result, nil
+}
@@ -0,0 +1,251 @@
+// Copyright (C) 2021 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package metabase_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"storj.io/common/storj"
+	"storj.io/common/testcontext"
+	"storj.io/storj/satellite/metabase"
+	"storj.io/storj/satellite/metabase/metabasetest"
+)
+
+func TestUpdateObjectExpiration(t *testing.T) {
+	metabasetest.Run(t, func(ctx *testcontext.Context, t *testing.T, db *metabase.DB) {
+		obj := metabasetest.RandObjectStream()
+
+		for _, test := range metabasetest.InvalidObjectStreams(obj) {
+			test := test
+			t.Run(test.Name, func(t *testing.T) {
+				defer metabasetest.DeleteAll{}.Check(ctx, t, db)
+				metabasetest.UpdateObjectExpiration{
+					Opts: metabase.UpdateObjectExpiration{
+						ObjectStream: test.ObjectStream,
+					},
+					ErrClass: test.ErrClass,
+					ErrText:  test.ErrText,
+				}.Check(ctx, t, db)
+				metabasetest.Verify{}.Check(ctx, t, db)
+			})
+		}
+
+		t.Run("object missing", func(t *testing.T) {
+			defer metabasetest.DeleteAll{}.Check(ctx, t, db)
+
+			metabasetest.UpdateObjectExpiration{
+				Opts: metabase.UpdateObjectExpiration{
+					ObjectStream: obj,
+				},
+				ErrClass: &storj.ErrObjectNotFound,
+				ErrText:  "metabase: object with specified version and committed status is missing",
+			}.Check(ctx, t, db)
+			metabasetest.Verify{}.Check(ctx, t, db)
+		})
+
+		t.Run("shorten expiration", func(t *testing.T) {
+			defer metabasetest.DeleteAll{}.Check(ctx, t, db)
+
+			now := time.Now()
+			oldExpiresAt := now.Add(48 * time.Hour)
+			newExpiresAt := now.Add(24 * time.Hour)
+
+			metabasetest.CreateExpiredObject(ctx, t, db, obj, 2, oldExpiresAt)
+
+			expectedSegment := func(index uint32) metabase.SegmentExpirationChange {
+				return metabase.SegmentExpirationChange{
+					StreamID:            obj.StreamID,
+					Position:            metabase.SegmentPosition{Index: index},
+					RootPieceID:         storj.PieceID{1},
+					Pieces:              metabase.Pieces{{Number: 0, StorageNode: storj.NodeID{2}}},
+					OldExpiresAt:        &oldExpiresAt,
+					NewExpiresAt:        &newExpiresAt,
+					ExpirationUpdatedAt: now,
+				}
+			}
+
+			metabasetest.UpdateObjectExpiration{
+				Opts: metabase.UpdateObjectExpiration{
+					ObjectStream: obj,
+					ExpiresAt:    &newExpiresAt,
+				},
+				Result: metabase.UpdateObjectExpirationResult{
+					Segments: []metabase.SegmentExpirationChange{
+						expectedSegment(0),
+						expectedSegment(1),
+					},
+				},
+			}.Check(ctx, t, db)
+
+			changed := metabasetest.GetSegmentsWithExpirationChangedSince{
+				Opts: metabase.GetSegmentsWithExpirationChangedSince{
+					Since: now.Add(-time.Minute),
+				},
+				Result: metabase.GetSegmentsWithExpirationChangedSinceResult{
+					Segments: []metabase.SegmentExpirationChange{
+						withoutOldExpiration(expectedSegment(0)),
+						withoutOldExpiration(expectedSegment(1)),
+					},
+				},
+			}.Check(ctx, t, db)
+
+			// setting the same expiration doesn't change anything.
+			metabasetest.UpdateObjectExpiration{
+				Opts: metabase.UpdateObjectExpiration{
+					ObjectStream: obj,
+					ExpiresAt:    &newExpiresAt,
+				},
+			}.Check(ctx, t, db)
+
+			metabasetest.GetSegmentsWithExpirationChangedSince{
+				Opts: metabase.GetSegmentsWithExpirationChangedSince{
+					Since: changed.Segments[1].ExpirationUpdatedAt.Add(time.Microsecond),
+				},
+			}.Check(ctx, t, db)
+
+			// listing continues from the cursor.
+			metabasetest.GetSegmentsWithExpirationChangedSince{
+				Opts: metabase.GetSegmentsWithExpirationChangedSince{
+					Since: now.Add(-time.Minute),
+					Cursor: metabase.SegmentExpirationCursor{
+						ExpirationUpdatedAt: changed.Segments[0].ExpirationUpdatedAt,
+						StreamID:            changed.Segments[0].StreamID,
+						Position:            changed.Segments[0].Position,
+					},
+					Limit: 1,
+				},
+				Result: metabase.GetSegmentsWithExpirationChangedSinceResult{
+					Segments: []metabase.SegmentExpirationChange{
+						withoutOldExpiration(expectedSegment(1)),
+					},
+				},
+			}.Check(ctx, t, db)
+		})
+
+		t.Run("remove expiration", func(t *testing.T) {
+			defer metabasetest.DeleteAll{}.Check(ctx, t, db)
+
+			now := time.Now()
+			oldExpiresAt := now.Add(48 * time.Hour)
+
+			metabasetest.CreateExpiredObject(ctx, t, db, obj, 1, oldExpiresAt)
+
+			metabasetest.UpdateObjectExpiration{
+				Opts: metabase.UpdateObjectExpiration{
+					ObjectStream: obj,
+				},
+				Result: metabase.UpdateObjectExpirationResult{
+					Segments: []metabase.SegmentExpirationChange{{
+						StreamID:            obj.StreamID,
+						RootPieceID:         storj.PieceID{1},
+						Pieces:              metabase.Pieces{{Number: 0, StorageNode: storj.NodeID{2}}},
+						OldExpiresAt:        &oldExpiresAt,
+						ExpirationUpdatedAt: now,
+					}},
+				},
+			}.Check(ctx, t, db)
+		})
+	})
+}
+
+func withoutOldExpiration(change metabase.SegmentExpirationChange) metabase.SegmentExpirationChange {
+	change.OldExpiresAt = nil
+	return change
+}
+
+func TestUpdateSegmentsExpiration(t *testing.T) {
+	metabasetest.Run(t, func(ctx *testcontext.Context, t *testing.T, db *metabase.DB) {
+		obj := metabasetest.RandObjectStream()
+
+		t.Run("StreamID missing", func(t *testing.T) {
+			defer metabasetest.DeleteAll{}.Check(ctx, t, db)
+
+			metabasetest.UpdateSegmentsExpiration{
+				Opts:     metabase.UpdateSegmentsExpiration{},
+				ErrClass: &metabase.ErrInvalidRequest,
+				ErrText:  "StreamID missing",
+			}.Check(ctx, t, db)
+			metabasetest.Verify{}.Check(ctx, t, db)
+		})
+
+		t.Run("object missing", func(t *testing.T) {
+			defer metabasetest.DeleteAll{}.Check(ctx, t, db)
+
+			metabasetest.UpdateSegmentsExpiration{
+				Opts: metabase.UpdateSegmentsExpiration{
+					StreamID: obj.StreamID,
+				},
+				ErrClass: &storj.ErrObjectNotFound,
+				ErrText:  "metabase: object with specified stream id and committed status is missing",
+			}.Check(ctx, t, db)
+			metabasetest.Verify{}.Check(ctx, t, db)
+		})
+
+		expirations := func(t *testing.T) (object *time.Time, segments []*time.Time) {
+			objects, err := db.TestingAllObjects(ctx)
+			require.NoError(t, err)
+			require.Len(t, objects, 1)
+
+			allSegments, err := db.TestingAllSegments(ctx)
+			require.NoError(t, err)
+			for _, segment := range allSegments {
+				segments = append(segments, segment.ExpiresAt)
+			}
+			return objects[0].ExpiresAt, segments
+		}
+
+		t.Run("extend expiration", func(t *testing.T) {
+			defer metabasetest.DeleteAll{}.Check(ctx, t, db)
+
+			now := time.Now()
+			newExpiresAt := now.Add(48 * time.Hour)
+
+			metabasetest.CreateExpiredObject(ctx, t, db, obj, 2, now.Add(24*time.Hour))
+
+			metabasetest.UpdateSegmentsExpiration{
+				Opts: metabase.UpdateSegmentsExpiration{
+					StreamID:  obj.StreamID,
+					ExpiresAt: &newExpiresAt,
+				},
+				Updated: 2,
+			}.Check(ctx, t, db)
+
+			object, segments := expirations(t)
+			require.WithinDuration(t, newExpiresAt, *object, time.Second)
+			require.Len(t, segments, 2)
+			for _, expiresAt := range segments {
+				require.WithinDuration(t, newExpiresAt, *expiresAt, time.Second)
+			}
+
+			// setting the same expiration doesn't change anything.
+			metabasetest.UpdateSegmentsExpiration{
+				Opts: metabase.UpdateSegmentsExpiration{
+					StreamID:  obj.StreamID,
+					ExpiresAt: &newExpiresAt,
+				},
+				Updated: 0,
+			}.Check(ctx, t, db)
+		})
+
+		t.Run("remove expiration", func(t *testing.T) {
+			defer metabasetest.DeleteAll{}.Check(ctx, t, db)
+
+			metabasetest.CreateExpiredObject(ctx, t, db, obj, 1, time.Now().Add(24*time.Hour))
+
+			metabasetest.UpdateSegmentsExpiration{
+				Opts: metabase.UpdateSegmentsExpiration{
+					StreamID: obj.StreamID,
+				},
+				Updated: 1,
+			}.Check(ctx, t, db)
+
+			object, segments := expirations(t)
+			require.Nil(t, object)
+			require.Equal(t, []*time.Time{nil}, segments)
+		})
+	})
+}
@@ -13,6 +13,8 @@ import (
 
 	"storj.io/common/storj"
 	"storj.io/common/uuid"
+	"storj.io/private/dbutil/pgutil"
+	"storj.io/private/tagsql"
 )
 
 // ErrSegmentNotFound is an error class for non-existing segment.
@@ -169,6 +171,124 @@ func (db *DB) GetObjectLatestVersion(ctx context.Context, opts GetObjectLatestVe
 	return object, nil
 }
 
+// GetObjectsLastCommittedBatch contains arguments necessary for fetching
+// latest committed versions of multiple objects from the same bucket.
+type GetObjectsLastCommittedBatch struct {
+	Bucket     BucketLocation
+	ObjectKeys []ObjectKey
+}
+
+// Verify verifies get objects batch request fields.
+func (opts *GetObjectsLastCommittedBatch) Verify() error {
+	if err := opts.Bucket.Verify(); err != nil {
+		return err
+	}
+	if len(opts.ObjectKeys) > getObjectsBatchLimit {
+		return ErrInvalidRequest.New("cannot get more than %d objects in a single request", getObjectsBatchLimit)
+	}
+	for _, key := range opts.ObjectKeys {
+		if key == "" {
+			return ErrInvalidRequest.New("ObjectKey missing")
+		}
+	}
+	return nil
+}
+
+// getObjectsBatchLimit is the maximum number of object keys in GetObjectsLastCommittedBatch.
+const getObjectsBatchLimit = 1000
+
+// GetObjectsLastCommittedBatchResult is the result of GetObjectsLastCommittedBatch.
+type GetObjectsLastCommittedBatchResult struct {
+	// Objects contains found objects in the order of the requested keys.
+	Objects []Object
+	// Missing contains keys without a committed object in the order of the requested keys.
+	Missing []ObjectKey
+}
+
+// GetObjectsLastCommittedBatch returns latest committed versions of multiple
+// objects from the same bucket using a single query. Duplicate keys are
+// returned only once.
+func (db *DB) GetObjectsLastCommittedBatch(ctx context.Context, opts GetObjectsLastCommittedBatch) (result GetObjectsLastCommittedBatchResult, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	if err := opts.Verify(); err != nil {
+		return GetObjectsLastCommittedBatchResult{}, err
+	}
+
+	// deduplicate keys keeping the order of the first occurrence.
+	keys := make([]ObjectKey, 0, len(opts.ObjectKeys))
+	seen := make(map[ObjectKey]struct{}, len(opts.ObjectKeys))
+	for _, key := range opts.ObjectKeys {
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		keys = append(keys, key)
+	}
+
+	if len(keys) == 0 {
+		return GetObjectsLastCommittedBatchResult{}, nil
+	}
+
+	rawKeys := make([][]byte, len(keys))
+	for i, key := range keys {
+		rawKeys[i] = []byte(key)
+	}
+
+	found := make(map[ObjectKey]Object, len(keys))
+	err = withRows(db.db.QueryContext(ctx, `
+		SELECT DISTINCT ON (objects.object_key)
+			objects.object_key, objects.stream_id, objects.version,
+			objects.created_at, objects.expires_at,
+			objects.segment_count,
+			objects.encrypted_metadata_nonce, objects.encrypted_metadata, objects.encrypted_metadata_encrypted_key,
+			objects.total_plain_size, objects.total_encrypted_size, objects.fixed_segment_size,
+			objects.encryption
+		FROM unnest($3::BYTEA[]) AS keys(object_key)
+		JOIN objects ON
+			objects.project_id  = $1 AND
+			objects.bucket_name = $2 AND
+			objects.object_key  = keys.object_key AND
+			objects.status      = `+committedStatus+`
+		ORDER BY objects.object_key, objects.version DESC
+	`, opts.Bucket.ProjectID, []byte(opts.Bucket.BucketName), pgutil.ByteaArray(rawKeys)))(func(rows tagsql.Rows) error {
+		for rows.Next() {
+			var object Object
+			err := rows.Scan(
+				&object.ObjectKey, &object.StreamID, &object.Version,
+				&object.CreatedAt, &object.ExpiresAt,
+				&object.SegmentCount,
+				&object.EncryptedMetadataNonce, &object.EncryptedMetadata, &object.EncryptedMetadataEncryptedKey,
+				&object.TotalPlainSize, &object.TotalEncryptedSize, &object.FixedSegmentSize,
+				encryptionParameters{&object.Encryption},
+			)
+			if err != nil {
+				return Error.New("unable to scan object: %w", err)
+			}
+
+			object.ProjectID = opts.Bucket.ProjectID
+			object.BucketName = opts.Bucket.BucketName
+			object.Status = Committed
+
+			found[object.ObjectKey] = object
+		}
+		return nil
+	})
+	if err != nil {
+		return GetObjectsLastCommittedBatchResult{}, Error.New("unable to query objects: %w", err)
+	}
+
+	for _, key := range keys {
+		if object, ok := found[key]; ok {
+			result.Objects = append(result.Objects, object)
+		} else {
+			result.Missing = append(result.Missing, key)
+		}
+	}
+
+	return result, nil
+}
+
 // GetSegmentByLocation contains arguments necessary for fetching a segment on specific segment location.
 type GetSegmentByLocation struct {
 	SegmentLocation
@@ -186,7 +306,7 @@ func (db *DB) GetSegmentByLocation(ctx context.Context, opts GetSegmentByLocatio
 	err = db.db.QueryRowContext(ctx, `
 			SELECT
 				stream_id,
-				created_at, expires_at, repaired_at,
+				created_at, expires_at, repaired_at, repair_count, placement,
 				root_piece_id, encrypted_key_nonce, encrypted_key,
 				encrypted_size, plain_offset, plain_size,
 				encrypted_etag,
@@ -205,7 +325,7 @@ func (db *DB) GetSegmentByLocation(ctx context.Context, opts GetSegmentByLocatio
 		`, opts.ProjectID, []byte(opts.BucketName), []byte(opts.ObjectKey), opts.Position.Encode()).
 		Scan(
 			&segment.StreamID,
-			&segment.CreatedAt, &segment.ExpiresAt, &segment.RepairedAt,
+			&segment.CreatedAt, &segment.ExpiresAt, &segment.RepairedAt, &segment.RepairCount, &segment.Placement,
 			&segment.RootPieceID, &segment.EncryptedKeyNonce, &segment.EncryptedKey,
 			&segment.EncryptedSize, &segment.PlainOffset, &segment.PlainSize,
 			&segment.EncryptedETag,
@@ -253,7 +373,7 @@ func (db *DB) GetSegmentByPosition(ctx context.Context, opts GetSegmentByPositio
 	var aliasPieces AliasPieces
 	err = db.db.QueryRowContext(ctx, `
 		SELECT
-			created_at, expires_at, repaired_at,
+			created_at, expires_at, repaired_at, repair_count, placement,
 			root_piece_id, encrypted_key_nonce, encrypted_key,
 			encrypted_size, plain_offset, plain_size,
 			encrypted_etag,
@@ -265,7 +385,7 @@ func (db *DB) GetSegmentByPosition(ctx context.Context, opts GetSegmentByPositio
 			position  = $2
 	`, opts.StreamID, opts.Position.Encode()).
 		Scan(
-			&segment.CreatedAt, &segment.ExpiresAt, &segment.RepairedAt,
+			&segment.CreatedAt, &segment.ExpiresAt, &segment.RepairedAt, &segment.RepairCount, &segment.Placement,
 			&segment.RootPieceID, &segment.EncryptedKeyNonce, &segment.EncryptedKey,
 			&segment.EncryptedSize, &segment.PlainOffset, &segment.PlainSize,
 			&segment.EncryptedETag,
@@ -307,7 +427,7 @@ func (db *DB) GetLatestObjectLastSegment(ctx context.Context, opts GetLatestObje
 	err = db.db.QueryRowContext(ctx, `
 		SELECT
 			stream_id, position,
-			created_at, repaired_at,
+			created_at, repaired_at, repair_count, placement,
 			root_piece_id, encrypted_key_nonce, encrypted_key,
 			encrypted_size, plain_offset, plain_size,
 			encrypted_etag,
@@ -328,7 +448,7 @@ func (db *DB) GetLatestObjectLastSegment(ctx context.Context, opts GetLatestObje
 	`, opts.ProjectID, []byte(opts.BucketName), []byte(opts.ObjectKey)).
 		Scan(
 			&segment.StreamID, &segment.Position,
-			&segment.CreatedAt, &segment.RepairedAt,
+			&segment.CreatedAt, &segment.RepairedAt, &segment.RepairCount, &segment.Placement,
 			&segment.RootPieceID, &segment.EncryptedKeyNonce, &segment.EncryptedKey,
 			&segment.EncryptedSize, &segment.PlainOffset, &segment.PlainSize,
 			&segment.EncryptedETag,
@@ -372,7 +492,7 @@ func (db *DB) GetSegmentByOffset(ctx context.Context, opts GetSegmentByOffset) (
 	err = db.db.QueryRowContext(ctx, `
 		SELECT
 			stream_id, position,
-			created_at, expires_at, repaired_at,
+			created_at, expires_at, repaired_at, repair_count, placement,
 			root_piece_id, encrypted_key_nonce, encrypted_key,
 			encrypted_size, plain_offset, plain_size,
 			encrypted_etag,
@@ -395,7 +515,7 @@ func (db *DB) GetSegmentByOffset(ctx context.Context, opts GetSegmentByOffset) (
 	`, opts.ProjectID, []byte(opts.BucketName), []byte(opts.ObjectKey), opts.PlainOffset).
 		Scan(
 			&segment.StreamID, &segment.Position,
-			&segment.CreatedAt, &segment.ExpiresAt, &segment.RepairedAt,
+			&segment.CreatedAt, &segment.ExpiresAt, &segment.RepairedAt, &segment.RepairCount, &segment.Placement,
 			&segment.RootPieceID, &segment.EncryptedKeyNonce, &segment.EncryptedKey,
 			&segment.EncryptedSize, &segment.PlainOffset, &segment.PlainSize,
 			&segment.EncryptedETag,
@@ -904,3 +904,98 @@ func TestBucketEmpty(t *testing.T) {
 		})
 	})
 }
+
+func TestGetObjectsLastCommittedBatch(t *testing.T) {
+	metabasetest.Run(t, func(ctx *testcontext.Context, t *testing.T, db *metabase.DB) {
+		obj := metabasetest.RandObjectStream()
+		bucket := obj.Location().Bucket()
+
+		t.Run("invalid request", func(t *testing.T) {
+			defer metabasetest.DeleteAll{}.Check(ctx, t, db)
+
+			metabasetest.GetObjectsLastCommittedBatch{
+				Opts: metabase.GetObjectsLastCommittedBatch{
+					Bucket: metabase.BucketLocation{BucketName: bucket.BucketName},
+				},
+				ErrClass: &metabase.ErrInvalidRequest,
+				ErrText:  "ProjectID missing",
+			}.Check(ctx, t, db)
+
+			metabasetest.GetObjectsLastCommittedBatch{
+				Opts: metabase.GetObjectsLastCommittedBatch{
+					Bucket:     bucket,
+					ObjectKeys: []metabase.ObjectKey{"a", ""},
+				},
+				ErrClass: &metabase.ErrInvalidRequest,
+				ErrText:  "ObjectKey missing",
+			}.Check(ctx, t, db)
+
+			metabasetest.GetObjectsLastCommittedBatch{
+				Opts: metabase.GetObjectsLastCommittedBatch{
+					Bucket:     bucket,
+					ObjectKeys: make([]metabase.ObjectKey, 1001),
+				},
+				ErrClass: &metabase.ErrInvalidRequest,
+				ErrText:  "cannot get more than 1000 objects in a single request",
+			}.Check(ctx, t, db)
+
+			metabasetest.Verify{}.Check(ctx, t, db)
+		})
+
+		t.Run("no keys", func(t *testing.T) {
+			defer metabasetest.DeleteAll{}.Check(ctx, t, db)
+
+			metabasetest.GetObjectsLastCommittedBatch{
+				Opts: metabase.GetObjectsLastCommittedBatch{
+					Bucket: bucket,
+				},
+			}.Check(ctx, t, db)
+
+			metabasetest.Verify{}.Check(ctx, t, db)
+		})
+
+		t.Run("mixed", func(t *testing.T) {
+			defer metabasetest.DeleteAll{}.Check(ctx, t, db)
+
+			objA := obj
+			objA.ObjectKey = "a"
+			objA.Version = 1
+			metabasetest.CreateObject(ctx, t, db, objA, 0)
+
+			// newer committed version of the same key.
+			objA2 := objA
+			objA2.Version = 2
+			objA2.StreamID = testrand.UUID()
+			objectA := metabasetest.CreateObject(ctx, t, db, objA2, 0)
+
+			objB := obj
+			objB.ObjectKey = "b"
+			objB.StreamID = testrand.UUID()
+			objectB := metabasetest.CreateObject(ctx, t, db, objB, 0)
+
+			// key with only a pending version.
+			objPending := obj
+			objPending.ObjectKey = "pending"
+			objPending.StreamID = testrand.UUID()
+			metabasetest.CreatePendingObject(ctx, t, db, objPending, 0)
+
+			// object with the same key in another bucket.
+			objOther := obj
+			objOther.BucketName = "other"
+			objOther.ObjectKey = "missing"
+			objOther.StreamID = testrand.UUID()
+			metabasetest.CreateObject(ctx, t, db, objOther, 0)
+
+			metabasetest.GetObjectsLastCommittedBatch{
+				Opts: metabase.GetObjectsLastCommittedBatch{
+					Bucket:     bucket,
+					ObjectKeys: []metabase.ObjectKey{"b", "missing", "a", "pending", "b", "a"},
+				},
+				Result: metabase.GetObjectsLastCommittedBatchResult{
+					Objects: []metabase.Object{objectB, objectA},
+					Missing: []metabase.ObjectKey{"missing", "pending"},
+				},
+			}.Check(ctx, t, db)
+		})
+	})
+}
@@ -177,10 +177,78 @@ type UpdateSegmentPieces struct {
 
 // Check runs the test.
 func (step UpdateSegmentPieces) Check(ctx *testcontext.Context, t testing.TB, db *metabase.DB) {
-	err := db.UpdateSegmentPieces(ctx, step.Opts)
+	_, err := db.UpdateSegmentPieces(ctx, step.Opts)
 	checkError(t, err, step.ErrClass, step.ErrText)
 }
 
+// UpdateObjectExpiration is for testing metabase.UpdateObjectExpiration.
+type UpdateObjectExpiration struct {
+	Opts     metabase.UpdateObjectExpiration
+	Result   metabase.UpdateObjectExpirationResult
+	ErrClass *errs.Class
+	ErrText  string
+}
+
+// Check runs the test.
+func (step UpdateObjectExpiration) Check(ctx *testcontext.Context, t testing.TB, db *metabase.DB) metabase.UpdateObjectExpirationResult {
+	result, err := db.UpdateObjectExpiration(ctx, step.Opts)
+	checkError(t, err, step.ErrClass, step.ErrText)
+
+	diff := cmp.Diff(step.Result, result, cmpopts.EquateApproxTime(5*time.Second))
+	require.Zero(t, diff)
+	return result
+}
+
+// UpdateSegmentsExpiration is for testing metabase.UpdateSegmentsExpiration.
+type UpdateSegmentsExpiration struct {
+	Opts     metabase.UpdateSegmentsExpiration
+	Updated  int64
+	ErrClass *errs.Class
+	ErrText  string
+}
+
+// Check runs the test.
+func (step UpdateSegmentsExpiration) Check(ctx *testcontext.Context, t testing.TB, db *metabase.DB) {
+	updated, err := db.UpdateSegmentsExpiration(ctx, step.Opts)
+	checkError(t, err, step.ErrClass, step.ErrText)
+	require.Equal(t, step.Updated, updated)
+}
+
+// GetSegmentsWithExpirationChangedSince is for testing metabase.GetSegmentsWithExpirationChangedSince.
+type GetSegmentsWithExpirationChangedSince struct {
+	Opts     metabase.GetSegmentsWithExpirationChangedSince
+	Result   metabase.GetSegmentsWithExpirationChangedSinceResult
+	ErrClass *errs.Class
+	ErrText  string
+}
+
+// Check runs the test.
+func (step GetSegmentsWithExpirationChangedSince) Check(ctx *testcontext.Context, t testing.TB, db *metabase.DB) metabase.GetSegmentsWithExpirationChangedSinceResult {
+	result, err := db.GetSegmentsWithExpirationChangedSince(ctx, step.Opts)
+	checkError(t, err, step.ErrClass, step.ErrText)
+
+	diff := cmp.Diff(step.Result, result, cmpopts.EquateApproxTime(5*time.Second))
+	require.Zero(t, diff)
+	return result
+}
+
+// GetObjectsLastCommittedBatch is for testing metabase.GetObjectsLastCommittedBatch.
+type GetObjectsLastCommittedBatch struct {
+	Opts     metabase.GetObjectsLastCommittedBatch
+	Result   metabase.GetObjectsLastCommittedBatchResult
+	ErrClass *errs.Class
+	ErrText  string
+}
+
+// Check runs the test.
+func (step GetObjectsLastCommittedBatch) Check(ctx *testcontext.Context, t testing.TB, db *metabase.DB) {
+	result, err := db.GetObjectsLastCommittedBatch(ctx, step.Opts)
+	checkError(t, err, step.ErrClass, step.ErrText)
+
+	diff := cmp.Diff(step.Result, result, cmpopts.EquateApproxTime(5*time.Second))
+	require.Zero(t, diff)
+}
+
 // GetObjectExactVersion is for testing metabase.GetObjectExactVersion.
 type GetObjectExactVersion struct {
 	Opts     metabase.GetObjectExactVersion
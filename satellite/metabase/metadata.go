@@ -4,9 +4,17 @@
 package metabase
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/binary"
+	"errors"
 
 	"storj.io/common/storj"
+	"storj.io/private/dbutil/txutil"
+	"storj.io/private/tagsql"
+	"storj.io/storj/storage"
 )
 
 // UpdateObjectMetadata contains arguments necessary for replacing an object metadata.
@@ -16,9 +24,43 @@ type UpdateObjectMetadata struct {
 	EncryptedMetadata             []byte
 	EncryptedMetadataNonce        []byte
 	EncryptedMetadataEncryptedKey []byte
+
+	// ExpectedMetadataHash is the MetadataHash of the current metadata of the object (optional).
+	// When set, the metadata is replaced only when it wasn't changed meanwhile.
+	ExpectedMetadataHash []byte
+}
+
+// MetadataHash returns a hash of the encrypted metadata of an object, which identifies the
+// version of the metadata for UpdateObjectMetadata.
+func MetadataHash(encryptedMetadataNonce, encryptedMetadata, encryptedMetadataEncryptedKey []byte) []byte {
+	hash := sha256.New()
+	for _, value := range [][]byte{encryptedMetadataNonce, encryptedMetadata, encryptedMetadataEncryptedKey} {
+		var length [8]byte
+		binary.BigEndian.PutUint64(length[:], uint64(len(value)))
+		_, _ = hash.Write(length[:])
+		_, _ = hash.Write(value)
+	}
+	return hash.Sum(nil)
+}
+
+// MetadataChangedError is returned by UpdateObjectMetadata when the current metadata of the
+// object doesn't match ExpectedMetadataHash. It's a storage.ErrValueChanged error.
+type MetadataChangedError struct {
+	CurrentEncryptedMetadataNonce        []byte
+	CurrentEncryptedMetadata             []byte
+	CurrentEncryptedMetadataEncryptedKey []byte
+
+	err error
 }
 
-// UpdateObjectMetadata updates an object metadata.
+// Error implements the error interface.
+func (err *MetadataChangedError) Error() string { return err.err.Error() }
+
+// Unwrap returns the underlying error.
+func (err *MetadataChangedError) Unwrap() error { return err.err }
+
+// UpdateObjectMetadata updates an object metadata. If ExpectedMetadataHash is provided and
+// doesn't match the current metadata, the update fails with *MetadataChangedError.
 func (db *DB) UpdateObjectMetadata(ctx context.Context, opts UpdateObjectMetadata) (err error) {
 	defer mon.Task()(&ctx)(&err)
 
@@ -30,25 +72,33 @@ func (db *DB) UpdateObjectMetadata(ctx context.Context, opts UpdateObjectMetadat
 		return ErrInvalidRequest.New("Version invalid: %v", opts.Version)
 	}
 
+	if opts.ExpectedMetadataHash != nil {
+		return db.updateObjectMetadataIfUnchanged(ctx, opts)
+	}
+
 	// TODO So the issue is that during a multipart upload of an object,
 	// uplink can update object metadata. If we add the arguments EncryptedMetadata
 	// to CommitObject, they will need to account for them being optional.
 	// Leading to scenarios where uplink calls update metadata, but wants to clear them
 	// during commit object.
-	result, err := db.db.ExecContext(ctx, `
-		UPDATE objects SET
-			encrypted_metadata_nonce         = $6,
-			encrypted_metadata               = $7,
-			encrypted_metadata_encrypted_key = $8
-		WHERE
-			project_id   = $1 AND
-			bucket_name  = $2 AND
-			object_key   = $3 AND
-			version      = $4 AND
-			stream_id    = $5 AND
-			status       = `+committedStatus,
-		opts.ProjectID, []byte(opts.BucketName), []byte(opts.ObjectKey), opts.Version, opts.StreamID,
-		opts.EncryptedMetadataNonce, opts.EncryptedMetadata, opts.EncryptedMetadataEncryptedKey)
+	var result sql.Result
+	err = updateRetryPolicy.Do(ctx, func(ctx context.Context) (err error) {
+		result, err = db.db.ExecContext(ctx, `
+			UPDATE objects SET
+				encrypted_metadata_nonce         = $6,
+				encrypted_metadata               = $7,
+				encrypted_metadata_encrypted_key = $8
+			WHERE
+				project_id   = $1 AND
+				bucket_name  = $2 AND
+				object_key   = $3 AND
+				version      = $4 AND
+				stream_id    = $5 AND
+				status       = `+committedStatus,
+			opts.ProjectID, []byte(opts.BucketName), []byte(opts.ObjectKey), opts.Version, opts.StreamID,
+			opts.EncryptedMetadataNonce, opts.EncryptedMetadata, opts.EncryptedMetadataEncryptedKey)
+		return err
+	})
 	if err != nil {
 		return Error.New("unable to update object metadata: %w", err)
 	}
@@ -68,3 +118,65 @@ func (db *DB) UpdateObjectMetadata(ctx context.Context, opts UpdateObjectMetadat
 
 	return nil
 }
+
+// updateObjectMetadataIfUnchanged updates an object metadata, when the current metadata matches
+// ExpectedMetadataHash.
+func (db *DB) updateObjectMetadataIfUnchanged(ctx context.Context, opts UpdateObjectMetadata) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	err = txutil.WithTx(ctx, db.db, nil, func(ctx context.Context, tx tagsql.Tx) error {
+		var current MetadataChangedError
+		err := tx.QueryRowContext(ctx, `
+			SELECT encrypted_metadata_nonce, encrypted_metadata, encrypted_metadata_encrypted_key
+			FROM objects
+			WHERE
+				project_id   = $1 AND
+				bucket_name  = $2 AND
+				object_key   = $3 AND
+				version      = $4 AND
+				stream_id    = $5 AND
+				status       = `+committedStatus+`
+			FOR UPDATE
+		`, opts.ProjectID, []byte(opts.BucketName), []byte(opts.ObjectKey), opts.Version, opts.StreamID).
+			Scan(&current.CurrentEncryptedMetadataNonce, &current.CurrentEncryptedMetadata, &current.CurrentEncryptedMetadataEncryptedKey)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return storj.ErrObjectNotFound.Wrap(
+					Error.New("object with specified version and committed status is missing"),
+				)
+			}
+			return Error.New("unable to query object metadata: %w", err)
+		}
+
+		currentHash := MetadataHash(current.CurrentEncryptedMetadataNonce, current.CurrentEncryptedMetadata, current.CurrentEncryptedMetadataEncryptedKey)
+		if !bytes.Equal(currentHash, opts.ExpectedMetadataHash) {
+			current.err = storage.ErrValueChanged.New("object metadata was changed")
+			return &current
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			UPDATE objects SET
+				encrypted_metadata_nonce         = $6,
+				encrypted_metadata               = $7,
+				encrypted_metadata_encrypted_key = $8
+			WHERE
+				project_id   = $1 AND
+				bucket_name  = $2 AND
+				object_key   = $3 AND
+				version      = $4 AND
+				stream_id    = $5
+			`, opts.ProjectID, []byte(opts.BucketName), []byte(opts.ObjectKey), opts.Version, opts.StreamID,
+			opts.EncryptedMetadataNonce, opts.EncryptedMetadata, opts.EncryptedMetadataEncryptedKey)
+		if err != nil {
+			return Error.New("unable to update object metadata: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	mon.Meter("object_update_metadata").Mark(1)
+
+	return nil
+}
@@ -4,14 +4,18 @@
 package metabase_test
 
 import (
+	"errors"
 	"testing"
 	"time"
 
+	"github.com/stretchr/testify/require"
+
 	"storj.io/common/storj"
 	"storj.io/common/testcontext"
 	"storj.io/common/testrand"
 	"storj.io/storj/satellite/metabase"
 	"storj.io/storj/satellite/metabase/metabasetest"
+	"storj.io/storj/storage"
 )
 
 func TestUpdateObjectMetadata(t *testing.T) {
@@ -110,5 +114,90 @@ func TestUpdateObjectMetadata(t *testing.T) {
 				},
 			}.Check(ctx, t, db)
 		})
+
+		t.Run("Update metadata if unchanged", func(t *testing.T) {
+			defer metabasetest.DeleteAll{}.Check(ctx, t, db)
+
+			metabasetest.CreateTestObject{}.Run(ctx, t, db, obj, 0)
+
+			emptyHash := metabase.MetadataHash(nil, nil, nil)
+
+			first := metabase.UpdateObjectMetadata{
+				ObjectStream:                  obj,
+				EncryptedMetadata:             testrand.Bytes(1024),
+				EncryptedMetadataNonce:        testrand.Bytes(24),
+				EncryptedMetadataEncryptedKey: testrand.Bytes(265),
+				ExpectedMetadataHash:          emptyHash,
+			}
+			metabasetest.UpdateObjectMetadata{Opts: first}.Check(ctx, t, db)
+
+			// the second update expects the metadata from before the first one.
+			second := metabase.UpdateObjectMetadata{
+				ObjectStream:                  obj,
+				EncryptedMetadata:             testrand.Bytes(1024),
+				EncryptedMetadataNonce:        testrand.Bytes(24),
+				EncryptedMetadataEncryptedKey: testrand.Bytes(265),
+				ExpectedMetadataHash:          emptyHash,
+			}
+			err := db.UpdateObjectMetadata(ctx, second)
+			require.True(t, storage.ErrValueChanged.Has(err))
+
+			var changed *metabase.MetadataChangedError
+			require.True(t, errors.As(err, &changed))
+			require.Equal(t, first.EncryptedMetadataNonce, changed.CurrentEncryptedMetadataNonce)
+			require.Equal(t, first.EncryptedMetadata, changed.CurrentEncryptedMetadata)
+			require.Equal(t, first.EncryptedMetadataEncryptedKey, changed.CurrentEncryptedMetadataEncryptedKey)
+
+			metabasetest.Verify{
+				Objects: []metabase.RawObject{
+					{
+						ObjectStream: obj,
+						CreatedAt:    now,
+						Status:       metabase.Committed,
+						Encryption:   metabasetest.DefaultEncryption,
+
+						EncryptedMetadata:             first.EncryptedMetadata,
+						EncryptedMetadataNonce:        first.EncryptedMetadataNonce,
+						EncryptedMetadataEncryptedKey: first.EncryptedMetadataEncryptedKey,
+					},
+				},
+			}.Check(ctx, t, db)
+
+			second.ExpectedMetadataHash = metabase.MetadataHash(
+				changed.CurrentEncryptedMetadataNonce,
+				changed.CurrentEncryptedMetadata,
+				changed.CurrentEncryptedMetadataEncryptedKey,
+			)
+			metabasetest.UpdateObjectMetadata{Opts: second}.Check(ctx, t, db)
+
+			metabasetest.Verify{
+				Objects: []metabase.RawObject{
+					{
+						ObjectStream: obj,
+						CreatedAt:    now,
+						Status:       metabase.Committed,
+						Encryption:   metabasetest.DefaultEncryption,
+
+						EncryptedMetadata:             second.EncryptedMetadata,
+						EncryptedMetadataNonce:        second.EncryptedMetadataNonce,
+						EncryptedMetadataEncryptedKey: second.EncryptedMetadataEncryptedKey,
+					},
+				},
+			}.Check(ctx, t, db)
+		})
+
+		t.Run("Object missing if unchanged", func(t *testing.T) {
+			defer metabasetest.DeleteAll{}.Check(ctx, t, db)
+
+			metabasetest.UpdateObjectMetadata{
+				Opts: metabase.UpdateObjectMetadata{
+					ObjectStream:         obj,
+					ExpectedMetadataHash: metabase.MetadataHash(nil, nil, nil),
+				},
+				ErrClass: &storj.ErrObjectNotFound,
+				ErrText:  "metabase: object with specified version and committed status is missing",
+			}.Check(ctx, t, db)
+			metabasetest.Verify{}.Check(ctx, t, db)
+		})
 	})
 }
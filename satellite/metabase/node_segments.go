@@ -0,0 +1,394 @@
+// Copyright (C) 2021 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package metabase
+
+import (
+	"context"
+	"strings"
+
+	"storj.io/common/storj"
+	"storj.io/common/uuid"
+	"storj.io/private/dbutil"
+	"storj.io/private/dbutil/pgutil"
+	"storj.io/private/dbutil/txutil"
+	"storj.io/private/tagsql"
+)
+
+// NodeSegmentsCursor is a cursor used during node segments listing.
+// Listing starts after the specified segment.
+type NodeSegmentsCursor struct {
+	StreamID uuid.UUID
+	Position SegmentPosition
+}
+
+// ListNodeSegments contains arguments necessary for listing segments with pieces on a node.
+type ListNodeSegments struct {
+	NodeID storj.NodeID
+	Cursor NodeSegmentsCursor
+	Limit  int
+}
+
+// NodeSegment is a segment with a piece on the listed node.
+type NodeSegment struct {
+	StreamID uuid.UUID
+	Position SegmentPosition
+
+	RootPieceID storj.PieceID
+	// PieceNumber is the number of the piece held by the node.
+	PieceNumber uint16
+}
+
+// ListNodeSegmentsResult result of listing node segments.
+type ListNodeSegmentsResult struct {
+	Segments []NodeSegment
+	More     bool
+}
+
+// ListNodeSegments lists segments with pieces on the specified node ordered by
+// stream id and position. The listing can be resumed by using the last segment
+// as the cursor.
+//
+// Segments are looked up using the remote_alias_nodes index. Segments committed
+// before the column was added are listed only after they are backfilled by
+// BackfillSegmentPieces.
+func (db *DB) ListNodeSegments(ctx context.Context, opts ListNodeSegments) (result ListNodeSegmentsResult, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	if opts.NodeID.IsZero() {
+		return ListNodeSegmentsResult{}, ErrInvalidRequest.New("NodeID missing")
+	}
+	if opts.Limit < 0 {
+		return ListNodeSegmentsResult{}, ErrInvalidRequest.New("Invalid limit: %d", opts.Limit)
+	}
+	ListLimit.Ensure(&opts.Limit)
+
+	aliases, err := db.aliasCache.Aliases(ctx, []storj.NodeID{opts.NodeID})
+	if err != nil {
+		return ListNodeSegmentsResult{}, Error.New("unable to get node alias: %w", err)
+	}
+	alias := aliases[0]
+
+	err = withRows(db.db.QueryContext(ctx, db.listNodeSegmentsQuery(),
+		pgutil.Int4Array([]int32{int32(alias)}), opts.Cursor.StreamID, opts.Cursor.Position, opts.Limit+1,
+	))(func(rows tagsql.Rows) error {
+		for rows.Next() {
+			var segment NodeSegment
+			var aliasPieces AliasPieces
+			err := rows.Scan(&segment.StreamID, &segment.Position, &segment.RootPieceID, &aliasPieces)
+			if err != nil {
+				return Error.New("failed to scan segments: %w", err)
+			}
+
+			found := false
+			for _, piece := range aliasPieces {
+				if piece.Alias == alias {
+					segment.PieceNumber = piece.Number
+					found = true
+					break
+				}
+			}
+			if !found {
+				return Error.New("segment %s/%d doesn't contain piece of node %s", segment.StreamID, segment.Position.Encode(), opts.NodeID)
+			}
+
+			result.Segments = append(result.Segments, segment)
+		}
+		return nil
+	})
+	if err != nil {
+		return ListNodeSegmentsResult{}, Error.New("unable to fetch node segments: %w", err)
+	}
+
+	if len(result.Segments) > opts.Limit {
+		result.More = true
+		result.Segments = result.Segments[:len(result.Segments)-1]
+	}
+
+	return result, nil
+}
+
+func (db *DB) listNodeSegmentsQuery() string {
+	table := "segments"
+	if db.impl == dbutil.Cockroach {
+		// make sure cockroach doesn't prefer scanning the primary index in order.
+		table = "segments@segments_remote_alias_nodes_index"
+	}
+
+	return `
+		SELECT stream_id, position, root_piece_id, remote_alias_pieces
+		FROM ` + table + `
+		WHERE
+			remote_alias_nodes @> $1::INT4[] AND
+			(stream_id, position) > ($2, $3)
+		ORDER BY stream_id ASC, position ASC
+		LIMIT $4
+	`
+}
+
+// TestingExplainListNodeSegments returns the query plan used by ListNodeSegments.
+//
+// Test tables are too small for the postgres planner to prefer any index, so
+// sequential and ordered index scans are disabled while explaining. The plan then
+// shows whether the query can be served by the remote_alias_nodes index.
+func (db *DB) TestingExplainListNodeSegments(ctx context.Context) (plan string, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	var lines []string
+	err = txutil.WithTx(ctx, db.db, nil, func(ctx context.Context, tx tagsql.Tx) error {
+		if db.impl == dbutil.Postgres {
+			for _, setting := range []string{"enable_seqscan", "enable_indexscan"} {
+				if _, err := tx.ExecContext(ctx, `SET LOCAL `+setting+` = off`); err != nil {
+					return err
+				}
+			}
+		}
+
+		return withRows(tx.QueryContext(ctx, `EXPLAIN `+db.listNodeSegmentsQuery(),
+			pgutil.Int4Array([]int32{1}), uuid.UUID{}, SegmentPosition{}, 1,
+		))(func(rows tagsql.Rows) error {
+			for rows.Next() {
+				var line string
+				if err := rows.Scan(&line); err != nil {
+					return err
+				}
+				lines = append(lines, line)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return "", Error.New("unable to explain node segments query: %w", err)
+	}
+
+	return strings.Join(lines, "\n"), nil
+}
+
+// BackfillSegmentPiecesCursor is a cursor used during backfilling of segment pieces columns.
+// Backfilling continues after the specified segment.
+type BackfillSegmentPiecesCursor struct {
+	StreamID uuid.UUID
+	Position SegmentPosition
+}
+
+// BackfillSegmentPieces contains arguments necessary for backfilling columns derived from
+// remote_alias_pieces.
+type BackfillSegmentPieces struct {
+	Cursor    BackfillSegmentPiecesCursor
+	BatchSize int
+}
+
+// BackfillSegmentPiecesResult is the result of BackfillSegmentPieces.
+type BackfillSegmentPiecesResult struct {
+	Updated int
+	// Cursor is the last segment of the batch, the backfill continues after it.
+	Cursor BackfillSegmentPiecesCursor
+	More   bool
+}
+
+// BackfillSegmentPieces sets columns derived from remote_alias_pieces (remote_alias_nodes
+// and pieces_count) for up to BatchSize segments after the cursor, which were committed
+// before the columns were added. Segments are visited in stream id and position order, so
+// the whole table is backfilled by continuing from the returned cursor while More is set.
+func (db *DB) BackfillSegmentPieces(ctx context.Context, opts BackfillSegmentPieces) (result BackfillSegmentPiecesResult, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	if opts.BatchSize <= 0 {
+		return BackfillSegmentPiecesResult{}, ErrInvalidRequest.New("Invalid batch size: %d", opts.BatchSize)
+	}
+
+	type segmentPieces struct {
+		StreamID    uuid.UUID
+		Position    SegmentPosition
+		AliasPieces AliasPieces
+	}
+	var segments []segmentPieces
+
+	err = withRows(db.db.QueryContext(ctx, `
+		SELECT stream_id, position, remote_alias_pieces
+		FROM segments
+		WHERE
+			(stream_id, position) > ($1, $2) AND
+			(remote_alias_nodes IS NULL OR pieces_count IS NULL) AND
+			remote_alias_pieces IS NOT NULL
+		ORDER BY stream_id ASC, position ASC
+		LIMIT $3
+	`, opts.Cursor.StreamID, opts.Cursor.Position, opts.BatchSize+1))(func(rows tagsql.Rows) error {
+		for rows.Next() {
+			var segment segmentPieces
+			if err := rows.Scan(&segment.StreamID, &segment.Position, &segment.AliasPieces); err != nil {
+				return Error.New("failed to scan segments: %w", err)
+			}
+			segments = append(segments, segment)
+		}
+		return nil
+	})
+	if err != nil {
+		return BackfillSegmentPiecesResult{}, Error.New("unable to fetch segments: %w", err)
+	}
+
+	if len(segments) > opts.BatchSize {
+		result.More = true
+		segments = segments[:opts.BatchSize]
+	}
+	result.Cursor = opts.Cursor
+
+	for _, segment := range segments {
+		result.Cursor = BackfillSegmentPiecesCursor{StreamID: segment.StreamID, Position: segment.Position}
+
+		// segments without pieces have nothing to backfill.
+		if len(segment.AliasPieces) == 0 {
+			continue
+		}
+
+		// pieces may be updated concurrently, which also sets the columns.
+		res, err := db.db.ExecContext(ctx, `
+			UPDATE segments SET
+				remote_alias_nodes = COALESCE(remote_alias_nodes, $3),
+				pieces_count = COALESCE(pieces_count, $4)
+			WHERE
+				stream_id = $1 AND
+				position = $2 AND
+				remote_alias_pieces = $5
+		`, segment.StreamID, segment.Position, segment.AliasPieces.nodes(), segment.AliasPieces.count(), segment.AliasPieces)
+		if err != nil {
+			return result, Error.New("unable to update segment: %w", err)
+		}
+
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return result, Error.New("failed to get rows affected: %w", err)
+		}
+		result.Updated += int(affected)
+	}
+
+	return result, nil
+}
+
+// SegmentWithNode is a segment processed by ProcessSegmentsWithNode.
+type SegmentWithNode struct {
+	StreamID uuid.UUID
+	Position SegmentPosition
+
+	Redundancy storj.RedundancyScheme
+	Pieces     Pieces
+}
+
+// ProcessSegmentsWithNode selects up to limit segments with a piece on the specified node and
+// calls fn for each of them within a single transaction. The pieces returned by fn replace the
+// pieces of the segment before the transaction is committed, nil pieces leave the segment
+// unchanged. It returns the number of updated segments.
+//
+// Segments are locked for the duration of the transaction. On Postgres segments locked by a
+// concurrent call are skipped. Cockroach doesn't support skipping them, instead one of the
+// transactions is retried, which calls fn again for the segments it selects after the retry.
+// fn must not have side effects, which can't be repeated.
+func (db *DB) ProcessSegmentsWithNode(ctx context.Context, nodeID storj.NodeID, limit int, fn func(segment SegmentWithNode) (Pieces, error)) (updated int, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	if nodeID.IsZero() {
+		return 0, ErrInvalidRequest.New("NodeID missing")
+	}
+	if limit < 0 {
+		return 0, ErrInvalidRequest.New("Invalid limit: %d", limit)
+	}
+	ListLimit.Ensure(&limit)
+
+	aliases, err := db.aliasCache.Aliases(ctx, []storj.NodeID{nodeID})
+	if err != nil {
+		return 0, Error.New("unable to get node alias: %w", err)
+	}
+
+	lock := "FOR UPDATE SKIP LOCKED"
+	if db.impl == dbutil.Cockroach {
+		lock = "FOR UPDATE"
+	}
+
+	err = txutil.WithTx(ctx, db.db, nil, func(ctx context.Context, tx tagsql.Tx) error {
+		// the transaction may be retried.
+		updated = 0
+
+		type segmentPieces struct {
+			SegmentWithNode
+			AliasPieces AliasPieces
+		}
+		var segments []segmentPieces
+
+		err := withRows(tx.QueryContext(ctx, `
+			SELECT stream_id, position, redundancy, remote_alias_pieces
+			FROM segments
+			WHERE remote_alias_nodes @> $1::INT4[]
+			ORDER BY stream_id, position
+			LIMIT $2
+			`+lock,
+			pgutil.Int4Array([]int32{int32(aliases[0])}), limit,
+		))(func(rows tagsql.Rows) error {
+			for rows.Next() {
+				var segment segmentPieces
+				err := rows.Scan(&segment.StreamID, &segment.Position, redundancyScheme{&segment.Redundancy}, &segment.AliasPieces)
+				if err != nil {
+					return Error.New("failed to scan segments: %w", err)
+				}
+				segments = append(segments, segment)
+			}
+			return nil
+		})
+		if err != nil {
+			return Error.New("unable to fetch node segments: %w", err)
+		}
+
+		for _, segment := range segments {
+			segment.Pieces, err = db.aliasCache.ConvertAliasesToPieces(ctx, segment.AliasPieces)
+			if err != nil {
+				return Error.New("unable to convert aliases to pieces: %w", err)
+			}
+
+			newPieces, err := fn(segment.SegmentWithNode)
+			if err != nil {
+				return err
+			}
+			if newPieces == nil {
+				continue
+			}
+
+			err = UpdateSegmentPieces{
+				StreamID:      segment.StreamID,
+				Position:      segment.Position,
+				OldPieces:     segment.Pieces,
+				NewRedundancy: segment.Redundancy,
+				NewPieces:     newPieces,
+			}.Verify()
+			if err != nil {
+				return err
+			}
+
+			newAliasPieces, err := db.aliasCache.ConvertPiecesToAliases(ctx, newPieces)
+			if err != nil {
+				return Error.New("unable to convert pieces to aliases: %w", err)
+			}
+
+			_, err = tx.ExecContext(ctx, `
+				UPDATE segments SET
+					remote_alias_pieces = $3,
+					remote_alias_nodes  = $4,
+					pieces_count        = $5,
+					pieces_changed_seq  = nextval('segment_pieces_changed_seq')
+				WHERE
+					stream_id = $1 AND
+					position  = $2
+			`, segment.StreamID, segment.Position, newAliasPieces, newAliasPieces.nodes(), newAliasPieces.count())
+			if err != nil {
+				return Error.New("unable to update segment pieces: %w", err)
+			}
+			updated++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	mon.Meter("segment_update").Mark(updated)
+
+	return updated, nil
+}
@@ -0,0 +1,259 @@
+// Copyright (C) 2021 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package metabase_test
+
+import (
+	"bytes"
+	"errors"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sync/errgroup"
+
+	"storj.io/common/storj"
+	"storj.io/common/testcontext"
+	"storj.io/common/testrand"
+	"storj.io/private/dbutil"
+	"storj.io/storj/satellite/metabase"
+	"storj.io/storj/satellite/metabase/metabasetest"
+)
+
+func TestListNodeSegments(t *testing.T) {
+	metabasetest.Run(t, func(ctx *testcontext.Context, t *testing.T, db *metabase.DB) {
+		defaultNode := storj.NodeID{2}
+		exitingNode := testrand.NodeID()
+
+		t.Run("invalid request", func(t *testing.T) {
+			defer metabasetest.DeleteAll{}.Check(ctx, t, db)
+
+			_, err := db.ListNodeSegments(ctx, metabase.ListNodeSegments{})
+			require.True(t, metabase.ErrInvalidRequest.Has(err))
+
+			_, err = db.ListNodeSegments(ctx, metabase.ListNodeSegments{NodeID: exitingNode, Limit: -1})
+			require.True(t, metabase.ErrInvalidRequest.Has(err))
+		})
+
+		t.Run("cursor", func(t *testing.T) {
+			defer metabasetest.DeleteAll{}.Check(ctx, t, db)
+
+			var expected []metabase.NodeSegment
+			for i := 0; i < 3; i++ {
+				obj := metabasetest.RandObjectStream()
+				metabasetest.CreateObject(ctx, t, db, obj, 3)
+
+				// move a piece of every other segment to the exiting node.
+				for _, index := range []uint32{0, 2} {
+					position := metabase.SegmentPosition{Index: index}
+					_, err := db.UpdateSegmentPieces(ctx, metabase.UpdateSegmentPieces{
+						StreamID:      obj.StreamID,
+						Position:      position,
+						OldPieces:     metabase.Pieces{{Number: 0, StorageNode: defaultNode}},
+						NewRedundancy: metabasetest.DefaultRedundancy,
+						NewPieces: metabase.Pieces{
+							{Number: 0, StorageNode: defaultNode},
+							{Number: uint16(index + 1), StorageNode: exitingNode},
+						},
+					})
+					require.NoError(t, err)
+
+					expected = append(expected, metabase.NodeSegment{
+						StreamID:    obj.StreamID,
+						Position:    position,
+						RootPieceID: storj.PieceID{1},
+						PieceNumber: uint16(index + 1),
+					})
+				}
+			}
+			sort.Slice(expected, func(i, k int) bool {
+				if cmp := bytes.Compare(expected[i].StreamID[:], expected[k].StreamID[:]); cmp != 0 {
+					return cmp < 0
+				}
+				return expected[i].Position.Less(expected[k].Position)
+			})
+
+			result, err := db.ListNodeSegments(ctx, metabase.ListNodeSegments{NodeID: exitingNode})
+			require.NoError(t, err)
+			require.False(t, result.More)
+			require.Equal(t, expected, result.Segments)
+
+			// other nodes still list all of their segments.
+			result, err = db.ListNodeSegments(ctx, metabase.ListNodeSegments{NodeID: defaultNode})
+			require.NoError(t, err)
+			require.Len(t, result.Segments, 9)
+
+			// nodes without pieces have no segments.
+			result, err = db.ListNodeSegments(ctx, metabase.ListNodeSegments{NodeID: testrand.NodeID()})
+			require.NoError(t, err)
+			require.Empty(t, result.Segments)
+			require.False(t, result.More)
+
+			// listing resumes after the cursor.
+			var listed []metabase.NodeSegment
+			opts := metabase.ListNodeSegments{NodeID: exitingNode, Limit: 4}
+			for {
+				result, err := db.ListNodeSegments(ctx, opts)
+				require.NoError(t, err)
+				require.LessOrEqual(t, len(result.Segments), opts.Limit)
+				listed = append(listed, result.Segments...)
+				if !result.More {
+					break
+				}
+				last := result.Segments[len(result.Segments)-1]
+				opts.Cursor = metabase.NodeSegmentsCursor{StreamID: last.StreamID, Position: last.Position}
+			}
+			require.Equal(t, expected, listed)
+
+			// cursor pointing to a segment without the node's piece.
+			result, err = db.ListNodeSegments(ctx, metabase.ListNodeSegments{
+				NodeID: exitingNode,
+				Cursor: metabase.NodeSegmentsCursor{
+					StreamID: expected[0].StreamID,
+					Position: metabase.SegmentPosition{Index: 1},
+				},
+			})
+			require.NoError(t, err)
+			require.Equal(t, expected[1:], result.Segments)
+		})
+
+		t.Run("backfill", func(t *testing.T) {
+			defer metabasetest.DeleteAll{}.Check(ctx, t, db)
+
+			for i := 0; i < 3; i++ {
+				metabasetest.CreateObject(ctx, t, db, metabasetest.RandObjectStream(), 2)
+			}
+
+			// simulate segments committed before remote_alias_nodes was added.
+			_, err := db.UnderlyingTagSQL().ExecContext(ctx, `UPDATE segments SET remote_alias_nodes = NULL, pieces_count = NULL`)
+			require.NoError(t, err)
+
+			result, err := db.ListNodeSegments(ctx, metabase.ListNodeSegments{NodeID: defaultNode})
+			require.NoError(t, err)
+			require.Empty(t, result.Segments)
+
+			_, err = db.BackfillSegmentPieces(ctx, metabase.BackfillSegmentPieces{})
+			require.True(t, metabase.ErrInvalidRequest.Has(err))
+
+			// a segment without pieces has nothing to backfill and must be skipped.
+			empty := metabasetest.CreateObject(ctx, t, db, metabasetest.RandObjectStream(), 1)
+			_, err = db.UnderlyingTagSQL().ExecContext(ctx, `UPDATE segments SET remote_alias_pieces = $2 WHERE stream_id = $1`, empty.StreamID, []byte{})
+			require.NoError(t, err)
+
+			opts := metabase.BackfillSegmentPieces{BatchSize: 4}
+			total, batches := 0, 0
+			for {
+				result, err := db.BackfillSegmentPieces(ctx, opts)
+				require.NoError(t, err)
+				require.LessOrEqual(t, result.Updated, 4)
+				total += result.Updated
+				batches++
+				if !result.More {
+					break
+				}
+				opts.Cursor = result.Cursor
+			}
+			require.Equal(t, 6, total)
+			require.Equal(t, 2, batches)
+
+			// only the segment without pieces is left, which has nothing to backfill.
+			last, err := db.BackfillSegmentPieces(ctx, metabase.BackfillSegmentPieces{BatchSize: 4})
+			require.NoError(t, err)
+			require.Equal(t, 0, last.Updated)
+			require.False(t, last.More)
+			require.Equal(t, empty.StreamID, last.Cursor.StreamID)
+
+			listed, err := db.ListNodeSegments(ctx, metabase.ListNodeSegments{NodeID: defaultNode})
+			require.NoError(t, err)
+			require.Len(t, listed.Segments, 6)
+		})
+
+		t.Run("query plan", func(t *testing.T) {
+			plan, err := db.TestingExplainListNodeSegments(ctx)
+			require.NoError(t, err)
+
+			require.Contains(t, plan, "segments_remote_alias_nodes_index")
+			switch db.Implementation() {
+			case dbutil.Cockroach:
+				require.NotContains(t, plan, "FULL SCAN")
+			case dbutil.Postgres:
+				require.NotContains(t, plan, "Seq Scan")
+			}
+		})
+	})
+}
+
+func TestProcessSegmentsWithNode(t *testing.T) {
+	metabasetest.Run(t, func(ctx *testcontext.Context, t *testing.T, db *metabase.DB) {
+		// segments created by CreateObject have a single piece on this node.
+		failedNode := storj.NodeID{2}
+
+		t.Run("invalid request", func(t *testing.T) {
+			defer metabasetest.DeleteAll{}.Check(ctx, t, db)
+
+			_, err := db.ProcessSegmentsWithNode(ctx, storj.NodeID{}, 1, nil)
+			require.True(t, metabase.ErrInvalidRequest.Has(err))
+
+			_, err = db.ProcessSegmentsWithNode(ctx, failedNode, -1, nil)
+			require.True(t, metabase.ErrInvalidRequest.Has(err))
+		})
+
+		t.Run("invalid pieces", func(t *testing.T) {
+			defer metabasetest.DeleteAll{}.Check(ctx, t, db)
+
+			object := metabasetest.CreateObject(ctx, t, db, metabasetest.RandObjectStream(), 1)
+
+			_, err := db.ProcessSegmentsWithNode(ctx, failedNode, 1, func(segment metabase.SegmentWithNode) (metabase.Pieces, error) {
+				return metabase.Pieces{{Number: 0}}, nil
+			})
+			require.True(t, metabase.ErrInvalidRequest.Has(err))
+
+			// the segment isn't changed.
+			segment, err := db.GetSegmentByPosition(ctx, metabase.GetSegmentByPosition{StreamID: object.StreamID})
+			require.NoError(t, err)
+			require.Equal(t, metabase.Pieces{{Number: 0, StorageNode: failedNode}}, segment.Pieces)
+		})
+
+		t.Run("concurrent callers", func(t *testing.T) {
+			defer metabasetest.DeleteAll{}.Check(ctx, t, db)
+
+			const segmentCount = 10
+			metabasetest.CreateObject(ctx, t, db, metabasetest.RandObjectStream(), segmentCount)
+
+			replace := func(segment metabase.SegmentWithNode) (metabase.Pieces, error) {
+				if !segment.Pieces.Equal(metabase.Pieces{{Number: 0, StorageNode: failedNode}}) {
+					return nil, errors.New("segment was processed already")
+				}
+				return metabase.Pieces{{Number: 0, StorageNode: testrand.NodeID()}}, nil
+			}
+
+			var group errgroup.Group
+			var updated [4]int
+			for i := range updated {
+				i := i
+				group.Go(func() (err error) {
+					updated[i], err = db.ProcessSegmentsWithNode(ctx, failedNode, segmentCount/2, replace)
+					return err
+				})
+			}
+			require.NoError(t, group.Wait())
+
+			// the calls may have been done sequentially, then the last ones find nothing.
+			total := 0
+			for _, count := range updated {
+				total += count
+			}
+			for total < segmentCount {
+				count, err := db.ProcessSegmentsWithNode(ctx, failedNode, segmentCount, replace)
+				require.NoError(t, err)
+				require.NotZero(t, count)
+				total += count
+			}
+			require.Equal(t, segmentCount, total)
+
+			result, err := db.ListNodeSegments(ctx, metabase.ListNodeSegments{NodeID: failedNode})
+			require.NoError(t, err)
+			require.Empty(t, result.Segments)
+		})
+	})
+}
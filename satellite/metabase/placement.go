@@ -0,0 +1,91 @@
+// Copyright (C) 2021 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package metabase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"storj.io/common/uuid"
+	"storj.io/storj/storage"
+)
+
+// Placement is the placement constraint of a segment, which restricts the nodes that may
+// store its pieces. Zero means that there's no constraint.
+type Placement uint16
+
+// UpdateSegmentPlacement contains arguments necessary for updating the placement of a segment.
+type UpdateSegmentPlacement struct {
+	StreamID uuid.UUID
+	Position SegmentPosition
+
+	OldPlacement Placement
+	NewPlacement Placement
+}
+
+// Verify verifies the update of segment placement.
+func (opts UpdateSegmentPlacement) Verify() error {
+	if opts.StreamID.IsZero() {
+		return ErrInvalidRequest.New("StreamID missing")
+	}
+	return nil
+}
+
+// PlacementChangedError is returned by UpdateSegmentPlacement when the placement of the
+// segment doesn't match OldPlacement. It's a storage.ErrValueChanged error.
+type PlacementChangedError struct {
+	CurrentPlacement Placement
+
+	err error
+}
+
+// Error implements the error interface.
+func (err *PlacementChangedError) Error() string { return err.err.Error() }
+
+// Unwrap returns the underlying error.
+func (err *PlacementChangedError) Unwrap() error { return err.err }
+
+// UpdateSegmentPlacement updates the placement of the specified segment. If the provided old
+// placement doesn't match the current database state, the update fails with *PlacementChangedError.
+func (db *DB) UpdateSegmentPlacement(ctx context.Context, opts UpdateSegmentPlacement) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	if err := opts.Verify(); err != nil {
+		return err
+	}
+
+	var resultPlacement Placement
+	err = updateRetryPolicy.Do(ctx, func(ctx context.Context) error {
+		return db.db.QueryRowContext(ctx, `
+			UPDATE segments SET
+				placement = CASE
+					WHEN placement = $3::INT2 THEN $4::INT2
+					ELSE placement
+				END
+			WHERE
+				stream_id     = $1 AND
+				position      = $2
+			RETURNING placement
+			`, opts.StreamID, opts.Position, opts.OldPlacement, opts.NewPlacement).
+			Scan(&resultPlacement)
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrSegmentNotFound.New("segment missing")
+		}
+		return Error.New("unable to update segment placement: %w", err)
+	}
+
+	if resultPlacement != opts.NewPlacement {
+		return &PlacementChangedError{
+			CurrentPlacement: resultPlacement,
+			err:              storage.ErrValueChanged.New("segment placement field was changed"),
+		}
+	}
+
+	mon.Meter("segment_placement_update").Mark(1)
+
+	return nil
+}
@@ -0,0 +1,88 @@
+// Copyright (C) 2021 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package metabase_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"storj.io/common/testcontext"
+	"storj.io/common/testrand"
+	"storj.io/storj/satellite/metabase"
+	"storj.io/storj/satellite/metabase/metabasetest"
+	"storj.io/storj/storage"
+)
+
+func TestUpdateSegmentPlacement(t *testing.T) {
+	metabasetest.Run(t, func(ctx *testcontext.Context, t *testing.T, db *metabase.DB) {
+		getPlacement := func(t *testing.T, object metabase.Object) metabase.Placement {
+			segment, err := db.GetSegmentByPosition(ctx, metabase.GetSegmentByPosition{
+				StreamID: object.StreamID,
+			})
+			require.NoError(t, err)
+			return segment.Placement
+		}
+
+		t.Run("StreamID missing", func(t *testing.T) {
+			defer metabasetest.DeleteAll{}.Check(ctx, t, db)
+
+			err := db.UpdateSegmentPlacement(ctx, metabase.UpdateSegmentPlacement{NewPlacement: 1})
+			require.True(t, metabase.ErrInvalidRequest.Has(err))
+			require.EqualError(t, err, metabase.ErrInvalidRequest.New("StreamID missing").Error())
+		})
+
+		t.Run("segment not found", func(t *testing.T) {
+			defer metabasetest.DeleteAll{}.Check(ctx, t, db)
+
+			err := db.UpdateSegmentPlacement(ctx, metabase.UpdateSegmentPlacement{
+				StreamID:     testrand.UUID(),
+				NewPlacement: 1,
+			})
+			require.True(t, metabase.ErrSegmentNotFound.Has(err))
+		})
+
+		t.Run("placement changed", func(t *testing.T) {
+			defer metabasetest.DeleteAll{}.Check(ctx, t, db)
+
+			object := metabasetest.CreateObject(ctx, t, db, metabasetest.RandObjectStream(), 1)
+
+			err := db.UpdateSegmentPlacement(ctx, metabase.UpdateSegmentPlacement{
+				StreamID:     object.StreamID,
+				OldPlacement: 2,
+				NewPlacement: 3,
+			})
+			require.True(t, storage.ErrValueChanged.Has(err))
+
+			var changed *metabase.PlacementChangedError
+			require.True(t, errors.As(err, &changed))
+			require.Equal(t, metabase.Placement(0), changed.CurrentPlacement)
+
+			require.Equal(t, metabase.Placement(0), getPlacement(t, object))
+		})
+
+		t.Run("update placement", func(t *testing.T) {
+			defer metabasetest.DeleteAll{}.Check(ctx, t, db)
+
+			object := metabasetest.CreateObject(ctx, t, db, metabasetest.RandObjectStream(), 1)
+
+			err := db.UpdateSegmentPlacement(ctx, metabase.UpdateSegmentPlacement{
+				StreamID:     object.StreamID,
+				OldPlacement: 0,
+				NewPlacement: 1,
+			})
+			require.NoError(t, err)
+			require.Equal(t, metabase.Placement(1), getPlacement(t, object))
+
+			err = db.UpdateSegmentPlacement(ctx, metabase.UpdateSegmentPlacement{
+				StreamID:     object.StreamID,
+				OldPlacement: 1,
+				NewPlacement: 0,
+			})
+			require.NoError(t, err)
+			require.Equal(t, metabase.Placement(0), getPlacement(t, object))
+		})
+	})
+}
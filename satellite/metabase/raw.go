@@ -49,6 +49,10 @@ type RawSegment struct {
 	CreatedAt  *time.Time // TODO: make it non-nilable after we migrate all existing segments to have creation time
 	RepairedAt *time.Time
 	ExpiresAt  *time.Time
+	// RepairCount is the number of times the segment was repaired.
+	RepairCount int32
+	// Placement is the placement constraint of the segment.
+	Placement Placement
 
 	RootPieceID       storj.PieceID
 	EncryptedKeyNonce []byte
@@ -95,6 +99,7 @@ func (db *DB) TestingDeleteAll(ctx context.Context) (err error) {
 	_, err = db.db.ExecContext(ctx, `
 		DELETE FROM objects;
 		DELETE FROM segments;
+		DELETE FROM segment_tombstones;
 		DELETE FROM node_aliases;
 		SELECT setval('node_alias_seq', 1, false);
 	`)
@@ -170,7 +175,7 @@ func (db *DB) testingGetAllSegments(ctx context.Context) (_ []RawSegment, err er
 	rows, err := db.db.QueryContext(ctx, `
 		SELECT
 			stream_id, position,
-			created_at, repaired_at, expires_at,
+			created_at, repaired_at, expires_at, repair_count, placement,
 			root_piece_id, encrypted_key_nonce, encrypted_key,
 			encrypted_size,
 			plain_offset, plain_size,
@@ -194,6 +199,8 @@ func (db *DB) testingGetAllSegments(ctx context.Context) (_ []RawSegment, err er
 			&seg.CreatedAt,
 			&seg.RepairedAt,
 			&seg.ExpiresAt,
+			&seg.RepairCount,
+			&seg.Placement,
 
 			&seg.RootPieceID,
 			&seg.EncryptedKeyNonce,
@@ -0,0 +1,192 @@
+// Copyright (C) 2021 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package metabase
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"storj.io/common/uuid"
+	"storj.io/private/tagsql"
+)
+
+// SegmentAgeBuckets are the upper bounds of segment age buckets used by GetSegmentAgeDistribution.
+// Segments older than the last bound, or without created_at, are counted in an additional bucket.
+var SegmentAgeBuckets = []time.Duration{
+	24 * time.Hour,
+	7 * 24 * time.Hour,
+	30 * 24 * time.Hour,
+	90 * 24 * time.Hour,
+	365 * 24 * time.Hour,
+}
+
+// GetSegmentAgeDistribution contains arguments necessary for counting at-risk segments by age.
+type GetSegmentAgeDistribution struct {
+	// HealthyBelow is the number of pieces below which a segment is at risk.
+	HealthyBelow int
+
+	// AsOf is the time used to compute segment age, defaults to the current time.
+	AsOf time.Time
+}
+
+// SegmentAgeBucket contains the number of at-risk segments within an age range.
+type SegmentAgeBucket struct {
+	MinAge time.Duration
+	// MaxAge is zero for the last, unbounded bucket.
+	MaxAge time.Duration
+
+	Count int64
+}
+
+// GetSegmentAgeDistribution returns counts of remote segments with less than HealthyBelow pieces
+// grouped by SegmentAgeBuckets. All buckets are returned, including empty ones.
+//
+// pieces_count of segments committed before the column was added is set by
+// BackfillSegmentPieces, such segments are counted only after they are backfilled.
+func (db *DB) GetSegmentAgeDistribution(ctx context.Context, opts GetSegmentAgeDistribution) (_ []SegmentAgeBucket, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	if opts.HealthyBelow <= 0 {
+		return nil, ErrInvalidRequest.New("Invalid HealthyBelow: %d", opts.HealthyBelow)
+	}
+	if opts.AsOf.IsZero() {
+		opts.AsOf = time.Now()
+	}
+
+	buckets := make([]SegmentAgeBucket, len(SegmentAgeBuckets)+1)
+	args := []interface{}{opts.HealthyBelow}
+
+	var caseExpr strings.Builder
+	caseExpr.WriteString("CASE")
+	var minAge time.Duration
+	for i, maxAge := range SegmentAgeBuckets {
+		buckets[i] = SegmentAgeBucket{MinAge: minAge, MaxAge: maxAge}
+		minAge = maxAge
+
+		args = append(args, opts.AsOf.Add(-maxAge))
+		caseExpr.WriteString(" WHEN created_at > $" + strconv.Itoa(len(args)) + " THEN " + strconv.Itoa(i))
+	}
+	buckets[len(SegmentAgeBuckets)] = SegmentAgeBucket{MinAge: minAge}
+	caseExpr.WriteString(" ELSE " + strconv.Itoa(len(SegmentAgeBuckets)) + " END")
+
+	// pieces_count is NULL for inline segments, which are never at risk.
+	err = withRows(db.db.QueryContext(ctx, `
+		SELECT age_bucket, count(*)
+		FROM (
+			SELECT `+caseExpr.String()+` AS age_bucket
+			FROM segments
+			WHERE pieces_count < $1
+		) AS at_risk
+		GROUP BY age_bucket
+	`, args...))(func(rows tagsql.Rows) error {
+		for rows.Next() {
+			var bucket int
+			var count int64
+			if err := rows.Scan(&bucket, &count); err != nil {
+				return Error.New("failed to scan age distribution: %w", err)
+			}
+			buckets[bucket].Count = count
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, Error.New("unable to get segment age distribution: %w", err)
+	}
+
+	return buckets, nil
+}
+
+// RepairOrder defines the order in which segments needing repair are returned.
+type RepairOrder int
+
+const (
+	// RepairOrderByHealth returns segments with the least pieces first.
+	RepairOrderByHealth RepairOrder = iota
+	// RepairOrderByAgeWeightedHealth returns segments with the least pieces per
+	// RepairAgeWeightPeriod of age first, so that older segments are repaired
+	// before younger ones with the same number of pieces.
+	RepairOrderByAgeWeightedHealth
+)
+
+// RepairAgeWeightPeriod is the age by which the weight of a segment's pieces is halved
+// with RepairOrderByAgeWeightedHealth, i.e. the weighted health of a segment is
+// pieces_count / (1 + age/RepairAgeWeightPeriod).
+const RepairAgeWeightPeriod = 30 * 24 * time.Hour
+
+// GetSegmentsNeedingRepair contains arguments necessary for listing segments needing repair.
+type GetSegmentsNeedingRepair struct {
+	// HealthyBelow is the number of pieces below which a segment needs repair.
+	HealthyBelow int
+	OrderBy      RepairOrder
+	Limit        int
+
+	// AsOf is the time used to compute segment age, defaults to the current time.
+	AsOf time.Time
+}
+
+// SegmentNeedingRepair is a segment with less pieces than required to be healthy.
+type SegmentNeedingRepair struct {
+	StreamID    uuid.UUID
+	Position    SegmentPosition
+	CreatedAt   *time.Time
+	PiecesCount int
+}
+
+// GetSegmentsNeedingRepair returns remote segments with less than HealthyBelow pieces in the specified order.
+// Segments without created_at are considered to be created at the unix epoch. Like
+// GetSegmentAgeDistribution it relies on pieces_count being set by BackfillSegmentPieces.
+func (db *DB) GetSegmentsNeedingRepair(ctx context.Context, opts GetSegmentsNeedingRepair) (segments []SegmentNeedingRepair, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	if opts.HealthyBelow <= 0 {
+		return nil, ErrInvalidRequest.New("Invalid HealthyBelow: %d", opts.HealthyBelow)
+	}
+	if opts.Limit < 0 {
+		return nil, ErrInvalidRequest.New("Invalid limit: %d", opts.Limit)
+	}
+	ListLimit.Ensure(&opts.Limit)
+	if opts.AsOf.IsZero() {
+		opts.AsOf = time.Now()
+	}
+
+	args := []interface{}{opts.HealthyBelow, opts.Limit}
+
+	var orderBy string
+	switch opts.OrderBy {
+	case RepairOrderByHealth:
+		orderBy = `pieces_count ASC, stream_id ASC, position ASC`
+	case RepairOrderByAgeWeightedHealth:
+		args = append(args, opts.AsOf, time.Unix(0, 0))
+		orderBy = `pieces_count / (1 + EXTRACT(EPOCH FROM ($3::TIMESTAMPTZ - COALESCE(created_at, $4::TIMESTAMPTZ))) / ` +
+			strconv.FormatFloat(RepairAgeWeightPeriod.Seconds(), 'f', -1, 64) + `) ASC,
+			stream_id ASC, position ASC`
+	default:
+		return nil, ErrInvalidRequest.New("Invalid OrderBy: %d", opts.OrderBy)
+	}
+
+	err = withRows(db.db.QueryContext(ctx, `
+		SELECT stream_id, position, created_at, pieces_count
+		FROM segments
+		WHERE pieces_count < $1
+		ORDER BY `+orderBy+`
+		LIMIT $2
+	`, args...))(func(rows tagsql.Rows) error {
+		for rows.Next() {
+			var segment SegmentNeedingRepair
+			err := rows.Scan(&segment.StreamID, &segment.Position, &segment.CreatedAt, &segment.PiecesCount)
+			if err != nil {
+				return Error.New("failed to scan segments: %w", err)
+			}
+			segments = append(segments, segment)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, Error.New("unable to fetch segments needing repair: %w", err)
+	}
+
+	return segments, nil
+}
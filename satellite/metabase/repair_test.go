@@ -0,0 +1,154 @@
+// Copyright (C) 2021 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package metabase_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"storj.io/common/storj"
+	"storj.io/common/testcontext"
+	"storj.io/common/testrand"
+	"storj.io/storj/satellite/metabase"
+	"storj.io/storj/satellite/metabase/metabasetest"
+)
+
+func TestSegmentsNeedingRepair(t *testing.T) {
+	metabasetest.Run(t, func(ctx *testcontext.Context, t *testing.T, db *metabase.DB) {
+		t.Run("invalid request", func(t *testing.T) {
+			defer metabasetest.DeleteAll{}.Check(ctx, t, db)
+
+			_, err := db.GetSegmentAgeDistribution(ctx, metabase.GetSegmentAgeDistribution{})
+			require.True(t, metabase.ErrInvalidRequest.Has(err))
+
+			_, err = db.GetSegmentsNeedingRepair(ctx, metabase.GetSegmentsNeedingRepair{})
+			require.True(t, metabase.ErrInvalidRequest.Has(err))
+
+			_, err = db.GetSegmentsNeedingRepair(ctx, metabase.GetSegmentsNeedingRepair{HealthyBelow: 1, Limit: -1})
+			require.True(t, metabase.ErrInvalidRequest.Has(err))
+
+			_, err = db.GetSegmentsNeedingRepair(ctx, metabase.GetSegmentsNeedingRepair{HealthyBelow: 1, OrderBy: 100})
+			require.True(t, metabase.ErrInvalidRequest.Has(err))
+		})
+
+		t.Run("ordering", func(t *testing.T) {
+			defer metabasetest.DeleteAll{}.Check(ctx, t, db)
+
+			const day = 24 * time.Hour
+			asOf := time.Now().UTC().Truncate(time.Second)
+
+			obj := metabasetest.RandObjectStream()
+			metabasetest.CreateObject(ctx, t, db, obj, 5)
+
+			seeds := []struct {
+				pieces int
+				age    time.Duration
+			}{
+				{pieces: 1, age: 2 * time.Hour},
+				{pieces: 2, age: 400 * day},
+				{pieces: 3, age: 10 * day},
+				{pieces: 2, age: 60 * day},
+				{pieces: 5, age: 500 * day}, // healthy
+			}
+			for i, seed := range seeds {
+				position := metabase.SegmentPosition{Index: uint32(i)}
+
+				var pieces metabase.Pieces
+				for number := 0; number < seed.pieces; number++ {
+					pieces = append(pieces, metabase.Piece{Number: uint16(number), StorageNode: testrand.NodeID()})
+				}
+				_, err := db.UpdateSegmentPieces(ctx, metabase.UpdateSegmentPieces{
+					StreamID:      obj.StreamID,
+					Position:      position,
+					OldPieces:     metabase.Pieces{{Number: 0, StorageNode: storj.NodeID{2}}},
+					NewRedundancy: metabasetest.DefaultRedundancy,
+					NewPieces:     pieces,
+				})
+				require.NoError(t, err)
+
+				_, err = db.UnderlyingTagSQL().ExecContext(ctx, `
+					UPDATE segments SET created_at = $3 WHERE stream_id = $1 AND position = $2
+				`, obj.StreamID, position, asOf.Add(-seed.age))
+				require.NoError(t, err)
+			}
+
+			distribution, err := db.GetSegmentAgeDistribution(ctx, metabase.GetSegmentAgeDistribution{
+				HealthyBelow: 4,
+				AsOf:         asOf,
+			})
+			require.NoError(t, err)
+			require.Equal(t, []metabase.SegmentAgeBucket{
+				{MinAge: 0, MaxAge: day, Count: 1},
+				{MinAge: day, MaxAge: 7 * day, Count: 0},
+				{MinAge: 7 * day, MaxAge: 30 * day, Count: 1},
+				{MinAge: 30 * day, MaxAge: 90 * day, Count: 1},
+				{MinAge: 90 * day, MaxAge: 365 * day, Count: 0},
+				{MinAge: 365 * day, Count: 1},
+			}, distribution)
+
+			positions := func(orderBy metabase.RepairOrder, limit int) []uint32 {
+				segments, err := db.GetSegmentsNeedingRepair(ctx, metabase.GetSegmentsNeedingRepair{
+					HealthyBelow: 4,
+					OrderBy:      orderBy,
+					Limit:        limit,
+					AsOf:         asOf,
+				})
+				require.NoError(t, err)
+
+				var indexes []uint32
+				for _, segment := range segments {
+					require.Equal(t, obj.StreamID, segment.StreamID)
+					require.Equal(t, seeds[segment.Position.Index].pieces, segment.PiecesCount)
+					require.NotNil(t, segment.CreatedAt)
+					require.True(t, asOf.Add(-seeds[segment.Position.Index].age).Equal(*segment.CreatedAt))
+					indexes = append(indexes, segment.Position.Index)
+				}
+				return indexes
+			}
+
+			require.Equal(t, []uint32{0, 1, 3, 2}, positions(metabase.RepairOrderByHealth, 0))
+			// weighted health: 0.14, 0.67, 1.00, 2.25
+			require.Equal(t, []uint32{1, 3, 0, 2}, positions(metabase.RepairOrderByAgeWeightedHealth, 0))
+			require.Equal(t, []uint32{1, 3}, positions(metabase.RepairOrderByAgeWeightedHealth, 2))
+		})
+
+		t.Run("backfill", func(t *testing.T) {
+			defer metabasetest.DeleteAll{}.Check(ctx, t, db)
+
+			obj := metabasetest.RandObjectStream()
+			metabasetest.CreateObject(ctx, t, db, obj, 2)
+
+			// simulate segments committed before pieces_count was added.
+			_, err := db.UnderlyingTagSQL().ExecContext(ctx, `UPDATE segments SET remote_alias_nodes = NULL, pieces_count = NULL`)
+			require.NoError(t, err)
+
+			opts := metabase.GetSegmentsNeedingRepair{HealthyBelow: 4}
+			segments, err := db.GetSegmentsNeedingRepair(ctx, opts)
+			require.NoError(t, err)
+			require.Empty(t, segments)
+
+			result, err := db.BackfillSegmentPieces(ctx, metabase.BackfillSegmentPieces{BatchSize: 10})
+			require.NoError(t, err)
+			require.Equal(t, 2, result.Updated)
+
+			segments, err = db.GetSegmentsNeedingRepair(ctx, opts)
+			require.NoError(t, err)
+			require.Len(t, segments, 2)
+			for _, segment := range segments {
+				require.Equal(t, obj.StreamID, segment.StreamID)
+				require.Equal(t, 1, segment.PiecesCount)
+			}
+
+			distribution, err := db.GetSegmentAgeDistribution(ctx, metabase.GetSegmentAgeDistribution{HealthyBelow: 4})
+			require.NoError(t, err)
+			var total int64
+			for _, bucket := range distribution {
+				total += bucket.Count
+			}
+			require.EqualValues(t, 2, total)
+		})
+	})
+}
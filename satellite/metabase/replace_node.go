@@ -0,0 +1,244 @@
+// Copyright (C) 2021 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package metabase
+
+import (
+	"context"
+
+	"storj.io/common/storj"
+	"storj.io/common/uuid"
+	"storj.io/private/dbutil/pgutil"
+	"storj.io/private/tagsql"
+)
+
+// replaceNodeBatchSizeLimit is the maximum number of segments ReplaceNodeInSegments reads at once.
+const replaceNodeBatchSizeLimit = intLimitRange(1000)
+
+// NodeReplacement replaces the piece of a node in a segment.
+type NodeReplacement struct {
+	StreamID uuid.UUID
+	Position SegmentPosition
+
+	// Piece replaces the piece with the same number, which has to be held by the replaced node.
+	Piece Piece
+}
+
+// ReplaceNodeInSegments contains arguments necessary for replacing a node in the pieces of segments.
+type ReplaceNodeInSegments struct {
+	OldNode      storj.NodeID
+	Replacements []NodeReplacement
+
+	BatchSize int
+}
+
+// Verify verifies the replacement request fields.
+func (opts ReplaceNodeInSegments) Verify() error {
+	if opts.OldNode.IsZero() {
+		return ErrInvalidRequest.New("OldNode missing")
+	}
+	for i, replacement := range opts.Replacements {
+		switch {
+		case replacement.StreamID.IsZero():
+			return ErrInvalidRequest.New("Replacements[%d]: StreamID missing", i)
+		case replacement.Piece.StorageNode.IsZero():
+			return ErrInvalidRequest.New("Replacements[%d]: piece number %d is missing storage node id", i, replacement.Piece.Number)
+		case replacement.Piece.StorageNode == opts.OldNode:
+			return ErrInvalidRequest.New("Replacements[%d]: piece number %d is stored on OldNode", i, replacement.Piece.Number)
+		}
+	}
+	return nil
+}
+
+// ReplaceNodeInSegmentsResult contains the number of segments by the outcome of the replacement.
+type ReplaceNodeInSegmentsResult struct {
+	// Updated is the number of segments where the piece was replaced.
+	Updated int
+	// Skipped is the number of segments which don't exist, where the old node doesn't hold
+	// the piece anymore, or where the new node already holds another piece.
+	Skipped int
+	// Failed is the number of segments whose pieces were modified concurrently.
+	Failed int
+}
+
+// ReplaceNodeInSegments replaces the pieces of OldNode with the pieces of Replacements. Segments
+// are read in batches of BatchSize and every segment is updated only if its pieces didn't change
+// since they were read, like UpdateSegmentPieces does.
+func (db *DB) ReplaceNodeInSegments(ctx context.Context, opts ReplaceNodeInSegments) (result ReplaceNodeInSegmentsResult, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	if err := opts.Verify(); err != nil {
+		return ReplaceNodeInSegmentsResult{}, err
+	}
+	replaceNodeBatchSizeLimit.Ensure(&opts.BatchSize)
+
+	aliases, err := db.aliasCache.Aliases(ctx, []storj.NodeID{opts.OldNode})
+	if err != nil {
+		return ReplaceNodeInSegmentsResult{}, Error.New("unable to get node alias: %w", err)
+	}
+	oldAlias := aliases[0]
+
+	replacements := opts.Replacements
+	for len(replacements) > 0 {
+		batch := replacements
+		if len(batch) > opts.BatchSize {
+			batch = batch[:opts.BatchSize]
+		}
+		replacements = replacements[len(batch):]
+
+		if err := db.replaceNodeInBatch(ctx, oldAlias, batch, &result); err != nil {
+			return result, err
+		}
+	}
+
+	mon.Meter("segment_node_replace").Mark(result.Updated)
+
+	return result, nil
+}
+
+// replaceNodeInBatch replaces the pieces of the old node in a batch of segments and adds the
+// outcomes to result.
+func (db *DB) replaceNodeInBatch(ctx context.Context, oldAlias NodeAlias, batch []NodeReplacement, result *ReplaceNodeInSegmentsResult) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	type segmentKey struct {
+		StreamID uuid.UUID
+		Position SegmentPosition
+	}
+
+	streamIDs := make([][]byte, len(batch))
+	positions := make([]int64, len(batch))
+	for i := range batch {
+		streamIDs[i] = batch[i].StreamID[:]
+		positions[i] = int64(batch[i].Position.Encode())
+	}
+
+	current := make(map[segmentKey]AliasPieces, len(batch))
+	err = withRows(db.db.QueryContext(ctx, `
+		SELECT stream_id, position, remote_alias_pieces
+		FROM segments
+		WHERE (stream_id, position) IN (
+			SELECT unnest($1::BYTEA[]), unnest($2::INT8[])
+		)
+	`, pgutil.ByteaArray(streamIDs), pgutil.Int8Array(positions)))(func(rows tagsql.Rows) error {
+		for rows.Next() {
+			var key segmentKey
+			var aliasPieces AliasPieces
+			if err := rows.Scan(&key.StreamID, &key.Position, &aliasPieces); err != nil {
+				return Error.New("failed to scan segments: %w", err)
+			}
+			current[key] = aliasPieces
+		}
+		return nil
+	})
+	if err != nil {
+		return Error.New("unable to query segments: %w", err)
+	}
+
+	var (
+		updateStreamIDs [][]byte
+		updatePositions []int64
+		updateOldPieces [][]byte
+		updateNewPieces [][]byte
+		updateNewNodes  []string
+	)
+	for _, replacement := range batch {
+		oldPieces, ok := current[segmentKey{replacement.StreamID, replacement.Position}]
+		if !ok {
+			result.Skipped++
+			continue
+		}
+
+		newPiece, err := db.aliasCache.ConvertPiecesToAliases(ctx, Pieces{replacement.Piece})
+		if err != nil {
+			return Error.New("unable to convert pieces to aliases: %w", err)
+		}
+
+		newPieces, ok := replaceAliasPiece(oldPieces, oldAlias, newPiece[0])
+		if !ok {
+			result.Skipped++
+			continue
+		}
+
+		oldBytes, err := oldPieces.Bytes()
+		if err != nil {
+			return Error.New("unable to encode pieces: %w", err)
+		}
+		newBytes, err := newPieces.Bytes()
+		if err != nil {
+			return Error.New("unable to encode pieces: %w", err)
+		}
+
+		updateStreamIDs = append(updateStreamIDs, replacement.StreamID[:])
+		updatePositions = append(updatePositions, int64(replacement.Position.Encode()))
+		updateOldPieces = append(updateOldPieces, oldBytes)
+		updateNewPieces = append(updateNewPieces, newBytes)
+		updateNewNodes = append(updateNewNodes, newPieces.nodesLiteral())
+	}
+
+	if len(updateStreamIDs) == 0 {
+		return nil
+	}
+
+	// segments are updated only when their pieces didn't change since they were read,
+	// the other segments aren't returned.
+	updated := 0
+	err = withRows(db.db.QueryContext(ctx, `
+		UPDATE segments SET
+			remote_alias_pieces = updates.new_pieces,
+			remote_alias_nodes  = updates.new_nodes::INT4[],
+			pieces_changed_seq  = nextval('segment_pieces_changed_seq')
+		FROM (
+			SELECT
+				unnest($1::BYTEA[]) AS stream_id,
+				unnest($2::INT8[])  AS position,
+				unnest($3::BYTEA[]) AS old_pieces,
+				unnest($4::BYTEA[]) AS new_pieces,
+				unnest($5::TEXT[])  AS new_nodes
+		) AS updates
+		WHERE
+			segments.stream_id           = updates.stream_id AND
+			segments.position            = updates.position AND
+			segments.remote_alias_pieces = updates.old_pieces
+		RETURNING segments.stream_id
+	`, pgutil.ByteaArray(updateStreamIDs), pgutil.Int8Array(updatePositions),
+		pgutil.ByteaArray(updateOldPieces), pgutil.ByteaArray(updateNewPieces), pgutil.TextArray(updateNewNodes),
+	))(func(rows tagsql.Rows) error {
+		for rows.Next() {
+			var streamID uuid.UUID
+			if err := rows.Scan(&streamID); err != nil {
+				return Error.New("failed to scan updated segments: %w", err)
+			}
+			updated++
+		}
+		return nil
+	})
+	if err != nil {
+		return Error.New("unable to update segment pieces: %w", err)
+	}
+
+	result.Updated += updated
+	result.Failed += len(updateStreamIDs) - updated
+
+	return nil
+}
+
+// replaceAliasPiece returns a copy of pieces where the piece of oldAlias with the number of
+// newPiece is replaced by newPiece. It returns false when oldAlias doesn't hold that piece or
+// when the new node already holds another piece.
+func replaceAliasPiece(pieces AliasPieces, oldAlias NodeAlias, newPiece AliasPiece) (AliasPieces, bool) {
+	replaced := make(AliasPieces, len(pieces))
+	found := false
+	for i, piece := range pieces {
+		switch {
+		case piece.Alias == newPiece.Alias:
+			return nil, false
+		case piece.Number == newPiece.Number && piece.Alias == oldAlias:
+			replaced[i] = newPiece
+			found = true
+		default:
+			replaced[i] = piece
+		}
+	}
+	return replaced, found
+}
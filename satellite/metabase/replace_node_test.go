@@ -0,0 +1,93 @@
+// Copyright (C) 2021 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package metabase_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"storj.io/common/storj"
+	"storj.io/common/testcontext"
+	"storj.io/common/testrand"
+	"storj.io/storj/satellite/metabase"
+	"storj.io/storj/satellite/metabase/metabasetest"
+)
+
+func TestReplaceNodeInSegments(t *testing.T) {
+	metabasetest.Run(t, func(ctx *testcontext.Context, t *testing.T, db *metabase.DB) {
+		// segments created by CreateObject have a single piece on this node.
+		oldNode := storj.NodeID{2}
+
+		t.Run("invalid request", func(t *testing.T) {
+			defer metabasetest.DeleteAll{}.Check(ctx, t, db)
+
+			_, err := db.ReplaceNodeInSegments(ctx, metabase.ReplaceNodeInSegments{})
+			require.True(t, metabase.ErrInvalidRequest.Has(err))
+
+			_, err = db.ReplaceNodeInSegments(ctx, metabase.ReplaceNodeInSegments{
+				OldNode: oldNode,
+				Replacements: []metabase.NodeReplacement{
+					{StreamID: testrand.UUID(), Piece: metabase.Piece{Number: 0, StorageNode: oldNode}},
+				},
+			})
+			require.True(t, metabase.ErrInvalidRequest.Has(err))
+		})
+
+		t.Run("replace", func(t *testing.T) {
+			defer metabasetest.DeleteAll{}.Check(ctx, t, db)
+
+			first := metabasetest.CreateObject(ctx, t, db, metabasetest.RandObjectStream(), 2)
+			moved := metabasetest.CreateObject(ctx, t, db, metabasetest.RandObjectStream(), 1)
+
+			// the piece of the old node was already moved by someone else.
+			movedPieces := metabase.Pieces{{Number: 0, StorageNode: testrand.NodeID()}}
+			_, err := db.UpdateSegmentPieces(ctx, metabase.UpdateSegmentPieces{
+				StreamID:      moved.StreamID,
+				OldPieces:     metabase.Pieces{{Number: 0, StorageNode: oldNode}},
+				NewRedundancy: metabasetest.DefaultRedundancy,
+				NewPieces:     movedPieces,
+			})
+			require.NoError(t, err)
+
+			newNode := testrand.NodeID()
+			newPiece := metabase.Piece{Number: 0, StorageNode: newNode}
+			result, err := db.ReplaceNodeInSegments(ctx, metabase.ReplaceNodeInSegments{
+				OldNode: oldNode,
+				Replacements: []metabase.NodeReplacement{
+					{StreamID: first.StreamID, Position: metabase.SegmentPosition{Index: 0}, Piece: newPiece},
+					{StreamID: first.StreamID, Position: metabase.SegmentPosition{Index: 1}, Piece: newPiece},
+					{StreamID: moved.StreamID, Position: metabase.SegmentPosition{Index: 0}, Piece: newPiece},
+					{StreamID: testrand.UUID(), Position: metabase.SegmentPosition{Index: 0}, Piece: newPiece},
+					// the old node doesn't hold the piece with this number.
+					{StreamID: first.StreamID, Position: metabase.SegmentPosition{Index: 0}, Piece: metabase.Piece{Number: 1, StorageNode: newNode}},
+				},
+				BatchSize: 2,
+			})
+			require.NoError(t, err)
+			require.Equal(t, metabase.ReplaceNodeInSegmentsResult{Updated: 2, Skipped: 3}, result)
+
+			for _, expected := range []struct {
+				object   metabase.Object
+				position metabase.SegmentPosition
+				pieces   metabase.Pieces
+			}{
+				{first, metabase.SegmentPosition{Index: 0}, metabase.Pieces{newPiece}},
+				{first, metabase.SegmentPosition{Index: 1}, metabase.Pieces{newPiece}},
+				{moved, metabase.SegmentPosition{Index: 0}, movedPieces},
+			} {
+				segment, err := db.GetSegmentByPosition(ctx, metabase.GetSegmentByPosition{
+					StreamID: expected.object.StreamID,
+					Position: expected.position,
+				})
+				require.NoError(t, err)
+				require.Equal(t, expected.pieces, segment.Pieces)
+			}
+
+			nodeSegments, err := db.ListNodeSegments(ctx, metabase.ListNodeSegments{NodeID: newNode})
+			require.NoError(t, err)
+			require.Len(t, nodeSegments.Segments, 2)
+		})
+	})
+}
@@ -0,0 +1,51 @@
+// Copyright (C) 2021 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package metabase
+
+import (
+	"context"
+	"time"
+
+	"storj.io/common/sync2"
+	"storj.io/private/dbutil/cockroachutil"
+)
+
+// retryPolicy defines how a single statement update is retried on transient database errors.
+type retryPolicy struct {
+	// Attempts is the maximum number of attempts.
+	Attempts int
+	// Backoff is the delay before the first retry, it's doubled for every next retry.
+	Backoff time.Duration
+	// NeedsRetry reports whether the update failed with an error, which can be retried.
+	NeedsRetry func(err error) bool
+}
+
+// updateRetryPolicy retries the compare-and-swap updates, which fail with serialization
+// errors under contention on CockroachDB.
+var updateRetryPolicy = retryPolicy{
+	Attempts:   5,
+	Backoff:    10 * time.Millisecond,
+	NeedsRetry: cockroachutil.NeedsRetry,
+}
+
+// Do calls update until it succeeds, fails with an error which can't be retried, runs out
+// of attempts or the context is canceled. A compare-and-swap conflict isn't a database
+// error and must be reported by an error that doesn't need retry, e.g.
+// storage.ErrValueChanged, so that it's not retried.
+func (policy retryPolicy) Do(ctx context.Context, update func(ctx context.Context) error) (err error) {
+	backoff := policy.Backoff
+	for attempt := 1; ; attempt++ {
+		err = update(ctx)
+		if err == nil || attempt >= policy.Attempts || !policy.NeedsRetry(err) {
+			return err
+		}
+
+		mon.Meter("update_retry").Mark(1)
+
+		if !sync2.Sleep(ctx, backoff) {
+			return ctx.Err()
+		}
+		backoff *= 2
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright (C) 2021 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package metabase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgconn"
+	pgxerrcode "github.com/jackc/pgerrcode"
+	"github.com/stretchr/testify/require"
+
+	"storj.io/common/testcontext"
+	"storj.io/storj/storage"
+)
+
+// fakeQuerier fails the updates with the queued errors, before it succeeds.
+type fakeQuerier struct {
+	errs  []error
+	calls int
+}
+
+func (querier *fakeQuerier) update(ctx context.Context) error {
+	querier.calls++
+	if len(querier.errs) == 0 {
+		return nil
+	}
+	err := querier.errs[0]
+	querier.errs = querier.errs[1:]
+	return err
+}
+
+func TestRetryPolicy(t *testing.T) {
+	ctx := testcontext.New(t)
+	defer ctx.Cleanup()
+
+	policy := updateRetryPolicy
+	policy.Backoff = time.Millisecond
+
+	serializationFailure := func() error {
+		return Error.New("unable to update segment pieces: %w", &pgconn.PgError{Code: pgxerrcode.SerializationFailure})
+	}
+
+	t.Run("retryable error", func(t *testing.T) {
+		querier := &fakeQuerier{errs: []error{serializationFailure(), serializationFailure()}}
+		require.NoError(t, policy.Do(ctx, querier.update))
+		require.Equal(t, 3, querier.calls)
+	})
+
+	t.Run("attempts exhausted", func(t *testing.T) {
+		querier := &fakeQuerier{}
+		for i := 0; i < policy.Attempts+1; i++ {
+			querier.errs = append(querier.errs, serializationFailure())
+		}
+		err := policy.Do(ctx, querier.update)
+		require.Error(t, err)
+		require.True(t, policy.NeedsRetry(err))
+		require.Equal(t, policy.Attempts, querier.calls)
+	})
+
+	t.Run("compare-and-swap conflict", func(t *testing.T) {
+		changed := &PiecesChangedError{err: storage.ErrValueChanged.New("segment remote_alias_pieces field was changed")}
+		querier := &fakeQuerier{errs: []error{changed}}
+		err := policy.Do(ctx, querier.update)
+		require.True(t, errors.Is(err, changed))
+		require.Equal(t, 1, querier.calls)
+	})
+
+	t.Run("segment missing", func(t *testing.T) {
+		querier := &fakeQuerier{errs: []error{ErrSegmentNotFound.New("segment missing")}}
+		err := policy.Do(ctx, querier.update)
+		require.True(t, ErrSegmentNotFound.Has(err))
+		require.Equal(t, 1, querier.calls)
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		canceledCtx, cancel := context.WithCancel(ctx)
+		cancel()
+
+		querier := &fakeQuerier{errs: []error{serializationFailure(), serializationFailure()}}
+		err := policy.Do(canceledCtx, querier.update)
+		require.True(t, errors.Is(err, context.Canceled))
+		require.Equal(t, 1, querier.calls)
+	})
+}
@@ -13,6 +13,8 @@ import (
 
 	"storj.io/common/storj"
 	"storj.io/common/uuid"
+	"storj.io/private/dbutil/txutil"
+	"storj.io/private/tagsql"
 	"storj.io/storj/storage"
 )
 
@@ -26,14 +28,29 @@ type UpdateSegmentPieces struct {
 	NewRedundancy storj.RedundancyScheme
 	NewPieces     Pieces
 
-	NewRepairedAt time.Time // sets new time of last segment repair (optional).
+	// NewRepairedAt sets new time of last segment repair and increments the repair count
+	// of the segment, only when the pieces are updated (optional).
+	NewRepairedAt time.Time
 }
 
-// UpdateSegmentPieces updates pieces for specified segment. If provided old pieces
-// won't match current database state update will fail.
-func (db *DB) UpdateSegmentPieces(ctx context.Context, opts UpdateSegmentPieces) (err error) {
-	defer mon.Task()(&ctx)(&err)
+// PiecesChangedError is returned by UpdateSegmentPieces when the pieces of the segment
+// don't match OldPieces, because they were modified concurrently. It's a
+// storage.ErrValueChanged error, which contains the current pieces of the segment, so
+// that the update can be recomputed without querying the segment again.
+type PiecesChangedError struct {
+	CurrentPieces Pieces
+
+	err error
+}
 
+// Error implements the error interface.
+func (err *PiecesChangedError) Error() string { return err.err.Error() }
+
+// Unwrap returns the underlying error.
+func (err *PiecesChangedError) Unwrap() error { return err.err }
+
+// Verify verifies the update of segment pieces.
+func (opts UpdateSegmentPieces) Verify() error {
 	if opts.StreamID.IsZero() {
 		return ErrInvalidRequest.New("StreamID missing")
 	}
@@ -62,20 +79,172 @@ func (db *DB) UpdateSegmentPieces(ctx context.Context, opts UpdateSegmentPieces)
 		return err
 	}
 
+	// OldPieces have to match the database, so only new pieces are required to be
+	// on distinct storage nodes.
+	if err := opts.NewPieces.verifyStorageNodesUnique(); err != nil {
+		return ErrInvalidRequest.New("NewPieces: %v", errs.Unwrap(err))
+	}
+
+	return nil
+}
+
+// UpdateSegmentPiecesStatus is the outcome of an update of segment pieces.
+type UpdateSegmentPiecesStatus int
+
+const (
+	// UpdateSegmentPiecesUpdated means that the pieces were updated.
+	UpdateSegmentPiecesUpdated UpdateSegmentPiecesStatus = iota
+	// UpdateSegmentPiecesNotFound means that the segment doesn't exist.
+	UpdateSegmentPiecesNotFound
+	// UpdateSegmentPiecesChanged means that the pieces didn't match OldPieces.
+	UpdateSegmentPiecesChanged
+)
+
+// UpdateSegmentPiecesResult is the result of an update of segment pieces.
+type UpdateSegmentPiecesResult struct {
+	StreamID uuid.UUID
+	Position SegmentPosition
+
+	Status UpdateSegmentPiecesStatus
+	// CurrentPieces are the pieces of the segment when Status is UpdateSegmentPiecesChanged.
+	CurrentPieces Pieces
+
+	// PiecesBefore and PiecesAfter are the number of pieces of the segment before and
+	// after the update. They are equal when the pieces weren't updated.
+	PiecesBefore int
+	PiecesAfter  int
+
+	// RepairedAt and RepairCount are the repair metadata of the segment after the update.
+	// They are changed only when the pieces were updated with NewRepairedAt.
+	RepairedAt  *time.Time
+	RepairCount int32
+}
+
+// UpdateSegmentPieces updates pieces for specified segment. If provided old pieces
+// won't match current database state update will fail with *PiecesChangedError.
+// The result is returned also when the segment is missing or its pieces were changed.
+func (db *DB) UpdateSegmentPieces(ctx context.Context, opts UpdateSegmentPieces) (result UpdateSegmentPiecesResult, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	if err := opts.Verify(); err != nil {
+		return UpdateSegmentPiecesResult{}, err
+	}
+
+	err = updateRetryPolicy.Do(ctx, func(ctx context.Context) (err error) {
+		result, err = db.updateSegmentPieces(ctx, db.db, opts)
+		return err
+	})
+	if err != nil {
+		return result, err
+	}
+
+	if result.Status == UpdateSegmentPiecesChanged {
+		return result, &PiecesChangedError{
+			CurrentPieces: result.CurrentPieces,
+			err:           storage.ErrValueChanged.New("segment remote_alias_pieces field was changed"),
+		}
+	}
+
+	monitorSegmentPiecesUpdate(opts.OldPieces, opts.NewPieces)
+
+	return result, nil
+}
+
+// UpdateSegmentPiecesBatch updates pieces of multiple segments in a single transaction. It
+// returns a result for every update, in the same order. Segments which don't exist or whose
+// pieces don't match OldPieces are reported in the results and don't fail the other updates.
+// The transaction is retried on transient database errors.
+func (db *DB) UpdateSegmentPiecesBatch(ctx context.Context, updates []UpdateSegmentPieces) (results []UpdateSegmentPiecesResult, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	for i, opts := range updates {
+		if err := opts.Verify(); err != nil {
+			if ErrInvalidRequest.Has(err) {
+				return nil, ErrInvalidRequest.New("update %d: %v", i, errs.Unwrap(err))
+			}
+			return nil, err
+		}
+	}
+
+	if len(updates) == 0 {
+		return nil, nil
+	}
+
+	err = updateRetryPolicy.Do(ctx, func(ctx context.Context) error {
+		return txutil.WithTx(ctx, db.db, nil, func(ctx context.Context, tx tagsql.Tx) error {
+			// the transaction may be retried, hence the results are reset.
+			results = make([]UpdateSegmentPiecesResult, 0, len(updates))
+
+			for _, opts := range updates {
+				result, err := db.updateSegmentPieces(ctx, tx, opts)
+				if err != nil && !ErrSegmentNotFound.Has(err) {
+					return err
+				}
+				results = append(results, result)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i, result := range results {
+		if result.Status == UpdateSegmentPiecesUpdated {
+			monitorSegmentPiecesUpdate(updates[i].OldPieces, updates[i].NewPieces)
+		}
+	}
+
+	return results, nil
+}
+
+// RowQuerier runs a query, which returns at most one row. It's used by updates of
+// segment pieces, see DB.TestingWrapRowQuerier.
+type RowQuerier func(ctx context.Context, query string, args ...interface{}) RowScanner
+
+// RowScanner scans the row returned by RowQuerier, it's implemented by *sql.Row.
+type RowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// rowQuerier returns the RowQuerier for q, which is either the database or a transaction.
+func (db *DB) rowQuerier(q interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}) RowQuerier {
+	querier := RowQuerier(func(ctx context.Context, query string, args ...interface{}) RowScanner {
+		return q.QueryRowContext(ctx, query, args...)
+	})
+	if db.testWrapRowQuerier != nil {
+		querier = db.testWrapRowQuerier(querier)
+	}
+	return querier
+}
+
+// updateSegmentPieces updates the pieces of a segment if they match OldPieces. When they don't
+// match, the result contains the current pieces of the segment. When the segment is missing,
+// the result is returned together with ErrSegmentNotFound.
+func (db *DB) updateSegmentPieces(ctx context.Context, q interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}, opts UpdateSegmentPieces) (result UpdateSegmentPiecesResult, err error) {
+	result = UpdateSegmentPiecesResult{
+		StreamID: opts.StreamID,
+		Position: opts.Position,
+	}
+
 	updateRepairAt := !opts.NewRepairedAt.IsZero()
 
 	oldPieces, err := db.aliasCache.ConvertPiecesToAliases(ctx, opts.OldPieces)
 	if err != nil {
-		return Error.New("unable to convert pieces to aliases: %w", err)
+		return UpdateSegmentPiecesResult{}, Error.New("unable to convert pieces to aliases: %w", err)
 	}
 
 	newPieces, err := db.aliasCache.ConvertPiecesToAliases(ctx, opts.NewPieces)
 	if err != nil {
-		return Error.New("unable to convert pieces to aliases: %w", err)
+		return UpdateSegmentPiecesResult{}, Error.New("unable to convert pieces to aliases: %w", err)
 	}
 
 	var resultPieces AliasPieces
-	err = db.db.QueryRowContext(ctx, `
+	err = db.rowQuerier(q)(ctx, `
 		UPDATE segments SET
 			remote_alias_pieces = CASE
 				WHEN remote_alias_pieces = $3 THEN $4
@@ -88,25 +257,189 @@ func (db *DB) UpdateSegmentPieces(ctx context.Context, opts UpdateSegmentPieces)
 			repaired_at = CASE
 				WHEN remote_alias_pieces = $3 AND $7 = true THEN $6
 				ELSE repaired_at
+			END,
+			repair_count = CASE
+				WHEN remote_alias_pieces = $3 AND $7 = true THEN repair_count + 1
+				ELSE repair_count
+			END,
+			remote_alias_nodes = CASE
+				WHEN remote_alias_pieces = $3 THEN $8
+				ELSE remote_alias_nodes
+			END,
+			pieces_count = CASE
+				WHEN remote_alias_pieces = $3 THEN $9
+				ELSE pieces_count
+			END,
+			pieces_changed_seq = CASE
+				WHEN remote_alias_pieces = $3 THEN nextval('segment_pieces_changed_seq')
+				ELSE pieces_changed_seq
 			END
 		WHERE
 			stream_id     = $1 AND
 			position      = $2
-		RETURNING remote_alias_pieces
-		`, opts.StreamID, opts.Position, oldPieces, newPieces, redundancyScheme{&opts.NewRedundancy}, opts.NewRepairedAt, updateRepairAt).
-		Scan(&resultPieces)
+		RETURNING remote_alias_pieces, repaired_at, repair_count
+		`, opts.StreamID, opts.Position, oldPieces, newPieces, redundancyScheme{&opts.NewRedundancy}, opts.NewRepairedAt, updateRepairAt,
+		newPieces.nodes(), newPieces.count()).
+		Scan(&resultPieces, &result.RepairedAt, &result.RepairCount)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return ErrSegmentNotFound.New("segment missing")
+			result.Status = UpdateSegmentPiecesNotFound
+			return result, ErrSegmentNotFound.New("segment missing")
 		}
-		return Error.New("unable to update segment pieces: %w", err)
+		return UpdateSegmentPiecesResult{}, Error.New("unable to update segment pieces: %w", err)
 	}
 
-	if !EqualAliasPieces(newPieces, resultPieces) {
-		return storage.ErrValueChanged.New("segment remote_alias_pieces field was changed")
+	if EqualAliasPieces(newPieces, resultPieces) {
+		result.Status = UpdateSegmentPiecesUpdated
+		result.PiecesBefore = len(opts.OldPieces)
+		result.PiecesAfter = len(opts.NewPieces)
+		return result, nil
 	}
 
+	currentPieces, err := db.aliasCache.ConvertAliasesToPieces(ctx, resultPieces)
+	if err != nil {
+		return UpdateSegmentPiecesResult{}, Error.New("unable to convert aliases to pieces: %w", err)
+	}
+
+	result.Status = UpdateSegmentPiecesChanged
+	result.CurrentPieces = currentPieces
+	result.PiecesBefore = len(currentPieces)
+	result.PiecesAfter = len(currentPieces)
+	return result, nil
+}
+
+// monitorSegmentPiecesUpdate marks an update of segment pieces and counts the pieces
+// which were added and removed by it.
+func monitorSegmentPiecesUpdate(oldPieces, newPieces Pieces) {
 	mon.Meter("segment_update").Mark(1)
 
+	removed := make(map[Piece]struct{}, len(oldPieces))
+	for _, piece := range oldPieces {
+		removed[piece] = struct{}{}
+	}
+
+	var added int64
+	for _, piece := range newPieces {
+		if _, ok := removed[piece]; ok {
+			delete(removed, piece)
+			continue
+		}
+		added++
+	}
+
+	mon.Counter("segment_update_pieces_added").Inc(added)
+	mon.Counter("segment_update_pieces_removed").Inc(int64(len(removed)))
+}
+
+// ErrTooFewPieces is returned by RemoveSegmentPieces when the segment would have less
+// pieces than the minimum.
+var ErrTooFewPieces = errs.Class("metabase: too few pieces")
+
+// RemoveSegmentPieces contains arguments necessary for removing pieces from a segment.
+type RemoveSegmentPieces struct {
+	StreamID uuid.UUID
+	Position SegmentPosition
+
+	// Pieces are removed when the segment has them, other pieces are ignored.
+	Pieces Pieces
+	// MinimumPieces is the minimum number of pieces which have to remain in the segment.
+	MinimumPieces int
+}
+
+// Verify verifies the removal of segment pieces.
+func (opts RemoveSegmentPieces) Verify() error {
+	switch {
+	case opts.StreamID.IsZero():
+		return ErrInvalidRequest.New("StreamID missing")
+	case len(opts.Pieces) == 0:
+		return ErrInvalidRequest.New("Pieces missing")
+	case opts.MinimumPieces < 0:
+		return ErrInvalidRequest.New("MinimumPieces negative")
+	}
 	return nil
 }
+
+// RemoveSegmentPieces atomically removes the specified pieces from a segment, without
+// replacing them, and returns the number of the remaining pieces. When the segment would
+// have less than MinimumPieces pieces, nothing is removed and the error is ErrTooFewPieces.
+func (db *DB) RemoveSegmentPieces(ctx context.Context, opts RemoveSegmentPieces) (piecesCount int, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	if err := opts.Verify(); err != nil {
+		return 0, err
+	}
+
+	remove := make(map[Piece]struct{}, len(opts.Pieces))
+	for _, piece := range opts.Pieces {
+		remove[piece] = struct{}{}
+	}
+
+	var oldPieces, newPieces Pieces
+	err = txutil.WithTx(ctx, db.db, nil, func(ctx context.Context, tx tagsql.Tx) error {
+		var aliasPieces AliasPieces
+		err := tx.QueryRowContext(ctx, `
+			SELECT remote_alias_pieces
+			FROM segments
+			WHERE
+				stream_id = $1 AND
+				position  = $2
+			FOR UPDATE
+		`, opts.StreamID, opts.Position).Scan(&aliasPieces)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrSegmentNotFound.New("segment missing")
+			}
+			return Error.New("unable to query segment pieces: %w", err)
+		}
+
+		oldPieces, err = db.aliasCache.ConvertAliasesToPieces(ctx, aliasPieces)
+		if err != nil {
+			return Error.New("unable to convert aliases to pieces: %w", err)
+		}
+
+		// the transaction may be retried, hence the remaining pieces are reset.
+		newPieces = nil
+		var newAliasPieces AliasPieces
+		for i, piece := range oldPieces {
+			if _, ok := remove[piece]; !ok {
+				newPieces = append(newPieces, piece)
+				newAliasPieces = append(newAliasPieces, aliasPieces[i])
+			}
+		}
+
+		if len(newPieces) == len(oldPieces) {
+			return nil
+		}
+		if len(newPieces) < opts.MinimumPieces {
+			return ErrTooFewPieces.New("removing %d pieces leaves %d pieces, minimum is %d",
+				len(oldPieces)-len(newPieces), len(newPieces), opts.MinimumPieces)
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			UPDATE segments SET
+				remote_alias_pieces = $3,
+				remote_alias_nodes  = $4,
+				pieces_count        = $5,
+				pieces_changed_seq  = nextval('segment_pieces_changed_seq')
+			WHERE
+				stream_id = $1 AND
+				position  = $2
+		`, opts.StreamID, opts.Position, newAliasPieces, newAliasPieces.nodes(), newAliasPieces.count())
+		if err != nil {
+			return Error.New("unable to update segment pieces: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		if ErrTooFewPieces.Has(err) {
+			return len(oldPieces), err
+		}
+		return 0, err
+	}
+
+	if len(newPieces) != len(oldPieces) {
+		monitorSegmentPiecesUpdate(oldPieces, newPieces)
+	}
+
+	return len(newPieces), nil
+}
@@ -4,16 +4,21 @@
 package metabase_test
 
 import (
+	"context"
+	"errors"
 	"testing"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/jackc/pgconn"
+	pgxerrcode "github.com/jackc/pgerrcode"
 	"github.com/stretchr/testify/require"
 
 	"storj.io/common/storj"
 	"storj.io/common/testcontext"
 	"storj.io/common/testrand"
+	"storj.io/common/uuid"
 	"storj.io/storj/satellite/metabase"
 	"storj.io/storj/satellite/metabase/metabasetest"
 	"storj.io/storj/storage"
@@ -217,6 +222,36 @@ func TestUpdateSegmentPieces(t *testing.T) {
 			metabasetest.Verify{}.Check(ctx, t, db)
 		})
 
+		t.Run("NewPieces: same storage node", func(t *testing.T) {
+			defer metabasetest.DeleteAll{}.Check(ctx, t, db)
+
+			node := testrand.NodeID()
+			metabasetest.UpdateSegmentPieces{
+				Opts: metabase.UpdateSegmentPieces{
+					StreamID:      obj.StreamID,
+					OldPieces:     validPieces,
+					NewRedundancy: metabasetest.DefaultRedundancy,
+					NewPieces: []metabase.Piece{
+						{
+							Number:      1,
+							StorageNode: node,
+						},
+						{
+							Number:      2,
+							StorageNode: testrand.NodeID(),
+						},
+						{
+							Number:      3,
+							StorageNode: node,
+						},
+					},
+				},
+				ErrClass: &metabase.ErrInvalidRequest,
+				ErrText:  "NewPieces: piece number 3 is stored on the same storage node as piece number 1",
+			}.Check(ctx, t, db)
+			metabasetest.Verify{}.Check(ctx, t, db)
+		})
+
 		t.Run("segment not found", func(t *testing.T) {
 			defer metabasetest.DeleteAll{}.Check(ctx, t, db)
 
@@ -231,6 +266,17 @@ func TestUpdateSegmentPieces(t *testing.T) {
 				ErrClass: &metabase.ErrSegmentNotFound,
 				ErrText:  "segment missing",
 			}.Check(ctx, t, db)
+
+			// the repairer handles a missing segment differently from a missing object.
+			_, err := db.UpdateSegmentPieces(ctx, metabase.UpdateSegmentPieces{
+				StreamID:      obj.StreamID,
+				Position:      metabase.SegmentPosition{Index: 1},
+				OldPieces:     validPieces,
+				NewRedundancy: metabasetest.DefaultRedundancy,
+				NewPieces:     validPieces,
+			})
+			require.True(t, metabase.ErrSegmentNotFound.Has(err))
+			require.False(t, storj.ErrObjectNotFound.Has(err))
 		})
 
 		t.Run("segment pieces column was changed", func(t *testing.T) {
@@ -245,22 +291,24 @@ func TestUpdateSegmentPieces(t *testing.T) {
 				TotalShares:    4,
 			}
 
-			metabasetest.UpdateSegmentPieces{
-				Opts: metabase.UpdateSegmentPieces{
-					StreamID:      obj.StreamID,
-					Position:      metabase.SegmentPosition{Index: 0},
-					OldPieces:     validPieces,
-					NewRedundancy: newRedundancy,
-					NewPieces: metabase.Pieces{
-						metabase.Piece{
-							Number:      1,
-							StorageNode: testrand.NodeID(),
-						},
+			result, err := db.UpdateSegmentPieces(ctx, metabase.UpdateSegmentPieces{
+				StreamID:      obj.StreamID,
+				Position:      metabase.SegmentPosition{Index: 0},
+				OldPieces:     validPieces,
+				NewRedundancy: newRedundancy,
+				NewPieces: metabase.Pieces{
+					metabase.Piece{
+						Number:      1,
+						StorageNode: testrand.NodeID(),
 					},
 				},
-				ErrClass: &storage.ErrValueChanged,
-				ErrText:  "segment remote_alias_pieces field was changed",
-			}.Check(ctx, t, db)
+				// repair metadata isn't changed either.
+				NewRepairedAt: now.Add(time.Hour),
+			})
+			require.True(t, storage.ErrValueChanged.Has(err))
+			require.EqualError(t, err, storage.ErrValueChanged.New("segment remote_alias_pieces field was changed").Error())
+			require.Nil(t, result.RepairedAt)
+			require.Zero(t, result.RepairCount)
 
 			// verify that original pieces and redundancy did not change
 			metabasetest.Verify{
@@ -286,6 +334,122 @@ func TestUpdateSegmentPieces(t *testing.T) {
 			}.Check(ctx, t, db)
 		})
 
+		t.Run("concurrent update", func(t *testing.T) {
+			defer metabasetest.DeleteAll{}.Check(ctx, t, db)
+
+			object := metabasetest.CreateObject(ctx, t, db, obj, 1)
+			oldPieces := metabase.Pieces{{Number: 0, StorageNode: storj.NodeID{2}}}
+
+			winner := metabase.Pieces{{Number: 1, StorageNode: testrand.NodeID()}}
+			_, err := db.UpdateSegmentPieces(ctx, metabase.UpdateSegmentPieces{
+				StreamID:      object.StreamID,
+				Position:      metabase.SegmentPosition{Index: 0},
+				OldPieces:     oldPieces,
+				NewRedundancy: metabasetest.DefaultRedundancy,
+				NewPieces:     winner,
+			})
+			require.NoError(t, err)
+
+			loser := metabase.Pieces{{Number: 2, StorageNode: testrand.NodeID()}}
+			_, err = db.UpdateSegmentPieces(ctx, metabase.UpdateSegmentPieces{
+				StreamID:      object.StreamID,
+				Position:      metabase.SegmentPosition{Index: 0},
+				OldPieces:     oldPieces,
+				NewRedundancy: metabasetest.DefaultRedundancy,
+				NewPieces:     loser,
+			})
+			require.True(t, storage.ErrValueChanged.Has(err))
+
+			var changed *metabase.PiecesChangedError
+			require.True(t, errors.As(err, &changed))
+			require.Equal(t, winner, changed.CurrentPieces)
+
+			// the update succeeds when it's based on the current pieces.
+			newPieces, err := changed.CurrentPieces.Update(loser, nil)
+			require.NoError(t, err)
+			_, err = db.UpdateSegmentPieces(ctx, metabase.UpdateSegmentPieces{
+				StreamID:      object.StreamID,
+				Position:      metabase.SegmentPosition{Index: 0},
+				OldPieces:     changed.CurrentPieces,
+				NewRedundancy: metabasetest.DefaultRedundancy,
+				NewPieces:     newPieces,
+			})
+			require.NoError(t, err)
+
+			segment, err := db.GetSegmentByPosition(ctx, metabase.GetSegmentByPosition{
+				StreamID: object.StreamID,
+				Position: metabase.SegmentPosition{Index: 0},
+			})
+			require.NoError(t, err)
+			require.Equal(t, metabase.Pieces{winner[0], loser[0]}, segment.Pieces)
+		})
+
+		t.Run("pieces counts", func(t *testing.T) {
+			defer metabasetest.DeleteAll{}.Check(ctx, t, db)
+
+			object := metabasetest.CreateObject(ctx, t, db, obj, 1)
+
+			initial := metabase.Pieces{{Number: 0, StorageNode: storj.NodeID{2}}}
+			added := metabase.Pieces{
+				{Number: 0, StorageNode: storj.NodeID{2}},
+				{Number: 1, StorageNode: testrand.NodeID()},
+				{Number: 2, StorageNode: testrand.NodeID()},
+			}
+			removed := metabase.Pieces{
+				{Number: 0, StorageNode: storj.NodeID{2}},
+				{Number: 2, StorageNode: added[2].StorageNode},
+			}
+			mixed := metabase.Pieces{
+				{Number: 2, StorageNode: added[2].StorageNode},
+				{Number: 3, StorageNode: testrand.NodeID()},
+				{Number: 4, StorageNode: testrand.NodeID()},
+			}
+
+			for _, tt := range []struct {
+				name      string
+				oldPieces metabase.Pieces
+				newPieces metabase.Pieces
+				before    int
+				after     int
+			}{
+				{"add only", initial, added, 1, 3},
+				{"remove only", added, removed, 3, 2},
+				{"mixed", removed, mixed, 2, 3},
+			} {
+				result, err := db.UpdateSegmentPieces(ctx, metabase.UpdateSegmentPieces{
+					StreamID:      object.StreamID,
+					Position:      metabase.SegmentPosition{Index: 0},
+					OldPieces:     tt.oldPieces,
+					NewRedundancy: metabasetest.DefaultRedundancy,
+					NewPieces:     tt.newPieces,
+				})
+				require.NoError(t, err, tt.name)
+				require.Equal(t, metabase.UpdateSegmentPiecesResult{
+					StreamID:     object.StreamID,
+					Status:       metabase.UpdateSegmentPiecesUpdated,
+					PiecesBefore: tt.before,
+					PiecesAfter:  tt.after,
+				}, result, tt.name)
+			}
+
+			// a failed update reports the current number of pieces.
+			result, err := db.UpdateSegmentPieces(ctx, metabase.UpdateSegmentPieces{
+				StreamID:      object.StreamID,
+				Position:      metabase.SegmentPosition{Index: 0},
+				OldPieces:     initial,
+				NewRedundancy: metabasetest.DefaultRedundancy,
+				NewPieces:     added,
+			})
+			require.True(t, storage.ErrValueChanged.Has(err))
+			require.Equal(t, metabase.UpdateSegmentPiecesResult{
+				StreamID:      object.StreamID,
+				Status:        metabase.UpdateSegmentPiecesChanged,
+				CurrentPieces: mixed,
+				PiecesBefore:  3,
+				PiecesAfter:   3,
+			}, result)
+		})
+
 		t.Run("update pieces", func(t *testing.T) {
 			defer metabasetest.DeleteAll{}.Check(ctx, t, db)
 
@@ -364,20 +528,23 @@ func TestUpdateSegmentPieces(t *testing.T) {
 			}
 
 			repairedAt := now.Add(time.Hour)
-			metabasetest.UpdateSegmentPieces{
-				Opts: metabase.UpdateSegmentPieces{
-					StreamID:      obj.StreamID,
-					Position:      metabase.SegmentPosition{Index: 0},
-					OldPieces:     segment.Pieces,
-					NewRedundancy: segment.Redundancy,
-					NewPieces:     expectedPieces,
-					NewRepairedAt: repairedAt,
-				},
-			}.Check(ctx, t, db)
+			result, err := db.UpdateSegmentPieces(ctx, metabase.UpdateSegmentPieces{
+				StreamID:      obj.StreamID,
+				Position:      metabase.SegmentPosition{Index: 0},
+				OldPieces:     segment.Pieces,
+				NewRedundancy: segment.Redundancy,
+				NewPieces:     expectedPieces,
+				NewRepairedAt: repairedAt,
+			})
+			require.NoError(t, err)
+			require.NotNil(t, result.RepairedAt)
+			require.WithinDuration(t, repairedAt, *result.RepairedAt, time.Second)
+			require.EqualValues(t, 1, result.RepairCount)
 
 			expectedSegment := segment
 			expectedSegment.Pieces = expectedPieces
 			expectedSegment.RepairedAt = &repairedAt
+			expectedSegment.RepairCount = 1
 
 			segment, err = db.GetSegmentByLocation(ctx, metabase.GetSegmentByLocation{
 				SegmentLocation: metabase.SegmentLocation{
@@ -429,3 +596,301 @@ func TestUpdateSegmentPieces(t *testing.T) {
 		})
 	})
 }
+
+func TestUpdateSegmentPiecesBatch(t *testing.T) {
+	metabasetest.Run(t, func(ctx *testcontext.Context, t *testing.T, db *metabase.DB) {
+		// segments created by CreateObject are stored on this node.
+		oldPieces := metabase.Pieces{{Number: 0, StorageNode: storj.NodeID{2}}}
+
+		t.Run("invalid update", func(t *testing.T) {
+			defer metabasetest.DeleteAll{}.Check(ctx, t, db)
+
+			_, err := db.UpdateSegmentPiecesBatch(ctx, []metabase.UpdateSegmentPieces{
+				{
+					StreamID:      testrand.UUID(),
+					OldPieces:     oldPieces,
+					NewRedundancy: metabasetest.DefaultRedundancy,
+					NewPieces:     metabase.Pieces{{Number: 1, StorageNode: testrand.NodeID()}},
+				},
+				{
+					StreamID:      testrand.UUID(),
+					OldPieces:     oldPieces,
+					NewRedundancy: metabasetest.DefaultRedundancy,
+				},
+			})
+			require.True(t, metabase.ErrInvalidRequest.Has(err))
+			require.EqualError(t, err, metabase.ErrInvalidRequest.New("update 1: NewPieces: pieces missing").Error())
+		})
+
+		t.Run("no updates", func(t *testing.T) {
+			results, err := db.UpdateSegmentPiecesBatch(ctx, nil)
+			require.NoError(t, err)
+			require.Empty(t, results)
+		})
+
+		t.Run("partial success", func(t *testing.T) {
+			defer metabasetest.DeleteAll{}.Check(ctx, t, db)
+
+			updated := metabasetest.CreateObject(ctx, t, db, metabasetest.RandObjectStream(), 1)
+			raced := metabasetest.CreateObject(ctx, t, db, metabasetest.RandObjectStream(), 1)
+			missing := metabasetest.RandObjectStream()
+
+			// another process modifies the pieces of the raced segment first.
+			racedPieces := metabase.Pieces{{Number: 3, StorageNode: testrand.NodeID()}}
+			_, err := db.UpdateSegmentPieces(ctx, metabase.UpdateSegmentPieces{
+				StreamID:      raced.StreamID,
+				OldPieces:     oldPieces,
+				NewRedundancy: metabasetest.DefaultRedundancy,
+				NewPieces:     racedPieces,
+			})
+			require.NoError(t, err)
+
+			newPieces := metabase.Pieces{{Number: 1, StorageNode: testrand.NodeID()}}
+			results, err := db.UpdateSegmentPiecesBatch(ctx, []metabase.UpdateSegmentPieces{
+				{
+					StreamID:      updated.StreamID,
+					OldPieces:     oldPieces,
+					NewRedundancy: metabasetest.DefaultRedundancy,
+					NewPieces:     newPieces,
+				},
+				{
+					StreamID:      raced.StreamID,
+					OldPieces:     oldPieces,
+					NewRedundancy: metabasetest.DefaultRedundancy,
+					NewPieces:     newPieces,
+				},
+				{
+					StreamID:      missing.StreamID,
+					OldPieces:     oldPieces,
+					NewRedundancy: metabasetest.DefaultRedundancy,
+					NewPieces:     newPieces,
+				},
+			})
+			require.NoError(t, err)
+			require.Equal(t, []metabase.UpdateSegmentPiecesResult{
+				{StreamID: updated.StreamID, Status: metabase.UpdateSegmentPiecesUpdated, PiecesBefore: 1, PiecesAfter: 1},
+				{StreamID: raced.StreamID, Status: metabase.UpdateSegmentPiecesChanged, CurrentPieces: racedPieces, PiecesBefore: 1, PiecesAfter: 1},
+				{StreamID: missing.StreamID, Status: metabase.UpdateSegmentPiecesNotFound},
+			}, results)
+
+			for _, expected := range []struct {
+				streamID uuid.UUID
+				pieces   metabase.Pieces
+			}{
+				{updated.StreamID, newPieces},
+				{raced.StreamID, racedPieces},
+			} {
+				segment, err := db.GetSegmentByPosition(ctx, metabase.GetSegmentByPosition{
+					StreamID: expected.streamID,
+				})
+				require.NoError(t, err)
+				require.Equal(t, expected.pieces, segment.Pieces)
+			}
+		})
+	})
+}
+
+// fakeQuerier fails the queries with the queued errors, before it passes them to the database.
+type fakeQuerier struct {
+	errs  []error
+	calls int
+}
+
+func (querier *fakeQuerier) wrap(next metabase.RowQuerier) metabase.RowQuerier {
+	return func(ctx context.Context, query string, args ...interface{}) metabase.RowScanner {
+		querier.calls++
+		if len(querier.errs) == 0 {
+			return next(ctx, query, args...)
+		}
+		err := querier.errs[0]
+		querier.errs = querier.errs[1:]
+		return errRow{err: err}
+	}
+}
+
+// errRow is a row which fails to scan with err.
+type errRow struct{ err error }
+
+func (row errRow) Scan(dest ...interface{}) error { return row.err }
+
+func TestUpdateSegmentPiecesRetry(t *testing.T) {
+	metabasetest.Run(t, func(ctx *testcontext.Context, t *testing.T, db *metabase.DB) {
+		// segments created by CreateObject are stored on this node.
+		oldPieces := metabase.Pieces{{Number: 0, StorageNode: storj.NodeID{2}}}
+		newPieces := metabase.Pieces{{Number: 1, StorageNode: testrand.NodeID()}}
+
+		serializationFailure := func() error {
+			return &pgconn.PgError{Code: pgxerrcode.SerializationFailure}
+		}
+
+		// race modifies the pieces of the segment as if another process updated them first.
+		race := func(t *testing.T, obj metabase.ObjectStream) metabase.Pieces {
+			racedPieces := metabase.Pieces{{Number: 3, StorageNode: testrand.NodeID()}}
+			_, err := db.UpdateSegmentPieces(ctx, metabase.UpdateSegmentPieces{
+				StreamID:      obj.StreamID,
+				OldPieces:     oldPieces,
+				NewRedundancy: metabasetest.DefaultRedundancy,
+				NewPieces:     racedPieces,
+			})
+			require.NoError(t, err)
+			return racedPieces
+		}
+
+		t.Run("single update", func(t *testing.T) {
+			defer metabasetest.DeleteAll{}.Check(ctx, t, db)
+			defer db.TestingWrapRowQuerier(nil)
+
+			obj := metabasetest.CreateObject(ctx, t, db, metabasetest.RandObjectStream(), 1)
+			racedPieces := race(t, obj.ObjectStream)
+
+			querier := &fakeQuerier{errs: []error{serializationFailure(), serializationFailure()}}
+			db.TestingWrapRowQuerier(querier.wrap)
+
+			result, err := db.UpdateSegmentPieces(ctx, metabase.UpdateSegmentPieces{
+				StreamID:      obj.StreamID,
+				OldPieces:     oldPieces,
+				NewRedundancy: metabasetest.DefaultRedundancy,
+				NewPieces:     newPieces,
+			})
+			require.Equal(t, 3, querier.calls)
+
+			var changed *metabase.PiecesChangedError
+			require.True(t, errors.As(err, &changed))
+			require.Equal(t, racedPieces, changed.CurrentPieces)
+			require.Equal(t, metabase.UpdateSegmentPiecesChanged, result.Status)
+		})
+
+		t.Run("batch", func(t *testing.T) {
+			defer metabasetest.DeleteAll{}.Check(ctx, t, db)
+			defer db.TestingWrapRowQuerier(nil)
+
+			updated := metabasetest.CreateObject(ctx, t, db, metabasetest.RandObjectStream(), 1)
+			raced := metabasetest.CreateObject(ctx, t, db, metabasetest.RandObjectStream(), 1)
+			racedPieces := race(t, raced.ObjectStream)
+
+			querier := &fakeQuerier{errs: []error{serializationFailure(), serializationFailure()}}
+			db.TestingWrapRowQuerier(querier.wrap)
+
+			results, err := db.UpdateSegmentPiecesBatch(ctx, []metabase.UpdateSegmentPieces{
+				{
+					StreamID:      updated.StreamID,
+					OldPieces:     oldPieces,
+					NewRedundancy: metabasetest.DefaultRedundancy,
+					NewPieces:     newPieces,
+				},
+				{
+					StreamID:      raced.StreamID,
+					OldPieces:     oldPieces,
+					NewRedundancy: metabasetest.DefaultRedundancy,
+					NewPieces:     newPieces,
+				},
+			})
+			require.NoError(t, err)
+			// two failed attempts of the first update and both updates of the last attempt.
+			require.Equal(t, 4, querier.calls)
+			require.Equal(t, []metabase.UpdateSegmentPiecesResult{
+				{StreamID: updated.StreamID, Status: metabase.UpdateSegmentPiecesUpdated, PiecesBefore: 1, PiecesAfter: 1},
+				{StreamID: raced.StreamID, Status: metabase.UpdateSegmentPiecesChanged, CurrentPieces: racedPieces, PiecesBefore: 1, PiecesAfter: 1},
+			}, results)
+
+			segment, err := db.GetSegmentByPosition(ctx, metabase.GetSegmentByPosition{
+				StreamID: updated.StreamID,
+			})
+			require.NoError(t, err)
+			require.Equal(t, newPieces, segment.Pieces)
+		})
+	})
+}
+
+func TestRemoveSegmentPieces(t *testing.T) {
+	metabasetest.Run(t, func(ctx *testcontext.Context, t *testing.T, db *metabase.DB) {
+		t.Run("invalid request", func(t *testing.T) {
+			defer metabasetest.DeleteAll{}.Check(ctx, t, db)
+
+			piece := metabase.Piece{Number: 0, StorageNode: testrand.NodeID()}
+			for _, tt := range []struct {
+				opts    metabase.RemoveSegmentPieces
+				errText string
+			}{
+				{metabase.RemoveSegmentPieces{Pieces: metabase.Pieces{piece}}, "StreamID missing"},
+				{metabase.RemoveSegmentPieces{StreamID: testrand.UUID()}, "Pieces missing"},
+				{metabase.RemoveSegmentPieces{StreamID: testrand.UUID(), Pieces: metabase.Pieces{piece}, MinimumPieces: -1}, "MinimumPieces negative"},
+			} {
+				_, err := db.RemoveSegmentPieces(ctx, tt.opts)
+				require.True(t, metabase.ErrInvalidRequest.Has(err), tt.errText)
+				require.EqualError(t, err, metabase.ErrInvalidRequest.New(tt.errText).Error())
+			}
+		})
+
+		t.Run("segment not found", func(t *testing.T) {
+			defer metabasetest.DeleteAll{}.Check(ctx, t, db)
+
+			_, err := db.RemoveSegmentPieces(ctx, metabase.RemoveSegmentPieces{
+				StreamID: testrand.UUID(),
+				Pieces:   metabase.Pieces{{Number: 0, StorageNode: testrand.NodeID()}},
+			})
+			require.True(t, metabase.ErrSegmentNotFound.Has(err))
+		})
+
+		t.Run("remove pieces", func(t *testing.T) {
+			defer metabasetest.DeleteAll{}.Check(ctx, t, db)
+
+			object := metabasetest.CreateObject(ctx, t, db, metabasetest.RandObjectStream(), 1)
+
+			pieces := metabase.Pieces{
+				{Number: 0, StorageNode: testrand.NodeID()},
+				{Number: 1, StorageNode: testrand.NodeID()},
+				{Number: 2, StorageNode: testrand.NodeID()},
+			}
+			_, err := db.UpdateSegmentPieces(ctx, metabase.UpdateSegmentPieces{
+				StreamID:      object.StreamID,
+				OldPieces:     metabase.Pieces{{Number: 0, StorageNode: storj.NodeID{2}}},
+				NewRedundancy: metabasetest.DefaultRedundancy,
+				NewPieces:     pieces,
+			})
+			require.NoError(t, err)
+
+			getPieces := func() metabase.Pieces {
+				segment, err := db.GetSegmentByPosition(ctx, metabase.GetSegmentByPosition{
+					StreamID: object.StreamID,
+				})
+				require.NoError(t, err)
+				return segment.Pieces
+			}
+
+			// pieces which the segment doesn't have are ignored.
+			count, err := db.RemoveSegmentPieces(ctx, metabase.RemoveSegmentPieces{
+				StreamID: object.StreamID,
+				Pieces: metabase.Pieces{
+					{Number: 1, StorageNode: testrand.NodeID()},
+					{Number: 3, StorageNode: testrand.NodeID()},
+				},
+			})
+			require.NoError(t, err)
+			require.Equal(t, 3, count)
+			require.Equal(t, pieces, getPieces())
+
+			count, err = db.RemoveSegmentPieces(ctx, metabase.RemoveSegmentPieces{
+				StreamID: object.StreamID,
+				Pieces: metabase.Pieces{
+					pieces[1],
+					{Number: 3, StorageNode: testrand.NodeID()},
+				},
+				MinimumPieces: 2,
+			})
+			require.NoError(t, err)
+			require.Equal(t, 2, count)
+			require.Equal(t, metabase.Pieces{pieces[0], pieces[2]}, getPieces())
+
+			// the segment would have less than the minimum.
+			count, err = db.RemoveSegmentPieces(ctx, metabase.RemoveSegmentPieces{
+				StreamID:      object.StreamID,
+				Pieces:        metabase.Pieces{pieces[0]},
+				MinimumPieces: 2,
+			})
+			require.True(t, metabase.ErrTooFewPieces.Has(err))
+			require.Equal(t, 2, count)
+			require.Equal(t, metabase.Pieces{pieces[0], pieces[2]}, getPieces())
+		})
+	})
+}
@@ -236,7 +236,7 @@ func TestQueryAttribution(t *testing.T) {
 			before := now.Add(-time.Hour)
 			after := before.Add(2 * time.Hour)
 
-			usage, err := planet.Satellites[0].DB.ProjectAccounting().GetProjectTotal(ctx, satProject.ID, before, after)
+			usage, err := planet.Satellites[0].DB.ProjectAccounting().GetProjectTotal(ctx, satProject.ID, before, after, 0)
 			require.NoError(t, err)
 			require.NotZero(t, usage.Egress)
 
@@ -307,7 +307,7 @@ func TestAttributionReport(t *testing.T) {
 
 			projectID := up.Projects[0].ID
 
-			usage, err := planet.Satellites[0].DB.ProjectAccounting().GetProjectTotal(ctx, projectID, before, after)
+			usage, err := planet.Satellites[0].DB.ProjectAccounting().GetProjectTotal(ctx, projectID, before, after, 0)
 			require.NoError(t, err)
 			require.NotZero(t, usage.Egress)
 
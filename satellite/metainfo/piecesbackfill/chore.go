@@ -0,0 +1,102 @@
+// Copyright (C) 2021 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package piecesbackfill
+
+import (
+	"context"
+	"time"
+
+	"github.com/spacemonkeygo/monkit/v3"
+	"github.com/zeebo/errs"
+	"go.uber.org/zap"
+
+	"storj.io/common/sync2"
+	"storj.io/storj/satellite/metabase"
+)
+
+var (
+	// Error defines the piecesbackfill chore errors class.
+	Error = errs.Class("pieces backfill")
+	mon   = monkit.Package()
+)
+
+// Config contains configurable values for the segment pieces backfill.
+type Config struct {
+	Interval  time.Duration `help:"the time between attempts to backfill segment pieces columns, until the backfill completes" releaseDefault:"1h" devDefault:"10s" testDefault:"$TESTINTERVAL"`
+	Enabled   bool          `help:"set if segment pieces columns backfill is enabled or not" releaseDefault:"true" devDefault:"true"`
+	BatchSize int           `help:"how many segments are backfilled in a single batch" default:"1000"`
+}
+
+// Chore implements the segment pieces backfill chore.
+//
+// architecture: Chore
+type Chore struct {
+	log      *zap.Logger
+	config   Config
+	metabase *metabase.DB
+
+	cursor metabase.BackfillSegmentPiecesCursor
+	done   bool
+
+	Loop *sync2.Cycle
+}
+
+// NewChore creates a new instance of the piecesbackfill chore.
+func NewChore(log *zap.Logger, config Config, metabase *metabase.DB) *Chore {
+	return &Chore{
+		log:      log,
+		config:   config,
+		metabase: metabase,
+
+		Loop: sync2.NewCycle(config.Interval),
+	}
+}
+
+// Run starts the piecesbackfill loop service.
+func (chore *Chore) Run(ctx context.Context) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	if !chore.config.Enabled {
+		return nil
+	}
+
+	return chore.Loop.Run(ctx, chore.backfill)
+}
+
+// Close stops the piecesbackfill chore.
+func (chore *Chore) Close() error {
+	chore.Loop.Close()
+	return nil
+}
+
+// backfill continues the backfill from the last processed segment until the end of the
+// segments table. A failed backfill is continued in the next cycle.
+func (chore *Chore) backfill(ctx context.Context) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	if chore.done {
+		return nil
+	}
+
+	for {
+		result, err := chore.metabase.BackfillSegmentPieces(ctx, metabase.BackfillSegmentPieces{
+			Cursor:    chore.cursor,
+			BatchSize: chore.config.BatchSize,
+		})
+		if err != nil {
+			chore.log.Error("backfilling segment pieces failed", zap.Error(err))
+			return nil
+		}
+
+		mon.IntVal("backfilled_segment_pieces").Observe(int64(result.Updated))
+		chore.cursor = result.Cursor
+		if !result.More {
+			break
+		}
+	}
+
+	chore.done = true
+	chore.log.Info("segment pieces backfill completed")
+	return nil
+}
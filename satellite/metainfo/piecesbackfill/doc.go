@@ -0,0 +1,13 @@
+// Copyright (C) 2021 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+/*
+Package piecesbackfill contains the chore, which backfills the columns derived
+from the pieces of a segment (remote_alias_nodes and pieces_count) for segments
+committed before the columns were added.
+
+Node segment listing and repair queries rely on these columns. The chore walks
+the segments table once after the satellite starts and stops when it reaches the
+end, so that the backfill doesn't block the database migration.
+*/
+package piecesbackfill
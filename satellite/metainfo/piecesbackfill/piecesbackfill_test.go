@@ -0,0 +1,61 @@
+// Copyright (C) 2021 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package piecesbackfill_test
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"storj.io/common/memory"
+	"storj.io/common/testcontext"
+	"storj.io/common/testrand"
+	"storj.io/storj/private/testplanet"
+	"storj.io/storj/satellite/metabase"
+	"storj.io/storj/satellite/metainfo/piecesbackfill"
+)
+
+func TestPiecesBackfill(t *testing.T) {
+	testplanet.Run(t, testplanet.Config{
+		SatelliteCount: 1, StorageNodeCount: 4, UplinkCount: 1,
+		Reconfigure: testplanet.Reconfigure{
+			Satellite: testplanet.ReconfigureRS(2, 2, 4, 4),
+		},
+	}, func(t *testing.T, ctx *testcontext.Context, planet *testplanet.Planet) {
+		satellite := planet.Satellites[0]
+		metabaseDB := satellite.Metainfo.Metabase
+
+		for i := 0; i < 3; i++ {
+			err := planet.Uplinks[0].Upload(ctx, satellite, "testbucket", "remote"+strconv.Itoa(i), testrand.Bytes(8*memory.KiB))
+			require.NoError(t, err)
+		}
+
+		// simulate segments committed before the columns were added.
+		_, err := metabaseDB.UnderlyingTagSQL().ExecContext(ctx, `UPDATE segments SET remote_alias_nodes = NULL, pieces_count = NULL`)
+		require.NoError(t, err)
+
+		listed := func() int {
+			result, err := metabaseDB.ListNodeSegments(ctx, metabase.ListNodeSegments{
+				NodeID: planet.StorageNodes[0].ID(),
+			})
+			require.NoError(t, err)
+			return len(result.Segments)
+		}
+		require.Zero(t, listed())
+
+		chore := piecesbackfill.NewChore(zaptest.NewLogger(t), piecesbackfill.Config{
+			Interval:  time.Hour,
+			Enabled:   true,
+			BatchSize: 2,
+		}, metabaseDB)
+		ctx.Go(func() error { return chore.Run(ctx) })
+		defer ctx.Check(chore.Close)
+
+		chore.Loop.TriggerWait()
+		require.Equal(t, 3, listed())
+	})
+}
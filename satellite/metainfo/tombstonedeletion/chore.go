@@ -0,0 +1,93 @@
+// Copyright (C) 2021 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package tombstonedeletion
+
+import (
+	"context"
+	"time"
+
+	"github.com/spacemonkeygo/monkit/v3"
+	"github.com/zeebo/errs"
+	"go.uber.org/zap"
+
+	"storj.io/common/sync2"
+	"storj.io/storj/satellite/metabase"
+)
+
+var (
+	// Error defines the tombstonedeletion chore errors class.
+	Error = errs.Class("tombstone deletion")
+	mon   = monkit.Package()
+)
+
+// Config contains configurable values for segment tombstone cleanup.
+type Config struct {
+	Interval  time.Duration `help:"the time between each attempt to clean up old segment tombstones" releaseDefault:"24h" devDefault:"10s" testDefault:"$TESTINTERVAL"`
+	Enabled   bool          `help:"set if segment tombstone cleanup is enabled or not" releaseDefault:"true" devDefault:"true"`
+	Retention time.Duration `help:"how long segment tombstones are kept for the segment changes readers" default:"168h"`
+	BatchSize int           `help:"how many segment tombstones are deleted by a single statement" default:"1000"`
+}
+
+// Chore implements the segment tombstone cleanup chore.
+//
+// architecture: Chore
+type Chore struct {
+	log      *zap.Logger
+	config   Config
+	metabase *metabase.DB
+
+	nowFn func() time.Time
+	Loop  *sync2.Cycle
+}
+
+// NewChore creates a new instance of the tombstonedeletion chore.
+func NewChore(log *zap.Logger, config Config, metabase *metabase.DB) *Chore {
+	return &Chore{
+		log:      log,
+		config:   config,
+		metabase: metabase,
+
+		nowFn: time.Now,
+		Loop:  sync2.NewCycle(config.Interval),
+	}
+}
+
+// Run starts the tombstonedeletion loop service.
+func (chore *Chore) Run(ctx context.Context) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	if !chore.config.Enabled {
+		return nil
+	}
+
+	return chore.Loop.Run(ctx, chore.deleteTombstones)
+}
+
+// Close stops the tombstonedeletion chore.
+func (chore *Chore) Close() error {
+	chore.Loop.Close()
+	return nil
+}
+
+// SetNow allows tests to have the server act as if the current time is whatever they want.
+func (chore *Chore) SetNow(nowFn func() time.Time) {
+	chore.nowFn = nowFn
+}
+
+func (chore *Chore) deleteTombstones(ctx context.Context) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	deleted, err := chore.metabase.DeleteSegmentTombstones(ctx, metabase.DeleteSegmentTombstones{
+		DeletedBefore: chore.nowFn().Add(-chore.config.Retention),
+		BatchSize:     chore.config.BatchSize,
+	})
+	if err != nil {
+		chore.log.Error("deleting segment tombstones failed", zap.Error(err))
+		return nil
+	}
+
+	mon.IntVal("deleted_segment_tombstones").Observe(deleted)
+	chore.log.Debug("deleted segment tombstones", zap.Int64("count", deleted))
+	return nil
+}
@@ -0,0 +1,11 @@
+// Copyright (C) 2021 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+/*
+Package tombstonedeletion contains the chore, which deletes segment tombstones
+older than the retention of the segment pieces change feed.
+
+Tombstones record deleted segments for GetSegmentsChangedSince readers. Readers,
+which didn't catch up within the retention, have to do a full segment loop pass.
+*/
+package tombstonedeletion
@@ -0,0 +1,63 @@
+// Copyright (C) 2021 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package tombstonedeletion_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"storj.io/common/memory"
+	"storj.io/common/testcontext"
+	"storj.io/common/testrand"
+	"storj.io/storj/private/testplanet"
+	"storj.io/storj/satellite"
+	"storj.io/storj/satellite/metabase"
+)
+
+func TestTombstoneDeletion(t *testing.T) {
+	testplanet.Run(t, testplanet.Config{
+		SatelliteCount: 1, StorageNodeCount: 1, UplinkCount: 1,
+		Reconfigure: testplanet.Reconfigure{
+			Satellite: func(log *zap.Logger, index int, config *satellite.Config) {
+				config.TombstoneDeletion.Interval = 500 * time.Millisecond
+				config.TombstoneDeletion.Retention = time.Hour
+			},
+		},
+	}, func(t *testing.T, ctx *testcontext.Context, planet *testplanet.Planet) {
+		satellite := planet.Satellites[0]
+		upl := planet.Uplinks[0]
+		tombstoneChore := satellite.Core.TombstoneDeletion.Chore
+
+		tombstoneChore.Loop.Pause()
+
+		err := upl.Upload(ctx, satellite, "testbucket", "remote", testrand.Bytes(8*memory.KiB))
+		require.NoError(t, err)
+		err = upl.DeleteObject(ctx, satellite, "testbucket", "remote")
+		require.NoError(t, err)
+
+		tombstones := func() []metabase.SegmentPiecesChange {
+			watermark, err := satellite.Metainfo.Metabase.GetSegmentPiecesChangedWatermark(ctx)
+			require.NoError(t, err)
+
+			result, err := satellite.Metainfo.Metabase.GetSegmentsChangedSince(ctx, metabase.GetSegmentsChangedSince{
+				UpToSeq: watermark,
+			})
+			require.NoError(t, err)
+			return result.Changes
+		}
+
+		// tombstones within the retention are kept.
+		tombstoneChore.Loop.TriggerWait()
+		require.Len(t, tombstones(), 1)
+
+		tombstoneChore.SetNow(func() time.Time {
+			return time.Now().Add(2 * time.Hour)
+		})
+		tombstoneChore.Loop.TriggerWait()
+		require.Empty(t, tombstones())
+	})
+}
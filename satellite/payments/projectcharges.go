@@ -19,4 +19,6 @@ type ProjectCharge struct {
 	Egress int64 `json:"egressPrice"`
 	// ObjectCount shows how many cents we should pay for objects count.
 	ObjectCount int64 `json:"objectPrice"`
+	// Exempt shows that the project is exempt from billing, hence all its prices are zero.
+	Exempt bool `json:"exempt"`
 }
@@ -112,11 +112,26 @@ func (accounts *accounts) ProjectCharges(ctx context.Context, userID uuid.UUID,
 	}
 
 	for _, project := range projects {
-		usage, err := accounts.service.usageDB.GetProjectTotal(ctx, project.ID, since, before)
+		usage, err := accounts.service.usageDB.GetProjectTotal(ctx, project.ID, since, before, accounts.service.usageAsOfSystemInterval)
 		if err != nil {
 			return charges, Error.Wrap(err)
 		}
 
+		exempt, err := accounts.service.usageDB.GetProjectExemptFromBilling(ctx, project.ID)
+		if err != nil {
+			return charges, Error.Wrap(err)
+		}
+		if exempt {
+			// the usage is still reported, so that the project members can see it.
+			charges = append(charges, payments.ProjectCharge{
+				ProjectUsage: *usage,
+
+				ProjectID: project.ID,
+				Exempt:    true,
+			})
+			continue
+		}
+
 		projectPrice := accounts.service.calculateProjectUsagePrice(usage.Egress, usage.Storage, usage.ObjectCount)
 
 		charges = append(charges, payments.ProjectCharge{
@@ -141,7 +156,7 @@ func (accounts *accounts) CheckProjectInvoicingStatus(ctx context.Context, proje
 	year, month, _ := accounts.service.nowFn().UTC().Date()
 	firstOfMonth := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
 
-	currentUsage, err := accounts.service.usageDB.GetProjectTotal(ctx, projectID, firstOfMonth, accounts.service.nowFn())
+	currentUsage, err := accounts.service.usageDB.GetProjectTotal(ctx, projectID, firstOfMonth, accounts.service.nowFn(), 0)
 	if err != nil {
 		return false, err
 	}
@@ -150,7 +165,7 @@ func (accounts *accounts) CheckProjectInvoicingStatus(ctx context.Context, proje
 	}
 
 	// if usage of last month exist, make sure to look for billing records
-	lastMonthUsage, err := accounts.service.usageDB.GetProjectTotal(ctx, projectID, firstOfMonth.AddDate(0, -1, 0), firstOfMonth.AddDate(0, 0, -1))
+	lastMonthUsage, err := accounts.service.usageDB.GetProjectTotal(ctx, projectID, firstOfMonth.AddDate(0, -1, 0), firstOfMonth.AddDate(0, 0, -1), 0)
 	if err != nil {
 		return false, err
 	}
@@ -35,9 +35,6 @@ var (
 	mon = monkit.Package()
 )
 
-// hoursPerMonth is the number of months in a billing month. For the purpose of billing, the billing month is always 30 days.
-const hoursPerMonth = 24 * 30
-
 // Config stores needed information for payment service initialization.
 type Config struct {
 	StripeSecretKey              string        `help:"stripe API secret key" default:""`
@@ -50,6 +47,7 @@ type Config struct {
 	ConversionRatesCycleInterval time.Duration `help:"amount of time we wait before running next conversion rates update loop" default:"10m" testDefault:"$TESTINTERVAL"`
 	AutoAdvance                  bool          `help:"toogle autoadvance feature for invoice creation" default:"false"`
 	ListingLimit                 int           `help:"sets the maximum amount of items before we start paging on requests" default:"100" hidden:"true"`
+	UsageAsOfSystemInterval      time.Duration `help:"interval for AS OF SYSTEM TIME clause (crdb specific) used when reading project usage for invoices and charges" default:"-10s" testDefault:"-1µs"`
 }
 
 // Service is an implementation for payment service via Stripe and Coinpayments.
@@ -83,8 +81,9 @@ type Service struct {
 	rates    coinpayments.CurrencyRateInfos
 	ratesErr error
 
-	listingLimit int
-	nowFn        func() time.Time
+	listingLimit            int
+	usageAsOfSystemInterval time.Duration
+	nowFn                   func() time.Time
 }
 
 // NewService creates a Service instance.
@@ -133,6 +132,7 @@ func NewService(log *zap.Logger, stripeClient StripeClient, config Config, db DB
 		MinCoinPayment:           minCoinPayment,
 		AutoAdvance:              config.AutoAdvance,
 		listingLimit:             config.ListingLimit,
+		usageAsOfSystemInterval:  config.UsageAsOfSystemInterval,
 		nowFn:                    time.Now,
 	}, nil
 }
@@ -537,7 +537,16 @@ func (service *Service) createProjectRecords(ctx context.Context, customerID str
 			return 0, nil, err
 		}
 
-		usage, err := service.usageDB.GetProjectTotal(ctx, project.ID, start, end)
+		exempt, err := service.usageDB.GetProjectExemptFromBilling(ctx, project.ID)
+		if err != nil {
+			return 0, nil, err
+		}
+		if exempt {
+			service.log.Info("Project is exempt from billing.", zap.String("Customer ID", customerID), zap.String("Project ID", project.ID.String()))
+			continue
+		}
+
+		usage, err := service.usageDB.GetProjectTotal(ctx, project.ID, start, end, service.usageAsOfSystemInterval)
 		if err != nil {
 			return 0, nil, err
 		}
@@ -1010,7 +1019,7 @@ func (service *Service) SetNow(now func() time.Time) {
 // storageMBMonthDecimal converts storage usage from Byte-Hours to Megabyte-Months.
 // The result is rounded to the nearest whole number, but returned as Decimal for convenience.
 func storageMBMonthDecimal(storage float64) decimal.Decimal {
-	return decimal.NewFromFloat(storage).Shift(-6).Div(decimal.NewFromInt(hoursPerMonth)).Round(0)
+	return decimal.NewFromFloat(storage).Shift(-6).Div(decimal.NewFromInt(accounting.HoursPerMonth)).Round(0)
 }
 
 // egressMBDecimal converts egress usage from bytes to Megabytes
@@ -1022,5 +1031,5 @@ func egressMBDecimal(egress int64) decimal.Decimal {
 // objectMonthDecimal converts objects usage from Object-Hours to Object-Months.
 // The result is rounded to the nearest whole number, but returned as Decimal for convenience.
 func objectMonthDecimal(objects float64) decimal.Decimal {
-	return decimal.NewFromFloat(objects).Div(decimal.NewFromInt(hoursPerMonth)).Round(0)
+	return decimal.NewFromFloat(objects).Div(decimal.NewFromInt(accounting.HoursPerMonth)).Round(0)
 }
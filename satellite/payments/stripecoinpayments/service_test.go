@@ -96,6 +96,105 @@ func TestService_InvoiceElementsProcessing(t *testing.T) {
 	})
 }
 
+func TestService_InvoiceSkipsExemptProjects(t *testing.T) {
+	testplanet.Run(t, testplanet.Config{
+		SatelliteCount: 1, StorageNodeCount: 0, UplinkCount: 0,
+	}, func(t *testing.T, ctx *testcontext.Context, planet *testplanet.Planet) {
+		satellite := planet.Satellites[0]
+
+		period := time.Date(time.Now().Year(), time.Now().Month()+1, 20, 0, 0, 0, 0, time.UTC)
+
+		user, err := satellite.AddUser(ctx, console.CreateUser{
+			FullName: "testuser",
+			Email:    "user@test",
+		}, 2)
+		require.NoError(t, err)
+
+		billed, err := satellite.AddProject(ctx, user.ID, "billed")
+		require.NoError(t, err)
+		exempt, err := satellite.AddProject(ctx, user.ID, "exempt")
+		require.NoError(t, err)
+
+		err = satellite.DB.ProjectAccounting().UpdateProjectExemptFromBilling(ctx, exempt.ID, true)
+		require.NoError(t, err)
+
+		for _, project := range []*console.Project{billed, exempt} {
+			err = satellite.DB.Orders().UpdateBucketBandwidthSettle(ctx, project.ID, []byte("testbucket"),
+				pb.PieceAction_GET, 10*memory.GiB.Int64(), period)
+			require.NoError(t, err)
+		}
+
+		satellite.API.Payments.Service.SetNow(func() time.Time {
+			return time.Date(period.Year(), period.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+		})
+		err = satellite.API.Payments.Service.PrepareInvoiceProjectRecords(ctx, period)
+		require.NoError(t, err)
+
+		start := time.Date(period.Year(), period.Month(), 1, 0, 0, 0, 0, time.UTC)
+		end := time.Date(period.Year(), period.Month()+1, 0, 0, 0, 0, 0, time.UTC)
+
+		recordsPage, err := satellite.DB.StripeCoinPayments().ProjectRecords().ListUnapplied(ctx, 0, 40, start, end)
+		require.NoError(t, err)
+		require.Len(t, recordsPage.Records, 1)
+		require.Equal(t, billed.ID, recordsPage.Records[0].ProjectID)
+	})
+}
+
+func TestService_ProjectChargesOfExemptProjects(t *testing.T) {
+	testplanet.Run(t, testplanet.Config{
+		SatelliteCount: 1, StorageNodeCount: 0, UplinkCount: 0,
+	}, func(t *testing.T, ctx *testcontext.Context, planet *testplanet.Planet) {
+		satellite := planet.Satellites[0]
+
+		period := time.Date(time.Now().Year(), time.Now().Month()+1, 20, 0, 0, 0, 0, time.UTC)
+
+		user, err := satellite.AddUser(ctx, console.CreateUser{
+			FullName: "testuser",
+			Email:    "user@test",
+		}, 2)
+		require.NoError(t, err)
+
+		billed, err := satellite.AddProject(ctx, user.ID, "billed")
+		require.NoError(t, err)
+		exempt, err := satellite.AddProject(ctx, user.ID, "exempt")
+		require.NoError(t, err)
+
+		err = satellite.DB.ProjectAccounting().UpdateProjectExemptFromBilling(ctx, exempt.ID, true)
+		require.NoError(t, err)
+
+		for _, project := range []*console.Project{billed, exempt} {
+			err = satellite.DB.Orders().UpdateBucketBandwidthSettle(ctx, project.ID, []byte("testbucket"),
+				pb.PieceAction_GET, 10*memory.GiB.Int64(), period)
+			require.NoError(t, err)
+		}
+
+		start := time.Date(period.Year(), period.Month(), 1, 0, 0, 0, 0, time.UTC)
+		end := time.Date(period.Year(), period.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+
+		charges, err := satellite.API.Payments.Accounts.ProjectCharges(ctx, user.ID, start, end)
+		require.NoError(t, err)
+		require.Len(t, charges, 2)
+
+		for _, charge := range charges {
+			// the usage is reported for both projects.
+			require.Equal(t, 10*memory.GiB.Int64(), charge.ProjectUsage.Egress)
+
+			switch charge.ProjectID {
+			case billed.ID:
+				require.False(t, charge.Exempt)
+				require.NotZero(t, charge.Egress)
+			case exempt.ID:
+				require.True(t, charge.Exempt)
+				require.Zero(t, charge.Egress)
+				require.Zero(t, charge.StorageGbHrs)
+				require.Zero(t, charge.ObjectCount)
+			default:
+				t.Fatalf("unexpected project %s", charge.ProjectID)
+			}
+		}
+	})
+}
+
 func TestService_InvoiceUserWithManyProjects(t *testing.T) {
 	testplanet.Run(t, testplanet.Config{
 		SatelliteCount: 1, StorageNodeCount: 0, UplinkCount: 0,
@@ -154,10 +253,10 @@ func TestService_InvoiceUserWithManyProjects(t *testing.T) {
 			tallies := map[metabase.BucketLocation]*accounting.BucketTally{
 				{}: tally,
 			}
-			err = satellite.DB.ProjectAccounting().SaveTallies(ctx, period, tallies)
+			_, _, err = satellite.DB.ProjectAccounting().SaveTallies(ctx, period, tallies)
 			require.NoError(t, err)
 
-			err = satellite.DB.ProjectAccounting().SaveTallies(ctx, period.Add(time.Duration(storageHours)*time.Hour), tallies)
+			_, _, err = satellite.DB.ProjectAccounting().SaveTallies(ctx, period.Add(time.Duration(storageHours)*time.Hour), tallies)
 			require.NoError(t, err)
 
 			// verify that projects don't have records yet
@@ -265,10 +364,10 @@ func TestService_InvoiceUserWithManyCoupons(t *testing.T) {
 			tallies := map[metabase.BucketLocation]*accounting.BucketTally{
 				{}: tally,
 			}
-			err = satellite.DB.ProjectAccounting().SaveTallies(ctx, period, tallies)
+			_, _, err = satellite.DB.ProjectAccounting().SaveTallies(ctx, period, tallies)
 			require.NoError(t, err)
 
-			err = satellite.DB.ProjectAccounting().SaveTallies(ctx, period.Add(time.Duration(storageHours)*time.Hour), tallies)
+			_, _, err = satellite.DB.ProjectAccounting().SaveTallies(ctx, period.Add(time.Duration(storageHours)*time.Hour), tallies)
 			require.NoError(t, err)
 		}
 
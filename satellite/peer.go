@@ -32,6 +32,8 @@ import (
 	"storj.io/storj/satellite/mailservice"
 	"storj.io/storj/satellite/metainfo"
 	"storj.io/storj/satellite/metainfo/expireddeletion"
+	"storj.io/storj/satellite/metainfo/piecesbackfill"
+	"storj.io/storj/satellite/metainfo/tombstonedeletion"
 	"storj.io/storj/satellite/metrics"
 	"storj.io/storj/satellite/nodeapiversion"
 	"storj.io/storj/satellite/orders"
@@ -126,7 +128,9 @@ type Config struct {
 
 	GarbageCollection gc.Config
 
-	ExpiredDeletion expireddeletion.Config
+	ExpiredDeletion   expireddeletion.Config
+	TombstoneDeletion tombstonedeletion.Config
+	PiecesBackfill    piecesbackfill.Config
 
 	Tally            tally.Config
 	Rollup           rollup.Config
@@ -387,7 +387,7 @@ func (repairer *SegmentRepairer) Repair(ctx context.Context, queueSegment *queue
 		return false, repairPutError.Wrap(err)
 	}
 
-	err = repairer.metabase.UpdateSegmentPieces(ctx, metabase.UpdateSegmentPieces{
+	_, err = repairer.metabase.UpdateSegmentPieces(ctx, metabase.UpdateSegmentPieces{
 		StreamID: segment.StreamID,
 		Position: segment.Position,
 
@@ -397,7 +397,36 @@ func (repairer *SegmentRepairer) Repair(ctx context.Context, queueSegment *queue
 
 		NewRepairedAt: time.Now(),
 	})
+	var changed *metabase.PiecesChangedError
+	if errors.As(err, &changed) {
+		// the pieces were modified concurrently, e.g. by graceful exit, apply
+		// the repair to the current pieces once more.
+		mon.Meter("repair_pieces_changed").Mark(1)
+
+		newPieces, err = changed.CurrentPieces.Update(repairedPieces, toRemove)
+		if err != nil {
+			return false, repairPutError.Wrap(err)
+		}
+
+		_, err = repairer.metabase.UpdateSegmentPieces(ctx, metabase.UpdateSegmentPieces{
+			StreamID: segment.StreamID,
+			Position: segment.Position,
+
+			OldPieces:     changed.CurrentPieces,
+			NewRedundancy: segment.Redundancy,
+			NewPieces:     newPieces,
+
+			NewRepairedAt: time.Now(),
+		})
+	}
 	if err != nil {
+		if metabase.ErrSegmentNotFound.Has(err) {
+			// the segment was deleted while it was repaired, the uploaded pieces
+			// will be removed by garbage collection.
+			mon.Meter("segment_deleted_during_repair").Mark(1)
+			repairer.log.Debug("segment was deleted during repair")
+			return true, nil
+		}
 		return false, metainfoPutError.Wrap(err)
 	}
 
@@ -0,0 +1,125 @@
+// Copyright (C) 2021 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package satellitedb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/zeebo/errs"
+
+	"storj.io/private/dbutil/pgutil"
+)
+
+// bulkColumn is a column inserted by bulkUpsert.
+type bulkColumn struct {
+	Name string
+	// Type is the array type of the argument, e.g. "int8[]", which is unnested into a row
+	// per element. Columns without a type take the same argument value for every row.
+	Type string
+}
+
+// bulkUpsert builds an INSERT ... SELECT unnest(...) ON CONFLICT DO UPDATE statement, which
+// inserts or updates a row for every element of the array arguments. The statement is the same
+// for Postgres and Cockroach.
+type bulkUpsert struct {
+	Table   string
+	Columns []bulkColumn
+	// ConflictColumns are the columns of the conflict target, usually the primary key.
+	ConflictColumns []string
+	// UpdateColumns are the columns updated on conflict.
+	UpdateColumns []string
+	// Accumulate adds the inserted values to the updated columns, instead of replacing them.
+	Accumulate bool
+}
+
+// SQL returns the statement, which takes the arguments of Columns in order as positional parameters.
+func (upsert bulkUpsert) SQL() string {
+	table := pgutil.QuoteIdentifier(upsert.Table)
+
+	columns := make([]string, 0, len(upsert.Columns))
+	values := make([]string, 0, len(upsert.Columns))
+	for i, column := range upsert.Columns {
+		columns = append(columns, pgutil.QuoteIdentifier(column.Name))
+		if column.Type == "" {
+			values = append(values, fmt.Sprintf("$%d", i+1))
+		} else {
+			values = append(values, fmt.Sprintf("unnest($%d::%s)", i+1, column.Type))
+		}
+	}
+
+	conflictColumns := make([]string, 0, len(upsert.ConflictColumns))
+	for _, column := range upsert.ConflictColumns {
+		conflictColumns = append(conflictColumns, pgutil.QuoteIdentifier(column))
+	}
+
+	updates := make([]string, 0, len(upsert.UpdateColumns))
+	for _, column := range upsert.UpdateColumns {
+		column = pgutil.QuoteIdentifier(column)
+		if upsert.Accumulate {
+			updates = append(updates, column+" = "+table+"."+column+" + EXCLUDED."+column)
+		} else {
+			updates = append(updates, column+" = EXCLUDED."+column)
+		}
+	}
+
+	return "INSERT INTO " + table + " (" + strings.Join(columns, ", ") + ")\n" +
+		"SELECT " + strings.Join(values, ", ") + "\n" +
+		"ON CONFLICT (" + strings.Join(conflictColumns, ", ") + ")\n" +
+		"DO UPDATE SET " + strings.Join(updates, ", ")
+}
+
+// ExecContext executes the statement with args, which have to match Columns.
+func (upsert bulkUpsert) ExecContext(ctx context.Context, db interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}, args ...interface{}) (sql.Result, error) {
+	if len(args) != len(upsert.Columns) {
+		return nil, errs.New("bulk upsert into %s: expected %d arguments, got %d", upsert.Table, len(upsert.Columns), len(args))
+	}
+	return db.ExecContext(ctx, upsert.SQL(), args...)
+}
+
+// saveTalliesUpsert replaces the tallies of an interval, so that saving an interval again is idempotent.
+var saveTalliesUpsert = bulkUpsert{
+	Table: "bucket_storage_tallies",
+	Columns: []bulkColumn{
+		{Name: "interval_start"},
+		{Name: "bucket_name", Type: "bytea[]"},
+		{Name: "project_id", Type: "bytea[]"},
+		{Name: "total_bytes", Type: "int8[]"},
+		{Name: "inline"},
+		{Name: "remote"},
+		{Name: "total_segments_count", Type: "int8[]"},
+		{Name: "remote_segments_count"},
+		{Name: "inline_segments_count"},
+		{Name: "object_count", Type: "int8[]"},
+		{Name: "metadata_size", Type: "int8[]"},
+	},
+	ConflictColumns: []string{"bucket_name", "project_id", "interval_start"},
+	UpdateColumns: []string{
+		"total_bytes", "inline", "remote",
+		"total_segments_count", "remote_segments_count", "inline_segments_count",
+		"object_count", "metadata_size",
+	},
+}
+
+// bandwidthRollupsUpsert adds bandwidth to the bucket bandwidth rollups of an interval.
+var bandwidthRollupsUpsert = bulkUpsert{
+	Table: "bucket_bandwidth_rollups",
+	Columns: []bulkColumn{
+		{Name: "bucket_name", Type: "bytea[]"},
+		{Name: "project_id", Type: "bytea[]"},
+		{Name: "interval_start"},
+		{Name: "interval_seconds"},
+		{Name: "action", Type: "int4[]"},
+		{Name: "inline", Type: "int8[]"},
+		{Name: "allocated", Type: "int8[]"},
+		{Name: "settled", Type: "int8[]"},
+	},
+	ConflictColumns: []string{"bucket_name", "project_id", "interval_start", "action"},
+	UpdateColumns:   []string{"allocated", "inline", "settled"},
+	Accumulate:      true,
+}
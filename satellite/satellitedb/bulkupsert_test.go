@@ -0,0 +1,75 @@
+// Copyright (C) 2021 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package satellitedb
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBulkUpsertSQL(t *testing.T) {
+	// the statements are the same for Postgres and Cockroach, their execution is
+	// covered by the tests of SaveTallies and CreateBandwidthRollups.
+	require.Equal(t, `INSERT INTO "bucket_storage_tallies" (`+
+		`"interval_start", "bucket_name", "project_id", "total_bytes", "inline", "remote", `+
+		`"total_segments_count", "remote_segments_count", "inline_segments_count", "object_count", "metadata_size")
+SELECT $1, unnest($2::bytea[]), unnest($3::bytea[]), unnest($4::int8[]), $5, $6, `+
+		`unnest($7::int8[]), $8, $9, unnest($10::int8[]), unnest($11::int8[])
+ON CONFLICT ("bucket_name", "project_id", "interval_start")
+DO UPDATE SET "total_bytes" = EXCLUDED."total_bytes", "inline" = EXCLUDED."inline", "remote" = EXCLUDED."remote", `+
+		`"total_segments_count" = EXCLUDED."total_segments_count", `+
+		`"remote_segments_count" = EXCLUDED."remote_segments_count", `+
+		`"inline_segments_count" = EXCLUDED."inline_segments_count", `+
+		`"object_count" = EXCLUDED."object_count", "metadata_size" = EXCLUDED."metadata_size"`,
+		saveTalliesUpsert.SQL())
+
+	require.Equal(t, `INSERT INTO "bucket_bandwidth_rollups" (`+
+		`"bucket_name", "project_id", "interval_start", "interval_seconds", "action", "inline", "allocated", "settled")
+SELECT unnest($1::bytea[]), unnest($2::bytea[]), $3, $4, unnest($5::int4[]), unnest($6::int8[]), unnest($7::int8[]), unnest($8::int8[])
+ON CONFLICT ("bucket_name", "project_id", "interval_start", "action")
+DO UPDATE SET "allocated" = "bucket_bandwidth_rollups"."allocated" + EXCLUDED."allocated", `+
+		`"inline" = "bucket_bandwidth_rollups"."inline" + EXCLUDED."inline", `+
+		`"settled" = "bucket_bandwidth_rollups"."settled" + EXCLUDED."settled"`,
+		bandwidthRollupsUpsert.SQL())
+
+	require.Equal(t, `INSERT INTO "odd""table" ("id", "value")
+SELECT unnest($1::bytea[]), unnest($2::text[])
+ON CONFLICT ("id")
+DO UPDATE SET "value" = EXCLUDED."value"`,
+		bulkUpsert{
+			Table:           `odd"table`,
+			Columns:         []bulkColumn{{Name: "id", Type: "bytea[]"}, {Name: "value", Type: "text[]"}},
+			ConflictColumns: []string{"id"},
+			UpdateColumns:   []string{"value"},
+		}.SQL())
+}
+
+func TestBulkUpsertHostileIdentifiers(t *testing.T) {
+	for _, name := range []string{
+		`"`, `""`, `a"b`, `x" = 1; DROP TABLE projects; --`, `with space`, `semi;colon`, `back\slash`, `ünïcödé`, `UPPER`,
+	} {
+		quoted := `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+
+		query := bulkUpsert{
+			Table:           name,
+			Columns:         []bulkColumn{{Name: name, Type: "int8[]"}},
+			ConflictColumns: []string{name},
+			UpdateColumns:   []string{name},
+			Accumulate:      true,
+		}.SQL()
+
+		require.Equal(t, "INSERT INTO "+quoted+" ("+quoted+")\n"+
+			"SELECT unnest($1::int8[])\n"+
+			"ON CONFLICT ("+quoted+")\n"+
+			"DO UPDATE SET "+quoted+" = "+quoted+"."+quoted+" + EXCLUDED."+quoted, query, name)
+	}
+}
+
+func TestBulkUpsertArguments(t *testing.T) {
+	_, err := bandwidthRollupsUpsert.ExecContext(context.Background(), nil, 1, 2, 3)
+	require.Error(t, err)
+}
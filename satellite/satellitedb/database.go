@@ -6,6 +6,7 @@ package satellitedb
 import (
 	"context"
 	"sync"
+	"time"
 
 	"github.com/zeebo/errs"
 	"go.uber.org/zap"
@@ -59,6 +60,9 @@ type satelliteDB struct {
 
 	revocationDBOnce sync.Once
 	revocationDB     *revocationDB
+
+	projectLimitsCacheOnce sync.Once
+	projectLimitsCache     *projectLimitsCache
 }
 
 // Options includes options for how a satelliteDB runs.
@@ -66,10 +70,38 @@ type Options struct {
 	ApplicationName      string
 	APIKeysLRUOptions    lrucache.Options
 	RevocationLRUOptions lrucache.Options
+	// ProjectLimitsLRUOptions configures the cache of project limits read through
+	// ProjectAccounting. Zero capacity disables the cache.
+	ProjectLimitsLRUOptions lrucache.Options
 
 	// How many storage node rollups to save/read in one batch.
 	SaveRollupBatchSize int
 	ReadRollupBatchSize int
+	// How many bucket tallies to save in one batch. Zero uses defaultSaveTalliesBatchSize.
+	SaveTalliesBatchSize int
+
+	// StrictAccountingReads makes bulk accounting listings fail on rows with
+	// a corrupt project_id instead of skipping them, see
+	// tally.Config.StrictAccountingReads.
+	StrictAccountingReads bool
+
+	// MaxBucketSearchMatches is the number of buckets a bucket usage search
+	// may match before it's rejected. Zero uses defaultMaxBucketSearchMatches.
+	MaxBucketSearchMatches int
+	// MaxAccountingScanResults is the number of buckets or rows a long accounting
+	// scan may process before it's rejected. Zero disables the limit.
+	MaxAccountingScanResults int
+	// BucketTotalsParallelism is the number of buckets of a page whose usage is fetched
+	// concurrently by GetBucketTotals. Zero uses defaultBucketTotalsParallelism.
+	BucketTotalsParallelism int
+
+	// ExpectedTallyInterval is how often storage tallies are expected to be
+	// taken, see tally.Config.ExpectedInterval. When set, the most recent tally
+	// of a usage period is accounted for this duration instead of being skipped.
+	ExpectedTallyInterval time.Duration
+	// MaxTallyGap caps the duration a single storage tally is accounted for,
+	// see tally.Config.MaxGap. Zero disables the cap.
+	MaxTallyGap time.Duration
 }
 
 var _ dbx.DBMethods = &satelliteDB{}
@@ -203,7 +235,20 @@ func (dbc *satelliteDBCollection) StoragenodeAccounting() accounting.Storagenode
 
 // ProjectAccounting returns database for tracking project data use.
 func (dbc *satelliteDBCollection) ProjectAccounting() accounting.ProjectAccounting {
-	return &ProjectAccounting{db: dbc.getByName("projectaccounting")}
+	db := dbc.getByName("projectaccounting")
+	projectAccounting := &ProjectAccounting{db: db}
+	if db.opts.ProjectLimitsLRUOptions.Capacity <= 0 {
+		return projectAccounting
+	}
+
+	// the cache is shared, so that updates through any ProjectAccounting invalidate it.
+	db.projectLimitsCacheOnce.Do(func() {
+		db.projectLimitsCache = &projectLimitsCache{
+			ProjectAccounting: projectAccounting,
+			lru:               lrucache.New(db.opts.ProjectLimitsLRUOptions),
+		}
+	})
+	return db.projectLimitsCache
 }
 
 // Revocation returns the database to deal with macaroon revocation.
@@ -8,6 +8,8 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgerrcode"
 	"github.com/spacemonkeygo/monkit/v3"
 	"github.com/zeebo/errs"
 
@@ -59,8 +61,70 @@ func (err *constraintError) Cause() error { return err.err }
 
 // IsConstraintError returns true if the error is a constraint error.
 func IsConstraintError(err error) bool {
+	_, _, ok := ParseConstraintError(err)
+	return ok
+}
+
+// ConstraintKind is the kind of a violated constraint.
+type ConstraintKind int
+
+const (
+	// ConstraintUnknown is a constraint of an unrecognized kind.
+	ConstraintUnknown ConstraintKind = iota
+	// ConstraintUnique is a unique constraint or a primary key.
+	ConstraintUnique
+	// ConstraintForeignKey is a foreign key.
+	ConstraintForeignKey
+	// ConstraintCheck is a check constraint.
+	ConstraintCheck
+	// ConstraintNotNull is a not null constraint.
+	ConstraintNotNull
+)
+
+// String returns the name of the constraint kind.
+func (kind ConstraintKind) String() string {
+	switch kind {
+	case ConstraintUnique:
+		return "unique"
+	case ConstraintForeignKey:
+		return "foreign key"
+	case ConstraintCheck:
+		return "check"
+	case ConstraintNotNull:
+		return "not null"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseConstraintError returns the kind and the name of the constraint violated by err, which
+// may come from dbx methods as well as from raw queries. ok is false if err isn't a constraint
+// violation. The name is empty when the database doesn't report it.
+func ParseConstraintError(err error) (kind ConstraintKind, constraint string, ok bool) {
 	var cerr *constraintError
-	return errors.As(err, &cerr)
+	if errors.As(err, &cerr) {
+		constraint, ok = cerr.constraint, true
+	}
+
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) || len(pgErr.Code) < 2 || pgErr.Code[:2] != "23" {
+		return ConstraintUnknown, constraint, ok
+	}
+	if pgErr.ConstraintName != "" {
+		constraint = pgErr.ConstraintName
+	}
+
+	switch pgErr.Code {
+	case pgerrcode.UniqueViolation:
+		kind = ConstraintUnique
+	case pgerrcode.ForeignKeyViolation:
+		kind = ConstraintForeignKey
+	case pgerrcode.CheckViolation:
+		kind = ConstraintCheck
+	case pgerrcode.NotNullViolation:
+		kind = ConstraintNotNull
+	}
+	return kind, constraint, true
 }
 
 // Error implements the error interface.
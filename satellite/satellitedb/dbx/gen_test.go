@@ -0,0 +1,87 @@
+// Copyright (C) 2021 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package dbx_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"storj.io/common/testcontext"
+	"storj.io/private/dbutil/tempdb"
+	"storj.io/storj/satellite/satellitedb/dbx"
+	"storj.io/storj/satellite/satellitedb/satellitedbtest"
+)
+
+func TestParseConstraintError(t *testing.T) {
+	for _, dbInfo := range satellitedbtest.Databases() {
+		dbInfo := dbInfo
+		t.Run(dbInfo.Name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := testcontext.New(t)
+			defer ctx.Cleanup()
+
+			if dbInfo.MasterDB.URL == "" {
+				t.Skipf("Database %s connection string not provided. %s", dbInfo.MasterDB.Name, dbInfo.MasterDB.Message)
+			}
+
+			db, err := tempdb.OpenUnique(ctx, dbInfo.MasterDB.URL, "constraints")
+			require.NoError(t, err)
+			defer ctx.Check(db.Close)
+
+			_, err = db.ExecContext(ctx, `
+				CREATE TABLE parents (
+					id INT8 NOT NULL,
+					CONSTRAINT parents_pk PRIMARY KEY (id)
+				);
+				CREATE TABLE children (
+					id INT8 NOT NULL,
+					parent_id INT8 NOT NULL,
+					amount INT8 NOT NULL,
+					CONSTRAINT children_pk PRIMARY KEY (id),
+					CONSTRAINT children_parent_fk FOREIGN KEY (parent_id) REFERENCES parents (id),
+					CONSTRAINT children_amount_check CHECK (amount >= 0)
+				);
+				INSERT INTO parents (id) VALUES (1);
+				INSERT INTO children (id, parent_id, amount) VALUES (1, 1, 0);
+			`)
+			require.NoError(t, err)
+
+			for _, tt := range []struct {
+				name       string
+				query      string
+				kind       dbx.ConstraintKind
+				constraint string
+			}{
+				{"unique", `INSERT INTO children (id, parent_id, amount) VALUES (1, 1, 0)`, dbx.ConstraintUnique, "children_pk"},
+				{"foreign key", `INSERT INTO children (id, parent_id, amount) VALUES (2, 2, 0)`, dbx.ConstraintForeignKey, "children_parent_fk"},
+				{"check", `INSERT INTO children (id, parent_id, amount) VALUES (2, 1, -1)`, dbx.ConstraintCheck, "children_amount_check"},
+				{"not null", `INSERT INTO children (id, parent_id, amount) VALUES (2, 1, NULL)`, dbx.ConstraintNotNull, ""},
+			} {
+				_, err := db.ExecContext(ctx, tt.query)
+				require.Error(t, err, tt.name)
+
+				kind, constraint, ok := dbx.ParseConstraintError(err)
+				require.True(t, ok, tt.name)
+				require.Equal(t, tt.kind, kind, tt.name)
+				// cockroach doesn't report the names of all violated constraints.
+				if tt.constraint != "" && dbInfo.Name == "Postgres" {
+					require.Equal(t, tt.constraint, constraint, tt.name)
+				}
+				require.True(t, dbx.IsConstraintError(err), tt.name)
+			}
+
+			_, err = db.ExecContext(ctx, `SELECT * FROM missing_table`)
+			require.Error(t, err)
+			_, _, ok := dbx.ParseConstraintError(err)
+			require.False(t, ok)
+			require.False(t, dbx.IsConstraintError(err))
+
+			_, _, ok = dbx.ParseConstraintError(errors.New("constraint"))
+			require.False(t, ok)
+		})
+	}
+}
@@ -57,6 +57,20 @@ CREATE TABLE bucket_storage_tallies (
 	metadata_size bigint NOT NULL,
 	PRIMARY KEY ( bucket_name, project_id, interval_start )
 );
+CREATE TABLE bucket_storage_tally_archives (
+	bucket_name bytea NOT NULL,
+	project_id bytea NOT NULL,
+	interval_start timestamp with time zone NOT NULL,
+	total_bytes bigint NOT NULL DEFAULT 0,
+	inline bigint NOT NULL,
+	remote bigint NOT NULL,
+	total_segments_count integer NOT NULL DEFAULT 0,
+	remote_segments_count integer NOT NULL,
+	inline_segments_count integer NOT NULL,
+	object_count integer NOT NULL,
+	metadata_size bigint NOT NULL,
+	PRIMARY KEY ( bucket_name, project_id, interval_start )
+);
 CREATE TABLE coinpayments_transactions (
 	id text NOT NULL,
 	user_id bytea NOT NULL,
@@ -222,9 +236,21 @@ CREATE TABLE projects (
 	max_buckets integer,
 	partner_id bytea,
 	owner_id bytea NOT NULL,
+	exempt_from_billing boolean NOT NULL DEFAULT false,
 	created_at timestamp with time zone NOT NULL,
 	PRIMARY KEY ( id )
 );
+CREATE TABLE project_bandwidth_adjustments (
+	project_id bytea NOT NULL,
+	interval_day date NOT NULL,
+	requested_allocated bigint NOT NULL,
+	requested_settled bigint NOT NULL,
+	applied_allocated bigint NOT NULL,
+	applied_settled bigint NOT NULL,
+	reason text NOT NULL,
+	created_at timestamp with time zone NOT NULL,
+	PRIMARY KEY ( project_id, created_at )
+);
 CREATE TABLE project_bandwidth_daily_rollups (
 	project_id bytea NOT NULL,
 	interval_day date NOT NULL,
@@ -239,6 +265,31 @@ CREATE TABLE project_bandwidth_rollups (
 	egress_allocated bigint NOT NULL,
 	PRIMARY KEY ( project_id, interval_month )
 );
+CREATE TABLE project_limit_changes (
+	project_id bytea NOT NULL,
+	field text NOT NULL,
+	old_value bigint,
+	new_value bigint,
+	changed_at timestamp with time zone NOT NULL,
+	actor text NOT NULL,
+	PRIMARY KEY ( project_id, changed_at, field )
+);
+CREATE TABLE project_monthly_usages (
+	project_id bytea NOT NULL,
+	period date NOT NULL,
+	storage double precision NOT NULL,
+	egress bigint NOT NULL,
+	object_count double precision NOT NULL,
+	updated_at timestamp with time zone NOT NULL,
+	PRIMARY KEY ( project_id, period )
+);
+CREATE TABLE project_usage_alert_thresholds (
+	project_id bytea NOT NULL,
+	storage_percent integer NOT NULL,
+	bandwidth_percent integer NOT NULL,
+	updated_at timestamp with time zone NOT NULL,
+	PRIMARY KEY ( project_id )
+);
 CREATE TABLE registration_tokens (
 	secret bytea NOT NULL,
 	owner_id bytea,
@@ -453,6 +504,8 @@ CREATE TABLE bucket_metainfos (
 	default_redundancy_repair_shares integer NOT NULL,
 	default_redundancy_optimal_shares integer NOT NULL,
 	default_redundancy_total_shares integer NOT NULL,
+	bandwidth_limit bigint,
+	storage_limit bigint,
 	PRIMARY KEY ( id ),
 	UNIQUE ( project_id, name )
 );
@@ -487,6 +540,7 @@ CREATE INDEX bucket_bandwidth_rollups_action_interval_project_id_index ON bucket
 CREATE INDEX bucket_bandwidth_rollups_archive_project_id_action_interval_index ON bucket_bandwidth_rollup_archives ( project_id, action, interval_start ) ;
 CREATE INDEX bucket_bandwidth_rollups_archive_action_interval_project_id_index ON bucket_bandwidth_rollup_archives ( action, interval_start, project_id ) ;
 CREATE INDEX bucket_storage_tallies_project_id_interval_start_index ON bucket_storage_tallies ( project_id, interval_start ) ;
+CREATE INDEX bucket_storage_tally_archives_interval_start_index ON bucket_storage_tally_archives ( interval_start ) ;
 CREATE INDEX graceful_exit_segment_transfer_nid_dr_qa_fa_lfa_index ON graceful_exit_segment_transfer_queue ( node_id, durability_ratio, queued_at, finished_at, last_failed_at ) ;
 CREATE INDEX graceful_exit_transfer_queue_nid_dr_qa_fa_lfa_index ON graceful_exit_transfer_queue ( node_id, durability_ratio, queued_at, finished_at, last_failed_at ) ;
 CREATE INDEX node_last_ip ON nodes ( last_net ) ;
@@ -1605,6 +1605,116 @@ func (db *satelliteDB) PostgresMigration() *migrate.Migration {
 					`DROP TABLE injuredsegments`,
 				},
 			},
+			{
+				DB:          &db.migrationDB,
+				Description: "add exempt_from_billing to projects",
+				Version:     170,
+				Action: migrate.SQL{
+					`ALTER TABLE projects ADD COLUMN exempt_from_billing boolean NOT NULL DEFAULT false;`,
+				},
+			},
+			{
+				DB:          &db.migrationDB,
+				Description: "add bucket_storage_tally_archives table",
+				Version:     171,
+				Action: migrate.SQL{
+					`CREATE TABLE bucket_storage_tally_archives (
+						bucket_name bytea NOT NULL,
+						project_id bytea NOT NULL,
+						interval_start timestamp with time zone NOT NULL,
+						total_bytes bigint NOT NULL DEFAULT 0,
+						inline bigint NOT NULL,
+						remote bigint NOT NULL,
+						total_segments_count integer NOT NULL DEFAULT 0,
+						remote_segments_count integer NOT NULL,
+						inline_segments_count integer NOT NULL,
+						object_count integer NOT NULL,
+						metadata_size bigint NOT NULL,
+						PRIMARY KEY ( bucket_name, project_id, interval_start )
+					);`,
+					`CREATE INDEX bucket_storage_tally_archives_interval_start_index ON bucket_storage_tally_archives ( interval_start );`,
+				},
+			},
+			{
+				DB:          &db.migrationDB,
+				Description: "add bandwidth_limit to bucket_metainfos",
+				Version:     172,
+				Action: migrate.SQL{
+					`ALTER TABLE bucket_metainfos ADD COLUMN bandwidth_limit bigint;`,
+				},
+			},
+			{
+				DB:          &db.migrationDB,
+				Description: "add storage_limit to bucket_metainfos",
+				Version:     173,
+				Action: migrate.SQL{
+					`ALTER TABLE bucket_metainfos ADD COLUMN storage_limit bigint;`,
+				},
+			},
+			{
+				DB:          &db.migrationDB,
+				Description: "add project_limit_changes table",
+				Version:     174,
+				Action: migrate.SQL{
+					`CREATE TABLE project_limit_changes (
+						project_id bytea NOT NULL,
+						field text NOT NULL,
+						old_value bigint,
+						new_value bigint,
+						changed_at timestamp with time zone NOT NULL,
+						actor text NOT NULL,
+						PRIMARY KEY ( project_id, changed_at, field )
+					);`,
+				},
+			},
+			{
+				DB:          &db.migrationDB,
+				Description: "add project_monthly_usages table",
+				Version:     175,
+				Action: migrate.SQL{
+					`CREATE TABLE project_monthly_usages (
+						project_id bytea NOT NULL,
+						period date NOT NULL,
+						storage double precision NOT NULL,
+						egress bigint NOT NULL,
+						object_count double precision NOT NULL,
+						updated_at timestamp with time zone NOT NULL,
+						PRIMARY KEY ( project_id, period )
+					);`,
+				},
+			},
+			{
+				DB:          &db.migrationDB,
+				Description: "add project_usage_alert_thresholds table",
+				Version:     176,
+				Action: migrate.SQL{
+					`CREATE TABLE project_usage_alert_thresholds (
+						project_id bytea NOT NULL,
+						storage_percent integer NOT NULL,
+						bandwidth_percent integer NOT NULL,
+						updated_at timestamp with time zone NOT NULL,
+						PRIMARY KEY ( project_id )
+					);`,
+				},
+			},
+			{
+				DB:          &db.migrationDB,
+				Description: "add project_bandwidth_adjustments table",
+				Version:     177,
+				Action: migrate.SQL{
+					`CREATE TABLE project_bandwidth_adjustments (
+						project_id bytea NOT NULL,
+						interval_day date NOT NULL,
+						requested_allocated bigint NOT NULL,
+						requested_settled bigint NOT NULL,
+						applied_allocated bigint NOT NULL,
+						applied_settled bigint NOT NULL,
+						reason text NOT NULL,
+						created_at timestamp with time zone NOT NULL,
+						PRIMARY KEY ( project_id, created_at )
+					);`,
+				},
+			},
 			// NB: after updating testdata in `testdata`, run
 			//     `go generate` to update `migratez.go`.
 		},
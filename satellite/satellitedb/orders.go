@@ -250,20 +250,7 @@ func (db *ordersDB) UpdateBucketBandwidthBatch(ctx context.Context, intervalStar
 			}
 		}
 
-		_, err = tx.Tx.ExecContext(ctx, `
-		INSERT INTO bucket_bandwidth_rollups (
-			bucket_name, project_id,
-			interval_start, interval_seconds,
-			action, inline, allocated, settled)
-		SELECT
-			unnest($1::bytea[]), unnest($2::bytea[]),
-			$3, $4,
-			unnest($5::int4[]), unnest($6::bigint[]), unnest($7::bigint[]), unnest($8::bigint[])
-		ON CONFLICT(bucket_name, project_id, interval_start, action)
-		DO UPDATE SET
-			allocated = bucket_bandwidth_rollups.allocated + EXCLUDED.allocated,
-			inline = bucket_bandwidth_rollups.inline + EXCLUDED.inline,
-			settled = bucket_bandwidth_rollups.settled + EXCLUDED.settled`,
+		_, err = bandwidthRollupsUpsert.ExecContext(ctx, tx.Tx,
 			pgutil.ByteaArray(bucketNames), pgutil.ByteaArray(projectIDs),
 			intervalStart, defaultIntervalSeconds,
 			pgutil.Int4Array(actionSlice), pgutil.Int8Array(inlineSlice), pgutil.Int8Array(allocatedSlice), pgutil.Int8Array(settledSlice))
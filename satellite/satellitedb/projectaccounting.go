@@ -6,20 +6,29 @@ package satellitedb
 import (
 	"context"
 	"database/sql"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
+	"github.com/spacemonkeygo/monkit/v3"
 	"github.com/zeebo/errs"
+	"golang.org/x/sync/errgroup"
 
 	"storj.io/common/memory"
 	"storj.io/common/pb"
+	"storj.io/common/storj"
 	"storj.io/common/uuid"
 	"storj.io/private/dbutil"
 	"storj.io/private/dbutil/pgutil"
+	"storj.io/private/tagsql"
 	"storj.io/storj/satellite/accounting"
 	"storj.io/storj/satellite/metabase"
 	"storj.io/storj/satellite/orders"
+	"storj.io/storj/satellite/rewards"
 	"storj.io/storj/satellite/satellitedb/dbx"
 )
 
@@ -28,48 +37,168 @@ var _ accounting.ProjectAccounting = (*ProjectAccounting)(nil)
 
 var allocatedExpirationInDays = 2
 
+// bandwidthBreakdownLimit is the maximum number of buckets returned by GetBucketBandwidthBreakdown.
+const bandwidthBreakdownLimit = 100
+
+// defaultMaxBucketSearchMatches is used when Options.MaxBucketSearchMatches is not set.
+const defaultMaxBucketSearchMatches = 100000
+
+// defaultBucketTotalsParallelism is used when Options.BucketTotalsParallelism is not set.
+const defaultBucketTotalsParallelism = 4
+
+// defaultSaveTalliesBatchSize is used when Options.SaveTalliesBatchSize is not set.
+const defaultSaveTalliesBatchSize = 10000
+
+// maxHourlyBandwidthRange is the longest range GetProjectHourlyBandwidth accepts.
+const maxHourlyBandwidthRange = 14 * 24 * time.Hour
+
+// activeProjectsBatchSize is the number of projects GetActiveProjects reads with a single query.
+const activeProjectsBatchSize = 1000
+
+// bucketAccountingDeleteBatchSize is the number of rows DeleteBucketAccountingData deletes at once.
+const bucketAccountingDeleteBatchSize = 1000
+
 // ProjectAccounting implements the accounting/db ProjectAccounting interface.
 type ProjectAccounting struct {
 	db *satelliteDB
 }
 
 // SaveTallies saves the latest bucket info.
-func (db *ProjectAccounting) SaveTallies(ctx context.Context, intervalStart time.Time, bucketTallies map[metabase.BucketLocation]*accounting.BucketTally) (err error) {
+//
+// Tallies which were already saved for the same interval start are replaced, so that
+// retrying a partially saved interval doesn't duplicate them. The tallies are inserted
+// in batches of Options.SaveTalliesBatchSize within a single transaction.
+//
+// Tallies which fail verification are skipped and returned in invalid, the valid
+// tallies are still saved. Bucket names are normalized with accounting.NormalizeBucketName,
+// tallies whose names can't be normalized are skipped the same way.
+func (db *ProjectAccounting) SaveTallies(ctx context.Context, intervalStart time.Time, bucketTallies map[metabase.BucketLocation]*accounting.BucketTally) (saved int, invalid map[metabase.BucketLocation]error, err error) {
 	defer mon.Task()(&ctx)(&err)
 	if len(bucketTallies) == 0 {
+		return 0, nil, nil
+	}
+
+	batchSize := db.db.opts.SaveTalliesBatchSize
+	if batchSize <= 0 {
+		batchSize = defaultSaveTalliesBatchSize
+	}
+
+	tallies := make([]*accounting.BucketTally, 0, len(bucketTallies))
+	for location, info := range bucketTallies {
+		verifyErr := accounting.ErrInvalidArgument.New("tally is missing")
+		if info != nil {
+			verifyErr = info.Verify()
+		}
+		if verifyErr != nil {
+			if invalid == nil {
+				invalid = make(map[metabase.BucketLocation]error)
+			}
+			invalid[location] = verifyErr
+			continue
+		}
+		tallies = append(tallies, info)
+	}
+
+	tallies, invalidNames := normalizeTallyNames(tallies)
+	for location, err := range invalidNames {
+		if invalid == nil {
+			invalid = make(map[metabase.BucketLocation]error)
+		}
+		invalid[location] = err
+	}
+	if len(tallies) == 0 {
+		return 0, invalid, nil
+	}
+
+	insertBatch := func(ctx context.Context, tx *dbx.Tx, batch []*accounting.BucketTally) (err error) {
+		defer mon.Task()(&ctx)(&err)
+
+		var bucketNames, projectIDs [][]byte
+		var totalBytes, metadataSizes []int64
+		var totalSegments, objectCounts []int64
+		for _, info := range batch {
+			bucketNames = append(bucketNames, []byte(info.BucketName))
+			projectIDs = append(projectIDs, info.ProjectID[:])
+			totalBytes = append(totalBytes, info.TotalBytes)
+			totalSegments = append(totalSegments, info.TotalSegments)
+			objectCounts = append(objectCounts, info.ObjectCount)
+			metadataSizes = append(metadataSizes, info.MetadataSize)
+		}
+		_, err = saveTalliesUpsert.ExecContext(ctx, tx.Tx,
+			intervalStart,
+			pgutil.ByteaArray(bucketNames), pgutil.ByteaArray(projectIDs),
+			pgutil.Int8Array(totalBytes), 0, 0,
+			pgutil.Int8Array(totalSegments), 0, 0,
+			pgutil.Int8Array(objectCounts), pgutil.Int8Array(metadataSizes))
+		return err
+	}
+
+	err = db.db.WithTx(ctx, func(ctx context.Context, tx *dbx.Tx) error {
+		for remaining := tallies; len(remaining) > 0; {
+			batch := remaining
+			if len(batch) > batchSize {
+				batch = batch[:batchSize]
+			}
+			remaining = remaining[len(batch):]
+
+			if err := insertBatch(ctx, tx, batch); err != nil {
+				return err
+			}
+		}
 		return nil
+	})
+	if err != nil {
+		return 0, invalid, Error.Wrap(err)
 	}
-	var bucketNames, projectIDs [][]byte
-	var totalBytes, metadataSizes []int64
-	var totalSegments, objectCounts []int64
-	for _, info := range bucketTallies {
-		bucketNames = append(bucketNames, []byte(info.BucketName))
-		projectIDs = append(projectIDs, info.ProjectID[:])
-		totalBytes = append(totalBytes, info.TotalBytes)
-		totalSegments = append(totalSegments, info.TotalSegments)
-		objectCounts = append(objectCounts, info.ObjectCount)
-		metadataSizes = append(metadataSizes, info.MetadataSize)
+
+	return len(tallies), invalid, nil
+}
+
+// normalizeTallyNames returns copies of tallies with bucket names normalized by accounting.NormalizeBucketName.
+// Tallies with names which can't be normalized are returned as invalid. Of the tallies whose names normalize
+// to the same name, only the one already using the normalized name, or else the first one by name, is returned,
+// since they would be stored in the same row.
+func normalizeTallyNames(tallies []*accounting.BucketTally) (normalized []*accounting.BucketTally, invalid map[metabase.BucketLocation]error) {
+	addInvalid := func(tally *accounting.BucketTally, err error) {
+		if invalid == nil {
+			invalid = make(map[metabase.BucketLocation]error)
+		}
+		invalid[tally.BucketLocation] = err
 	}
-	_, err = db.db.DB.ExecContext(ctx, db.db.Rebind(`
-		INSERT INTO bucket_storage_tallies (
-			interval_start,
-			bucket_name, project_id,
-			total_bytes, inline, remote,
-			total_segments_count, remote_segments_count, inline_segments_count,
-			object_count, metadata_size)
-		SELECT
-			$1,
-			unnest($2::bytea[]), unnest($3::bytea[]),
-			unnest($4::int8[]), $5, $6,
-			unnest($7::int8[]), $8, $9,
-			unnest($10::int8[]), unnest($11::int8[])`),
-		intervalStart,
-		pgutil.ByteaArray(bucketNames), pgutil.ByteaArray(projectIDs),
-		pgutil.Int8Array(totalBytes), 0, 0,
-		pgutil.Int8Array(totalSegments), 0, 0,
-		pgutil.Int8Array(objectCounts), pgutil.Int8Array(metadataSizes))
 
-	return Error.Wrap(err)
+	sort.Slice(tallies, func(i, k int) bool {
+		return tallies[i].BucketName < tallies[k].BucketName
+	})
+
+	kept := make(map[metabase.BucketLocation]*accounting.BucketTally, len(tallies))
+	for _, tally := range tallies {
+		name, err := accounting.NormalizeBucketName(tally.BucketName)
+		if err != nil {
+			addInvalid(tally, err)
+			continue
+		}
+
+		location := metabase.BucketLocation{ProjectID: tally.ProjectID, BucketName: name}
+		other, ok := kept[location]
+		if ok && tally.BucketName == name {
+			tally, other = other, tally
+			kept[location] = other
+		}
+		if ok {
+			addInvalid(tally, accounting.ErrInvalidArgument.Wrap(fmt.Errorf("%w: %q normalizes to the name of bucket %q",
+				accounting.ErrInvalidBucketName, tally.BucketName, other.BucketName)))
+			continue
+		}
+		kept[location] = tally
+	}
+
+	normalized = make([]*accounting.BucketTally, 0, len(kept))
+	for location, tally := range kept {
+		copied := *tally
+		copied.BucketLocation = location
+		normalized = append(normalized, &copied)
+	}
+	return normalized, invalid
 }
 
 // GetTallies saves the latest bucket info.
@@ -81,11 +210,62 @@ func (db *ProjectAccounting) GetTallies(ctx context.Context) (tallies []accounti
 		return nil, Error.Wrap(err)
 	}
 
-	for _, dbxTally := range dbxTallies {
-		projectID, err := uuid.FromBytes(dbxTally.ProjectId)
+	return db.bucketTalliesFromDBX(dbxTallies)
+}
+
+// GetTalliesSince returns the tallies with interval start at or after since.
+// Zero since returns all tallies, same as GetTallies.
+func (db *ProjectAccounting) GetTalliesSince(ctx context.Context, since time.Time) (tallies []accounting.BucketTally, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	if since.IsZero() {
+		return db.GetTallies(ctx)
+	}
+
+	rows, err := db.db.QueryContext(ctx, db.db.Rebind(`
+		SELECT
+			bucket_name, project_id, interval_start,
+			total_bytes, inline, remote,
+			total_segments_count, remote_segments_count, inline_segments_count,
+			object_count, metadata_size
+		FROM bucket_storage_tallies
+		WHERE interval_start >= ?
+	`), since)
+	if err != nil {
+		return nil, Error.Wrap(err)
+	}
+	defer func() { err = errs.Combine(err, rows.Close()) }()
+
+	var dbxTallies []*dbx.BucketStorageTally
+	for rows.Next() {
+		dbxTally := &dbx.BucketStorageTally{}
+		err := rows.Scan(&dbxTally.BucketName, &dbxTally.ProjectId, &dbxTally.IntervalStart,
+			&dbxTally.TotalBytes, &dbxTally.Inline, &dbxTally.Remote,
+			&dbxTally.TotalSegmentsCount, &dbxTally.RemoteSegmentsCount, &dbxTally.InlineSegmentsCount,
+			&dbxTally.ObjectCount, &dbxTally.MetadataSize)
 		if err != nil {
 			return nil, Error.Wrap(err)
 		}
+		dbxTallies = append(dbxTallies, dbxTally)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, Error.Wrap(err)
+	}
+
+	return db.bucketTalliesFromDBX(dbxTallies)
+}
+
+// bucketTalliesFromDBX converts dbx tallies to bucket tallies. Tallies with a corrupt project_id are
+// skipped or fail the conversion, depending on Options.StrictAccountingReads.
+func (db *ProjectAccounting) bucketTalliesFromDBX(dbxTallies []*dbx.BucketStorageTally) (tallies []accounting.BucketTally, err error) {
+	for _, dbxTally := range dbxTallies {
+		projectID := scanUUIDBytes(dbxTally.ProjectId)
+		if !projectID.Valid {
+			if err := db.corruptRow("bucket_storage_tallies", projectID); err != nil {
+				return nil, err
+			}
+			continue
+		}
 
 		totalBytes := dbxTally.TotalBytes
 		if totalBytes == 0 {
@@ -99,7 +279,7 @@ func (db *ProjectAccounting) GetTallies(ctx context.Context) (tallies []accounti
 
 		tallies = append(tallies, accounting.BucketTally{
 			BucketLocation: metabase.BucketLocation{
-				ProjectID:  projectID,
+				ProjectID:  projectID.UUID,
 				BucketName: string(dbxTally.BucketName),
 			},
 			ObjectCount:   int64(dbxTally.ObjectCount),
@@ -113,9 +293,15 @@ func (db *ProjectAccounting) GetTallies(ctx context.Context) (tallies []accounti
 }
 
 // CreateStorageTally creates a record in the bucket_storage_tallies accounting table.
+// The bucket name is normalized with accounting.NormalizeBucketName.
 func (db *ProjectAccounting) CreateStorageTally(ctx context.Context, tally accounting.BucketStorageTally) (err error) {
 	defer mon.Task()(&ctx)(&err)
 
+	bucketName, err := accounting.NormalizeBucketName(tally.BucketName)
+	if err != nil {
+		return err
+	}
+
 	_, err = db.db.DB.ExecContext(ctx, db.db.Rebind(`
 		INSERT INTO bucket_storage_tallies (
 			interval_start,
@@ -130,8 +316,8 @@ func (db *ProjectAccounting) CreateStorageTally(ctx context.Context, tally accou
 			?, ?, ?,
 			?, ?
 		)`), tally.IntervalStart,
-		[]byte(tally.BucketName), tally.ProjectID,
-		tally.TotalBytes, 0, 0,
+		[]byte(bucketName), tally.ProjectID,
+		tally.TotalBytes, tally.InlineBytes, tally.RemoteBytes,
 		tally.TotalSegmentCount, 0, 0,
 		tally.ObjectCount, tally.MetadataSize,
 	)
@@ -139,6 +325,66 @@ func (db *ProjectAccounting) CreateStorageTally(ctx context.Context, tally accou
 	return Error.Wrap(err)
 }
 
+// CreateBandwidthRollup adds the amounts of rollup to the bucket_bandwidth_rollups record
+// of the interval, creating the record when it doesn't exist yet.
+func (db *ProjectAccounting) CreateBandwidthRollup(ctx context.Context, rollup orders.BucketBandwidthRollup, intervalStart time.Time, intervalSeconds int) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	return db.CreateBandwidthRollups(ctx, []orders.BucketBandwidthRollup{rollup}, intervalStart, intervalSeconds)
+}
+
+// CreateBandwidthRollups adds the amounts of rollups to the bucket_bandwidth_rollups records
+// of the interval with a single statement. Rollups of the same bucket and action are summed up.
+//
+// Only the bucket rollups are written, project_bandwidth_daily_rollups are left untouched.
+func (db *ProjectAccounting) CreateBandwidthRollups(ctx context.Context, rollups []orders.BucketBandwidthRollup, intervalStart time.Time, intervalSeconds int) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	if intervalSeconds <= 0 {
+		return accounting.ErrInvalidArgument.New("interval seconds must be positive, got %d", intervalSeconds)
+	}
+	if len(rollups) == 0 {
+		return nil
+	}
+
+	type rollupKey struct {
+		projectID  uuid.UUID
+		bucketName string
+		action     pb.PieceAction
+	}
+
+	// a single statement can't insert and update the same record twice.
+	merged := make(map[rollupKey]int, len(rollups))
+	var bucketNames, projectIDs [][]byte
+	var actions []int32
+	var inlines, allocateds, settleds []int64
+	for _, rollup := range rollups {
+		key := rollupKey{rollup.ProjectID, rollup.BucketName, rollup.Action}
+		if i, ok := merged[key]; ok {
+			inlines[i] += rollup.Inline
+			allocateds[i] += rollup.Allocated
+			settleds[i] += rollup.Settled
+			continue
+		}
+		merged[key] = len(actions)
+
+		projectID := rollup.ProjectID
+		bucketNames = append(bucketNames, []byte(rollup.BucketName))
+		projectIDs = append(projectIDs, projectID[:])
+		actions = append(actions, int32(rollup.Action))
+		inlines = append(inlines, rollup.Inline)
+		allocateds = append(allocateds, rollup.Allocated)
+		settleds = append(settleds, rollup.Settled)
+	}
+
+	_, err = bandwidthRollupsUpsert.ExecContext(ctx, db.db,
+		pgutil.ByteaArray(bucketNames), pgutil.ByteaArray(projectIDs),
+		intervalStart.UTC(), intervalSeconds,
+		pgutil.Int4Array(actions), pgutil.Int8Array(inlines), pgutil.Int8Array(allocateds), pgutil.Int8Array(settleds))
+
+	return Error.Wrap(err)
+}
+
 // GetAllocatedBandwidthTotal returns the sum of GET bandwidth usage allocated for a projectID for a time frame.
 func (db *ProjectAccounting) GetAllocatedBandwidthTotal(ctx context.Context, projectID uuid.UUID, from time.Time) (_ int64, err error) {
 	defer mon.Task()(&ctx)(&err)
@@ -155,19 +401,89 @@ func (db *ProjectAccounting) GetAllocatedBandwidthTotal(ctx context.Context, pro
 // GetProjectBandwidth returns the used bandwidth (settled or allocated) for the specified year, month and day.
 func (db *ProjectAccounting) GetProjectBandwidth(ctx context.Context, projectID uuid.UUID, year int, month time.Month, day int, asOfSystemInterval time.Duration) (_ int64, err error) {
 	defer mon.Task()(&ctx)(&err)
+
+	return db.GetProjectCycleBandwidth(ctx, projectID, year, month, day, 1, asOfSystemInterval)
+}
+
+// GetProjectCycleBandwidth returns the used bandwidth (settled or allocated) for the specified year, month and day
+// within a billing cycle starting on cycleStartDay of a month. The cycle start day has to be between 1 and 28.
+func (db *ProjectAccounting) GetProjectCycleBandwidth(ctx context.Context, projectID uuid.UUID, year int, month time.Month, day, cycleStartDay int, asOfSystemInterval time.Duration) (_ int64, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	if cycleStartDay < 1 || cycleStartDay > maxCycleStartDay {
+		return 0, accounting.ErrInvalidArgument.New("cycle start day %d isn't between 1 and %d", cycleStartDay, maxCycleStartDay)
+	}
+
+	breakdown, err := db.getProjectBandwidthBreakdown(ctx, projectID, year, month, day, cycleStartDay, asOfSystemInterval)
+	return breakdown.Used, err
+}
+
+// GetProjectBandwidthBreakdown returns the allocated, settled and dead bandwidth for the month of the specified
+// year, month and day, together with the used bandwidth computed the same way as in GetProjectBandwidth.
+func (db *ProjectAccounting) GetProjectBandwidthBreakdown(ctx context.Context, projectID uuid.UUID, year int, month time.Month, day int, asOfSystemInterval time.Duration) (_ accounting.ProjectBandwidthBreakdown, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	breakdown, err := db.getProjectBandwidthBreakdown(ctx, projectID, year, month, day, 1, asOfSystemInterval)
+	return breakdown, Error.Wrap(err)
+}
+
+// getProjectBandwidthBreakdown sums up the project bandwidth daily rollups of the billing cycle containing the
+// given day. The settled amount is used for days where allocations have expired and the allocated amount otherwise.
+func (db *ProjectAccounting) getProjectBandwidthBreakdown(ctx context.Context, projectID uuid.UUID, year int, month time.Month, day, cycleStartDay int, asOfSystemInterval time.Duration) (breakdown accounting.ProjectBandwidthBreakdown, err error) {
+	expiredSince, cycleStart, cycleEnd := projectBandwidthPeriod(year, month, day, cycleStartDay)
+
+	query := db.db.Rebind(`
+		SELECT
+			COALESCE(SUM(egress_allocated), 0)::INT8,
+			COALESCE(SUM(egress_settled), 0)::INT8,
+			COALESCE(SUM(egress_dead), 0)::INT8,
+			COALESCE(SUM(
+				CASE WHEN interval_day < ?
+					THEN egress_settled
+					ELSE egress_allocated
+				END
+			), 0)::INT8
+		FROM project_bandwidth_daily_rollups
+		` + db.db.impl.AsOfSystemInterval(asOfSystemInterval) + `
+		WHERE project_id = ? AND ` + intervalCondition("interval_day") + `
+	`)
+
+	err = db.db.QueryRow(ctx, query, expiredSince, projectID[:], cycleStart, cycleEnd).Scan(
+		&breakdown.AllocatedTotal, &breakdown.SettledTotal, &breakdown.DeadTotal, &breakdown.Used)
+	if err != nil {
+		return accounting.ProjectBandwidthBreakdown{}, err
+	}
+
+	return breakdown, nil
+}
+
+// GetProjectBandwidthUsageAndLimit returns project bandwidth usage the same way as GetProjectBandwidth
+// together with the project bandwidth limit, nil limit means the default limit is used.
+func (db *ProjectAccounting) GetProjectBandwidthUsageAndLimit(ctx context.Context, projectID uuid.UUID, year int, month time.Month, day int, asOfSystemInterval time.Duration) (used int64, limit *int64, err error) {
+	defer mon.Task()(&ctx)(&err)
 	var egress *int64
 
-	startOfMonth := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	expiredSince, startOfMonth, periodEnd := projectBandwidthPeriod(year, month, day, 1)
 
-	var expiredSince time.Time
-	if day < allocatedExpirationInDays {
-		expiredSince = startOfMonth
-	} else {
-		expiredSince = time.Date(year, month, day-allocatedExpirationInDays, 0, 0, 0, 0, time.UTC)
+	query := projectEgressCTE + `
+		SELECT projects.bandwidth_limit, (SELECT sum(amount) FROM egress)
+		FROM projects
+		WHERE projects.id = ?` + db.db.impl.AsOfSystemInterval(asOfSystemInterval)
+	err = db.db.QueryRow(ctx, db.db.Rebind(query), expiredSince, projectID[:], startOfMonth, periodEnd, projectID[:]).Scan(&limit, &egress)
+	if err != nil {
+		return 0, nil, Error.Wrap(err)
 	}
-	periodEnd := time.Date(year, month+1, 1, 0, 0, 0, 0, time.UTC)
+	if egress != nil {
+		used = *egress
+	}
+
+	return used, limit, nil
+}
 
-	query := `WITH egress AS (
+// projectEgressCTE selects the project egress of the period into egress, using the settled amount
+// for days where allocations have expired and the allocated amount otherwise.
+// It expects expiredSince, project id, period start and period end as arguments.
+const projectEgressCTE = `WITH egress AS (
 					SELECT
 						CASE WHEN interval_day < ?
 							THEN egress_settled
@@ -175,13 +491,116 @@ func (db *ProjectAccounting) GetProjectBandwidth(ctx context.Context, projectID
 						END AS amount
 					FROM project_bandwidth_daily_rollups
 					WHERE project_id = ? AND interval_day >= ? AND interval_day < ?
-				) SELECT sum(amount) FROM egress` + db.db.impl.AsOfSystemInterval(asOfSystemInterval)
-	err = db.db.QueryRow(ctx, db.db.Rebind(query), expiredSince, projectID[:], startOfMonth, periodEnd).Scan(&egress)
-	if errors.Is(err, sql.ErrNoRows) || egress == nil {
-		return 0, nil
+				)`
+
+// maxCycleStartDay is the last day of month a billing cycle can start on, so that it exists in every month.
+const maxCycleStartDay = 28
+
+// projectBandwidthPeriod returns the billing cycle containing the given day, which starts on cycleStartDay
+// of a month, together with the time since when the allocated bandwidth has expired.
+func projectBandwidthPeriod(year int, month time.Month, day, cycleStartDay int) (expiredSince, cycleStart, cycleEnd time.Time) {
+	date := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+
+	cycleStart = time.Date(year, month, cycleStartDay, 0, 0, 0, 0, time.UTC)
+	if day < cycleStartDay {
+		cycleStart = cycleStart.AddDate(0, -1, 0)
+	}
+	cycleEnd = cycleStart.AddDate(0, 1, 0)
+
+	expiredSince = date.AddDate(0, 0, -allocatedExpirationInDays)
+	if expiredSince.Before(cycleStart) {
+		expiredSince = cycleStart
+	}
+
+	return expiredSince, cycleStart, cycleEnd
+}
+
+// VerifyProjectBandwidthConsistency compares the project bandwidth daily rollups of a month with the
+// bucket bandwidth rollups, including the archived ones, and returns the days on which they differ by
+// more than tolerance bytes. Same as in GetProjectBandwidth, the settled amount is compared for days
+// where allocations have expired and the allocated amount otherwise.
+func (db *ProjectAccounting) VerifyProjectBandwidthConsistency(ctx context.Context, projectID uuid.UUID, year int, month time.Month, tolerance int64) (inconsistencies []accounting.BandwidthInconsistency, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	startOfMonth := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	periodEnd := time.Date(year, month+1, 1, 0, 0, 0, 0, time.UTC)
+
+	// allocations of past months have expired.
+	expiredSince := periodEnd
+	if now := time.Now().UTC(); !now.Before(startOfMonth) && now.Before(periodEnd) {
+		expiredSince, _, _ = projectBandwidthPeriod(year, month, now.Day(), 1)
+	}
+
+	dailyRollups := make(map[time.Time]int64)
+	bucketRollups := make(map[time.Time]int64)
+	addEgress := func(egress map[time.Time]int64, interval time.Time, allocated, settled int64) {
+		interval = interval.UTC()
+		day := time.Date(interval.Year(), interval.Month(), interval.Day(), 0, 0, 0, 0, time.UTC)
+		if day.Before(expiredSince) {
+			egress[day] += settled
+		} else {
+			egress[day] += allocated
+		}
+	}
+
+	for _, source := range []struct {
+		egress map[time.Time]int64
+		query  string
+		args   []interface{}
+	}{
+		{
+			egress: dailyRollups,
+			query: `
+				SELECT interval_day, egress_allocated, egress_settled
+				FROM project_bandwidth_daily_rollups
+				WHERE project_id = ? AND ` + intervalCondition("interval_day"),
+			args: []interface{}{projectID[:], startOfMonth, periodEnd},
+		},
+		{
+			egress: bucketRollups,
+			query: `
+				SELECT interval_start, allocated, settled
+				FROM ` + rollupsWithArchives("interval_start, allocated, settled", "project_id = ? AND "+intervalCondition("interval_start")+" AND action = ?"),
+			args: []interface{}{
+				projectID[:], startOfMonth, periodEnd, pb.PieceAction_GET,
+				projectID[:], startOfMonth, periodEnd, pb.PieceAction_GET,
+			},
+		},
+	} {
+		err := func() (err error) {
+			rows, err := db.db.QueryContext(ctx, db.db.Rebind(source.query), source.args...)
+			if err != nil {
+				return err
+			}
+			defer func() { err = errs.Combine(err, rows.Close()) }()
+
+			for rows.Next() {
+				var interval time.Time
+				var allocated, settled int64
+				if err := rows.Scan(&interval, &allocated, &settled); err != nil {
+					return err
+				}
+				addEgress(source.egress, interval, allocated, settled)
+			}
+			return rows.Err()
+		}()
+		if err != nil {
+			return nil, Error.Wrap(err)
+		}
+	}
+
+	for day := startOfMonth; day.Before(periodEnd); day = day.AddDate(0, 0, 1) {
+		difference := dailyRollups[day] - bucketRollups[day]
+		if difference > tolerance || -difference > tolerance {
+			inconsistencies = append(inconsistencies, accounting.BandwidthInconsistency{
+				Day:           day,
+				DailyRollup:   dailyRollups[day],
+				BucketRollups: bucketRollups[day],
+			})
+		}
 	}
 
-	return *egress, err
+	return inconsistencies, nil
 }
 
 // GetProjectDailyBandwidth returns project bandwidth (allocated and settled) for the specified day.
@@ -199,632 +618,3128 @@ func (db *ProjectAccounting) GetProjectDailyBandwidth(ctx context.Context, proje
 	return allocated, settled, err
 }
 
-// DeleteProjectBandwidthBefore deletes project bandwidth rollups before the given time.
-func (db *ProjectAccounting) DeleteProjectBandwidthBefore(ctx context.Context, before time.Time) (err error) {
+// ApplyProjectBandwidthAdjustment adds the deltas to the project bandwidth daily rollup of the day,
+// creating the rollup when it doesn't exist. The adjusted bandwidth is clamped at zero and the adjustment
+// is recorded with both the requested and the applied deltas within the same transaction, so that
+// clamped bandwidth isn't silently lost.
+func (db *ProjectAccounting) ApplyProjectBandwidthAdjustment(ctx context.Context, projectID uuid.UUID, day time.Time, deltaAllocated, deltaSettled int64, reason string) (adjustment accounting.ProjectBandwidthAdjustment, err error) {
 	defer mon.Task()(&ctx)(&err)
 
-	_, err = db.db.DB.ExecContext(ctx, db.db.Rebind("DELETE FROM project_bandwidth_daily_rollups WHERE interval_day < ?"), before)
+	if reason == "" {
+		return accounting.ProjectBandwidthAdjustment{}, accounting.ErrInvalidArgument.New("reason is required")
+	}
+	if deltaAllocated == 0 && deltaSettled == 0 {
+		return accounting.ProjectBandwidthAdjustment{}, accounting.ErrInvalidArgument.New("adjustment is empty")
+	}
 
-	return err
+	day = day.UTC()
+	adjustment = accounting.ProjectBandwidthAdjustment{
+		ProjectID:          projectID,
+		Day:                time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC),
+		RequestedAllocated: deltaAllocated,
+		RequestedSettled:   deltaSettled,
+		Reason:             reason,
+		CreatedAt:          time.Now().UTC(),
+	}
+
+	err = db.db.WithTx(ctx, func(ctx context.Context, tx *dbx.Tx) error {
+		_, err := tx.Tx.ExecContext(ctx, `
+			INSERT INTO project_bandwidth_daily_rollups (project_id, interval_day, egress_allocated, egress_settled, egress_dead)
+			VALUES ($1, $2, 0, 0, 0)
+			ON CONFLICT (project_id, interval_day) DO NOTHING
+		`, projectID[:], adjustment.Day)
+		if err != nil {
+			return err
+		}
+
+		var allocated, settled int64
+		err = tx.Tx.QueryRowContext(ctx, `
+			SELECT egress_allocated, egress_settled
+			FROM project_bandwidth_daily_rollups
+			WHERE project_id = $1 AND interval_day = $2
+			FOR UPDATE
+		`, projectID[:], adjustment.Day).Scan(&allocated, &settled)
+		if err != nil {
+			return err
+		}
+
+		clamp := func(current, delta int64) int64 {
+			if current+delta < 0 {
+				return -current
+			}
+			return delta
+		}
+		adjustment.AppliedAllocated = clamp(allocated, deltaAllocated)
+		adjustment.AppliedSettled = clamp(settled, deltaSettled)
+
+		_, err = tx.Tx.ExecContext(ctx, `
+			UPDATE project_bandwidth_daily_rollups
+			SET egress_allocated = $3, egress_settled = $4
+			WHERE project_id = $1 AND interval_day = $2
+		`, projectID[:], adjustment.Day, allocated+adjustment.AppliedAllocated, settled+adjustment.AppliedSettled)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Tx.ExecContext(ctx, `
+			INSERT INTO project_bandwidth_adjustments (
+				project_id, interval_day,
+				requested_allocated, requested_settled,
+				applied_allocated, applied_settled,
+				reason, created_at
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		`, projectID[:], adjustment.Day,
+			adjustment.RequestedAllocated, adjustment.RequestedSettled,
+			adjustment.AppliedAllocated, adjustment.AppliedSettled,
+			adjustment.Reason, adjustment.CreatedAt)
+		return err
+	})
+	if err != nil {
+		return accounting.ProjectBandwidthAdjustment{}, Error.Wrap(err)
+	}
+
+	if adjustment.AppliedAllocated != adjustment.RequestedAllocated || adjustment.AppliedSettled != adjustment.RequestedSettled {
+		mon.Counter("project_bandwidth_adjustments_clamped").Inc(1) //mon:locked
+	}
+
+	return adjustment, nil
 }
 
-// UpdateProjectUsageLimit updates project usage limit.
-func (db *ProjectAccounting) UpdateProjectUsageLimit(ctx context.Context, projectID uuid.UUID, limit memory.Size) (err error) {
+// GetProjectBandwidthAdjustments returns the recorded bandwidth adjustments of a project since a given time, ordered by time.
+func (db *ProjectAccounting) GetProjectBandwidthAdjustments(ctx context.Context, projectID uuid.UUID, since time.Time) (adjustments []accounting.ProjectBandwidthAdjustment, err error) {
 	defer mon.Task()(&ctx)(&err)
 
-	_, err = db.db.Update_Project_By_Id(ctx,
-		dbx.Project_Id(projectID[:]),
-		dbx.Project_Update_Fields{
-			UsageLimit: dbx.Project_UsageLimit(limit.Int64()),
-		},
-	)
+	rows, err := db.db.QueryContext(ctx, db.db.Rebind(`
+		SELECT
+			interval_day,
+			requested_allocated, requested_settled,
+			applied_allocated, applied_settled,
+			reason, created_at
+		FROM project_bandwidth_adjustments
+		WHERE project_id = ? AND created_at >= ?
+		ORDER BY created_at
+	`), projectID[:], since)
+	if err != nil {
+		return nil, Error.Wrap(err)
+	}
+	defer func() { err = errs.Combine(err, rows.Close()) }()
+
+	for rows.Next() {
+		adjustment := accounting.ProjectBandwidthAdjustment{ProjectID: projectID}
+		err := rows.Scan(&adjustment.Day,
+			&adjustment.RequestedAllocated, &adjustment.RequestedSettled,
+			&adjustment.AppliedAllocated, &adjustment.AppliedSettled,
+			&adjustment.Reason, &adjustment.CreatedAt)
+		if err != nil {
+			return nil, Error.Wrap(err)
+		}
+		adjustments = append(adjustments, adjustment)
+	}
 
-	return err
+	return adjustments, Error.Wrap(rows.Err())
 }
 
-// UpdateProjectBandwidthLimit updates project bandwidth limit.
-func (db *ProjectAccounting) UpdateProjectBandwidthLimit(ctx context.Context, projectID uuid.UUID, limit memory.Size) (err error) {
+// DeleteProjectBandwidthBefore deletes project bandwidth rollups before the given time
+// and returns the number of deleted rollups. Rollups are deleted in batches of batchSize;
+// a zero or negative batchSize deletes all of them with a single statement.
+func (db *ProjectAccounting) DeleteProjectBandwidthBefore(ctx context.Context, before time.Time, batchSize int) (deletedCount int64, err error) {
 	defer mon.Task()(&ctx)(&err)
 
-	_, err = db.db.Update_Project_By_Id(ctx,
-		dbx.Project_Id(projectID[:]),
-		dbx.Project_Update_Fields{
-			BandwidthLimit: dbx.Project_BandwidthLimit(limit.Int64()),
-		},
-	)
+	return db.deleteInBatches(ctx, "project_bandwidth_daily_rollups", deleteProjectBandwidthCondition, batchSize, before)
+}
 
-	return err
+// DeleteProjectBandwidthBeforeDryRun returns the number of project bandwidth rollups
+// DeleteProjectBandwidthBefore would delete, without deleting them.
+func (db *ProjectAccounting) DeleteProjectBandwidthBeforeDryRun(ctx context.Context, before time.Time) (count int64, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	return db.countRows(ctx, "project_bandwidth_daily_rollups", deleteProjectBandwidthCondition, before)
 }
 
-// GetProjectStorageLimit returns project storage usage limit.
-func (db *ProjectAccounting) GetProjectStorageLimit(ctx context.Context, projectID uuid.UUID) (_ *int64, err error) {
+// DeleteTalliesBefore deletes bucket storage tallies before the given time and returns the
+// number of deleted tallies. Tallies are deleted in batches of batchSize; a zero or negative
+// batchSize deletes all of them with a single statement.
+func (db *ProjectAccounting) DeleteTalliesBefore(ctx context.Context, before time.Time, batchSize int) (deleted int64, err error) {
 	defer mon.Task()(&ctx)(&err)
 
-	row, err := db.db.Get_Project_UsageLimit_By_Id(ctx,
-		dbx.Project_Id(projectID[:]),
-	)
-	if err != nil {
-		return nil, err
+	return db.deleteInBatches(ctx, "bucket_storage_tallies", deleteTalliesCondition, batchSize, before)
+}
+
+// DeleteTalliesBeforeDryRun returns the number of bucket storage tallies DeleteTalliesBefore
+// would delete, without deleting them.
+func (db *ProjectAccounting) DeleteTalliesBeforeDryRun(ctx context.Context, before time.Time) (count int64, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	return db.countRows(ctx, "bucket_storage_tallies", deleteTalliesCondition, before)
+}
+
+// The delete conditions are shared by the deletions and their dry runs, so that the dry runs
+// count exactly the rows which would be deleted.
+const (
+	deleteProjectBandwidthCondition = "interval_day < $1"
+	deleteTalliesCondition          = "interval_start < $1"
+	deleteRollupsCondition          = "interval_start < $1"
+)
+
+// DeleteProjectAccountingData deletes all storage and bandwidth accounting of a project and returns
+// the number of deleted rows per table. Rows are deleted in batches of batchSize; a zero or negative
+// batchSize deletes the rows of every table with a single statement.
+//
+// Rows of every table are deleted independently, hence on failure the deletion can be resumed by
+// calling it again.
+func (db *ProjectAccounting) DeleteProjectAccountingData(ctx context.Context, projectID uuid.UUID, batchSize int) (deletions accounting.ProjectAccountingDeletions, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	for _, table := range []struct {
+		name    string
+		deleted *int64
+	}{
+		{"bucket_storage_tallies", &deletions.StorageTallies},
+		{"bucket_storage_tally_archives", &deletions.StorageTallyArchives},
+		{"bucket_bandwidth_rollups", &deletions.BandwidthRollups},
+		{"bucket_bandwidth_rollup_archives", &deletions.BandwidthRollupArchives},
+		{"project_bandwidth_daily_rollups", &deletions.DailyBandwidthRollups},
+	} {
+		*table.deleted, err = db.deleteInBatches(ctx, table.name, "project_id = $1", batchSize, projectID[:])
+		if err != nil {
+			return deletions, err
+		}
 	}
 
-	return row.UsageLimit, nil
+	return deletions, nil
 }
 
-// GetProjectBandwidthLimit returns project bandwidth usage limit.
-func (db *ProjectAccounting) GetProjectBandwidthLimit(ctx context.Context, projectID uuid.UUID) (_ *int64, err error) {
+// DeleteBucketAccountingData deletes storage tallies and bandwidth rollups of a bucket before the given
+// time and returns the number of deleted rows per table. Rows are deleted in batches, hence on failure
+// the deletion can be resumed by calling it again.
+func (db *ProjectAccounting) DeleteBucketAccountingData(ctx context.Context, projectID uuid.UUID, bucketName string, before time.Time) (deletions accounting.ProjectAccountingDeletions, err error) {
 	defer mon.Task()(&ctx)(&err)
 
-	row, err := db.db.Get_Project_BandwidthLimit_By_Id(ctx,
-		dbx.Project_Id(projectID[:]),
-	)
-	if err != nil {
-		return nil, err
+	// project_bandwidth_daily_rollups aren't tracked per bucket.
+	for _, table := range []struct {
+		name    string
+		deleted *int64
+	}{
+		{"bucket_storage_tallies", &deletions.StorageTallies},
+		{"bucket_storage_tally_archives", &deletions.StorageTallyArchives},
+		{"bucket_bandwidth_rollups", &deletions.BandwidthRollups},
+		{"bucket_bandwidth_rollup_archives", &deletions.BandwidthRollupArchives},
+	} {
+		*table.deleted, err = db.deleteInBatches(ctx, table.name,
+			"project_id = $1 AND bucket_name = $2 AND interval_start < $3", bucketAccountingDeleteBatchSize,
+			projectID[:], []byte(bucketName), before)
+		if err != nil {
+			return deletions, err
+		}
 	}
 
-	return row.BandwidthLimit, nil
+	return deletions, nil
 }
 
-// GetProjectTotal retrieves project usage for a given period.
-func (db *ProjectAccounting) GetProjectTotal(ctx context.Context, projectID uuid.UUID, since, before time.Time) (usage *accounting.ProjectUsage, err error) {
+// ApplyRetention archives or deletes the accounting rows past the retention configured for their
+// table at now and returns the outcome per table. Tables are processed one after another, hence
+// on failure the summary contains the tables processed so far and applying the retention again
+// resumes the work.
+func (db *ProjectAccounting) ApplyRetention(ctx context.Context, config accounting.RetentionConfig, now time.Time) (summary accounting.RetentionSummary, err error) {
 	defer mon.Task()(&ctx)(&err)
-	since = timeTruncateDown(since)
-	bucketNames, err := db.getBucketsSinceAndBefore(ctx, projectID, since, before)
-	if err != nil {
-		return nil, err
+
+	if err := config.Validate(); err != nil {
+		return summary, err
+	}
+
+	// rollups are per day, a day is only deleted once it's entirely past the retention.
+	dailyCutoff := config.DailyBandwidthRollups.Cutoff(now)
+	dailyCutoff = time.Date(dailyCutoff.Year(), dailyCutoff.Month(), dailyCutoff.Day(), 0, 0, 0, 0, time.UTC)
+
+	for _, table := range []struct {
+		name      string
+		retention accounting.TableRetention
+		cutoff    time.Time
+		result    *accounting.RetentionResult
+		apply     func(cutoff time.Time) (int64, error)
+	}{
+		{
+			name:      "bucket_bandwidth_rollups",
+			retention: config.BandwidthRollups,
+			cutoff:    config.BandwidthRollups.Cutoff(now),
+			result:    &summary.BandwidthRollups,
+			apply: func(cutoff time.Time) (int64, error) {
+				if config.BandwidthRollups.Mode == accounting.RetentionArchive {
+					archived, err := db.ArchiveRollupsBefore(ctx, cutoff, config.BatchSize)
+					return int64(archived), err
+				}
+				return db.deleteInBatches(ctx, "bucket_bandwidth_rollups", deleteRollupsCondition, config.BatchSize, cutoff)
+			},
+		},
+		{
+			name:      "bucket_storage_tallies",
+			retention: config.StorageTallies,
+			cutoff:    config.StorageTallies.Cutoff(now),
+			result:    &summary.StorageTallies,
+			apply: func(cutoff time.Time) (int64, error) {
+				if config.StorageTallies.Mode == accounting.RetentionArchive {
+					archived, err := db.ArchiveStorageTalliesBefore(ctx, cutoff, config.BatchSize)
+					return int64(archived), err
+				}
+				return db.DeleteTalliesBefore(ctx, cutoff, config.BatchSize)
+			},
+		},
+		{
+			name:      "project_bandwidth_daily_rollups",
+			retention: config.DailyBandwidthRollups,
+			cutoff:    dailyCutoff,
+			result:    &summary.DailyBandwidthRollups,
+			apply: func(cutoff time.Time) (int64, error) {
+				return db.DeleteProjectBandwidthBefore(ctx, cutoff, config.BatchSize)
+			},
+		},
+	} {
+		if !table.retention.Enabled() {
+			continue
+		}
+
+		table.result.Mode = table.retention.Mode
+		table.result.Cutoff = table.cutoff
+
+		rows, err := table.apply(table.result.Cutoff)
+		table.result.Rows = rows
+		mon.Counter("accounting_retention_rows", monkit.NewSeriesTag("table", table.name)).Inc(rows)
+		if err != nil {
+			return summary, Error.Wrap(err)
+		}
+	}
+
+	return summary, nil
+}
+
+// countRows returns the number of rows of table matching condition.
+func (db *ProjectAccounting) countRows(ctx context.Context, table, condition string, args ...interface{}) (count int64, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	err = db.db.QueryRow(ctx, `SELECT count(*) FROM `+pgutil.QuoteIdentifier(table)+` WHERE `+condition, args...).Scan(&count)
+	return count, Error.Wrap(err)
+}
+
+// deleteInBatches deletes the rows of table matching condition in batches of batchSize and returns
+// the number of deleted rows. A zero or negative batchSize deletes them with a single statement.
+// The condition refers to args with $1 ... $n placeholders.
+func (db *ProjectAccounting) deleteInBatches(ctx context.Context, table, condition string, batchSize int, args ...interface{}) (deleted int64, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	table = pgutil.QuoteIdentifier(table)
+
+	if batchSize <= 0 {
+		result, err := db.db.DB.ExecContext(ctx, `DELETE FROM `+table+` WHERE `+condition, args...)
+		if err != nil {
+			return 0, Error.Wrap(err)
+		}
+		deleted, err = result.RowsAffected()
+		return deleted, Error.Wrap(err)
+	}
+
+	limit := fmt.Sprintf("$%d", len(args)+1)
+	args = append(args, batchSize)
+
+	var query string
+	switch db.db.impl {
+	case dbutil.Cockroach:
+		query = `
+			DELETE FROM ` + table + `
+			WHERE ` + condition + `
+			LIMIT ` + limit
+	case dbutil.Postgres:
+		// Postgres doesn't support DELETE ... LIMIT, so the batch is selected by ctid.
+		query = `
+			DELETE FROM ` + table + `
+			WHERE ctid IN (
+				SELECT ctid FROM ` + table + `
+				WHERE ` + condition + `
+				LIMIT ` + limit + `
+			)`
+	default:
+		return 0, Error.New("unsupported database: %v", db.db.impl)
+	}
+
+	for {
+		result, err := db.db.DB.ExecContext(ctx, query, args...)
+		if err != nil {
+			return deleted, Error.Wrap(err)
+		}
+		rowCount, err := result.RowsAffected()
+		if err != nil {
+			return deleted, Error.Wrap(err)
+		}
+		deleted += rowCount
+
+		if rowCount < int64(batchSize) {
+			return deleted, nil
+		}
+	}
+}
+
+// UpdateProjectUsageLimit updates project usage limit.
+func (db *ProjectAccounting) UpdateProjectUsageLimit(ctx context.Context, projectID uuid.UUID, limit memory.Size) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	usage := limit.Int64()
+	return db.UpdateProjectLimits(ctx, projectID, accounting.ProjectLimits{Usage: &usage})
+}
+
+// UpdateProjectBandwidthLimit updates project bandwidth limit.
+func (db *ProjectAccounting) UpdateProjectBandwidthLimit(ctx context.Context, projectID uuid.UUID, limit memory.Size) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	bandwidth := limit.Int64()
+	return db.UpdateProjectLimits(ctx, projectID, accounting.ProjectLimits{Bandwidth: &bandwidth})
+}
+
+// ClearProjectUsageLimit clears project usage limit.
+func (db *ProjectAccounting) ClearProjectUsageLimit(ctx context.Context, projectID uuid.UUID) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	clearLimit := accounting.ClearLimit
+	return db.UpdateProjectLimits(ctx, projectID, accounting.ProjectLimits{Usage: &clearLimit})
+}
+
+// ClearProjectBandwidthLimit clears project bandwidth limit.
+func (db *ProjectAccounting) ClearProjectBandwidthLimit(ctx context.Context, projectID uuid.UUID) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	clearLimit := accounting.ClearLimit
+	return db.UpdateProjectLimits(ctx, projectID, accounting.ProjectLimits{Bandwidth: &clearLimit})
+}
+
+// UpdateProjectLimits updates project storage and bandwidth limits with a single update.
+// Nil limits are left unchanged, limits set to accounting.ClearLimit are cleared.
+//
+// When ctx has a limit change actor, the changes are recorded in the limit history.
+func (db *ProjectAccounting) UpdateProjectLimits(ctx context.Context, projectID uuid.UUID, limits accounting.ProjectLimits) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	if limits.Usage == nil && limits.Bandwidth == nil {
+		return nil
+	}
+
+	if actor, ok := accounting.GetLimitChangeActor(ctx); ok {
+		_, err = db.updateProjectLimitsWithHistory(ctx, actor, []uuid.UUID{projectID}, limits)
+		return err
+	}
+
+	var updateFields dbx.Project_Update_Fields
+	if limits.Usage != nil {
+		if *limits.Usage == accounting.ClearLimit {
+			updateFields.UsageLimit = dbx.Project_UsageLimit_Null()
+		} else {
+			updateFields.UsageLimit = dbx.Project_UsageLimit(*limits.Usage)
+		}
+	}
+	if limits.Bandwidth != nil {
+		if *limits.Bandwidth == accounting.ClearLimit {
+			updateFields.BandwidthLimit = dbx.Project_BandwidthLimit_Null()
+		} else {
+			updateFields.BandwidthLimit = dbx.Project_BandwidthLimit(*limits.Bandwidth)
+		}
+	}
+	_, err = db.db.Update_Project_By_Id(ctx,
+		dbx.Project_Id(projectID[:]),
+		updateFields,
+	)
+
+	return err
+}
+
+// UpdateProjectLimitsBulk updates storage and bandwidth limits of all given projects with a single
+// statement and returns the number of updated projects. Nil limits are left unchanged, limits set
+// to accounting.ClearLimit are cleared.
+//
+// When ctx has a limit change actor, the changes are recorded in the limit history.
+func (db *ProjectAccounting) UpdateProjectLimitsBulk(ctx context.Context, projectIDs []uuid.UUID, limits accounting.ProjectLimits) (updated int64, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	if len(projectIDs) == 0 || (limits.Usage == nil && limits.Bandwidth == nil) {
+		return 0, nil
+	}
+
+	if actor, ok := accounting.GetLimitChangeActor(ctx); ok {
+		return db.updateProjectLimitsWithHistory(ctx, actor, projectIDs, limits)
+	}
+
+	query, args := projectLimitsUpdate(projectIDs, limits)
+	result, err := db.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, Error.Wrap(err)
+	}
+
+	updated, err = result.RowsAffected()
+	return updated, Error.Wrap(err)
+}
+
+// updateProjectLimitsWithHistory updates limits of all given projects and records the changes
+// made by actor within the same transaction. Only limits which actually change are recorded.
+func (db *ProjectAccounting) updateProjectLimitsWithHistory(ctx context.Context, actor string, projectIDs []uuid.UUID, limits accounting.ProjectLimits) (updated int64, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	changedAt := time.Now()
+	err = db.db.WithTx(ctx, func(ctx context.Context, tx *dbx.Tx) error {
+		_, err := tx.Tx.ExecContext(ctx, `
+			SELECT 1 FROM projects WHERE id = ANY($1::bytea[]) FOR UPDATE
+		`, pgutil.UUIDArray(projectIDs))
+		if err != nil {
+			return err
+		}
+
+		// the limit fields are named after the columns.
+		for _, change := range []struct {
+			field string
+			limit *int64
+		}{
+			{field: accounting.LimitFieldUsage, limit: limits.Usage},
+			{field: accounting.LimitFieldBandwidth, limit: limits.Bandwidth},
+		} {
+			if change.limit == nil {
+				continue
+			}
+			var newValue *int64
+			if *change.limit != accounting.ClearLimit {
+				newValue = change.limit
+			}
+
+			_, err := tx.Tx.ExecContext(ctx, `
+				INSERT INTO project_limit_changes (project_id, field, old_value, new_value, changed_at, actor)
+				SELECT id, $2::TEXT, `+pgutil.QuoteIdentifier(change.field)+`, $3::INT8, $4::TIMESTAMPTZ, $5::TEXT
+				FROM projects
+				WHERE id = ANY($1::bytea[]) AND `+pgutil.QuoteIdentifier(change.field)+` IS DISTINCT FROM $3::INT8
+			`, pgutil.UUIDArray(projectIDs), change.field, newValue, changedAt, actor)
+			if err != nil {
+				return err
+			}
+		}
+
+		query, args := projectLimitsUpdate(projectIDs, limits)
+		result, err := tx.Tx.ExecContext(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+
+		updated, err = result.RowsAffected()
+		return err
+	})
+	if err != nil {
+		return 0, Error.Wrap(err)
+	}
+
+	return updated, nil
+}
+
+// projectLimitsUpdate returns the statement updating limits of all given projects.
+func projectLimitsUpdate(projectIDs []uuid.UUID, limits accounting.ProjectLimits) (query string, args []interface{}) {
+	var sets []string
+	args = []interface{}{pgutil.UUIDArray(projectIDs)}
+	setLimit := func(column string, limit *int64) {
+		switch {
+		case limit == nil:
+		case *limit == accounting.ClearLimit:
+			sets = append(sets, column+" = NULL")
+		default:
+			args = append(args, *limit)
+			sets = append(sets, fmt.Sprintf("%s = $%d", column, len(args)))
+		}
+	}
+	setLimit("usage_limit", limits.Usage)
+	setLimit("bandwidth_limit", limits.Bandwidth)
+
+	return `
+		UPDATE projects
+		SET ` + strings.Join(sets, ", ") + `
+		WHERE id = ANY($1::bytea[])
+	`, args
+}
+
+// GetProjectLimitHistory returns the recorded limit changes of a project since a given time, ordered by time.
+func (db *ProjectAccounting) GetProjectLimitHistory(ctx context.Context, projectID uuid.UUID, since time.Time) (changes []accounting.ProjectLimitChange, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	rows, err := db.db.QueryContext(ctx, db.db.Rebind(`
+		SELECT field, old_value, new_value, changed_at, actor
+		FROM project_limit_changes
+		WHERE project_id = ? AND changed_at >= ?
+		ORDER BY changed_at, field
+	`), projectID[:], since)
+	if err != nil {
+		return nil, Error.Wrap(err)
+	}
+	defer func() { err = errs.Combine(err, rows.Close()) }()
+
+	for rows.Next() {
+		change := accounting.ProjectLimitChange{ProjectID: projectID}
+		if err := rows.Scan(&change.Field, &change.OldValue, &change.NewValue, &change.ChangedAt, &change.Actor); err != nil {
+			return nil, Error.Wrap(err)
+		}
+		changes = append(changes, change)
+	}
+
+	return changes, Error.Wrap(rows.Err())
+}
+
+// GetProjectStorageLimit returns project storage usage limit.
+func (db *ProjectAccounting) GetProjectStorageLimit(ctx context.Context, projectID uuid.UUID) (_ *int64, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	row, err := db.db.Get_Project_UsageLimit_By_Id(ctx,
+		dbx.Project_Id(projectID[:]),
+	)
+	if err != nil {
+		return nil, projectNotFound(err)
+	}
+
+	return row.UsageLimit, nil
+}
+
+// GetProjectBandwidthLimit returns project bandwidth usage limit.
+func (db *ProjectAccounting) GetProjectBandwidthLimit(ctx context.Context, projectID uuid.UUID) (_ *int64, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	row, err := db.db.Get_Project_BandwidthLimit_By_Id(ctx,
+		dbx.Project_Id(projectID[:]),
+	)
+	if err != nil {
+		return nil, projectNotFound(err)
+	}
+
+	return row.BandwidthLimit, nil
+}
+
+// projectNotFound replaces the error of a project lookup with accounting.ErrProjectNotFound
+// when the project doesn't exist.
+func projectNotFound(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return Error.Wrap(accounting.ErrProjectNotFound)
+	}
+	return err
+}
+
+// GetProjectTotal retrieves project usage for a given period.
+func (db *ProjectAccounting) GetProjectTotal(ctx context.Context, projectID uuid.UUID, since, before time.Time, asOfSystemInterval time.Duration) (usage *accounting.ProjectUsage, err error) {
+	defer mon.Task()(&ctx)(&err)
+	since = timeTruncateDown(since.UTC())
+	before = before.UTC()
+
+	bucketsTallies, err := db.getBucketsTallies(ctx, projectID, since, before, asOfSystemInterval)
+	if err != nil {
+		return nil, err
+	}
+
+	egress, rollupRows, err := db.getEgressByAction(ctx, projectID, since, before, asOfSystemInterval)
+	if err != nil {
+		return nil, err
+	}
+
+	shape := usageQueryShape{Buckets: int64(len(bucketsTallies)), RollupRows: rollupRows}
+	for _, tallies := range bucketsTallies {
+		shape.TallyRows += int64(len(tallies))
+	}
+	mon.IntVal("project_total_buckets").Observe(shape.Buckets)        //mon:locked
+	mon.IntVal("project_total_tally_rows").Observe(shape.TallyRows)   //mon:locked
+	mon.IntVal("project_total_rollup_rows").Observe(shape.RollupRows) //mon:locked
+
+	usage = new(accounting.ProjectUsage)
+	usage.Egress = memory.Size(egress[pb.PieceAction_GET]).Int64()
+	usage.GetEgress = usage.Egress
+	usage.AuditEgress = memory.Size(egress[pb.PieceAction_GET_AUDIT]).Int64()
+	usage.RepairEgress = memory.Size(egress[pb.PieceAction_GET_REPAIR]).Int64()
+	// sum up storage and objects
+	for _, tallies := range bucketsTallies {
+		db.addTalliesToUsage(usage, tallies, before)
+	}
+
+	usage.Since = since
+	usage.Before = before
+	return usage, nil
+}
+
+// SaveProjectMonthlyUsage stores the usage of a project for the month of period, replacing
+// the previously stored usage of the month.
+func (db *ProjectAccounting) SaveProjectMonthlyUsage(ctx context.Context, projectID uuid.UUID, period time.Time, usage accounting.ProjectUsage) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	_, err = db.db.ExecContext(ctx, db.db.Rebind(`
+		INSERT INTO project_monthly_usages (project_id, period, storage, egress, object_count, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (project_id, period)
+		DO UPDATE SET
+			storage = EXCLUDED.storage,
+			egress = EXCLUDED.egress,
+			object_count = EXCLUDED.object_count,
+			updated_at = EXCLUDED.updated_at
+	`), projectID[:], monthStart(period), usage.Storage, usage.Egress, usage.ObjectCount, time.Now())
+
+	return Error.Wrap(err)
+}
+
+// GetProjectMonthlyUsage returns the stored usage of a project for the month of period.
+// It returns an error wrapping sql.ErrNoRows when the usage of the month isn't stored.
+func (db *ProjectAccounting) GetProjectMonthlyUsage(ctx context.Context, projectID uuid.UUID, period time.Time) (_ *accounting.ProjectUsage, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	start := monthStart(period)
+	usage := &accounting.ProjectUsage{
+		Since:  start,
+		Before: start.AddDate(0, 1, 0),
+	}
+	err = db.db.QueryRowContext(ctx, db.db.Rebind(`
+		SELECT storage, egress, object_count
+		FROM project_monthly_usages
+		WHERE project_id = ? AND period = ?
+	`), projectID[:], start).Scan(&usage.Storage, &usage.Egress, &usage.ObjectCount)
+	if err != nil {
+		return nil, Error.Wrap(err)
+	}
+
+	return usage, nil
+}
+
+// SaveClosedProjectMonthlyUsage computes the usage of a project for the month of period the same
+// way as GetProjectTotal and stores it. The month has to be over.
+func (db *ProjectAccounting) SaveClosedProjectMonthlyUsage(ctx context.Context, projectID uuid.UUID, period time.Time) (_ *accounting.ProjectUsage, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	start := monthStart(period)
+	end := start.AddDate(0, 1, 0)
+	if time.Now().Before(end) {
+		return nil, accounting.ErrInvalidArgument.New("month %s isn't over", start.Format("2006-01"))
+	}
+
+	usage, err := db.GetProjectTotal(ctx, projectID, start, end, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.SaveProjectMonthlyUsage(ctx, projectID, start, *usage)
+	if err != nil {
+		return nil, err
+	}
+
+	return usage, nil
+}
+
+// monthStart returns the start of the month of t in UTC.
+func monthStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// GetProjectTotalByPartner retrieves project usage for a given period split by
+// the partner the buckets are attributed to. Usage of buckets without attribution,
+// or attributed to a partner not in partnerNames, is returned under the empty name.
+// The usages always sum up to the result of GetProjectTotal.
+func (db *ProjectAccounting) GetProjectTotalByPartner(ctx context.Context, projectID uuid.UUID, partnerNames []string, since, before time.Time) (usages map[string]*accounting.ProjectUsage, err error) {
+	defer mon.Task()(&ctx)(&err)
+	since = timeTruncateDown(since.UTC())
+	before = before.UTC()
+
+	usages = make(map[string]*accounting.ProjectUsage, len(partnerNames)+1)
+	usages[""] = &accounting.ProjectUsage{Since: since, Before: before}
+
+	// buckets are attributed by partner id, hence the names are resolved to ids.
+	partnerNameByID := make(map[uuid.UUID]string, len(partnerNames))
+	for _, name := range partnerNames {
+		partner, err := rewards.DefaultPartnersDB.ByName(ctx, name)
+		if err != nil {
+			if rewards.ErrPartnerNotExist.Has(err) {
+				return nil, accounting.ErrInvalidArgument.Wrap(err)
+			}
+			return nil, Error.Wrap(err)
+		}
+		partnerNameByID[partner.UUID] = name
+		usages[name] = &accounting.ProjectUsage{Since: since, Before: before}
+	}
+
+	attributions, err := db.getBucketAttributions(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	usageOf := func(bucket string) *accounting.ProjectUsage {
+		if name, ok := partnerNameByID[attributions[bucket]]; ok {
+			return usages[name]
+		}
+		return usages[""]
+	}
+
+	bucketsTallies, err := db.getBucketsTallies(ctx, projectID, since, before, 0)
+	if err != nil {
+		return nil, err
+	}
+	for bucket, tallies := range bucketsTallies {
+		db.addTalliesToUsage(usageOf(bucket), tallies, before)
+	}
+
+	bucketsEgress, err := db.getBucketsEgress(ctx, projectID, since, before)
+	if err != nil {
+		return nil, err
+	}
+	for bucket, egress := range bucketsEgress {
+		usageOf(bucket).Egress += memory.Size(egress).Int64()
+	}
+
+	return usages, nil
+}
+
+// getBucketsTallies returns storage tallies of every bucket of the project
+// within the timeframe, ordered by interval start in descending order.
+func (db *ProjectAccounting) getBucketsTallies(ctx context.Context, projectID uuid.UUID, since, before time.Time, asOfSystemInterval time.Duration) (_ map[string][]*accounting.BucketStorageTally, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	bucketNames, err := db.getBucketsSinceAndBefore(ctx, projectID, since, before, asOfSystemInterval)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.checkScanLimit(len(bucketNames)); err != nil {
+		return nil, err
 	}
 
 	storageQuery := db.db.Rebind(`
 		SELECT
-			bucket_storage_tallies.interval_start,
-			bucket_storage_tallies.total_bytes,
-			bucket_storage_tallies.inline,
-			bucket_storage_tallies.remote,
-			bucket_storage_tallies.object_count
-		FROM
-			bucket_storage_tallies
-		WHERE
-			bucket_storage_tallies.project_id = ? AND
-			bucket_storage_tallies.bucket_name = ? AND
-			bucket_storage_tallies.interval_start >= ? AND
-			bucket_storage_tallies.interval_start <= ?
-		ORDER BY bucket_storage_tallies.interval_start DESC
+			bucket_storage_tallies.interval_start,
+			bucket_storage_tallies.total_bytes,
+			bucket_storage_tallies.inline,
+			bucket_storage_tallies.remote,
+			bucket_storage_tallies.object_count
+		FROM
+			bucket_storage_tallies
+		` + db.db.impl.AsOfSystemInterval(asOfSystemInterval) + `
+		WHERE
+			bucket_storage_tallies.project_id = ? AND
+			bucket_storage_tallies.bucket_name = ? AND
+			` + intervalCondition("bucket_storage_tallies.interval_start") + `
+		ORDER BY bucket_storage_tallies.interval_start DESC
+	`)
+
+	bucketsTallies := make(map[string][]*accounting.BucketStorageTally)
+
+	for _, bucket := range bucketNames {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		storageTallies := make([]*accounting.BucketStorageTally, 0)
+
+		storageTalliesRows, err := db.db.QueryContext(ctx, storageQuery, projectID[:], []byte(bucket), since, before)
+		if err != nil {
+			return nil, err
+		}
+		// generating tallies for each bucket name.
+		for storageTalliesRows.Next() {
+			tally := accounting.BucketStorageTally{}
+
+			err = storageTalliesRows.Scan(&tally.IntervalStart, &tally.TotalBytes, &tally.InlineBytes, &tally.RemoteBytes, &tally.ObjectCount)
+			if err != nil {
+				return nil, errs.Combine(err, storageTalliesRows.Close())
+			}
+			if tally.TotalBytes == 0 {
+				tally.TotalBytes = tally.InlineBytes + tally.RemoteBytes
+			}
+
+			tally.BucketName = bucket
+			storageTallies = append(storageTallies, &tally)
+		}
+
+		err = errs.Combine(storageTalliesRows.Err(), storageTalliesRows.Close())
+		if err != nil {
+			return nil, err
+		}
+
+		bucketsTallies[bucket] = storageTallies
+	}
+
+	return bucketsTallies, nil
+}
+
+// getBucketAttributions returns the partner each attributed bucket of the project belongs to.
+func (db *ProjectAccounting) getBucketAttributions(ctx context.Context, projectID uuid.UUID) (_ map[string]uuid.UUID, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	rows, err := db.db.QueryContext(ctx, db.db.Rebind(`
+		SELECT bucket_name, partner_id
+		FROM value_attributions
+		WHERE project_id = ?
+	`), projectID[:])
+	if err != nil {
+		return nil, err
+	}
+	defer func() { err = errs.Combine(err, rows.Close()) }()
+
+	attributions := make(map[string]uuid.UUID)
+	for rows.Next() {
+		var bucketName []byte
+		var partnerID uuid.UUID
+		if err := rows.Scan(&bucketName, &partnerID); err != nil {
+			return nil, err
+		}
+		attributions[string(bucketName)] = partnerID
+	}
+
+	return attributions, rows.Err()
+}
+
+// getBucketsEgress returns total egress (settled + inline) of each bucket of the project
+// in selected time period. Only PieceAction_GET is processed, same as in GetProjectTotal.
+func (db *ProjectAccounting) getBucketsEgress(ctx context.Context, projectID uuid.UUID, since, before time.Time) (_ map[string]int64, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	egressQuery := db.db.Rebind(`
+		SELECT
+			bucket_name, COALESCE(SUM(settled) + SUM(inline), 0)
+		FROM
+			bucket_bandwidth_rollups
+		WHERE
+			project_id = ? AND
+			` + intervalCondition("interval_start") + ` AND
+			action = ?
+		GROUP BY bucket_name
+	`)
+
+	rows, err := db.db.QueryContext(ctx, egressQuery, projectID[:], since, before, pb.PieceAction_GET)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { err = errs.Combine(err, rows.Close()) }()
+
+	egress := make(map[string]int64)
+	for rows.Next() {
+		var bucketName []byte
+		var amount int64
+		if err := rows.Scan(&bucketName, &amount); err != nil {
+			return nil, err
+		}
+		egress[string(bucketName)] = amount
+	}
+
+	return egress, rows.Err()
+}
+
+// addTalliesToUsage sums up storage and objects from tallies of a single bucket,
+// including the inline and remote split of legacy tallies, which are expected to be ordered by interval start in descending order.
+func (db *ProjectAccounting) addTalliesToUsage(usage *accounting.ProjectUsage, tallies []*accounting.BucketStorageTally, before time.Time) {
+	for i := len(tallies) - 1; i >= 0; i-- {
+		current := tallies[i]
+		var next time.Time
+		if i > 0 {
+			next = tallies[i-1].IntervalStart
+		}
+		hours := db.tallyHours(current.IntervalStart, next, before)
+		usage.Storage += memory.Size(current.Bytes()).Float64() * hours
+		usage.InlineStorage += memory.Size(current.InlineBytes).Float64() * hours
+		usage.RemoteStorage += memory.Size(current.RemoteBytes).Float64() * hours
+		usage.ObjectCount += float64(current.ObjectCount) * hours
+	}
+}
+
+// tallyHours returns for how many hours a tally taken at intervalStart is
+// accounted, when the next tally was taken at next.
+//
+// Hours are calculated from the next tally, so without Options.ExpectedTallyInterval
+// the most recent tally (with zero next) is skipped. Otherwise it's accounted for the
+// expected interval, but not past before. Options.MaxTallyGap caps the hours, so that
+// missed tally runs don't inflate usage.
+//
+// A next tally with the same or an earlier interval start, e.g. after a restart
+// of the tally chore with a skewed clock, accounts no hours, so that such tallies
+// never subtract usage.
+func (db *ProjectAccounting) tallyHours(intervalStart, next, before time.Time) float64 {
+	var gap time.Duration
+	if next.IsZero() {
+		gap = db.db.opts.ExpectedTallyInterval
+		if remaining := before.Sub(intervalStart); remaining < gap {
+			gap = remaining
+		}
+	} else {
+		gap = next.Sub(intervalStart)
+		if gap <= 0 {
+			mon.Counter("tally_hours_clamped").Inc(1) //mon:locked
+			return 0
+		}
+	}
+
+	if maxGap := db.db.opts.MaxTallyGap; maxGap > 0 && gap > maxGap {
+		gap = maxGap
+	}
+	if gap < 0 {
+		return 0
+	}
+	return gap.Hours()
+}
+
+// GetProjectTotals retrieves usage for multiple projects for a given period.
+// Results match calling GetProjectTotal for every project, however the data
+// is fetched with a fixed number of queries.
+func (db *ProjectAccounting) GetProjectTotals(ctx context.Context, projectIDs []uuid.UUID, since, before time.Time) (_ map[uuid.UUID]*accounting.ProjectUsage, err error) {
+	defer mon.Task()(&ctx)(&err)
+	since = timeTruncateDown(since.UTC())
+	before = before.UTC()
+
+	usages := make(map[uuid.UUID]*accounting.ProjectUsage, len(projectIDs))
+	if len(projectIDs) == 0 {
+		return usages, nil
+	}
+	for _, projectID := range projectIDs {
+		usages[projectID] = &accounting.ProjectUsage{
+			Since:  since,
+			Before: before,
+		}
+	}
+
+	err = func() (err error) {
+		talliesQuery := db.db.Rebind(`
+			SELECT
+				project_id, bucket_name, interval_start,
+				total_bytes, inline, remote, object_count
+			FROM bucket_storage_tallies
+			WHERE
+				project_id = ANY(?::bytea[]) AND
+				` + intervalCondition("interval_start") + `
+			ORDER BY project_id, bucket_name, interval_start DESC
+		`)
+
+		rows, err := db.db.QueryContext(ctx, talliesQuery, pgutil.UUIDArray(projectIDs), since, before)
+		if err != nil {
+			return err
+		}
+		defer func() { err = errs.Combine(err, rows.Close()) }()
+
+		var current metabase.BucketLocation
+		var tallies []*accounting.BucketStorageTally
+		flush := func() {
+			if len(tallies) > 0 {
+				db.addTalliesToUsage(usages[current.ProjectID], tallies, before)
+			}
+			tallies = tallies[:0]
+		}
+
+		for rows.Next() {
+			var projectID uuidScanner
+			var bucketName []byte
+			tally := &accounting.BucketStorageTally{}
+			err := rows.Scan(&projectID, &bucketName, &tally.IntervalStart,
+				&tally.TotalBytes, &tally.InlineBytes, &tally.RemoteBytes, &tally.ObjectCount)
+			if err != nil {
+				return err
+			}
+			if !projectID.Valid {
+				if err := db.corruptRow("bucket_storage_tallies", projectID); err != nil {
+					return err
+				}
+				continue
+			}
+			if tally.TotalBytes == 0 {
+				tally.TotalBytes = tally.InlineBytes + tally.RemoteBytes
+			}
+			location := metabase.BucketLocation{ProjectID: projectID.UUID, BucketName: string(bucketName)}
+
+			if location != current {
+				flush()
+				current = location
+			}
+			tally.ProjectID = location.ProjectID
+			tally.BucketName = location.BucketName
+			tallies = append(tallies, tally)
+		}
+		flush()
+
+		return rows.Err()
+	}()
+	if err != nil {
+		return nil, Error.Wrap(err)
+	}
+
+	err = func() (err error) {
+		egressQuery := db.db.Rebind(`
+			SELECT project_id, COALESCE(SUM(settled) + SUM(inline), 0)
+			FROM bucket_bandwidth_rollups
+			WHERE
+				project_id = ANY(?::bytea[]) AND
+				` + intervalCondition("interval_start") + ` AND
+				action = ?
+			GROUP BY project_id
+		`)
+
+		rows, err := db.db.QueryContext(ctx, egressQuery, pgutil.UUIDArray(projectIDs), since, before, pb.PieceAction_GET)
+		if err != nil {
+			return err
+		}
+		defer func() { err = errs.Combine(err, rows.Close()) }()
+
+		for rows.Next() {
+			var projectID uuidScanner
+			var egress int64
+			if err := rows.Scan(&projectID, &egress); err != nil {
+				return err
+			}
+			if !projectID.Valid {
+				if err := db.corruptRow("bucket_bandwidth_rollups", projectID); err != nil {
+					return err
+				}
+				continue
+			}
+			usages[projectID.UUID].Egress = memory.Size(egress).Int64()
+		}
+
+		return rows.Err()
+	}()
+	if err != nil {
+		return nil, Error.Wrap(err)
+	}
+
+	return usages, nil
+}
+
+// GetActiveProjects returns IDs of projects with nonzero storage or egress within [since, before),
+// ordered by project ID. Egress includes archived bandwidth rollups. Projects are read in batches
+// of activeProjectsBatchSize, so that a single query doesn't return every project at once.
+func (db *ProjectAccounting) GetActiveProjects(ctx context.Context, since, before time.Time) (_ []uuid.UUID, err error) {
+	defer mon.Task()(&ctx)(&err)
+	since = timeTruncateDown(since.UTC())
+	before = before.UTC()
+
+	activeQuery := db.db.Rebind(`
+		SELECT project_id FROM bucket_storage_tallies
+		WHERE
+			` + intervalCondition("interval_start") + ` AND
+			(total_bytes > 0 OR inline > 0 OR remote > 0) AND
+			project_id > ?
+		UNION
+		SELECT project_id FROM ` + rollupsWithArchives("project_id", intervalCondition("interval_start")+` AND
+			action = ? AND
+			(settled > 0 OR inline > 0) AND
+			project_id > ?`) + `
+		ORDER BY project_id
+		LIMIT ?
+	`)
+
+	var projectIDs []uuid.UUID
+	after := []byte{}
+	for {
+		count, err := func() (count int, err error) {
+			rows, err := db.db.QueryContext(ctx, activeQuery,
+				since, before, after,
+				since, before, pb.PieceAction_GET, after,
+				since, before, pb.PieceAction_GET, after,
+				activeProjectsBatchSize)
+			if err != nil {
+				return 0, err
+			}
+			defer func() { err = errs.Combine(err, rows.Close()) }()
+
+			for rows.Next() {
+				var projectID uuidScanner
+				if err := rows.Scan(&projectID); err != nil {
+					return 0, err
+				}
+				// the next batch continues after corrupt rows as well.
+				after = projectID.Raw
+				count++
+
+				if !projectID.Valid {
+					if err := db.corruptRow("bucket_storage_tallies", projectID); err != nil {
+						return 0, err
+					}
+					continue
+				}
+				projectIDs = append(projectIDs, projectID.UUID)
+			}
+			return count, rows.Err()
+		}()
+		if err != nil {
+			return nil, Error.Wrap(err)
+		}
+
+		if count < activeProjectsBatchSize {
+			return projectIDs, nil
+		}
+	}
+}
+
+// intervalCondition returns a condition matching rows with column in the half-open range [since, before),
+// taking since and before as arguments. Range queries use it, so that a row at the boundary of two
+// adjacent periods, such as the first hour of a month, is accounted in only one of them.
+func intervalCondition(column string) string {
+	return column + " >= ? AND " + column + " < ?"
+}
+
+// rollupsWithArchives returns a subquery selecting columns of both bucket_bandwidth_rollups
+// and bucket_bandwidth_rollup_archives rows matching condition. The arguments of condition
+// have to be passed twice, once for each table.
+//
+// Archiving moves a rollup with a single statement, hence a statement using the subquery
+// sees every rollup exactly once, even while the archiving is in progress.
+func rollupsWithArchives(columns, condition string) string {
+	return `(
+			SELECT ` + columns + ` FROM bucket_bandwidth_rollups WHERE ` + condition + `
+			UNION ALL
+			SELECT ` + columns + ` FROM bucket_bandwidth_rollup_archives WHERE ` + condition + `
+		) AS rollups`
+}
+
+// GetProjectEgressByAction returns total egress (settled + inline) of the project for every piece action
+// in selected time period, including the archived rollups. Egress of actions unknown to the satellite
+// is summed up under accounting.OtherPieceActions.
+func (db *ProjectAccounting) GetProjectEgressByAction(ctx context.Context, projectID uuid.UUID, since, before time.Time) (_ map[pb.PieceAction]int64, err error) {
+	defer mon.Task()(&ctx)(&err)
+	since = timeTruncateDown(since.UTC())
+	before = before.UTC()
+
+	egress, _, err := db.getEgressByAction(ctx, projectID, since, before, 0)
+	return egress, Error.Wrap(err)
+}
+
+// GetProjectHourlyBandwidth returns the bandwidth of the project summed up per hour over all buckets
+// and actions within [from, to), ordered by hour. Archived rollups are included when includeArchives
+// is set. Ranges longer than maxHourlyBandwidthRange are rejected to keep the query bounded.
+func (db *ProjectAccounting) GetProjectHourlyBandwidth(ctx context.Context, projectID uuid.UUID, from, to time.Time, includeArchives bool) (_ []accounting.ProjectHourlyBandwidth, err error) {
+	defer mon.Task()(&ctx)(&err)
+	from = from.UTC().Truncate(time.Hour)
+	to = to.UTC()
+
+	if to.Before(from) {
+		return nil, accounting.ErrInvalidArgument.New("to (%v) is before from (%v)", to, from)
+	}
+	if to.Sub(from) > maxHourlyBandwidthRange {
+		return nil, accounting.ErrInvalidArgument.New("range from %v to %v exceeds %v", from, to, maxHourlyBandwidthRange)
+	}
+
+	const columns = "interval_start, allocated, settled, inline"
+	condition := "project_id = ? AND " + intervalCondition("interval_start")
+	args := []interface{}{projectID[:], from, to}
+
+	source := "(SELECT " + columns + " FROM bucket_bandwidth_rollups WHERE " + condition + ") AS rollups"
+	if includeArchives {
+		source = rollupsWithArchives(columns, condition)
+		args = append(args, args...)
+	}
+
+	query := db.db.Rebind(`
+		SELECT
+			date_trunc('hour', interval_start) AS hour,
+			COALESCE(SUM(allocated), 0)::INT8,
+			COALESCE(SUM(settled), 0)::INT8,
+			COALESCE(SUM(inline), 0)::INT8
+		FROM ` + source + `
+		GROUP BY hour
+		ORDER BY hour
+	`)
+
+	rows, err := db.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, Error.Wrap(err)
+	}
+	defer func() { err = errs.Combine(err, rows.Close()) }()
+
+	var hourly []accounting.ProjectHourlyBandwidth
+	for rows.Next() {
+		var usage accounting.ProjectHourlyBandwidth
+		if err := rows.Scan(&usage.Hour, &usage.Allocated, &usage.Settled, &usage.Inline); err != nil {
+			return nil, Error.Wrap(err)
+		}
+		usage.Hour = usage.Hour.UTC()
+		hourly = append(hourly, usage)
+	}
+
+	return hourly, Error.Wrap(rows.Err())
+}
+
+// getEgressByAction returns total egress (settled + inline) of each bucket_bandwidth_rollup
+// in selected time period, project id, including the archived rollups, grouped by action.
+// It also returns the number of rollup rows summed up.
+func (db *ProjectAccounting) getEgressByAction(ctx context.Context, projectID uuid.UUID, since, before time.Time, asOfSystemInterval time.Duration) (_ map[pb.PieceAction]int64, rollupRows int64, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	egressQuery := db.db.Rebind(`
+		SELECT
+			action, COALESCE(SUM(settled) + SUM(inline), 0), COUNT(*)
+		FROM
+			` + rollupsWithArchives("action, settled, inline", "project_id = ? AND "+intervalCondition("interval_start")) + `
+		` + db.db.impl.AsOfSystemInterval(asOfSystemInterval) + `
+		GROUP BY action
+	`)
+
+	rows, err := db.db.QueryContext(ctx, egressQuery, projectID[:], since, before, projectID[:], since, before)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() { err = errs.Combine(err, rows.Close()) }()
+
+	egress := make(map[pb.PieceAction]int64)
+	for rows.Next() {
+		var action pb.PieceAction
+		var amount, count int64
+		if err := rows.Scan(&action, &amount, &count); err != nil {
+			return nil, 0, err
+		}
+		if _, ok := pb.PieceAction_name[int32(action)]; !ok {
+			action = accounting.OtherPieceActions
+		}
+		egress[action] += amount
+		rollupRows += count
+	}
+
+	return egress, rollupRows, rows.Err()
+}
+
+// GetBucketEgressTotals returns egress (settled + inline) of every bucket of the project per piece action
+// in selected time period, including the archived rollups, with a single grouped query. Only rollups of the
+// given actions are included, nil actions include all of them. Egress of actions unknown to the satellite
+// is summed up under accounting.OtherPieceActions.
+func (db *ProjectAccounting) GetBucketEgressTotals(ctx context.Context, projectID uuid.UUID, since, before time.Time, actions []pb.PieceAction) (_ map[string]map[pb.PieceAction]int64, err error) {
+	defer mon.Task()(&ctx)(&err)
+	since = timeTruncateDown(since.UTC())
+	before = before.UTC()
+
+	egress := make(map[string]map[pb.PieceAction]int64)
+	if actions != nil && len(actions) == 0 {
+		return egress, nil
+	}
+
+	condition := "project_id = ? AND " + intervalCondition("interval_start")
+	args := []interface{}{projectID[:], since, before}
+	if actions != nil {
+		actionValues := make([]int32, 0, len(actions))
+		for _, action := range actions {
+			actionValues = append(actionValues, int32(action))
+		}
+		condition += " AND action = ANY(?::INT4[])"
+		args = append(args, pgutil.Int4Array(actionValues))
+	}
+
+	egressQuery := db.db.Rebind(`
+		SELECT
+			bucket_name, action, COALESCE(SUM(settled) + SUM(inline), 0)
+		FROM
+			` + rollupsWithArchives("bucket_name, action, settled, inline", condition) + `
+		GROUP BY bucket_name, action
+	`)
+
+	rows, err := db.db.QueryContext(ctx, egressQuery, append(args, args...)...)
+	if err != nil {
+		return nil, Error.Wrap(err)
+	}
+	defer func() { err = errs.Combine(err, rows.Close()) }()
+
+	for rows.Next() {
+		var bucketName []byte
+		var action pb.PieceAction
+		var amount int64
+		if err := rows.Scan(&bucketName, &action, &amount); err != nil {
+			return nil, Error.Wrap(err)
+		}
+		if _, ok := pb.PieceAction_name[int32(action)]; !ok {
+			action = accounting.OtherPieceActions
+		}
+
+		bucketEgress, ok := egress[string(bucketName)]
+		if !ok {
+			bucketEgress = make(map[pb.PieceAction]int64)
+			egress[string(bucketName)] = bucketEgress
+		}
+		bucketEgress[action] += amount
+	}
+
+	return egress, Error.Wrap(rows.Err())
+}
+
+// GetBucketUsageRollups retrieves summed usage rollups for every bucket of particular project for a given period.
+func (db *ProjectAccounting) GetBucketUsageRollups(ctx context.Context, projectID uuid.UUID, since, before time.Time, asOfSystemInterval time.Duration) (_ []accounting.BucketUsageRollup, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	var bucketUsageRollups []accounting.BucketUsageRollup
+	shape, err := db.iterateBucketUsageRollups(ctx, projectID, since, before, asOfSystemInterval, true, func(ctx context.Context, rollup accounting.BucketUsageRollup) error {
+		bucketUsageRollups = append(bucketUsageRollups, rollup)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	mon.IntVal("bucket_usage_rollups_buckets").Observe(shape.Buckets)        //mon:locked
+	mon.IntVal("bucket_usage_rollups_tally_rows").Observe(shape.TallyRows)   //mon:locked
+	mon.IntVal("bucket_usage_rollups_rollup_rows").Observe(shape.RollupRows) //mon:locked
+
+	return bucketUsageRollups, nil
+}
+
+// IterateBucketUsageRollups calls fn with summed usage rollup of every bucket of particular project
+// for a given period, as soon as the rollup of the bucket is computed.
+func (db *ProjectAccounting) IterateBucketUsageRollups(ctx context.Context, projectID uuid.UUID, since, before time.Time, asOfSystemInterval time.Duration, fn func(context.Context, accounting.BucketUsageRollup) error) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	_, err = db.iterateBucketUsageRollups(ctx, projectID, since, before, asOfSystemInterval, false, fn)
+	return err
+}
+
+// iterateBucketUsageRollups calls fn with summed usage rollup of every bucket of particular project
+// and returns the shape of the processed data. When limited, Options.MaxAccountingScanResults is
+// applied to the number of buckets.
+func (db *ProjectAccounting) iterateBucketUsageRollups(ctx context.Context, projectID uuid.UUID, since, before time.Time, asOfSystemInterval time.Duration, limited bool, fn func(context.Context, accounting.BucketUsageRollup) error) (shape usageQueryShape, err error) {
+	since = timeTruncateDown(since.UTC())
+	before = before.UTC()
+
+	buckets, err := db.getBucketsSinceAndBefore(ctx, projectID, since, before, asOfSystemInterval)
+	if err != nil {
+		return shape, err
+	}
+	if limited {
+		if err := db.checkScanLimit(len(buckets)); err != nil {
+			return shape, err
+		}
+	}
+
+	// TODO: should be optimized
+	for _, bucket := range buckets {
+		if err := ctx.Err(); err != nil {
+			return shape, err
+		}
+
+		bucketRollup, bucketShape, err := db.getBucketUsageRollup(ctx, projectID, bucket, since, before, asOfSystemInterval)
+		if err != nil {
+			return shape, err
+		}
+		shape.add(bucketShape)
+
+		if err := fn(ctx, bucketRollup); err != nil {
+			return shape, err
+		}
+	}
+
+	return shape, nil
+}
+
+// GetSingleBucketUsageRollup retrieves summed usage rollup of a single bucket for a given period.
+// A bucket without usage in the period results in an empty rollup.
+func (db *ProjectAccounting) GetSingleBucketUsageRollup(ctx context.Context, projectID uuid.UUID, bucketName string, since, before time.Time) (_ accounting.BucketUsageRollup, err error) {
+	defer mon.Task()(&ctx)(&err)
+	since = timeTruncateDown(since.UTC())
+	before = before.UTC()
+
+	rollup, _, err := db.getBucketUsageRollup(ctx, projectID, bucketName, since, before, 0)
+	return rollup, err
+}
+
+// GetBucketBandwidthBreakdown retrieves allocated and settled GET bandwidth of every bucket
+// of a project for a given period, ordered by bucket name.
+func (db *ProjectAccounting) GetBucketBandwidthBreakdown(ctx context.Context, projectID uuid.UUID, cursor accounting.BucketBandwidthBreakdownCursor, since, before time.Time) (page accounting.BucketBandwidthBreakdownPage, err error) {
+	defer mon.Task()(&ctx)(&err)
+	since = timeTruncateDown(since.UTC())
+	before = before.UTC()
+
+	if cursor.Limit <= 0 || cursor.Limit > bandwidthBreakdownLimit {
+		cursor.Limit = bandwidthBreakdownLimit
+	}
+
+	breakdownQuery := db.db.Rebind(`
+		SELECT
+			bucket_name,
+			COALESCE(SUM(allocated), 0),
+			COALESCE(SUM(settled), 0)
+		FROM bucket_bandwidth_rollups
+		WHERE
+			project_id = ? AND
+			action = ? AND
+			` + intervalCondition("interval_start") + ` AND
+			bucket_name > ?
+		GROUP BY bucket_name
+		ORDER BY bucket_name
+		LIMIT ?
+	`)
+
+	rows, err := db.db.QueryContext(ctx, breakdownQuery, projectID[:], pb.PieceAction_GET, since, before, []byte(cursor.StartAfter), cursor.Limit+1)
+	if err != nil {
+		return accounting.BucketBandwidthBreakdownPage{}, Error.Wrap(err)
+	}
+	defer func() { err = errs.Combine(err, rows.Close()) }()
+
+	page.Breakdowns = []accounting.BucketBandwidthBreakdown{}
+	for rows.Next() {
+		var bucketName []byte
+		var breakdown accounting.BucketBandwidthBreakdown
+		if err := rows.Scan(&bucketName, &breakdown.Allocated, &breakdown.Settled); err != nil {
+			return accounting.BucketBandwidthBreakdownPage{}, Error.Wrap(err)
+		}
+		breakdown.BucketName = string(bucketName)
+		breakdown.Difference = breakdown.Allocated - breakdown.Settled
+		page.Breakdowns = append(page.Breakdowns, breakdown)
+	}
+	if err := rows.Err(); err != nil {
+		return accounting.BucketBandwidthBreakdownPage{}, Error.Wrap(err)
+	}
+
+	if len(page.Breakdowns) > cursor.Limit {
+		page.More = true
+		page.Breakdowns = page.Breakdowns[:cursor.Limit]
+	}
+
+	return page, nil
+}
+
+// getBucketUsageRollup sums up bandwidth rollups and storage tallies of a single bucket
+// and returns the shape of the processed data.
+func (db *ProjectAccounting) getBucketUsageRollup(ctx context.Context, projectID uuid.UUID, bucket string, since, before time.Time, asOfSystemInterval time.Duration) (_ accounting.BucketUsageRollup, shape usageQueryShape, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	bucketRollup := accounting.BucketUsageRollup{
+		ProjectID:  projectID,
+		BucketName: []byte(bucket),
+		Since:      since,
+		Before:     before,
+	}
+
+	roullupsQuery := db.db.Rebind(`SELECT SUM(settled), SUM(inline), COUNT(*), action
+		FROM ` + rollupsWithArchives("settled, inline, action", "project_id = ? AND bucket_name = ? AND "+intervalCondition("interval_start")) + `
+		` + db.db.impl.AsOfSystemInterval(asOfSystemInterval) + `
+		GROUP BY action`)
+
+	// get bucket_bandwidth_rollups and the archived ones
+	rollupsRows, err := db.db.QueryContext(ctx, roullupsQuery,
+		projectID[:], []byte(bucket), since, before,
+		projectID[:], []byte(bucket), since, before)
+	if err != nil {
+		return accounting.BucketUsageRollup{}, shape, err
+	}
+	defer func() { err = errs.Combine(err, rollupsRows.Close()) }()
+
+	// fill egress
+	for rollupsRows.Next() {
+		var action pb.PieceAction
+		var settled, inline, count int64
+
+		err = rollupsRows.Scan(&settled, &inline, &count, &action)
+		if err != nil {
+			return accounting.BucketUsageRollup{}, shape, err
+		}
+		shape.RollupRows += count
+
+		switch action {
+		case pb.PieceAction_GET:
+			bucketRollup.GetEgress += memory.Size(settled + inline).GB()
+		case pb.PieceAction_GET_AUDIT:
+			bucketRollup.AuditEgress += memory.Size(settled + inline).GB()
+		case pb.PieceAction_GET_REPAIR:
+			bucketRollup.RepairEgress += memory.Size(settled + inline).GB()
+		default:
+			continue
+		}
+	}
+	if err := rollupsRows.Err(); err != nil {
+		return accounting.BucketUsageRollup{}, shape, err
+	}
+
+	bucketStorageTallies, err := db.getBucketStorageTallies(ctx, projectID, bucket, since, before, asOfSystemInterval)
+	if err != nil {
+		return accounting.BucketUsageRollup{}, shape, err
+	}
+
+	shape.Buckets = 1
+	shape.TallyRows = int64(len(bucketStorageTallies))
+	bucketRollup.HasData = shape.TallyRows > 0 || shape.RollupRows > 0
+
+	// fill metadata, objects and stored data
+	for i := len(bucketStorageTallies) - 1; i >= 0; i-- {
+		current := bucketStorageTallies[i]
+
+		var next time.Time
+		if i > 0 {
+			next = bucketStorageTallies[i-1].IntervalStart
+		}
+		hours := db.tallyHours(current.IntervalStart, next, before)
+
+		if current.TotalBytes > 0 {
+			bucketRollup.TotalStoredData += memory.Size(current.TotalBytes).GB() * hours
+		} else {
+			bucketRollup.TotalStoredData += memory.Size(current.Remote+current.Inline).GB() * hours
+		}
+		bucketRollup.InlineStoredData += memory.Size(current.Inline).GB() * hours
+		bucketRollup.RemoteStoredData += memory.Size(current.Remote).GB() * hours
+		bucketRollup.MetadataSize += memory.Size(current.MetadataSize).GB() * hours
+		if current.TotalSegmentsCount > 0 {
+			bucketRollup.TotalSegments += float64(current.TotalSegmentsCount) * hours
+		} else {
+			bucketRollup.TotalSegments += float64(current.RemoteSegmentsCount+current.InlineSegmentsCount) * hours
+		}
+		bucketRollup.ObjectCount += float64(current.ObjectCount) * hours
+	}
+
+	return bucketRollup, shape, nil
+}
+
+// GetLatestBucketTallies returns the most recent tally of every bucket of the project ordered by bucket name.
+func (db *ProjectAccounting) GetLatestBucketTallies(ctx context.Context, projectID uuid.UUID) (tallies []accounting.BucketTally, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	rows, err := db.db.QueryContext(ctx, db.db.Rebind(`
+		SELECT DISTINCT ON (bucket_name)
+			bucket_name, object_count,
+			total_segments_count, remote_segments_count, inline_segments_count,
+			total_bytes, inline, remote, metadata_size
+		FROM bucket_storage_tallies
+		WHERE project_id = ?
+		ORDER BY bucket_name, interval_start DESC
+	`), projectID[:])
+	if err != nil {
+		return nil, Error.Wrap(err)
+	}
+	defer func() { err = errs.Combine(err, rows.Close()) }()
+
+	for rows.Next() {
+		var bucketName []byte
+		var totalSegments, remoteSegments, inlineSegments int64
+		var totalBytes, inline, remote int64
+		tally := accounting.BucketTally{}
+		err := rows.Scan(&bucketName, &tally.ObjectCount,
+			&totalSegments, &remoteSegments, &inlineSegments,
+			&totalBytes, &inline, &remote, &tally.MetadataSize)
+		if err != nil {
+			return nil, Error.Wrap(err)
+		}
+
+		if totalBytes == 0 {
+			totalBytes = inline + remote
+		}
+		if totalSegments == 0 {
+			totalSegments = inlineSegments + remoteSegments
+		}
+
+		tally.BucketLocation = metabase.BucketLocation{ProjectID: projectID, BucketName: string(bucketName)}
+		tally.TotalSegments = totalSegments
+		tally.TotalBytes = totalBytes
+		tallies = append(tallies, tally)
+	}
+
+	return tallies, Error.Wrap(rows.Err())
+}
+
+// GetProjectStorageTotals returns the storage of the project summed over the most recent tally of every bucket.
+func (db *ProjectAccounting) GetProjectStorageTotals(ctx context.Context, projectID uuid.UUID) (bytes, segments, objects int64, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	// older tallies don't have total_bytes and total_segments_count set.
+	err = db.db.QueryRowContext(ctx, db.db.Rebind(`
+		SELECT
+			COALESCE(SUM(CASE WHEN total_bytes > 0 THEN total_bytes ELSE inline + remote END), 0)::INT8,
+			COALESCE(SUM(CASE WHEN total_segments_count > 0 THEN total_segments_count ELSE inline_segments_count + remote_segments_count END), 0)::INT8,
+			COALESCE(SUM(object_count), 0)::INT8
+		FROM (
+			SELECT DISTINCT ON (bucket_name)
+				total_bytes, inline, remote,
+				total_segments_count, remote_segments_count, inline_segments_count,
+				object_count
+			FROM bucket_storage_tallies
+			WHERE project_id = ?
+			ORDER BY bucket_name, interval_start DESC
+		) AS latest
+	`), projectID[:]).Scan(&bytes, &segments, &objects)
+	if err != nil {
+		return 0, 0, 0, Error.Wrap(err)
+	}
+
+	return bytes, segments, objects, nil
+}
+
+// GetProjectAccountingStatus returns the state of the accounting data of the project, read with
+// a single statement of MAX and COUNT subqueries. Subqueries without matching rows give zero values.
+func (db *ProjectAccounting) GetProjectAccountingStatus(ctx context.Context, projectID uuid.UUID) (status accounting.ProjectAccountingStatus, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	var latestTally, latestRollup, bandwidthArchiveCutoff, tallyArchiveCutoff *time.Time
+	err = db.db.QueryRowContext(ctx, `
+		SELECT
+			(SELECT MAX(interval_start) FROM bucket_storage_tallies WHERE project_id = $1),
+			(SELECT COUNT(*) FROM bucket_storage_tallies WHERE project_id = $1 AND interval_start = (
+				SELECT MAX(interval_start) FROM bucket_storage_tallies WHERE project_id = $1
+			)),
+			(SELECT COUNT(DISTINCT bucket_name) FROM bucket_storage_tallies WHERE project_id = $1),
+			(SELECT COUNT(*) FROM bucket_metainfos WHERE project_id = $1),
+			(SELECT MAX(interval_start) FROM bucket_bandwidth_rollups WHERE project_id = $1),
+			(SELECT MAX(interval_start) FROM bucket_bandwidth_rollup_archives WHERE project_id = $1),
+			(SELECT MAX(interval_start) FROM bucket_storage_tally_archives WHERE project_id = $1)
+	`, projectID[:]).Scan(
+		&latestTally, &status.LatestTallyBuckets, &status.TalliedBuckets, &status.Buckets,
+		&latestRollup, &bandwidthArchiveCutoff, &tallyArchiveCutoff)
+	if err != nil {
+		return accounting.ProjectAccountingStatus{}, Error.Wrap(err)
+	}
+
+	for _, t := range []struct {
+		value *time.Time
+		dest  *time.Time
+	}{
+		{latestTally, &status.LatestTally},
+		{latestRollup, &status.LatestBandwidthRollup},
+		{bandwidthArchiveCutoff, &status.BandwidthArchiveCutoff},
+		{tallyArchiveCutoff, &status.TallyArchiveCutoff},
+	} {
+		if t.value != nil {
+			*t.dest = t.value.UTC()
+		}
+	}
+
+	return status, nil
+}
+
+// GetProjectMetadataTotals returns the metadata bytes of every bucket of the project from its most recent tally,
+// along with the total over all buckets.
+func (db *ProjectAccounting) GetProjectMetadataTotals(ctx context.Context, projectID uuid.UUID) (total int64, buckets map[string]int64, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	rows, err := db.db.QueryContext(ctx, db.db.Rebind(`
+		SELECT DISTINCT ON (bucket_name)
+			bucket_name, metadata_size
+		FROM bucket_storage_tallies
+		WHERE project_id = ?
+		ORDER BY bucket_name, interval_start DESC
+	`), projectID[:])
+	if err != nil {
+		return 0, nil, Error.Wrap(err)
+	}
+	defer func() { err = errs.Combine(err, rows.Close()) }()
+
+	buckets = make(map[string]int64)
+	for rows.Next() {
+		var bucketName []byte
+		var metadataSize int64
+		if err := rows.Scan(&bucketName, &metadataSize); err != nil {
+			return 0, nil, Error.Wrap(err)
+		}
+		buckets[string(bucketName)] = metadataSize
+		total += metadataSize
+	}
+
+	return total, buckets, Error.Wrap(rows.Err())
+}
+
+// GetBucketObjectCountSeries returns object count history of a bucket for specified period of time,
+// ordered by interval start. When maxPoints is positive and there are more tallies, only every k-th
+// tally is returned, so that there are at most maxPoints points.
+func (db *ProjectAccounting) GetBucketObjectCountSeries(ctx context.Context, projectID uuid.UUID, bucketName string, since, before time.Time, maxPoints int) (_ []accounting.BucketObjectCountPoint, err error) {
+	defer mon.Task()(&ctx)(&err)
+	if maxPoints < 0 {
+		return nil, accounting.ErrInvalidArgument.New("maxPoints can not be negative")
+	}
+
+	seriesQuery := db.db.Rebind(`
+		SELECT interval_start, object_count, total_bytes, inline, remote
+		FROM bucket_storage_tallies
+		WHERE project_id = ? AND bucket_name = ? AND ` + intervalCondition("interval_start") + `
+		ORDER BY interval_start ASC
+	`)
+
+	rows, err := db.db.QueryContext(ctx, seriesQuery, projectID[:], []byte(bucketName), since.UTC(), before.UTC())
+	if err != nil {
+		return nil, Error.Wrap(err)
+	}
+	defer func() { err = errs.Combine(err, rows.Close()) }()
+
+	var points []accounting.BucketObjectCountPoint
+	for rows.Next() {
+		var point accounting.BucketObjectCountPoint
+		var inline, remote int64
+		err := rows.Scan(&point.IntervalStart, &point.ObjectCount, &point.TotalBytes, &inline, &remote)
+		if err != nil {
+			return nil, Error.Wrap(err)
+		}
+		if point.TotalBytes == 0 {
+			point.TotalBytes = inline + remote
+		}
+		points = append(points, point)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, Error.Wrap(err)
+	}
+
+	if maxPoints == 0 || len(points) <= maxPoints {
+		return points, nil
+	}
+
+	step := (len(points) + maxPoints - 1) / maxPoints
+	sampled := make([]accounting.BucketObjectCountPoint, 0, maxPoints)
+	for i := 0; i < len(points); i += step {
+		sampled = append(sampled, points[i])
+	}
+	return sampled, nil
+}
+
+// getBucketStorageTallies returns storage tallies of a single bucket
+// ordered by interval start in descending order.
+func (db *ProjectAccounting) getBucketStorageTallies(ctx context.Context, projectID uuid.UUID, bucket string, since, before time.Time, asOfSystemInterval time.Duration) (_ []*dbx.BucketStorageTally, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	storageQuery := db.db.Rebind(`SELECT interval_start,
+			total_bytes, inline, remote,
+			total_segments_count, remote_segments_count, inline_segments_count,
+			object_count, metadata_size
+		FROM bucket_storage_tallies
+		` + db.db.impl.AsOfSystemInterval(asOfSystemInterval) + `
+		WHERE project_id = ? AND bucket_name = ? AND ` + intervalCondition("interval_start") + `
+		ORDER BY interval_start DESC`)
+
+	rows, err := db.db.QueryContext(ctx, storageQuery, projectID[:], []byte(bucket), since, before)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { err = errs.Combine(err, rows.Close()) }()
+
+	var tallies []*dbx.BucketStorageTally
+	for rows.Next() {
+		tally := &dbx.BucketStorageTally{}
+		err := rows.Scan(&tally.IntervalStart,
+			&tally.TotalBytes, &tally.Inline, &tally.Remote,
+			&tally.TotalSegmentsCount, &tally.RemoteSegmentsCount, &tally.InlineSegmentsCount,
+			&tally.ObjectCount, &tally.MetadataSize)
+		if err != nil {
+			return nil, err
+		}
+		tallies = append(tallies, tally)
+	}
+
+	return tallies, rows.Err()
+}
+
+// prefixIncrement returns the lexicographically lowest byte string which is
+// greater than origPrefix and does not have origPrefix as a prefix. If no such
+// byte string exists (origPrefix is empty, or origPrefix contains only 0xff
+// bytes), returns false for ok.
+//
+// examples: prefixIncrement([]byte("abc"))          -> ([]byte("abd", true)
+//           prefixIncrement([]byte("ab\xff\xff"))   -> ([]byte("ac", true)
+//           prefixIncrement([]byte(""))             -> (nil, false)
+//           prefixIncrement([]byte("\x00"))         -> ([]byte("\x01", true)
+//           prefixIncrement([]byte("\xff\xff\xff")) -> (nil, false)
+//
+func prefixIncrement(origPrefix []byte) (incremented []byte, ok bool) {
+	incremented = make([]byte, len(origPrefix))
+	copy(incremented, origPrefix)
+	i := len(incremented) - 1
+	for i >= 0 {
+		if incremented[i] != 0xff {
+			incremented[i]++
+			return incremented[:i+1], true
+		}
+		i--
+	}
+
+	// there is no byte string which is greater than origPrefix and which does
+	// not have origPrefix as a prefix.
+	return nil, false
+}
+
+// prefixMatch creates a SQL expression which
+// will evaluate to true if and only if the value of expr starts with the value
+// of prefix.
+//
+// Returns also a slice of arguments that should be passed to the corresponding
+// db.Query* or db.Exec* to fill in parameters in the returned SQL expression.
+//
+// The returned SQL expression needs to be passed through Rebind(), as it uses
+// `?` markers instead of `$N`, because we don't know what N we would need to
+// use.
+func (db *ProjectAccounting) prefixMatch(expr string, prefix []byte) (string, []byte, error) {
+	incrementedPrefix, ok := prefixIncrement(prefix)
+	switch db.db.impl {
+	case dbutil.Postgres:
+		if !ok {
+			return fmt.Sprintf(`(%s >= ?)`, expr), nil, nil
+		}
+		return fmt.Sprintf(`(%s >= ? AND %s < ?)`, expr, expr), incrementedPrefix, nil
+	case dbutil.Cockroach:
+		if !ok {
+			return fmt.Sprintf(`(%s >= ?:::BYTEA)`, expr), nil, nil
+		}
+		return fmt.Sprintf(`(%s >= ?:::BYTEA AND %s < ?:::BYTEA)`, expr, expr), incrementedPrefix, nil
+	default:
+		return "", nil, errs.New("unhandled database: %v", db.db.driver)
+	}
+
+}
+
+// GetBucketTotals retrieves bucket usage totals for period of time.
+func (db *ProjectAccounting) GetBucketTotals(ctx context.Context, projectID uuid.UUID, cursor accounting.BucketUsageCursor, since, before time.Time, asOfSystemInterval time.Duration) (_ *accounting.BucketUsagePage, err error) {
+	defer mon.Task()(&ctx)(&err)
+	since = timeTruncateDown(since.UTC())
+	before = before.UTC()
+	bucketPrefix := []byte(cursor.Search)
+
+	if cursor.Limit > 50 {
+		cursor.Limit = 50
+	}
+	keyset := cursor.StartAfter != ""
+	if cursor.Page == 0 && !keyset {
+		return nil, accounting.ErrInvalidArgument.Wrap(fmt.Errorf("%w: page can not be 0", accounting.ErrInvalidCursor))
+	}
+
+	page := &accounting.BucketUsagePage{
+		Search: cursor.Search,
+		Limit:  cursor.Limit,
+	}
+	if !keyset {
+		page.Offset = uint64((cursor.Page - 1) * cursor.Limit)
+	}
+
+	bucketNameRange, incrPrefix, err := db.prefixMatch("name", bucketPrefix)
+	if err != nil {
+		return nil, err
+	}
+	countQuery := db.db.Rebind(`SELECT COUNT(name) FROM bucket_metainfos
+	` + db.db.impl.AsOfSystemInterval(asOfSystemInterval) + `
+	WHERE project_id = ? AND ` + bucketNameRange)
+
+	args := []interface{}{
+		projectID[:],
+		bucketPrefix,
+	}
+	if incrPrefix != nil {
+		args = append(args, incrPrefix)
+	}
+
+	maxMatches := db.db.opts.MaxBucketSearchMatches
+	if maxMatches <= 0 {
+		maxMatches = defaultMaxBucketSearchMatches
+	}
+	if len(bucketPrefix) > 0 {
+		// a short prefix can match a huge number of buckets, so the count is
+		// probed with a limit and the search is rejected when it's too broad.
+		countQuery = db.db.Rebind(`SELECT COUNT(*) FROM (
+			SELECT 1 FROM bucket_metainfos
+			` + db.db.impl.AsOfSystemInterval(asOfSystemInterval) + `
+			WHERE project_id = ? AND ` + bucketNameRange + `
+			LIMIT ?
+		) AS matches`)
+		args = append(args, maxMatches+1)
+	}
+
+	countRow := db.db.QueryRowContext(ctx, countQuery, args...)
+
+	err = countRow.Scan(&page.TotalCount)
+	if err != nil {
+		return nil, err
+	}
+	if len(bucketPrefix) > 0 && page.TotalCount > uint64(maxMatches) {
+		return nil, accounting.ErrSearchTooBroad.New("more than %d buckets match %q", maxMatches, cursor.Search)
+	}
+
+	if page.TotalCount == 0 {
+		return page, nil
+	}
+	if !keyset && page.Offset > page.TotalCount-1 {
+		return nil, accounting.ErrInvalidArgument.Wrap(accounting.ErrPageOutOfRange)
+	}
+
+	// the count and the listing aren't consistent with each other, buckets may be
+	// created or deleted in between. Hence one more bucket is listed to find out
+	// whether there is a next page, instead of relying on the count.
+	var buckets []string
+	bucketsQuery := `SELECT name FROM bucket_metainfos
+	` + db.db.impl.AsOfSystemInterval(asOfSystemInterval) + `
+	WHERE project_id = ? AND ` + bucketNameRange
+
+	args = []interface{}{
+		projectID[:],
+		bucketPrefix,
+	}
+	if incrPrefix != nil {
+		args = append(args, incrPrefix)
+	}
+	if keyset {
+		bucketsQuery += ` AND name > ? ORDER BY name ASC LIMIT ?`
+		args = append(args, []byte(cursor.StartAfter), page.Limit+1)
+	} else {
+		bucketsQuery += ` ORDER BY name ASC LIMIT ? OFFSET ?`
+		args = append(args, page.Limit+1, page.Offset)
+	}
+	bucketsQuery = db.db.Rebind(bucketsQuery)
+
+	bucketRows, err := db.db.QueryContext(ctx, bucketsQuery, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { err = errs.Combine(err, bucketRows.Close()) }()
+
+	for bucketRows.Next() {
+		var bucket string
+		err = bucketRows.Scan(&bucket)
+		if err != nil {
+			return nil, err
+		}
+
+		buckets = append(buckets, bucket)
+	}
+	if err := bucketRows.Err(); err != nil {
+		return nil, err
+	}
+
+	more := uint(len(buckets)) > page.Limit
+	if more {
+		buckets = buckets[:page.Limit]
+		page.NextCursor = buckets[len(buckets)-1]
+	}
+	if !keyset {
+		// don't report fewer buckets than the listing has seen.
+		seen := page.Offset + uint64(len(buckets))
+		if more {
+			seen++
+		}
+		if seen > page.TotalCount {
+			page.TotalCount = seen
+		}
+	}
+
+	rollupsQuery := db.db.Rebind(`SELECT COALESCE(SUM(settled) + SUM(inline), 0), COUNT(*)
+		FROM bucket_bandwidth_rollups
+		` + db.db.impl.AsOfSystemInterval(asOfSystemInterval) + `
+		WHERE project_id = ? AND bucket_name = ? AND ` + intervalCondition("interval_start") + ` AND action = ?`)
+
+	storageQuery := db.db.Rebind(`SELECT total_bytes, inline, remote, object_count, metadata_size
+		FROM bucket_storage_tallies
+		` + db.db.impl.AsOfSystemInterval(asOfSystemInterval) + `
+		WHERE project_id = ? AND bucket_name = ? AND ` + intervalCondition("interval_start") + `
+		ORDER BY interval_start DESC
+		LIMIT 1`)
+
+	parallelism := db.db.opts.BucketTotalsParallelism
+	if parallelism <= 0 {
+		parallelism = defaultBucketTotalsParallelism
+	}
+
+	// the usage of every bucket is fetched by separate queries, which are issued
+	// concurrently to not pay the round trips of the whole page sequentially.
+	bucketUsages := make([]accounting.BucketUsage, len(buckets))
+	group, groupCtx := errgroup.WithContext(ctx)
+	limiter := make(chan struct{}, parallelism)
+	for i, bucket := range buckets {
+		select {
+		case limiter <- struct{}{}:
+		case <-groupCtx.Done():
+		}
+		if groupCtx.Err() != nil {
+			break
+		}
+
+		i, bucket := i, bucket
+		group.Go(func() error {
+			defer func() { <-limiter }()
+
+			bucketUsage, err := db.getBucketTotal(groupCtx, rollupsQuery, storageQuery, projectID, bucket, since, before)
+			if err != nil {
+				return err
+			}
+			bucketUsages[i] = bucketUsage
+			return nil
+		})
+	}
+	if err := group.Wait(); err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	page.PageCount = uint(page.TotalCount / uint64(cursor.Limit))
+	if page.TotalCount%uint64(cursor.Limit) != 0 {
+		page.PageCount++
+	}
+
+	page.BucketUsages = bucketUsages
+	page.CurrentPage = cursor.Page
+	return page, nil
+}
+
+// getBucketTotal returns the usage of a single bucket for GetBucketTotals.
+func (db *ProjectAccounting) getBucketTotal(ctx context.Context, rollupsQuery, storageQuery string, projectID uuid.UUID, bucket string, since, before time.Time) (_ accounting.BucketUsage, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	bucketUsage := accounting.BucketUsage{
+		ProjectID:  projectID,
+		BucketName: bucket,
+		Since:      since,
+		Before:     before,
+	}
+
+	// get bucket_bandwidth_rollups
+	rollupRow := db.db.QueryRowContext(ctx, rollupsQuery, projectID[:], []byte(bucket), since, before, pb.PieceAction_GET)
+
+	var egress, rollupCount int64
+	err = rollupRow.Scan(&egress, &rollupCount)
+	if err != nil {
+		return bucketUsage, err
+	}
+
+	bucketUsage.Egress = memory.Size(egress).GB()
+	bucketUsage.HasData = rollupCount > 0
+
+	// tallies are stored under normalized bucket names.
+	tallyBucket := bucket
+	if normalized, err := accounting.NormalizeBucketName(bucket); err == nil {
+		tallyBucket = normalized
+	}
+	storageRow := db.db.QueryRowContext(ctx, storageQuery, projectID[:], []byte(tallyBucket), since, before)
+
+	var tally accounting.BucketStorageTally
+	var inline, remote int64
+	err = storageRow.Scan(&tally.TotalBytes, &inline, &remote, &tally.ObjectCount, &tally.MetadataSize)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		// no tally within the period, the storage is left zero.
+	case err != nil:
+		return bucketUsage, err
+	default:
+		bucketUsage.HasData = true
+	}
+
+	if tally.TotalBytes == 0 {
+		tally.TotalBytes = inline + remote
+	}
+
+	// fill storage and object count
+	bucketUsage.Storage = memory.Size(tally.Bytes()).GB()
+	bucketUsage.ObjectCount = tally.ObjectCount
+	bucketUsage.MetadataSize = tally.MetadataSize
+
+	return bucketUsage, nil
+}
+
+// GetBucketTotalsGrouped retrieves bucket usage totals for period of time aggregated per group of buckets
+// sharing the first component of the bucket name split on delimiter. Buckets without the delimiter form
+// their own group. Same as in GetBucketTotals, storage is taken from the latest tally of every bucket
+// within the period and egress is summed up.
+func (db *ProjectAccounting) GetBucketTotalsGrouped(ctx context.Context, projectID uuid.UUID, delimiter string, since, before time.Time) (_ []accounting.BucketUsage, err error) {
+	defer mon.Task()(&ctx)(&err)
+	since = timeTruncateDown(since.UTC())
+	before = before.UTC()
+
+	if delimiter == "" {
+		return nil, accounting.ErrInvalidArgument.New("delimiter must not be empty")
+	}
+
+	groupsQuery := db.db.Rebind(`
+		WITH latest_tallies AS (
+			SELECT DISTINCT ON (bucket_name)
+				bucket_name, total_bytes, inline, remote, object_count
+			FROM bucket_storage_tallies
+			WHERE project_id = ? AND ` + intervalCondition("interval_start") + `
+			ORDER BY bucket_name, interval_start DESC
+		), egress AS (
+			SELECT bucket_name, SUM(settled) + SUM(inline) AS amount
+			FROM bucket_bandwidth_rollups
+			WHERE project_id = ? AND ` + intervalCondition("interval_start") + ` AND action = ?
+			GROUP BY bucket_name
+		)
+		SELECT
+			split_part(convert_from(bucket_metainfos.name, 'UTF8'), ?, 1) AS bucket_group,
+			COALESCE(SUM(
+				CASE WHEN latest_tallies.total_bytes > 0
+					THEN latest_tallies.total_bytes
+					ELSE latest_tallies.inline + latest_tallies.remote
+				END
+			), 0)::INT8,
+			COALESCE(SUM(latest_tallies.object_count), 0)::INT8,
+			COALESCE(SUM(egress.amount), 0)::INT8
+		FROM bucket_metainfos
+			LEFT JOIN latest_tallies ON latest_tallies.bucket_name = bucket_metainfos.name
+			LEFT JOIN egress ON egress.bucket_name = bucket_metainfos.name
+		WHERE bucket_metainfos.project_id = ?
+		GROUP BY bucket_group
+		ORDER BY bucket_group
 	`)
 
-	bucketsTallies := make(map[string][]*accounting.BucketStorageTally)
+	rows, err := db.db.QueryContext(ctx, groupsQuery, projectID[:], since, before,
+		projectID[:], since, before, pb.PieceAction_GET,
+		delimiter, projectID[:])
+	if err != nil {
+		return nil, Error.Wrap(err)
+	}
+	defer func() { err = errs.Combine(err, rows.Close()) }()
 
-	for _, bucket := range bucketNames {
-		storageTallies := make([]*accounting.BucketStorageTally, 0)
+	var usages []accounting.BucketUsage
+	for rows.Next() {
+		var group string
+		var storage, objectCount, egress int64
+		if err := rows.Scan(&group, &storage, &objectCount, &egress); err != nil {
+			return nil, Error.Wrap(err)
+		}
 
-		storageTalliesRows, err := db.db.QueryContext(ctx, storageQuery, projectID[:], []byte(bucket), since, before)
-		if err != nil {
-			return nil, err
+		usages = append(usages, accounting.BucketUsage{
+			ProjectID:   projectID,
+			BucketName:  group,
+			Storage:     memory.Size(storage).GB(),
+			Egress:      memory.Size(egress).GB(),
+			ObjectCount: objectCount,
+			Since:       since,
+			Before:      before,
+		})
+	}
+
+	return usages, Error.Wrap(rows.Err())
+}
+
+// GetBucketCount returns the number of buckets in the project.
+func (db *ProjectAccounting) GetBucketCount(ctx context.Context, projectID uuid.UUID) (count int64, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	err = db.db.QueryRowContext(ctx, db.db.Rebind(`
+		SELECT COUNT(*) FROM bucket_metainfos WHERE project_id = ?
+	`), projectID[:]).Scan(&count)
+
+	return count, Error.Wrap(err)
+}
+
+// GetBucketCounts returns the number of buckets for each of the given projects.
+// Projects without buckets are included with a zero count.
+func (db *ProjectAccounting) GetBucketCounts(ctx context.Context, projectIDs []uuid.UUID) (_ map[uuid.UUID]int64, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	counts := make(map[uuid.UUID]int64, len(projectIDs))
+	if len(projectIDs) == 0 {
+		return counts, nil
+	}
+	for _, projectID := range projectIDs {
+		counts[projectID] = 0
+	}
+
+	rows, err := db.db.QueryContext(ctx, `
+		SELECT project_id, COUNT(*)
+		FROM bucket_metainfos
+		WHERE project_id = ANY($1::bytea[])
+		GROUP BY project_id
+	`, pgutil.UUIDArray(projectIDs))
+	if err != nil {
+		return nil, Error.Wrap(err)
+	}
+	defer func() { err = errs.Combine(err, rows.Close()) }()
+
+	for rows.Next() {
+		var projectID uuidScanner
+		var count int64
+		if err := rows.Scan(&projectID, &count); err != nil {
+			return nil, Error.Wrap(err)
 		}
-		// generating tallies for each bucket name.
-		for storageTalliesRows.Next() {
-			tally := accounting.BucketStorageTally{}
+		if !projectID.Valid {
+			if err := db.corruptRow("bucket_metainfos", projectID); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		counts[projectID.UUID] = count
+	}
+
+	return counts, Error.Wrap(rows.Err())
+}
+
+// ArchiveRollupsBefore archives rollups older than a given time.
+func (db *ProjectAccounting) ArchiveRollupsBefore(ctx context.Context, before time.Time, batchSize int) (archivedCount int, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	stats, err := db.ArchiveRollupsBeforeWithStats(ctx, before, batchSize)
+	for _, actionStats := range stats {
+		archivedCount += actionStats.Count
+	}
+	return archivedCount, err
+}
+
+// ArchiveRollupsBeforeWithStats archives rollups older than a given time and returns the number of
+// archived rollups per action. The stats of the archived rollups are returned also on error.
+//
+// On CockroachDB the rollups are archived one action at a time and the time spent per action is
+// measured, on Postgres all actions are archived together and Elapsed is left zero.
+func (db *ProjectAccounting) ArchiveRollupsBeforeWithStats(ctx context.Context, before time.Time, batchSize int) (stats map[pb.PieceAction]accounting.RollupArchiveStats, err error) {
+	defer mon.Task()(&ctx)(&err)
+	// bucket_bandwidth_rollups_archived counts the rollups moved to the archive.
+	defer func() {
+		var archivedCount int
+		for _, actionStats := range stats {
+			archivedCount += actionStats.Count
+		}
+		mon.Counter("bucket_bandwidth_rollups_archived").Inc(int64(archivedCount)) //mon:locked
+	}()
+
+	stats = make(map[pb.PieceAction]accounting.RollupArchiveStats)
+	if batchSize <= 0 {
+		return stats, nil
+	}
+
+	switch db.db.impl {
+	case dbutil.Cockroach:
 
-			var inline, remote int64
-			err = storageTalliesRows.Scan(&tally.IntervalStart, &tally.TotalBytes, &inline, &remote, &tally.ObjectCount)
+		// We operate one action at a time, because we have an index on `(action, interval_start, project_id)`.
+		for action := range pb.PieceAction_name {
+			start := time.Now()
+			count, err := db.archiveActionRollupsBefore(ctx, action, before, batchSize)
+			if count > 0 || err != nil {
+				stats[pb.PieceAction(action)] = accounting.RollupArchiveStats{
+					Count:   count,
+					Elapsed: time.Since(start),
+				}
+			}
 			if err != nil {
-				return nil, errs.Combine(err, storageTalliesRows.Close())
+				return stats, Error.Wrap(err)
 			}
-			if tally.TotalBytes == 0 {
-				tally.TotalBytes = inline + remote
+		}
+		return stats, nil
+	case dbutil.Postgres:
+		// Postgres doesn't support DELETE ... LIMIT, so the batch is selected by ctid.
+		// Moving the rows in batches keeps the transactions short.
+		for {
+			rowCount, err := db.archiveRollupsBatch(ctx, before, batchSize, stats)
+			if err != nil {
+				return stats, Error.Wrap(err)
 			}
+			if rowCount < batchSize {
+				return stats, nil
+			}
+		}
+	default:
+		return stats, nil
+	}
+}
 
-			tally.BucketName = bucket
-			storageTallies = append(storageTallies, &tally)
+// ArchiveRollupsBeforeDryRun returns the number of rollups per action ArchiveRollupsBefore would archive,
+// without archiving them.
+func (db *ProjectAccounting) ArchiveRollupsBeforeDryRun(ctx context.Context, before time.Time, batchSize int) (counts map[pb.PieceAction]int, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	counts = make(map[pb.PieceAction]int)
+	if batchSize <= 0 {
+		return counts, nil
+	}
+	if db.db.impl != dbutil.Cockroach && db.db.impl != dbutil.Postgres {
+		return counts, nil
+	}
+
+	rows, err := db.db.QueryContext(ctx, `
+		SELECT action, count(*) FROM bucket_bandwidth_rollups
+		WHERE interval_start <= $1
+		GROUP BY action
+	`, before)
+	if err != nil {
+		return nil, Error.Wrap(err)
+	}
+	defer func() { err = errs.Combine(err, rows.Close()) }()
+
+	for rows.Next() {
+		var action int32
+		var count int
+		if err := rows.Scan(&action, &count); err != nil {
+			return nil, Error.Wrap(err)
 		}
+		// on Cockroach only the known actions are archived.
+		if _, known := pb.PieceAction_name[action]; !known && db.db.impl == dbutil.Cockroach {
+			continue
+		}
+		counts[pb.PieceAction(action)] = count
+	}
+	return counts, Error.Wrap(rows.Err())
+}
 
-		err = errs.Combine(storageTalliesRows.Err(), storageTalliesRows.Close())
+// archiveRollupsBatch moves a batch of rollups older than a given time to the archive on Postgres
+// and adds the number of moved rollups per action to stats.
+func (db *ProjectAccounting) archiveRollupsBatch(ctx context.Context, before time.Time, batchSize int, stats map[pb.PieceAction]accounting.RollupArchiveStats) (rowCount int, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	rows, err := db.db.QueryContext(ctx, `
+		WITH rollups_to_move AS (
+			DELETE FROM bucket_bandwidth_rollups
+			WHERE ctid IN (
+				SELECT ctid FROM bucket_bandwidth_rollups
+				WHERE interval_start <= $1
+				LIMIT $2
+			)
+			RETURNING *
+		), moved_rollups AS (
+			INSERT INTO bucket_bandwidth_rollup_archives(bucket_name, project_id, interval_start, interval_seconds, action, inline, allocated, settled)
+			SELECT bucket_name, project_id, interval_start, interval_seconds, action, inline, allocated, settled FROM rollups_to_move
+			RETURNING *
+		)
+		SELECT action, count(*) FROM moved_rollups GROUP BY action
+	`, before, batchSize)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { err = errs.Combine(err, rows.Close()) }()
+
+	for rows.Next() {
+		var action int32
+		var count int
+		if err := rows.Scan(&action, &count); err != nil {
+			return rowCount, err
+		}
+		actionStats := stats[pb.PieceAction(action)]
+		actionStats.Count += count
+		stats[pb.PieceAction(action)] = actionStats
+		rowCount += count
+	}
+	return rowCount, rows.Err()
+}
+
+func (db *ProjectAccounting) archiveActionRollupsBefore(ctx context.Context, action int32, before time.Time, batchSize int) (archivedCount int, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	for {
+		var rowCount int
+		err := db.db.QueryRow(ctx, `
+			WITH rollups_to_move AS (
+				DELETE FROM bucket_bandwidth_rollups
+				WHERE action = $1 AND interval_start <= $2
+				LIMIT $3 RETURNING *
+			), moved_rollups AS (
+				INSERT INTO bucket_bandwidth_rollup_archives(bucket_name, project_id, interval_start, interval_seconds, action, inline, allocated, settled)
+				SELECT bucket_name, project_id, interval_start, interval_seconds, action, inline, allocated, settled FROM rollups_to_move
+				RETURNING *
+			)
+			SELECT count(*) FROM moved_rollups
+		`, int(action), before, batchSize).Scan(&rowCount)
 		if err != nil {
-			return nil, err
+			return archivedCount, Error.Wrap(err)
+		}
+		archivedCount += rowCount
+
+		if rowCount < batchSize {
+			return archivedCount, nil
+		}
+	}
+}
+
+// RestoreRollupsSince moves archived bucket bandwidth rollups since a given time back into
+// bucket_bandwidth_rollups and returns the number of restored rollups. When a rollup already
+// exists, the archived amounts are added to it.
+//
+// Rollups are moved in ReadRollupBatchSize sized batches, each by a single statement, hence
+// an interrupted restore can be resumed by calling RestoreRollupsSince again.
+func (db *ProjectAccounting) RestoreRollupsSince(ctx context.Context, since time.Time) (restoredCount int, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	var query string
+	switch db.db.impl {
+	case dbutil.Cockroach:
+		query = `
+			WITH rollups_to_move AS (
+				DELETE FROM bucket_bandwidth_rollup_archives
+				WHERE interval_start >= $1
+				LIMIT $2 RETURNING *
+			), moved_rollups AS (
+				INSERT INTO bucket_bandwidth_rollups(bucket_name, project_id, interval_start, interval_seconds, action, inline, allocated, settled)
+				SELECT bucket_name, project_id, interval_start, interval_seconds, action, inline, allocated, settled FROM rollups_to_move
+				ON CONFLICT(bucket_name, project_id, interval_start, action)
+				DO UPDATE SET
+					inline = bucket_bandwidth_rollups.inline + EXCLUDED.inline,
+					allocated = bucket_bandwidth_rollups.allocated + EXCLUDED.allocated,
+					settled = bucket_bandwidth_rollups.settled + EXCLUDED.settled
+				RETURNING *
+			)
+			SELECT count(*) FROM moved_rollups
+		`
+	case dbutil.Postgres:
+		query = `
+			WITH rollups_to_move AS (
+				DELETE FROM bucket_bandwidth_rollup_archives
+				WHERE ctid IN (
+					SELECT ctid FROM bucket_bandwidth_rollup_archives
+					WHERE interval_start >= $1
+					LIMIT $2
+				)
+				RETURNING *
+			), moved_rollups AS (
+				INSERT INTO bucket_bandwidth_rollups(bucket_name, project_id, interval_start, interval_seconds, action, inline, allocated, settled)
+				SELECT bucket_name, project_id, interval_start, interval_seconds, action, inline, allocated, settled FROM rollups_to_move
+				ON CONFLICT(bucket_name, project_id, interval_start, action)
+				DO UPDATE SET
+					inline = bucket_bandwidth_rollups.inline + EXCLUDED.inline,
+					allocated = bucket_bandwidth_rollups.allocated + EXCLUDED.allocated,
+					settled = bucket_bandwidth_rollups.settled + EXCLUDED.settled
+				RETURNING *
+			)
+			SELECT count(*) FROM moved_rollups
+		`
+	default:
+		return 0, Error.New("unsupported database: %v", db.db.impl)
+	}
+
+	batchSize := db.readRollupBatchSize()
+	for {
+		var rowCount int
+		err := db.db.QueryRow(ctx, query, since, batchSize).Scan(&rowCount)
+		if err != nil {
+			return restoredCount, Error.Wrap(err)
+		}
+		restoredCount += rowCount
+
+		if rowCount < batchSize {
+			return restoredCount, nil
+		}
+	}
+}
+
+// archiveTalliesRange is the interval_start range of tallies archived at once on Cockroach.
+const archiveTalliesRange = 24 * time.Hour
+
+// ArchiveStorageTalliesBefore moves bucket storage tallies older than a given time into the archive
+// table and returns the number of archived tallies.
+func (db *ProjectAccounting) ArchiveStorageTalliesBefore(ctx context.Context, before time.Time, batchSize int) (archivedCount int, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	if batchSize <= 0 {
+		return 0, nil
+	}
+
+	switch db.db.impl {
+	case dbutil.Cockroach:
+		var oldest *time.Time
+		err := db.db.QueryRow(ctx, `
+			SELECT min(interval_start) FROM bucket_storage_tallies WHERE interval_start <= $1
+		`, before).Scan(&oldest)
+		if err != nil {
+			return 0, Error.Wrap(err)
+		}
+		if oldest == nil {
+			return 0, nil
+		}
+
+		// We operate on one interval_start range at a time, so that every delete
+		// only needs to look at a limited part of the table.
+		for start := *oldest; !start.After(before); start = start.Add(archiveTalliesRange) {
+			count, err := db.archiveStorageTalliesInRange(ctx, start, start.Add(archiveTalliesRange), before, batchSize)
+			archivedCount += count
+			if err != nil {
+				return archivedCount, Error.Wrap(err)
+			}
+		}
+		return archivedCount, nil
+	case dbutil.Postgres:
+		// Postgres doesn't support DELETE ... LIMIT, so the batch is selected by ctid.
+		for {
+			var rowCount int
+			err := db.db.QueryRow(ctx, `
+				WITH tallies_to_move AS (
+					DELETE FROM bucket_storage_tallies
+					WHERE ctid IN (
+						SELECT ctid FROM bucket_storage_tallies
+						WHERE interval_start <= $1
+						LIMIT $2
+					)
+					RETURNING *
+				), moved_tallies AS (
+					INSERT INTO bucket_storage_tally_archives(bucket_name, project_id, interval_start, total_bytes, inline, remote, total_segments_count, remote_segments_count, inline_segments_count, object_count, metadata_size)
+					SELECT bucket_name, project_id, interval_start, total_bytes, inline, remote, total_segments_count, remote_segments_count, inline_segments_count, object_count, metadata_size FROM tallies_to_move
+					RETURNING *
+				)
+				SELECT count(*) FROM moved_tallies
+			`, before, batchSize).Scan(&rowCount)
+			if err != nil {
+				return archivedCount, Error.Wrap(err)
+			}
+			archivedCount += rowCount
+
+			if rowCount < batchSize {
+				return archivedCount, nil
+			}
 		}
-
-		bucketsTallies[bucket] = storageTallies
+	default:
+		return 0, nil
 	}
+}
 
-	totalEgress, err := db.getTotalEgress(ctx, projectID, since, before)
-	if err != nil {
-		return nil, err
-	}
+// ArchiveStorageTalliesBeforeDryRun returns the number of bucket storage tallies ArchiveStorageTalliesBefore
+// would archive, without archiving them.
+func (db *ProjectAccounting) ArchiveStorageTalliesBeforeDryRun(ctx context.Context, before time.Time, batchSize int) (count int, err error) {
+	defer mon.Task()(&ctx)(&err)
 
-	usage = new(accounting.ProjectUsage)
-	usage.Egress = memory.Size(totalEgress).Int64()
-	// sum up storage and objects
-	for _, tallies := range bucketsTallies {
-		for i := len(tallies) - 1; i > 0; i-- {
-			current := (tallies)[i]
-			hours := (tallies)[i-1].IntervalStart.Sub(current.IntervalStart).Hours()
-			usage.Storage += memory.Size(current.Bytes()).Float64() * hours
-			usage.ObjectCount += float64(current.ObjectCount) * hours
-		}
+	if batchSize <= 0 {
+		return 0, nil
+	}
+	if db.db.impl != dbutil.Cockroach && db.db.impl != dbutil.Postgres {
+		return 0, nil
 	}
 
-	usage.Since = since
-	usage.Before = before
-	return usage, nil
+	err = db.db.QueryRow(ctx, `
+		SELECT count(*) FROM bucket_storage_tallies WHERE interval_start <= $1
+	`, before).Scan(&count)
+	return count, Error.Wrap(err)
 }
 
-// getTotalEgress returns total egress (settled + inline) of each bucket_bandwidth_rollup
-// in selected time period, project id.
-// only process PieceAction_GET.
-func (db *ProjectAccounting) getTotalEgress(ctx context.Context, projectID uuid.UUID, since, before time.Time) (totalEgress int64, err error) {
-	totalEgressQuery := db.db.Rebind(`
-		SELECT
-			COALESCE(SUM(settled) + SUM(inline), 0)
-		FROM
-			bucket_bandwidth_rollups
-		WHERE
-			project_id = ? AND
-			interval_start >= ? AND
-			interval_start <= ? AND
-			action = ?;
-	`)
-
-	totalEgressRow := db.db.QueryRowContext(ctx, totalEgressQuery, projectID[:], since, before, pb.PieceAction_GET)
+func (db *ProjectAccounting) archiveStorageTalliesInRange(ctx context.Context, start, end, before time.Time, batchSize int) (archivedCount int, err error) {
+	defer mon.Task()(&ctx)(&err)
 
-	err = totalEgressRow.Scan(&totalEgress)
+	for {
+		var rowCount int
+		err := db.db.QueryRow(ctx, `
+			WITH tallies_to_move AS (
+				DELETE FROM bucket_storage_tallies
+				WHERE interval_start >= $1 AND interval_start < $2 AND interval_start <= $3
+				LIMIT $4 RETURNING *
+			), moved_tallies AS (
+				INSERT INTO bucket_storage_tally_archives(bucket_name, project_id, interval_start, total_bytes, inline, remote, total_segments_count, remote_segments_count, inline_segments_count, object_count, metadata_size)
+				SELECT bucket_name, project_id, interval_start, total_bytes, inline, remote, total_segments_count, remote_segments_count, inline_segments_count, object_count, metadata_size FROM tallies_to_move
+				RETURNING *
+			)
+			SELECT count(*) FROM moved_tallies
+		`, start, end, before, batchSize).Scan(&rowCount)
+		if err != nil {
+			return archivedCount, Error.Wrap(err)
+		}
+		archivedCount += rowCount
 
-	return totalEgress, err
+		if rowCount < batchSize {
+			return archivedCount, nil
+		}
+	}
 }
 
-// GetBucketUsageRollups retrieves summed usage rollups for every bucket of particular project for a given period.
-func (db *ProjectAccounting) GetBucketUsageRollups(ctx context.Context, projectID uuid.UUID, since, before time.Time) (_ []accounting.BucketUsageRollup, err error) {
+// GetArchivedTalliesSince retrieves all archived bucket storage tallies since a given time ordered
+// by interval start.
+func (db *ProjectAccounting) GetArchivedTalliesSince(ctx context.Context, since time.Time) (tallies []accounting.BucketStorageTally, err error) {
 	defer mon.Task()(&ctx)(&err)
-	since = timeTruncateDown(since.UTC())
-	before = before.UTC()
 
-	buckets, err := db.getBucketsSinceAndBefore(ctx, projectID, since, before)
-	if err != nil {
-		return nil, err
+	pageLimit := db.readRollupBatchSize()
+
+	firstQuery := `
+		SELECT bucket_name, project_id, interval_start, total_bytes, inline, remote, object_count, total_segments_count, remote_segments_count, inline_segments_count, metadata_size
+		FROM bucket_storage_tally_archives
+		WHERE interval_start >= $1
+		ORDER BY interval_start, bucket_name, project_id
+		LIMIT $2
+	`
+	nextQuery := `
+		SELECT bucket_name, project_id, interval_start, total_bytes, inline, remote, object_count, total_segments_count, remote_segments_count, inline_segments_count, metadata_size
+		FROM bucket_storage_tally_archives
+		WHERE interval_start >= $1
+			AND (interval_start, bucket_name, project_id) > ($3, $4, $5)
+		ORDER BY interval_start, bucket_name, project_id
+		LIMIT $2
+	`
+
+	type continuation struct {
+		intervalStart time.Time
+		bucketName    []byte
+		projectID     []byte
 	}
+	var cursor *continuation
 
-	roullupsQuery := db.db.Rebind(`SELECT SUM(settled), SUM(inline), action
-		FROM bucket_bandwidth_rollups
-		WHERE project_id = ? AND bucket_name = ? AND interval_start >= ? AND interval_start <= ?
-		GROUP BY action`)
-
-	// TODO: should be optimized
-	storageQuery := db.db.All_BucketStorageTally_By_ProjectId_And_BucketName_And_IntervalStart_GreaterOrEqual_And_IntervalStart_LessOrEqual_OrderBy_Desc_IntervalStart
-
-	var bucketUsageRollups []accounting.BucketUsageRollup
-	for _, bucket := range buckets {
-		err := func() error {
-			bucketRollup := accounting.BucketUsageRollup{
-				ProjectID:  projectID,
-				BucketName: []byte(bucket),
-				Since:      since,
-				Before:     before,
-			}
-
-			// get bucket_bandwidth_rollups
-			rollupsRows, err := db.db.QueryContext(ctx, roullupsQuery, projectID[:], []byte(bucket), since, before)
-			if err != nil {
-				return err
-			}
-			defer func() { err = errs.Combine(err, rollupsRows.Close()) }()
-
-			// fill egress
-			for rollupsRows.Next() {
-				var action pb.PieceAction
-				var settled, inline int64
+	for {
+		var rows tagsql.Rows
+		if cursor == nil {
+			rows, err = db.db.QueryContext(ctx, firstQuery, since, pageLimit)
+		} else {
+			rows, err = db.db.QueryContext(ctx, nextQuery, since, pageLimit,
+				cursor.intervalStart, cursor.bucketName, cursor.projectID)
+		}
+		if err != nil {
+			return nil, Error.Wrap(err)
+		}
 
-				err = rollupsRows.Scan(&settled, &inline, &action)
+		count := 0
+		err = func() (err error) {
+			defer func() { err = errs.Combine(err, rows.Close()) }()
+			for rows.Next() {
+				var next continuation
+				var totalBytes, inline, remote int64
+				var objectCount, totalSegments, remoteSegments, inlineSegments, metadataSize int64
+				err := rows.Scan(&next.bucketName, &next.projectID, &next.intervalStart,
+					&totalBytes, &inline, &remote,
+					&objectCount, &totalSegments, &remoteSegments, &inlineSegments, &metadataSize)
 				if err != nil {
 					return err
 				}
-
-				switch action {
-				case pb.PieceAction_GET:
-					bucketRollup.GetEgress += memory.Size(settled + inline).GB()
-				case pb.PieceAction_GET_AUDIT:
-					bucketRollup.AuditEgress += memory.Size(settled + inline).GB()
-				case pb.PieceAction_GET_REPAIR:
-					bucketRollup.RepairEgress += memory.Size(settled + inline).GB()
-				default:
+				cursor = &next
+				count++
+
+				projectID := scanUUIDBytes(next.projectID)
+				if !projectID.Valid {
+					if err := db.corruptRow("bucket_storage_tally_archives", projectID); err != nil {
+						return err
+					}
 					continue
 				}
-			}
-			if err := rollupsRows.Err(); err != nil {
-				return err
-			}
-
-			bucketStorageTallies, err := storageQuery(ctx,
-				dbx.BucketStorageTally_ProjectId(projectID[:]),
-				dbx.BucketStorageTally_BucketName([]byte(bucket)),
-				dbx.BucketStorageTally_IntervalStart(since),
-				dbx.BucketStorageTally_IntervalStart(before))
-
-			if err != nil {
-				return err
-			}
-
-			// fill metadata, objects and stored data
-			// hours calculated from previous tallies,
-			// so we skip the most recent one
-			for i := len(bucketStorageTallies) - 1; i > 0; i-- {
-				current := bucketStorageTallies[i]
-
-				hours := bucketStorageTallies[i-1].IntervalStart.Sub(current.IntervalStart).Hours()
 
-				if current.TotalBytes > 0 {
-					bucketRollup.TotalStoredData += memory.Size(current.TotalBytes).GB() * hours
-				} else {
-					bucketRollup.TotalStoredData += memory.Size(current.Remote+current.Inline).GB() * hours
+				if totalBytes == 0 {
+					totalBytes = inline + remote
 				}
-				bucketRollup.MetadataSize += memory.Size(current.MetadataSize).GB() * hours
-				if current.TotalSegmentsCount > 0 {
-					bucketRollup.TotalSegments += float64(current.TotalSegmentsCount) * hours
-				} else {
-					bucketRollup.TotalSegments += float64(current.RemoteSegmentsCount+current.InlineSegmentsCount) * hours
+				if totalSegments == 0 {
+					totalSegments = inlineSegments + remoteSegments
 				}
-				bucketRollup.ObjectCount += float64(current.ObjectCount) * hours
-			}
 
-			bucketUsageRollups = append(bucketUsageRollups, bucketRollup)
-			return nil
+				tallies = append(tallies, accounting.BucketStorageTally{
+					BucketName:        string(next.bucketName),
+					ProjectID:         projectID.UUID,
+					IntervalStart:     next.intervalStart,
+					ObjectCount:       objectCount,
+					TotalSegmentCount: totalSegments,
+					TotalBytes:        totalBytes,
+					MetadataSize:      metadataSize,
+				})
+			}
+			return rows.Err()
 		}()
 		if err != nil {
-			return nil, err
+			return nil, Error.Wrap(err)
 		}
-	}
 
-	return bucketUsageRollups, nil
+		if count < pageLimit {
+			return tallies, nil
+		}
+	}
 }
 
-// prefixIncrement returns the lexicographically lowest byte string which is
-// greater than origPrefix and does not have origPrefix as a prefix. If no such
-// byte string exists (origPrefix is empty, or origPrefix contains only 0xff
-// bytes), returns false for ok.
-//
-// examples: prefixIncrement([]byte("abc"))          -> ([]byte("abd", true)
-//           prefixIncrement([]byte("ab\xff\xff"))   -> ([]byte("ac", true)
-//           prefixIncrement([]byte(""))             -> (nil, false)
-//           prefixIncrement([]byte("\x00"))         -> ([]byte("\x01", true)
-//           prefixIncrement([]byte("\xff\xff\xff")) -> (nil, false)
-//
-func prefixIncrement(origPrefix []byte) (incremented []byte, ok bool) {
-	incremented = make([]byte, len(origPrefix))
-	copy(incremented, origPrefix)
-	i := len(incremented) - 1
-	for i >= 0 {
-		if incremented[i] != 0xff {
-			incremented[i]++
-			return incremented[:i+1], true
+// getBucketsSinceAndBefore lists distinct bucket names for a project within a specific timeframe.
+func (db *ProjectAccounting) getBucketsSinceAndBefore(ctx context.Context, projectID uuid.UUID, since, before time.Time, asOfSystemInterval time.Duration) (_ []string, err error) {
+	defer mon.Task()(&ctx)(&err)
+	bucketsQuery := db.db.Rebind(`SELECT DISTINCT bucket_name
+		FROM bucket_storage_tallies
+		` + db.db.impl.AsOfSystemInterval(asOfSystemInterval) + `
+		WHERE project_id = ?
+		AND ` + intervalCondition("interval_start"))
+	bucketRows, err := db.db.QueryContext(ctx, bucketsQuery, projectID[:], since, before)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { err = errs.Combine(err, bucketRows.Close()) }()
+
+	var buckets []string
+	for bucketRows.Next() {
+		var bucket string
+		err = bucketRows.Scan(&bucket)
+		if err != nil {
+			return nil, err
 		}
-		i--
+
+		buckets = append(buckets, bucket)
 	}
 
-	// there is no byte string which is greater than origPrefix and which does
-	// not have origPrefix as a prefix.
-	return nil, false
+	return buckets, bucketRows.Err()
 }
 
-// prefixMatch creates a SQL expression which
-// will evaluate to true if and only if the value of expr starts with the value
-// of prefix.
-//
-// Returns also a slice of arguments that should be passed to the corresponding
-// db.Query* or db.Exec* to fill in parameters in the returned SQL expression.
-//
-// The returned SQL expression needs to be passed through Rebind(), as it uses
-// `?` markers instead of `$N`, because we don't know what N we would need to
-// use.
-func (db *ProjectAccounting) prefixMatch(expr string, prefix []byte) (string, []byte, error) {
-	incrementedPrefix, ok := prefixIncrement(prefix)
-	switch db.db.impl {
-	case dbutil.Postgres:
-		if !ok {
-			return fmt.Sprintf(`(%s >= ?)`, expr), nil, nil
-		}
-		return fmt.Sprintf(`(%s >= ? AND %s < ?)`, expr, expr), incrementedPrefix, nil
-	case dbutil.Cockroach:
-		if !ok {
-			return fmt.Sprintf(`(%s >= ?:::BYTEA)`, expr), nil, nil
-		}
-		return fmt.Sprintf(`(%s >= ?:::BYTEA AND %s < ?:::BYTEA)`, expr, expr), incrementedPrefix, nil
-	default:
-		return "", nil, errs.New("unhandled database: %v", db.db.driver)
+// corruptRow decides what happens with a row with an invalid project_id found
+// by a bulk listing. In strict mode the listing fails, otherwise the row is
+// skipped and counted.
+func (db *ProjectAccounting) corruptRow(table string, projectID uuidScanner) error {
+	if db.db.opts.StrictAccountingReads {
+		return Error.New("%s: invalid project_id %x", table, projectID.Raw)
 	}
+	mon.Counter("accounting_corrupt_rows_skipped", monkit.NewSeriesTag("table", table)).Inc(1)
+	return nil
+}
 
+// timeTruncateDown truncates down to the hour before to be in sync with orders endpoint.
+func timeTruncateDown(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, t.Location())
 }
 
-// GetBucketTotals retrieves bucket usage totals for period of time.
-func (db *ProjectAccounting) GetBucketTotals(ctx context.Context, projectID uuid.UUID, cursor accounting.BucketUsageCursor, since, before time.Time) (_ *accounting.BucketUsagePage, err error) {
+// GetProjectLimits returns current project limit for both storage and bandwidth.
+func (db *ProjectAccounting) GetProjectLimits(ctx context.Context, projectID uuid.UUID) (_ accounting.ProjectLimits, err error) {
 	defer mon.Task()(&ctx)(&err)
-	since = timeTruncateDown(since)
-	bucketPrefix := []byte(cursor.Search)
 
-	if cursor.Limit > 50 {
-		cursor.Limit = 50
-	}
-	if cursor.Page == 0 {
-		return nil, errs.New("page can not be 0")
+	row, err := db.db.Get_Project_BandwidthLimit_Project_UsageLimit_By_Id(ctx,
+		dbx.Project_Id(projectID[:]),
+	)
+	if err != nil {
+		return accounting.ProjectLimits{}, projectNotFound(err)
 	}
 
-	page := &accounting.BucketUsagePage{
-		Search: cursor.Search,
-		Limit:  cursor.Limit,
-		Offset: uint64((cursor.Page - 1) * cursor.Limit),
+	return accounting.ProjectLimits{
+		Usage:     row.UsageLimit,
+		Bandwidth: row.BandwidthLimit,
+	}, nil
+}
+
+// GetEffectiveProjectLimits returns current project limits for both storage and bandwidth,
+// using the given defaults for limits which aren't set.
+func (db *ProjectAccounting) GetEffectiveProjectLimits(ctx context.Context, projectID uuid.UUID, defaults accounting.ProjectLimits) (_ accounting.EffectiveProjectLimits, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	if defaults.Usage == nil || defaults.Bandwidth == nil {
+		return accounting.EffectiveProjectLimits{}, Error.New("default limits must be set")
 	}
 
-	bucketNameRange, incrPrefix, err := db.prefixMatch("name", bucketPrefix)
+	limits, err := db.GetProjectLimits(ctx, projectID)
 	if err != nil {
-		return nil, err
+		return accounting.EffectiveProjectLimits{}, err
 	}
-	countQuery := db.db.Rebind(`SELECT COUNT(name) FROM bucket_metainfos
-	WHERE project_id = ? AND ` + bucketNameRange)
 
-	args := []interface{}{
-		projectID[:],
-		bucketPrefix,
+	return effectiveProjectLimits(limits, defaults), nil
+}
+
+// effectiveProjectLimits returns limits with the limits which aren't set replaced by defaults.
+func effectiveProjectLimits(limits, defaults accounting.ProjectLimits) accounting.EffectiveProjectLimits {
+	var effective accounting.EffectiveProjectLimits
+	if limits.Usage != nil {
+		effective.Usage = *limits.Usage
+	} else {
+		effective.Usage = *defaults.Usage
+		effective.UsageDefault = true
 	}
-	if incrPrefix != nil {
-		args = append(args, incrPrefix)
+	if limits.Bandwidth != nil {
+		effective.Bandwidth = *limits.Bandwidth
+	} else {
+		effective.Bandwidth = *defaults.Bandwidth
+		effective.BandwidthDefault = true
 	}
 
-	countRow := db.db.QueryRowContext(ctx, countQuery, args...)
+	return effective
+}
 
-	err = countRow.Scan(&page.TotalCount)
-	if err != nil {
-		return nil, err
-	}
+// SetProjectUsageAlertThresholds sets the percentages of the storage and bandwidth limits at which the
+// project is reported by GetProjectsOverThreshold. Setting both to zero removes the thresholds.
+func (db *ProjectAccounting) SetProjectUsageAlertThresholds(ctx context.Context, projectID uuid.UUID, storagePercent, bandwidthPercent int) (err error) {
+	defer mon.Task()(&ctx)(&err)
 
-	if page.TotalCount == 0 {
-		return page, nil
+	if storagePercent < 0 || storagePercent > 100 || bandwidthPercent < 0 || bandwidthPercent > 100 {
+		return accounting.ErrInvalidArgument.New("thresholds %d%% and %d%% aren't between 0 and 100", storagePercent, bandwidthPercent)
 	}
-	if page.Offset > page.TotalCount-1 {
-		return nil, errs.New("page is out of range")
+
+	if storagePercent == 0 && bandwidthPercent == 0 {
+		_, err = db.db.ExecContext(ctx, db.db.Rebind(`
+			DELETE FROM project_usage_alert_thresholds WHERE project_id = ?
+		`), projectID[:])
+		return Error.Wrap(err)
 	}
 
-	var buckets []string
-	bucketsQuery := db.db.Rebind(`SELECT name FROM bucket_metainfos
-	WHERE project_id = ? AND ` + bucketNameRange + `ORDER BY name ASC LIMIT ? OFFSET ?`)
+	_, err = db.db.ExecContext(ctx, db.db.Rebind(`
+		INSERT INTO project_usage_alert_thresholds (project_id, storage_percent, bandwidth_percent, updated_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT (project_id) DO UPDATE SET
+			storage_percent = EXCLUDED.storage_percent,
+			bandwidth_percent = EXCLUDED.bandwidth_percent,
+			updated_at = EXCLUDED.updated_at
+	`), projectID[:], storagePercent, bandwidthPercent, time.Now().UTC())
+	return Error.Wrap(err)
+}
 
-	args = []interface{}{
-		projectID[:],
-		bucketPrefix,
-	}
-	if incrPrefix != nil {
-		args = append(args, incrPrefix)
+// GetProjectsOverThreshold returns the projects whose storage or bandwidth reached their usage alert
+// thresholds. Storage is summed over the latest tally of every bucket at or before asOf and bandwidth
+// over the month containing asOf, same as in GetProjectBandwidth. Only projects with thresholds are
+// looked at and their usage is computed by a single query.
+func (db *ProjectAccounting) GetProjectsOverThreshold(ctx context.Context, asOf time.Time, defaults accounting.ProjectLimits) (alerts []accounting.ProjectUsageAlert, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	if defaults.Usage == nil || defaults.Bandwidth == nil {
+		return nil, Error.New("default limits must be set")
 	}
-	args = append(args, page.Limit, page.Offset)
 
-	bucketRows, err := db.db.QueryContext(ctx, bucketsQuery, args...)
+	asOf = asOf.UTC()
+	expiredSince, monthStart, monthEnd := projectBandwidthPeriod(asOf.Year(), asOf.Month(), asOf.Day(), 1)
+
+	rows, err := db.db.QueryContext(ctx, db.db.Rebind(`
+		WITH thresholds AS (
+			SELECT
+				t.project_id, t.storage_percent, t.bandwidth_percent,
+				COALESCE(p.usage_limit, ?)::INT8 AS storage_limit,
+				COALESCE(p.bandwidth_limit, ?)::INT8 AS bandwidth_limit
+			FROM project_usage_alert_thresholds AS t
+			JOIN projects AS p ON p.id = t.project_id
+		), storage AS (
+			SELECT project_id, SUM(CASE WHEN total_bytes > 0 THEN total_bytes ELSE inline + remote END)::INT8 AS usage
+			FROM (
+				SELECT DISTINCT ON (project_id, bucket_name) project_id, total_bytes, inline, remote
+				FROM bucket_storage_tallies
+				WHERE project_id IN (SELECT project_id FROM thresholds) AND interval_start <= ?
+				ORDER BY project_id, bucket_name, interval_start DESC
+			) AS latest
+			GROUP BY project_id
+		), bandwidth AS (
+			SELECT project_id, SUM(
+				CASE WHEN interval_day < ?
+					THEN egress_settled
+					ELSE egress_allocated
+				END
+			)::INT8 AS usage
+			FROM project_bandwidth_daily_rollups
+			WHERE project_id IN (SELECT project_id FROM thresholds) AND interval_day >= ? AND interval_day < ?
+			GROUP BY project_id
+		)
+		SELECT
+			t.project_id, t.storage_percent, t.bandwidth_percent, t.storage_limit, t.bandwidth_limit,
+			COALESCE(s.usage, 0), COALESCE(b.usage, 0)
+		FROM thresholds AS t
+		LEFT JOIN storage AS s ON s.project_id = t.project_id
+		LEFT JOIN bandwidth AS b ON b.project_id = t.project_id
+		WHERE (t.storage_percent > 0 AND COALESCE(s.usage, 0) * 100 >= t.storage_percent * t.storage_limit)
+			OR (t.bandwidth_percent > 0 AND COALESCE(b.usage, 0) * 100 >= t.bandwidth_percent * t.bandwidth_limit)
+		ORDER BY t.project_id
+	`), *defaults.Usage, *defaults.Bandwidth, asOf, expiredSince, monthStart, monthEnd)
 	if err != nil {
-		return nil, err
+		return nil, Error.Wrap(err)
 	}
-	defer func() { err = errs.Combine(err, bucketRows.Close()) }()
+	defer func() { err = errs.Combine(err, rows.Close()) }()
 
-	for bucketRows.Next() {
-		var bucket string
-		err = bucketRows.Scan(&bucket)
+	fraction := func(usage, limit int64) float64 {
+		if limit <= 0 {
+			return 0
+		}
+		return float64(usage) / float64(limit)
+	}
+
+	for rows.Next() {
+		var projectID uuidScanner
+		var alert accounting.ProjectUsageAlert
+		err := rows.Scan(&projectID, &alert.StorageThreshold, &alert.BandwidthThreshold,
+			&alert.StorageLimit, &alert.BandwidthLimit, &alert.StorageUsage, &alert.BandwidthUsage)
 		if err != nil {
-			return nil, err
+			return nil, Error.Wrap(err)
+		}
+		if !projectID.Valid {
+			if err := db.corruptRow("project_usage_alert_thresholds", projectID); err != nil {
+				return nil, err
+			}
+			continue
 		}
 
-		buckets = append(buckets, bucket)
-	}
-	if err := bucketRows.Err(); err != nil {
-		return nil, err
+		alert.ProjectID = projectID.UUID
+		alert.StorageFraction = fraction(alert.StorageUsage, alert.StorageLimit)
+		alert.BandwidthFraction = fraction(alert.BandwidthUsage, alert.BandwidthLimit)
+		alert.StorageExceeded = alert.StorageThreshold > 0 && alert.StorageUsage*100 >= int64(alert.StorageThreshold)*alert.StorageLimit
+		alert.BandwidthExceeded = alert.BandwidthThreshold > 0 && alert.BandwidthUsage*100 >= int64(alert.BandwidthThreshold)*alert.BandwidthLimit
+		alerts = append(alerts, alert)
 	}
 
-	rollupsQuery := db.db.Rebind(`SELECT COALESCE(SUM(settled) + SUM(inline), 0)
-		FROM bucket_bandwidth_rollups
-		WHERE project_id = ? AND bucket_name = ? AND interval_start >= ? AND interval_start <= ? AND action = ?`)
+	return alerts, Error.Wrap(rows.Err())
+}
 
-	storageQuery := db.db.Rebind(`SELECT total_bytes, inline, remote, object_count
-		FROM bucket_storage_tallies
-		WHERE project_id = ? AND bucket_name = ? AND interval_start >= ? AND interval_start <= ?
-		ORDER BY interval_start DESC
-		LIMIT 1`)
+// UpdateBucketBandwidthLimit updates bucket bandwidth limit.
+func (db *ProjectAccounting) UpdateBucketBandwidthLimit(ctx context.Context, projectID uuid.UUID, bucketName string, limit memory.Size) (err error) {
+	defer mon.Task()(&ctx)(&err)
 
-	var bucketUsages []accounting.BucketUsage
-	for _, bucket := range buckets {
-		bucketUsage := accounting.BucketUsage{
-			ProjectID:  projectID,
-			BucketName: bucket,
-			Since:      since,
-			Before:     before,
-		}
+	return db.updateBucketLimit(ctx, "bandwidth_limit", projectID, bucketName, limit.Int64())
+}
 
-		// get bucket_bandwidth_rollups
-		rollupRow := db.db.QueryRowContext(ctx, rollupsQuery, projectID[:], []byte(bucket), since, before, pb.PieceAction_GET)
+// ClearBucketBandwidthLimit clears bucket bandwidth limit.
+func (db *ProjectAccounting) ClearBucketBandwidthLimit(ctx context.Context, projectID uuid.UUID, bucketName string) (err error) {
+	defer mon.Task()(&ctx)(&err)
 
-		var egress int64
-		err = rollupRow.Scan(&egress)
-		if err != nil {
-			if !errors.Is(err, sql.ErrNoRows) {
-				return nil, err
-			}
-		}
+	return db.updateBucketLimit(ctx, "bandwidth_limit", projectID, bucketName, nil)
+}
 
-		bucketUsage.Egress = memory.Size(egress).GB()
+// GetBucketBandwidthLimit returns bucket bandwidth limit, nil means unlimited.
+func (db *ProjectAccounting) GetBucketBandwidthLimit(ctx context.Context, projectID uuid.UUID, bucketName string) (_ *int64, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	return db.getBucketLimit(ctx, "bandwidth_limit", projectID, bucketName)
+}
+
+// GetBucketBandwidthUsage returns the sum of GET bandwidth allocated for a bucket since from.
+func (db *ProjectAccounting) GetBucketBandwidthUsage(ctx context.Context, projectID uuid.UUID, bucketName string, from time.Time) (_ int64, err error) {
+	defer mon.Task()(&ctx)(&err)
+	var sum *int64
+	query := `SELECT SUM(allocated) FROM bucket_bandwidth_rollups WHERE bucket_name = ? AND project_id = ? AND action = ? AND interval_start >= ?;`
+	err = db.db.QueryRow(ctx, db.db.Rebind(query), []byte(bucketName), projectID[:], pb.PieceAction_GET, from.UTC()).Scan(&sum)
+	if errors.Is(err, sql.ErrNoRows) || sum == nil {
+		return 0, nil
+	}
 
-		storageRow := db.db.QueryRowContext(ctx, storageQuery, projectID[:], []byte(bucket), since, before)
+	return *sum, err
+}
 
-		var tally accounting.BucketStorageTally
-		var inline, remote int64
-		err = storageRow.Scan(&tally.TotalBytes, &inline, &remote, &tally.ObjectCount)
-		if err != nil {
-			if !errors.Is(err, sql.ErrNoRows) {
-				return nil, err
-			}
-		}
+// UpdateBucketStorageLimit updates bucket storage limit.
+func (db *ProjectAccounting) UpdateBucketStorageLimit(ctx context.Context, projectID uuid.UUID, bucketName string, limit memory.Size) (err error) {
+	defer mon.Task()(&ctx)(&err)
 
-		if tally.TotalBytes == 0 {
-			tally.TotalBytes = inline + remote
-		}
+	return db.updateBucketLimit(ctx, "storage_limit", projectID, bucketName, limit.Int64())
+}
 
-		// fill storage and object count
-		bucketUsage.Storage = memory.Size(tally.Bytes()).GB()
-		bucketUsage.ObjectCount = tally.ObjectCount
+// ClearBucketStorageLimit clears bucket storage limit.
+func (db *ProjectAccounting) ClearBucketStorageLimit(ctx context.Context, projectID uuid.UUID, bucketName string) (err error) {
+	defer mon.Task()(&ctx)(&err)
 
-		bucketUsages = append(bucketUsages, bucketUsage)
-	}
+	return db.updateBucketLimit(ctx, "storage_limit", projectID, bucketName, nil)
+}
 
-	page.PageCount = uint(page.TotalCount / uint64(cursor.Limit))
-	if page.TotalCount%uint64(cursor.Limit) != 0 {
-		page.PageCount++
-	}
+// GetBucketStorageLimit returns bucket storage limit, nil means unlimited.
+func (db *ProjectAccounting) GetBucketStorageLimit(ctx context.Context, projectID uuid.UUID, bucketName string) (_ *int64, err error) {
+	defer mon.Task()(&ctx)(&err)
 
-	page.BucketUsages = bucketUsages
-	page.CurrentPage = cursor.Page
-	return page, nil
+	return db.getBucketLimit(ctx, "storage_limit", projectID, bucketName)
 }
 
-// ArchiveRollupsBefore archives rollups older than a given time.
-func (db *ProjectAccounting) ArchiveRollupsBefore(ctx context.Context, before time.Time, batchSize int) (archivedCount int, err error) {
+// GetBucketStorageUsage returns the stored bytes of a bucket from the most recent tally.
+// A bucket without tallies uses no storage.
+func (db *ProjectAccounting) GetBucketStorageUsage(ctx context.Context, projectID uuid.UUID, bucketName string) (_ int64, err error) {
 	defer mon.Task()(&ctx)(&err)
 
-	if batchSize <= 0 {
+	var totalBytes, inline, remote int64
+	err = db.db.QueryRowContext(ctx, db.db.Rebind(`
+		SELECT total_bytes, inline, remote
+		FROM bucket_storage_tallies
+		WHERE bucket_name = ? AND project_id = ?
+		ORDER BY interval_start DESC
+		LIMIT 1
+	`), []byte(bucketName), projectID[:]).Scan(&totalBytes, &inline, &remote)
+	if errors.Is(err, sql.ErrNoRows) {
 		return 0, nil
 	}
+	if err != nil {
+		return 0, Error.Wrap(err)
+	}
 
-	switch db.db.impl {
-	case dbutil.Cockroach:
-
-		// We operate one action at a time, because we have an index on `(action, interval_start, project_id)`.
-		for action := range pb.PieceAction_name {
-			count, err := db.archiveRollupsBeforeByAction(ctx, action, before, batchSize)
-			archivedCount += count
-			if err != nil {
-				return archivedCount, Error.Wrap(err)
-			}
-		}
-		return archivedCount, nil
-	case dbutil.Postgres:
-		err := db.db.DB.QueryRow(ctx, `
-			WITH rollups_to_move AS (
-				DELETE FROM bucket_bandwidth_rollups
-				WHERE interval_start <= $1
-				RETURNING *
-			), moved_rollups AS (
-				INSERT INTO bucket_bandwidth_rollup_archives(bucket_name, project_id, interval_start, interval_seconds, action, inline, allocated, settled)
-				SELECT bucket_name, project_id, interval_start, interval_seconds, action, inline, allocated, settled FROM rollups_to_move
-				RETURNING *
-			)
-			SELECT count(*) FROM moved_rollups
-		`, before).Scan(&archivedCount)
-		return archivedCount, Error.Wrap(err)
-	default:
-		return 0, nil
+	// older tallies don't have total_bytes set.
+	if totalBytes > 0 {
+		return totalBytes, nil
 	}
+	return inline + remote, nil
 }
 
-func (db *ProjectAccounting) archiveRollupsBeforeByAction(ctx context.Context, action int32, before time.Time, batchSize int) (archivedCount int, err error) {
-	defer mon.Task()(&ctx)(&err)
+// updateBucketLimit sets the limit column of a bucket, nil limit clears it.
+func (db *ProjectAccounting) updateBucketLimit(ctx context.Context, column string, projectID uuid.UUID, bucketName string, limit interface{}) (err error) {
+	result, err := db.db.ExecContext(ctx, db.db.Rebind(`
+		UPDATE bucket_metainfos SET `+pgutil.QuoteIdentifier(column)+` = ?
+		WHERE project_id = ? AND name = ?
+	`), limit, projectID[:], []byte(bucketName))
+	if err != nil {
+		return Error.Wrap(err)
+	}
 
-	for {
-		var rowCount int
-		err := db.db.QueryRow(ctx, `
-			WITH rollups_to_move AS (
-				DELETE FROM bucket_bandwidth_rollups
-				WHERE action = $1 AND interval_start <= $2
-				LIMIT $3 RETURNING *
-			), moved_rollups AS (
-				INSERT INTO bucket_bandwidth_rollup_archives(bucket_name, project_id, interval_start, interval_seconds, action, inline, allocated, settled)
-				SELECT bucket_name, project_id, interval_start, interval_seconds, action, inline, allocated, settled FROM rollups_to_move
-				RETURNING *
-			)
-			SELECT count(*) FROM moved_rollups
-		`, int(action), before, batchSize).Scan(&rowCount)
-		if err != nil {
-			return archivedCount, Error.Wrap(err)
-		}
-		archivedCount += rowCount
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return Error.Wrap(err)
+	}
+	if affected == 0 {
+		return storj.ErrBucketNotFound.New("%s", bucketName)
+	}
 
-		if rowCount < batchSize {
-			return archivedCount, nil
-		}
+	return nil
+}
+
+// getBucketLimit returns the limit column of a bucket.
+func (db *ProjectAccounting) getBucketLimit(ctx context.Context, column string, projectID uuid.UUID, bucketName string) (limit *int64, err error) {
+	err = db.db.QueryRowContext(ctx, db.db.Rebind(`
+		SELECT `+pgutil.QuoteIdentifier(column)+` FROM bucket_metainfos
+		WHERE project_id = ? AND name = ?
+	`), projectID[:], []byte(bucketName)).Scan(&limit)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, storj.ErrBucketNotFound.New("%s", bucketName)
 	}
+	if err != nil {
+		return nil, Error.Wrap(err)
+	}
+
+	return limit, nil
 }
 
-// getBucketsSinceAndBefore lists distinct bucket names for a project within a specific timeframe.
-func (db *ProjectAccounting) getBucketsSinceAndBefore(ctx context.Context, projectID uuid.UUID, since, before time.Time) (_ []string, err error) {
+// GetProjectLimitsBatch returns current storage and bandwidth limits of all given projects.
+// Projects which don't exist are missing from the result.
+func (db *ProjectAccounting) GetProjectLimitsBatch(ctx context.Context, projectIDs []uuid.UUID) (_ map[uuid.UUID]accounting.ProjectLimits, err error) {
 	defer mon.Task()(&ctx)(&err)
-	bucketsQuery := db.db.Rebind(`SELECT DISTINCT bucket_name
-		FROM bucket_storage_tallies
-		WHERE project_id = ?
-		AND interval_start >= ?
-		AND interval_start <= ?`)
-	bucketRows, err := db.db.QueryContext(ctx, bucketsQuery, projectID[:], since, before)
+
+	limits := make(map[uuid.UUID]accounting.ProjectLimits, len(projectIDs))
+	if len(projectIDs) == 0 {
+		return limits, nil
+	}
+
+	rows, err := db.db.QueryContext(ctx, `
+		SELECT id, usage_limit, bandwidth_limit
+		FROM projects
+		WHERE id = ANY($1::bytea[])
+	`, pgutil.UUIDArray(projectIDs))
 	if err != nil {
-		return nil, err
+		return nil, Error.Wrap(err)
 	}
-	defer func() { err = errs.Combine(err, bucketRows.Close()) }()
+	defer func() { err = errs.Combine(err, rows.Close()) }()
 
-	var buckets []string
-	for bucketRows.Next() {
-		var bucket string
-		err = bucketRows.Scan(&bucket)
-		if err != nil {
-			return nil, err
+	for rows.Next() {
+		var projectID uuid.UUID
+		var projectLimits accounting.ProjectLimits
+		if err := rows.Scan(&projectID, &projectLimits.Usage, &projectLimits.Bandwidth); err != nil {
+			return nil, Error.Wrap(err)
 		}
-
-		buckets = append(buckets, bucket)
+		limits[projectID] = projectLimits
 	}
 
-	return buckets, bucketRows.Err()
+	return limits, Error.Wrap(rows.Err())
 }
 
-// timeTruncateDown truncates down to the hour before to be in sync with orders endpoint.
-func timeTruncateDown(t time.Time) time.Time {
-	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, t.Location())
+// GetProjectExemptFromBilling returns whether the project is excluded from
+// satellite-wide aggregates and invoicing.
+func (db *ProjectAccounting) GetProjectExemptFromBilling(ctx context.Context, projectID uuid.UUID) (exempt bool, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	row, err := db.db.Get_Project_ExemptFromBilling_By_Id(ctx,
+		dbx.Project_Id(projectID[:]),
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, Error.Wrap(accounting.ErrProjectNotFound)
+		}
+		return false, Error.Wrap(err)
+	}
+
+	return row.ExemptFromBilling, nil
 }
 
-// GetProjectLimits returns current project limit for both storage and bandwidth.
-func (db *ProjectAccounting) GetProjectLimits(ctx context.Context, projectID uuid.UUID) (_ accounting.ProjectLimits, err error) {
+// UpdateProjectExemptFromBilling sets whether the project is excluded from
+// satellite-wide aggregates and invoicing.
+func (db *ProjectAccounting) UpdateProjectExemptFromBilling(ctx context.Context, projectID uuid.UUID, exempt bool) (err error) {
 	defer mon.Task()(&ctx)(&err)
 
-	row, err := db.db.Get_Project_BandwidthLimit_Project_UsageLimit_By_Id(ctx,
+	project, err := db.db.Update_Project_By_Id(ctx,
 		dbx.Project_Id(projectID[:]),
+		dbx.Project_Update_Fields{
+			ExemptFromBilling: dbx.Project_ExemptFromBilling(exempt),
+		},
 	)
 	if err != nil {
-		return accounting.ProjectLimits{}, err
+		return Error.Wrap(err)
+	}
+	if project == nil {
+		return Error.Wrap(accounting.ErrProjectNotFound)
 	}
 
-	return accounting.ProjectLimits{
-		Usage:     row.UsageLimit,
-		Bandwidth: row.BandwidthLimit,
-	}, nil
+	return nil
 }
 
 // GetRollupsSince retrieves all archived rollup records since a given time.
 func (db *ProjectAccounting) GetRollupsSince(ctx context.Context, since time.Time) (bwRollups []orders.BucketBandwidthRollup, err error) {
 	defer mon.Task()(&ctx)(&err)
 
-	pageLimit := db.db.opts.ReadRollupBatchSize
-	if pageLimit <= 0 {
-		pageLimit = 10000
-	}
+	err = db.ForEachRollupSince(ctx, since, func(ctx context.Context, rollup orders.BucketBandwidthRollup) error {
+		if err := db.checkScanLimit(len(bwRollups) + 1); err != nil {
+			return err
+		}
+		bwRollups = append(bwRollups, rollup)
+		return nil
+	})
+	return bwRollups, err
+}
+
+// ForEachRollupSince calls fn for every rollup record since a given time. Records are read in
+// ReadRollupBatchSize sized pages and iteration stops when fn returns an error.
+func (db *ProjectAccounting) ForEachRollupSince(ctx context.Context, since time.Time, fn func(context.Context, orders.BucketBandwidthRollup) error) (err error) {
+	defer mon.Task()(&ctx)(&err)
 
 	var cursor *dbx.Paged_BucketBandwidthRollup_By_IntervalStart_GreaterOrEqual_Continuation
 	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
 		dbxRollups, next, err := db.db.Paged_BucketBandwidthRollup_By_IntervalStart_GreaterOrEqual(ctx,
 			dbx.BucketBandwidthRollup_IntervalStart(since),
-			pageLimit, cursor)
+			db.readRollupBatchSize(), cursor)
 		if err != nil {
-			return nil, Error.Wrap(err)
+			return Error.Wrap(err)
 		}
 		cursor = next
 		for _, dbxRollup := range dbxRollups {
-			projectID, err := uuid.FromBytes(dbxRollup.ProjectId)
-			if err != nil {
-				return nil, err
+			projectID := scanUUIDBytes(dbxRollup.ProjectId)
+			if !projectID.Valid {
+				if err := db.corruptRow("bucket_bandwidth_rollups", projectID); err != nil {
+					return err
+				}
+				continue
 			}
-			bwRollups = append(bwRollups, orders.BucketBandwidthRollup{
-				ProjectID:  projectID,
+			err := fn(ctx, orders.BucketBandwidthRollup{
+				ProjectID:  projectID.UUID,
 				BucketName: string(dbxRollup.BucketName),
 				Action:     pb.PieceAction(dbxRollup.Action),
 				Inline:     int64(dbxRollup.Inline),
 				Allocated:  int64(dbxRollup.Allocated),
 				Settled:    int64(dbxRollup.Settled),
 			})
+			if err != nil {
+				return err
+			}
 		}
 		if cursor == nil {
-			return bwRollups, nil
+			return nil
 		}
 	}
 }
@@ -833,35 +3748,301 @@ func (db *ProjectAccounting) GetRollupsSince(ctx context.Context, since time.Tim
 func (db *ProjectAccounting) GetArchivedRollupsSince(ctx context.Context, since time.Time) (bwRollups []orders.BucketBandwidthRollup, err error) {
 	defer mon.Task()(&ctx)(&err)
 
-	pageLimit := db.db.opts.ReadRollupBatchSize
-	if pageLimit <= 0 {
-		pageLimit = 10000
-	}
+	err = db.ForEachArchivedRollupSince(ctx, since, func(ctx context.Context, rollup orders.BucketBandwidthRollup) error {
+		bwRollups = append(bwRollups, rollup)
+		return nil
+	})
+	return bwRollups, err
+}
+
+// ForEachArchivedRollupSince calls fn for every archived rollup record since a given time. Records
+// are read in ReadRollupBatchSize sized pages and iteration stops when fn returns an error.
+func (db *ProjectAccounting) ForEachArchivedRollupSince(ctx context.Context, since time.Time, fn func(context.Context, orders.BucketBandwidthRollup) error) (err error) {
+	defer mon.Task()(&ctx)(&err)
 
 	var cursor *dbx.Paged_BucketBandwidthRollupArchive_By_IntervalStart_GreaterOrEqual_Continuation
 	for {
 		dbxRollups, next, err := db.db.Paged_BucketBandwidthRollupArchive_By_IntervalStart_GreaterOrEqual(ctx,
 			dbx.BucketBandwidthRollupArchive_IntervalStart(since),
-			pageLimit, cursor)
+			db.readRollupBatchSize(), cursor)
 		if err != nil {
-			return nil, Error.Wrap(err)
+			return Error.Wrap(err)
 		}
 		cursor = next
 		for _, dbxRollup := range dbxRollups {
-			projectID, err := uuid.FromBytes(dbxRollup.ProjectId)
-			if err != nil {
-				return nil, err
+			projectID := scanUUIDBytes(dbxRollup.ProjectId)
+			if !projectID.Valid {
+				if err := db.corruptRow("bucket_bandwidth_rollup_archives", projectID); err != nil {
+					return err
+				}
+				continue
 			}
-			bwRollups = append(bwRollups, orders.BucketBandwidthRollup{
-				ProjectID:  projectID,
+			err := fn(ctx, orders.BucketBandwidthRollup{
+				ProjectID:  projectID.UUID,
 				BucketName: string(dbxRollup.BucketName),
 				Action:     pb.PieceAction(dbxRollup.Action),
 				Inline:     int64(dbxRollup.Inline),
 				Allocated:  int64(dbxRollup.Allocated),
 				Settled:    int64(dbxRollup.Settled),
 			})
+			if err != nil {
+				return err
+			}
+		}
+		if cursor == nil {
+			return nil
+		}
+	}
+}
+
+// archivedRollupsContinuation is the decoded continuation token of GetArchivedRollupsPage,
+// it holds the key of the last returned row.
+type archivedRollupsContinuation struct {
+	IntervalStart time.Time `json:"i"`
+	ProjectID     []byte    `json:"p"`
+	BucketName    []byte    `json:"b"`
+	Action        int32     `json:"a"`
+}
+
+// encode returns the continuation as an opaque token.
+func (next archivedRollupsContinuation) encode() (string, error) {
+	data, err := json.Marshal(next)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// decodeArchivedRollupsContinuation parses a token returned by archivedRollupsContinuation.encode.
+func decodeArchivedRollupsContinuation(token string) (next archivedRollupsContinuation, err error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return next, err
+	}
+	err = json.Unmarshal(data, &next)
+	return next, err
+}
+
+// GetArchivedRollupsPage returns a page of archived rollup records since a given time, optionally
+// filtered by project and action. Rollups are ordered by interval_start, project_id, bucket_name
+// and action, which is not the primary key order the dbx paged queries use. The page size is capped
+// by ReadRollupBatchSize.
+func (db *ProjectAccounting) GetArchivedRollupsPage(ctx context.Context, cursor accounting.ArchivedRollupsCursor) (page *accounting.ArchivedRollupsPage, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	limit := cursor.Limit
+	if limit <= 0 || limit > db.readRollupBatchSize() {
+		limit = db.readRollupBatchSize()
+	}
+
+	conditions := []string{"interval_start >= ?"}
+	args := []interface{}{cursor.Since}
+	if cursor.ProjectID != nil {
+		conditions = append(conditions, "project_id = ?")
+		args = append(args, cursor.ProjectID[:])
+	}
+	if cursor.Action != nil {
+		conditions = append(conditions, "action = ?")
+		args = append(args, int32(*cursor.Action))
+	}
+	if cursor.Next != "" {
+		last, err := decodeArchivedRollupsContinuation(cursor.Next)
+		if err != nil {
+			return nil, accounting.ErrInvalidArgument.Wrap(fmt.Errorf("%w: invalid continuation token: %v", accounting.ErrInvalidCursor, err))
+		}
+		conditions = append(conditions, "(interval_start, project_id, bucket_name, action) > (?, ?, ?, ?)")
+		args = append(args, last.IntervalStart, last.ProjectID, last.BucketName, last.Action)
+	}
+	// one more row is read to know whether there is a next page.
+	args = append(args, limit+1)
+
+	query := db.db.Rebind(`
+		SELECT interval_start, project_id, bucket_name, action, inline, allocated, settled
+		FROM bucket_bandwidth_rollup_archives
+		WHERE ` + strings.Join(conditions, " AND ") + `
+		ORDER BY interval_start, project_id, bucket_name, action
+		LIMIT ?
+	`)
+
+	rows, err := db.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, Error.Wrap(err)
+	}
+	defer func() { err = errs.Combine(err, rows.Close()) }()
+
+	page = &accounting.ArchivedRollupsPage{
+		Rollups: []accounting.ArchivedBandwidthRollup{},
+	}
+	count := 0
+	for rows.Next() {
+		count++
+		if count > limit {
+			break
+		}
+
+		var last archivedRollupsContinuation
+		var inline, allocated, settled int64
+		err := rows.Scan(&last.IntervalStart, &last.ProjectID, &last.BucketName, &last.Action, &inline, &allocated, &settled)
+		if err != nil {
+			return nil, Error.Wrap(err)
+		}
+
+		if count == limit {
+			page.Next, err = last.encode()
+			if err != nil {
+				return nil, Error.Wrap(err)
+			}
+		}
+
+		projectID := scanUUIDBytes(last.ProjectID)
+		if !projectID.Valid {
+			if err := db.corruptRow("bucket_bandwidth_rollup_archives", projectID); err != nil {
+				return nil, err
+			}
+			continue
 		}
+		page.Rollups = append(page.Rollups, accounting.ArchivedBandwidthRollup{
+			IntervalStart: last.IntervalStart,
+			BucketBandwidthRollup: orders.BucketBandwidthRollup{
+				ProjectID:  projectID.UUID,
+				BucketName: string(last.BucketName),
+				Action:     pb.PieceAction(last.Action),
+				Inline:     inline,
+				Allocated:  allocated,
+				Settled:    settled,
+			},
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, Error.Wrap(err)
+	}
+	if count <= limit {
+		page.Next = ""
+	}
+
+	return page, nil
+}
+
+// readRollupBatchSize returns the page size used when reading rollups.
+func (db *ProjectAccounting) readRollupBatchSize() int {
+	if db.db.opts.ReadRollupBatchSize <= 0 {
+		return 10000
+	}
+	return db.db.opts.ReadRollupBatchSize
+}
+
+// usageQueryShape counts the buckets and rows processed by a usage query. It's reported by
+// GetProjectTotal as project_total_* and by GetBucketUsageRollups as bucket_usage_rollups_*
+// metrics, to find projects with pathological usage data.
+type usageQueryShape struct {
+	Buckets    int64
+	TallyRows  int64
+	RollupRows int64
+}
+
+// add adds the counts of other to shape.
+func (shape *usageQueryShape) add(other usageQueryShape) {
+	shape.Buckets += other.Buckets
+	shape.TallyRows += other.TallyRows
+	shape.RollupRows += other.RollupRows
+}
+
+// checkScanLimit returns ErrTooManyResults when a scan has to process more than
+// Options.MaxAccountingScanResults buckets or rows.
+func (db *ProjectAccounting) checkScanLimit(count int) error {
+	if maxResults := db.db.opts.MaxAccountingScanResults; maxResults > 0 && count > maxResults {
+		return accounting.ErrTooManyResults.New("more than %d buckets or rows to process", maxResults)
+	}
+	return nil
+}
+
+// GetRollupsSinceByProject retrieves all rollup records of a project since a given time.
+func (db *ProjectAccounting) GetRollupsSinceByProject(ctx context.Context, since time.Time, projectID uuid.UUID) (bwRollups []orders.BucketBandwidthRollup, err error) {
+	defer mon.Task()(&ctx)(&err)
+	return db.getRollupsSinceByProject(ctx, "bucket_bandwidth_rollups", since, projectID)
+}
+
+// GetArchivedRollupsSinceByProject retrieves all archived rollup records of a project since a given time.
+func (db *ProjectAccounting) GetArchivedRollupsSinceByProject(ctx context.Context, since time.Time, projectID uuid.UUID) (bwRollups []orders.BucketBandwidthRollup, err error) {
+	defer mon.Task()(&ctx)(&err)
+	return db.getRollupsSinceByProject(ctx, "bucket_bandwidth_rollup_archives", since, projectID)
+}
+
+// getRollupsSinceByProject pages through rollup records of a project in the specified table
+// using ReadRollupBatchSize sized batches.
+func (db *ProjectAccounting) getRollupsSinceByProject(ctx context.Context, table string, since time.Time, projectID uuid.UUID) (bwRollups []orders.BucketBandwidthRollup, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	pageLimit := db.readRollupBatchSize()
+	table = pgutil.QuoteIdentifier(table)
+
+	// the project_id predicate uses the (project_id, action, interval_start) index.
+	firstQuery := db.db.Rebind(`
+		SELECT bucket_name, interval_start, action, inline, allocated, settled
+		FROM ` + table + `
+		WHERE project_id = ? AND interval_start >= ?
+		ORDER BY bucket_name, interval_start, action
+		LIMIT ?
+	`)
+	nextQuery := db.db.Rebind(`
+		SELECT bucket_name, interval_start, action, inline, allocated, settled
+		FROM ` + table + `
+		WHERE project_id = ? AND interval_start >= ?
+			AND (bucket_name, interval_start, action) > (?, ?, ?)
+		ORDER BY bucket_name, interval_start, action
+		LIMIT ?
+	`)
+
+	type continuation struct {
+		bucketName    []byte
+		intervalStart time.Time
+		action        int32
+	}
+	var cursor *continuation
+
+	for {
+		var rows tagsql.Rows
 		if cursor == nil {
+			rows, err = db.db.QueryContext(ctx, firstQuery, projectID[:], since, pageLimit)
+		} else {
+			rows, err = db.db.QueryContext(ctx, nextQuery, projectID[:], since,
+				cursor.bucketName, cursor.intervalStart, cursor.action, pageLimit)
+		}
+		if err != nil {
+			return nil, Error.Wrap(err)
+		}
+
+		count := 0
+		err = func() (err error) {
+			defer func() { err = errs.Combine(err, rows.Close()) }()
+			for rows.Next() {
+				var next continuation
+				var inline, allocated, settled int64
+				err := rows.Scan(&next.bucketName, &next.intervalStart, &next.action, &inline, &allocated, &settled)
+				if err != nil {
+					return err
+				}
+				bwRollups = append(bwRollups, orders.BucketBandwidthRollup{
+					ProjectID:  projectID,
+					BucketName: string(next.bucketName),
+					Action:     pb.PieceAction(next.action),
+					Inline:     inline,
+					Allocated:  allocated,
+					Settled:    settled,
+				})
+				cursor = &next
+				count++
+			}
+			return rows.Err()
+		}()
+		if err != nil {
+			return nil, Error.Wrap(err)
+		}
+
+		if count < pageLimit {
+			if bwRollups == nil {
+				bwRollups = []orders.BucketBandwidthRollup{}
+			}
 			return bwRollups, nil
 		}
 	}
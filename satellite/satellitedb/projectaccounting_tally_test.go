@@ -0,0 +1,54 @@
+// Copyright (C) 2021 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package satellitedb
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"storj.io/storj/satellite/accounting"
+)
+
+func TestTallyHoursOutOfOrderTallies(t *testing.T) {
+	db := &ProjectAccounting{db: &satelliteDB{}}
+
+	start := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	before := start.Add(24 * time.Hour)
+
+	tally := func(hour int) *accounting.BucketStorageTally {
+		return &accounting.BucketStorageTally{
+			IntervalStart: start.Add(time.Duration(hour) * time.Hour),
+			TotalBytes:    100,
+			ObjectCount:   1,
+		}
+	}
+	total := func(tallies ...*accounting.BucketStorageTally) accounting.ProjectUsage {
+		var usage accounting.ProjectUsage
+		db.addTalliesToUsage(&usage, tallies, before)
+		return usage
+	}
+
+	assert.Zero(t, db.tallyHours(start, start, before), "duplicate interval start")
+	assert.Zero(t, db.tallyHours(start, start.Add(-time.Hour), before), "out of order interval start")
+
+	ordered := total(tally(3), tally(2), tally(1), tally(0))
+	require.EqualValues(t, 300, ordered.Storage)
+	require.EqualValues(t, 3, ordered.ObjectCount)
+
+	duplicate := total(tally(3), tally(2), tally(1), tally(1), tally(0))
+	assert.Equal(t, ordered, duplicate)
+
+	// the tally at hour 1 is reported after the one at hour 2, e.g. after the tally
+	// chore restarted with a skewed clock.
+	outOfOrder := total(tally(3), tally(1), tally(2), tally(0))
+	assert.GreaterOrEqual(t, outOfOrder.Storage, ordered.Storage)
+	assert.GreaterOrEqual(t, outOfOrder.ObjectCount, ordered.ObjectCount)
+
+	skewed := total(tally(3), tally(2), tally(1), tally(5), tally(0))
+	assert.GreaterOrEqual(t, skewed.Storage, ordered.Storage)
+	assert.GreaterOrEqual(t, skewed.ObjectCount, ordered.ObjectCount)
+}
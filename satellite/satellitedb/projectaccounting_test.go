@@ -0,0 +1,1445 @@
+// Copyright (C) 2021 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package satellitedb_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/spacemonkeygo/monkit/v3"
+	"github.com/stretchr/testify/require"
+	"github.com/zeebo/errs"
+	"go.uber.org/zap/zaptest"
+
+	"storj.io/common/memory"
+	"storj.io/common/pb"
+	"storj.io/common/storj"
+	"storj.io/common/testcontext"
+	"storj.io/common/testrand"
+	"storj.io/common/uuid"
+	"storj.io/private/dbutil/tempdb"
+	"storj.io/storj/satellite"
+	"storj.io/storj/satellite/accounting"
+	"storj.io/storj/satellite/console"
+	"storj.io/storj/satellite/metabase"
+	"storj.io/storj/satellite/orders"
+	"storj.io/storj/satellite/satellitedb"
+	"storj.io/storj/satellite/satellitedb/satellitedbtest"
+)
+
+func TestGetTalliesCorruptProjectID(t *testing.T) {
+	for _, dbInfo := range satellitedbtest.Databases() {
+		dbInfo := dbInfo
+		t.Run(dbInfo.Name, func(t *testing.T) {
+			if dbInfo.MasterDB.URL == "" {
+				t.Skipf("Database %s connection string not provided. %s", dbInfo.MasterDB.Name, dbInfo.MasterDB.Message)
+			}
+
+			for _, strict := range []bool{false, true} {
+				strict := strict
+				name := "lenient"
+				if strict {
+					name = "strict"
+				}
+				t.Run(name, func(t *testing.T) {
+					ctx := testcontext.New(t)
+					defer ctx.Cleanup()
+
+					tempDB, err := tempdb.OpenUnique(ctx, dbInfo.MasterDB.URL, "corrupttallies")
+					require.NoError(t, err)
+					defer ctx.Check(tempDB.Close)
+
+					db, err := satellitedb.Open(ctx, zaptest.NewLogger(t), tempDB.ConnStr, satellitedb.Options{
+						ApplicationName:       "satellite-accounting-test",
+						StrictAccountingReads: strict,
+					})
+					require.NoError(t, err)
+					defer ctx.Check(db.Close)
+					require.NoError(t, db.TestingMigrateToLatest(ctx))
+
+					projectID := testrand.UUID()
+					location := metabase.BucketLocation{ProjectID: projectID, BucketName: "valid"}
+					_, _, err = db.ProjectAccounting().SaveTallies(ctx, time.Now(), map[metabase.BucketLocation]*accounting.BucketTally{
+						location: {BucketLocation: location, ObjectCount: 1, TotalSegments: 1, TotalBytes: 1},
+					})
+					require.NoError(t, err)
+
+					// we need raw database access to store a corrupt project_id
+					rawdb := db.(migrationTestingAccess).MigrationTestingDefaultDB().TestDBAccess()
+					_, err = rawdb.ExecContext(ctx, `
+						INSERT INTO bucket_storage_tallies (
+							interval_start, bucket_name, project_id,
+							total_bytes, inline, remote,
+							total_segments_count, remote_segments_count, inline_segments_count,
+							object_count, metadata_size)
+						VALUES ($1, $2, $3, 0, 0, 0, 0, 0, 0, 0, 0)`,
+						time.Now(), []byte("corrupt"), []byte{1, 2, 3})
+					require.NoError(t, err)
+
+					tallies, err := db.ProjectAccounting().GetTallies(ctx)
+					sinceTallies, sinceErr := db.ProjectAccounting().GetTalliesSince(ctx, time.Now().Add(-time.Hour))
+					if strict {
+						require.Error(t, err)
+						require.Error(t, sinceErr)
+						return
+					}
+					require.NoError(t, err)
+					require.Len(t, tallies, 1)
+					require.Equal(t, location, tallies[0].BucketLocation)
+
+					require.NoError(t, sinceErr)
+					require.Equal(t, tallies, sinceTallies)
+				})
+			}
+		})
+	}
+}
+
+func TestProjectListingsCorruptProjectID(t *testing.T) {
+	for _, dbInfo := range satellitedbtest.Databases() {
+		dbInfo := dbInfo
+		t.Run(dbInfo.Name, func(t *testing.T) {
+			if dbInfo.MasterDB.URL == "" {
+				t.Skipf("Database %s connection string not provided. %s", dbInfo.MasterDB.Name, dbInfo.MasterDB.Message)
+			}
+
+			for _, strict := range []bool{false, true} {
+				strict := strict
+				name := "lenient"
+				if strict {
+					name = "strict"
+				}
+				t.Run(name, func(t *testing.T) {
+					ctx := testcontext.New(t)
+					defer ctx.Cleanup()
+
+					tempDB, err := tempdb.OpenUnique(ctx, dbInfo.MasterDB.URL, "corruptlistings")
+					require.NoError(t, err)
+					defer ctx.Check(tempDB.Close)
+
+					db, err := satellitedb.Open(ctx, zaptest.NewLogger(t), tempDB.ConnStr, satellitedb.Options{
+						ApplicationName:       "satellite-accounting-test",
+						StrictAccountingReads: strict,
+					})
+					require.NoError(t, err)
+					defer ctx.Check(db.Close)
+					require.NoError(t, db.TestingMigrateToLatest(ctx))
+
+					now := time.Now().UTC().Truncate(time.Hour)
+					since, before := now.Add(-time.Hour), now.Add(time.Hour)
+
+					project, err := db.Console().Projects().Insert(ctx, &console.Project{Name: "valid", OwnerID: testrand.UUID()})
+					require.NoError(t, err)
+					_, err = db.Buckets().CreateBucket(ctx, storj.Bucket{
+						ID:        testrand.UUID(),
+						Name:      "valid",
+						ProjectID: project.ID,
+					})
+					require.NoError(t, err)
+
+					// we need raw database access to store a corrupt project_id
+					rawdb := db.(migrationTestingAccess).MigrationTestingDefaultDB().TestDBAccess()
+					for _, projectID := range [][]byte{project.ID[:], {1, 2, 3}} {
+						_, err = rawdb.ExecContext(ctx, `
+							INSERT INTO bucket_storage_tallies (
+								interval_start, bucket_name, project_id,
+								total_bytes, inline, remote,
+								total_segments_count, remote_segments_count, inline_segments_count,
+								object_count, metadata_size)
+							VALUES ($1, $2, $3, 100, 0, 100, 1, 1, 0, 1, 0)`,
+							now, []byte("bucket"), projectID)
+						require.NoError(t, err)
+
+						_, err = rawdb.ExecContext(ctx, `
+							INSERT INTO bucket_bandwidth_rollups (
+								bucket_name, project_id, interval_start, interval_seconds,
+								action, inline, allocated, settled)
+							VALUES ($1, $2, $3, 3600, $4, 0, 0, 100)`,
+							[]byte("bucket"), projectID, now, int32(pb.PieceAction_GET))
+						require.NoError(t, err)
+					}
+
+					ownerID, bucketID := testrand.UUID(), testrand.UUID()
+					_, err = rawdb.ExecContext(ctx, `
+						INSERT INTO projects (id, name, description, owner_id, created_at)
+						VALUES ($1, 'corrupt', '', $2, now())`,
+						[]byte{1, 2, 3}, ownerID[:])
+					require.NoError(t, err)
+					_, err = rawdb.ExecContext(ctx, `
+						INSERT INTO bucket_metainfos (
+							id, project_id, name, path_cipher, created_at,
+							default_segment_size, default_encryption_cipher_suite, default_encryption_block_size,
+							default_redundancy_algorithm, default_redundancy_share_size, default_redundancy_required_shares,
+							default_redundancy_repair_shares, default_redundancy_optimal_shares, default_redundancy_total_shares)
+						VALUES ($1, $2, 'corrupt', 0, now(), 0, 0, 0, 0, 0, 0, 0, 0, 0)`,
+						bucketID[:], []byte{1, 2, 3})
+					require.NoError(t, err)
+
+					pdb := db.ProjectAccounting()
+
+					t.Run("GetActiveProjects", func(t *testing.T) {
+						active, err := pdb.GetActiveProjects(ctx, since, before)
+						if strict {
+							require.Error(t, err)
+							return
+						}
+						require.NoError(t, err)
+						require.Equal(t, []uuid.UUID{project.ID}, active)
+					})
+
+					// listings of given projects never match a corrupt project_id.
+					t.Run("GetProjectTotals", func(t *testing.T) {
+						expected, err := pdb.GetProjectTotal(ctx, project.ID, since, before, 0)
+						require.NoError(t, err)
+						require.NotZero(t, expected.Egress)
+
+						totals, err := pdb.GetProjectTotals(ctx, []uuid.UUID{project.ID}, since, before)
+						require.NoError(t, err)
+						require.Len(t, totals, 1)
+						require.Equal(t, expected, totals[project.ID])
+					})
+
+					t.Run("GetBucketCounts", func(t *testing.T) {
+						counts, err := pdb.GetBucketCounts(ctx, []uuid.UUID{project.ID})
+						require.NoError(t, err)
+						require.Equal(t, map[uuid.UUID]int64{project.ID: 1}, counts)
+					})
+				})
+			}
+		})
+	}
+}
+
+func TestGetBucketTotalsSearchTooBroad(t *testing.T) {
+	for _, dbInfo := range satellitedbtest.Databases() {
+		dbInfo := dbInfo
+		t.Run(dbInfo.Name, func(t *testing.T) {
+			if dbInfo.MasterDB.URL == "" {
+				t.Skipf("Database %s connection string not provided. %s", dbInfo.MasterDB.Name, dbInfo.MasterDB.Message)
+			}
+
+			ctx := testcontext.New(t)
+			defer ctx.Cleanup()
+
+			tempDB, err := tempdb.OpenUnique(ctx, dbInfo.MasterDB.URL, "searchtoobroad")
+			require.NoError(t, err)
+			defer ctx.Check(tempDB.Close)
+
+			db, err := satellitedb.Open(ctx, zaptest.NewLogger(t), tempDB.ConnStr, satellitedb.Options{
+				ApplicationName:        "satellite-accounting-test",
+				MaxBucketSearchMatches: 3,
+			})
+			require.NoError(t, err)
+			defer ctx.Check(db.Close)
+			require.NoError(t, db.TestingMigrateToLatest(ctx))
+
+			project, err := db.Console().Projects().Insert(ctx, &console.Project{Name: "test", OwnerID: testrand.UUID()})
+			require.NoError(t, err)
+
+			for _, name := range []string{"alpha0", "alpha1", "alpha2", "alpha3", "beta"} {
+				_, err := db.Buckets().CreateBucket(ctx, storj.Bucket{
+					ID:        testrand.UUID(),
+					Name:      name,
+					ProjectID: project.ID,
+				})
+				require.NoError(t, err)
+			}
+
+			since, before := time.Now().Add(-time.Hour), time.Now()
+			search := func(prefix string) (*accounting.BucketUsagePage, error) {
+				return db.ProjectAccounting().GetBucketTotals(ctx, project.ID, accounting.BucketUsageCursor{
+					Search: prefix,
+					Limit:  10,
+					Page:   1,
+				}, since, before, 0)
+			}
+
+			// a search matching more buckets than allowed is rejected.
+			_, err = search("a")
+			require.Error(t, err)
+			require.True(t, accounting.ErrSearchTooBroad.Has(err))
+
+			page, err := search("alpha0")
+			require.NoError(t, err)
+			require.EqualValues(t, 1, page.TotalCount)
+
+			err = db.Buckets().DeleteBucket(ctx, []byte("alpha3"), project.ID)
+			require.NoError(t, err)
+
+			// a search at the limit returns the exact count.
+			page, err = search("a")
+			require.NoError(t, err)
+			require.EqualValues(t, 3, page.TotalCount)
+			require.Len(t, page.BucketUsages, 3)
+
+			// listing without a search is not limited.
+			page, err = search("")
+			require.NoError(t, err)
+			require.EqualValues(t, 4, page.TotalCount)
+		})
+	}
+}
+
+func TestProjectTotalTallyInterval(t *testing.T) {
+	for _, dbInfo := range satellitedbtest.Databases() {
+		dbInfo := dbInfo
+		t.Run(dbInfo.Name, func(t *testing.T) {
+			if dbInfo.MasterDB.URL == "" {
+				t.Skipf("Database %s connection string not provided. %s", dbInfo.MasterDB.Name, dbInfo.MasterDB.Message)
+			}
+
+			ctx := testcontext.New(t)
+			defer ctx.Cleanup()
+
+			tempDB, err := tempdb.OpenUnique(ctx, dbInfo.MasterDB.URL, "tallyinterval")
+			require.NoError(t, err)
+			defer ctx.Check(tempDB.Close)
+
+			oldDB, err := satellitedb.Open(ctx, zaptest.NewLogger(t), tempDB.ConnStr, satellitedb.Options{
+				ApplicationName: "satellite-accounting-test",
+			})
+			require.NoError(t, err)
+			defer ctx.Check(oldDB.Close)
+			require.NoError(t, oldDB.TestingMigrateToLatest(ctx))
+
+			newDB, err := satellitedb.Open(ctx, zaptest.NewLogger(t), tempDB.ConnStr, satellitedb.Options{
+				ApplicationName:       "satellite-accounting-test",
+				ExpectedTallyInterval: time.Hour,
+				MaxTallyGap:           2 * time.Hour,
+			})
+			require.NoError(t, err)
+			defer ctx.Check(newDB.Close)
+
+			projectID := testrand.UUID()
+			since := time.Now().UTC().Truncate(time.Hour).Add(-24 * time.Hour)
+
+			// tally runs between 2h and 6h were missed.
+			for _, offset := range []time.Duration{0, 1, 2, 6, 7} {
+				err := oldDB.ProjectAccounting().CreateStorageTally(ctx, accounting.BucketStorageTally{
+					BucketName:    "bucket",
+					ProjectID:     projectID,
+					IntervalStart: since.Add(offset * time.Hour),
+					ObjectCount:   1,
+					TotalBytes:    1000,
+				})
+				require.NoError(t, err)
+			}
+
+			for _, tt := range []struct {
+				name     string
+				before   time.Time
+				oldHours float64
+				newHours float64
+			}{
+				// the most recent tally is accounted for the expected interval
+				// and the gap of missed runs is capped.
+				{name: "after last tally", before: since.Add(9 * time.Hour), oldHours: 1 + 1 + 4 + 1, newHours: 1 + 1 + 2 + 1 + 1},
+				// the most recent tally isn't accounted past the end of the period.
+				{name: "shortly after last tally", before: since.Add(7*time.Hour + 30*time.Minute), oldHours: 1 + 1 + 4 + 1, newHours: 1 + 1 + 2 + 1 + 0.5},
+			} {
+				t.Run(tt.name, func(t *testing.T) {
+					oldTotal, err := oldDB.ProjectAccounting().GetProjectTotal(ctx, projectID, since, tt.before, 0)
+					require.NoError(t, err)
+					require.InDelta(t, 1000*tt.oldHours, oldTotal.Storage, 1e-6)
+					require.InDelta(t, tt.oldHours, oldTotal.ObjectCount, 1e-6)
+
+					newTotal, err := newDB.ProjectAccounting().GetProjectTotal(ctx, projectID, since, tt.before, 0)
+					require.NoError(t, err)
+					require.InDelta(t, 1000*tt.newHours, newTotal.Storage, 1e-6)
+					require.InDelta(t, tt.newHours, newTotal.ObjectCount, 1e-6)
+
+					oldRollups, err := oldDB.ProjectAccounting().GetBucketUsageRollups(ctx, projectID, since, tt.before, 0)
+					require.NoError(t, err)
+					require.Len(t, oldRollups, 1)
+					require.InDelta(t, tt.oldHours, oldRollups[0].ObjectCount, 1e-6)
+
+					newRollups, err := newDB.ProjectAccounting().GetBucketUsageRollups(ctx, projectID, since, tt.before, 0)
+					require.NoError(t, err)
+					require.Len(t, newRollups, 1)
+					require.InDelta(t, tt.newHours, newRollups[0].ObjectCount, 1e-6)
+					require.InDelta(t, newTotal.Storage/1e9, newRollups[0].TotalStoredData, 1e-9)
+				})
+			}
+		})
+	}
+}
+
+func TestAdjacentPeriodsAreNotDoubleCounted(t *testing.T) {
+	for _, dbInfo := range satellitedbtest.Databases() {
+		dbInfo := dbInfo
+		t.Run(dbInfo.Name, func(t *testing.T) {
+			if dbInfo.MasterDB.URL == "" {
+				t.Skipf("Database %s connection string not provided. %s", dbInfo.MasterDB.Name, dbInfo.MasterDB.Message)
+			}
+
+			ctx := testcontext.New(t)
+			defer ctx.Cleanup()
+
+			tempDB, err := tempdb.OpenUnique(ctx, dbInfo.MasterDB.URL, "adjacentperiods")
+			require.NoError(t, err)
+			defer ctx.Check(tempDB.Close)
+
+			// with the expected tally interval the last tally of a period is accounted
+			// until the end of the period, so storage of adjacent periods adds up too.
+			db, err := satellitedb.Open(ctx, zaptest.NewLogger(t), tempDB.ConnStr, satellitedb.Options{
+				ApplicationName:       "satellite-accounting-test",
+				ExpectedTallyInterval: time.Hour,
+			})
+			require.NoError(t, err)
+			defer ctx.Check(db.Close)
+			require.NoError(t, db.TestingMigrateToLatest(ctx))
+
+			now := time.Now().UTC()
+			boundary := time.Date(now.Year(), now.Month()-1, 1, 0, 0, 0, 0, time.UTC)
+			first, second := boundary.AddDate(0, -1, 0), boundary.AddDate(0, 1, 0)
+			pdb := db.ProjectAccounting()
+
+			projectID := testrand.UUID()
+			for _, bucketName := range []string{"a-bucket", "b-bucket"} {
+				_, err := db.Buckets().CreateBucket(ctx, storj.Bucket{
+					ID:        testrand.UUID(),
+					Name:      bucketName,
+					ProjectID: projectID,
+				})
+				require.NoError(t, err)
+
+				// the tally and the rollup at the boundary belong to the second period.
+				for hour := -3; hour <= 3; hour++ {
+					interval := boundary.Add(time.Duration(hour) * time.Hour)
+					err := pdb.CreateStorageTally(ctx, accounting.BucketStorageTally{
+						BucketName:    bucketName,
+						ProjectID:     projectID,
+						IntervalStart: interval,
+						ObjectCount:   int64(hour + 4),
+						TotalBytes:    int64(hour+4) * memory.KB.Int64(),
+					})
+					require.NoError(t, err)
+
+					for _, action := range []pb.PieceAction{pb.PieceAction_GET, pb.PieceAction_GET_REPAIR} {
+						err = db.Orders().UpdateBucketBandwidthSettle(ctx, projectID, []byte(bucketName), action, int64(hour+4)*memory.MB.Int64(), interval)
+						require.NoError(t, err)
+					}
+				}
+			}
+
+			t.Run("project total", func(t *testing.T) {
+				firstTotal, err := pdb.GetProjectTotal(ctx, projectID, first, boundary, 0)
+				require.NoError(t, err)
+				secondTotal, err := pdb.GetProjectTotal(ctx, projectID, boundary, second, 0)
+				require.NoError(t, err)
+				combined, err := pdb.GetProjectTotal(ctx, projectID, first, second, 0)
+				require.NoError(t, err)
+
+				require.Equal(t, 2*(1+2+3)*memory.MB.Int64(), firstTotal.Egress)
+				require.Equal(t, combined.Egress, firstTotal.Egress+secondTotal.Egress)
+				require.InDelta(t, combined.Storage, firstTotal.Storage+secondTotal.Storage, 1e-6)
+				require.InDelta(t, combined.ObjectCount, firstTotal.ObjectCount+secondTotal.ObjectCount, 1e-6)
+
+				totals, err := pdb.GetProjectTotals(ctx, []uuid.UUID{projectID}, first, boundary)
+				require.NoError(t, err)
+				require.Equal(t, firstTotal.Egress, totals[projectID].Egress)
+				require.InDelta(t, firstTotal.Storage, totals[projectID].Storage, 1e-6)
+			})
+
+			t.Run("egress by action", func(t *testing.T) {
+				firstEgress, err := pdb.GetProjectEgressByAction(ctx, projectID, first, boundary)
+				require.NoError(t, err)
+				secondEgress, err := pdb.GetProjectEgressByAction(ctx, projectID, boundary, second)
+				require.NoError(t, err)
+				combined, err := pdb.GetProjectEgressByAction(ctx, projectID, first, second)
+				require.NoError(t, err)
+
+				for action, egress := range combined {
+					require.Equal(t, egress, firstEgress[action]+secondEgress[action], action)
+				}
+			})
+
+			t.Run("bucket usage rollups", func(t *testing.T) {
+				firstRollups, err := pdb.GetBucketUsageRollups(ctx, projectID, first, boundary, 0)
+				require.NoError(t, err)
+				secondRollups, err := pdb.GetBucketUsageRollups(ctx, projectID, boundary, second, 0)
+				require.NoError(t, err)
+				combined, err := pdb.GetBucketUsageRollups(ctx, projectID, first, second, 0)
+				require.NoError(t, err)
+				require.Len(t, firstRollups, 2)
+				require.Len(t, secondRollups, 2)
+				require.Len(t, combined, 2)
+
+				for i := range combined {
+					require.InDelta(t, combined[i].GetEgress, firstRollups[i].GetEgress+secondRollups[i].GetEgress, 1e-9)
+					require.InDelta(t, combined[i].RepairEgress, firstRollups[i].RepairEgress+secondRollups[i].RepairEgress, 1e-9)
+					require.InDelta(t, combined[i].TotalStoredData, firstRollups[i].TotalStoredData+secondRollups[i].TotalStoredData, 1e-9)
+					require.InDelta(t, combined[i].ObjectCount, firstRollups[i].ObjectCount+secondRollups[i].ObjectCount, 1e-6)
+				}
+			})
+
+			t.Run("bucket totals", func(t *testing.T) {
+				cursor := accounting.BucketUsageCursor{Limit: 10, Page: 1}
+				firstPage, err := pdb.GetBucketTotals(ctx, projectID, cursor, first, boundary, 0)
+				require.NoError(t, err)
+				secondPage, err := pdb.GetBucketTotals(ctx, projectID, cursor, boundary, second, 0)
+				require.NoError(t, err)
+				combined, err := pdb.GetBucketTotals(ctx, projectID, cursor, first, second, 0)
+				require.NoError(t, err)
+				require.Len(t, combined.BucketUsages, 2)
+
+				for i, usage := range combined.BucketUsages {
+					require.InDelta(t, usage.Egress, firstPage.BucketUsages[i].Egress+secondPage.BucketUsages[i].Egress, 1e-9)
+					// the latest tally of the first period is the one before the boundary.
+					require.InDelta(t, (3 * memory.KB).GB(), firstPage.BucketUsages[i].Storage, 1e-9)
+				}
+
+				firstGroups, err := pdb.GetBucketTotalsGrouped(ctx, projectID, "-", first, boundary)
+				require.NoError(t, err)
+				secondGroups, err := pdb.GetBucketTotalsGrouped(ctx, projectID, "-", boundary, second)
+				require.NoError(t, err)
+				combinedGroups, err := pdb.GetBucketTotalsGrouped(ctx, projectID, "-", first, second)
+				require.NoError(t, err)
+				require.Len(t, combinedGroups, 2)
+
+				for i, group := range combinedGroups {
+					require.InDelta(t, group.Egress, firstGroups[i].Egress+secondGroups[i].Egress, 1e-9)
+				}
+			})
+
+			t.Run("bandwidth breakdown", func(t *testing.T) {
+				firstPage, err := pdb.GetBucketBandwidthBreakdown(ctx, projectID, accounting.BucketBandwidthBreakdownCursor{}, first, boundary)
+				require.NoError(t, err)
+				secondPage, err := pdb.GetBucketBandwidthBreakdown(ctx, projectID, accounting.BucketBandwidthBreakdownCursor{}, boundary, second)
+				require.NoError(t, err)
+				combined, err := pdb.GetBucketBandwidthBreakdown(ctx, projectID, accounting.BucketBandwidthBreakdownCursor{}, first, second)
+				require.NoError(t, err)
+				require.Len(t, combined.Breakdowns, 2)
+
+				for i, breakdown := range combined.Breakdowns {
+					require.Equal(t, breakdown.Settled, firstPage.Breakdowns[i].Settled+secondPage.Breakdowns[i].Settled)
+				}
+			})
+
+			t.Run("object count series", func(t *testing.T) {
+				firstPoints, err := pdb.GetBucketObjectCountSeries(ctx, projectID, "a-bucket", first, boundary, 0)
+				require.NoError(t, err)
+				secondPoints, err := pdb.GetBucketObjectCountSeries(ctx, projectID, "a-bucket", boundary, second, 0)
+				require.NoError(t, err)
+				require.Len(t, firstPoints, 3)
+				require.Len(t, secondPoints, 4)
+				require.True(t, boundary.Equal(secondPoints[0].IntervalStart))
+			})
+		})
+	}
+}
+
+func TestGetRollupsSinceByProject(t *testing.T) {
+	for _, dbInfo := range satellitedbtest.Databases() {
+		dbInfo := dbInfo
+		t.Run(dbInfo.Name, func(t *testing.T) {
+			if dbInfo.MasterDB.URL == "" {
+				t.Skipf("Database %s connection string not provided. %s", dbInfo.MasterDB.Name, dbInfo.MasterDB.Message)
+			}
+
+			ctx := testcontext.New(t)
+			defer ctx.Cleanup()
+
+			tempDB, err := tempdb.OpenUnique(ctx, dbInfo.MasterDB.URL, "rollupsbyproject")
+			require.NoError(t, err)
+			defer ctx.Check(tempDB.Close)
+
+			// use small batches to exercise the continuation.
+			db, err := satellitedb.Open(ctx, zaptest.NewLogger(t), tempDB.ConnStr, satellitedb.Options{
+				ApplicationName:     "satellite-accounting-test",
+				ReadRollupBatchSize: 2,
+			})
+			require.NoError(t, err)
+			defer ctx.Check(db.Close)
+			require.NoError(t, db.TestingMigrateToLatest(ctx))
+
+			now := time.Now().UTC().Truncate(time.Hour)
+			old := now.Add(-48 * time.Hour)
+			since := now.Add(-72 * time.Hour)
+
+			project, otherProject := testrand.UUID(), testrand.UUID()
+			for _, interval := range []time.Time{old, now} {
+				for _, bucket := range []string{"alpha", "beta", "gamma"} {
+					for _, action := range []pb.PieceAction{pb.PieceAction_GET, pb.PieceAction_PUT} {
+						err := db.Orders().UpdateBucketBandwidthSettle(ctx, project, []byte(bucket), action, 100, interval)
+						require.NoError(t, err)
+					}
+				}
+				err := db.Orders().UpdateBucketBandwidthSettle(ctx, otherProject, []byte("alpha"), pb.PieceAction_GET, 100, interval)
+				require.NoError(t, err)
+			}
+
+			_, err = db.ProjectAccounting().ArchiveRollupsBefore(ctx, now.Add(-24*time.Hour), 100)
+			require.NoError(t, err)
+
+			check := func(rollups []orders.BucketBandwidthRollup, projectID uuid.UUID, count int) {
+				require.Len(t, rollups, count)
+				for _, rollup := range rollups {
+					require.Equal(t, projectID, rollup.ProjectID)
+					require.EqualValues(t, 100, rollup.Settled)
+				}
+			}
+
+			rollups, err := db.ProjectAccounting().GetRollupsSinceByProject(ctx, since, project)
+			require.NoError(t, err)
+			check(rollups, project, 6)
+
+			rollups, err = db.ProjectAccounting().GetRollupsSinceByProject(ctx, since, otherProject)
+			require.NoError(t, err)
+			check(rollups, otherProject, 1)
+
+			archived, err := db.ProjectAccounting().GetArchivedRollupsSinceByProject(ctx, since, project)
+			require.NoError(t, err)
+			check(archived, project, 6)
+
+			// the results match filtering all rollups.
+			all, err := db.ProjectAccounting().GetRollupsSince(ctx, since)
+			require.NoError(t, err)
+			var filtered []orders.BucketBandwidthRollup
+			for _, rollup := range all {
+				if rollup.ProjectID == project {
+					filtered = append(filtered, rollup)
+				}
+			}
+			rollups, err = db.ProjectAccounting().GetRollupsSinceByProject(ctx, since, project)
+			require.NoError(t, err)
+			require.ElementsMatch(t, filtered, rollups)
+
+			// filters without matches return an empty result.
+			rollups, err = db.ProjectAccounting().GetRollupsSinceByProject(ctx, since, testrand.UUID())
+			require.NoError(t, err)
+			require.NotNil(t, rollups)
+			require.Empty(t, rollups)
+
+			archived, err = db.ProjectAccounting().GetArchivedRollupsSinceByProject(ctx, now, project)
+			require.NoError(t, err)
+			require.NotNil(t, archived)
+			require.Empty(t, archived)
+		})
+	}
+}
+
+func TestForEachRollupSinceAbort(t *testing.T) {
+	for _, dbInfo := range satellitedbtest.Databases() {
+		dbInfo := dbInfo
+		t.Run(dbInfo.Name, func(t *testing.T) {
+			if dbInfo.MasterDB.URL == "" {
+				t.Skipf("Database %s connection string not provided. %s", dbInfo.MasterDB.Name, dbInfo.MasterDB.Message)
+			}
+
+			ctx := testcontext.New(t)
+			defer ctx.Cleanup()
+
+			tempDB, err := tempdb.OpenUnique(ctx, dbInfo.MasterDB.URL, "foreachrollup")
+			require.NoError(t, err)
+			defer ctx.Check(tempDB.Close)
+
+			// strict reads fail on the corrupt row, which is only reached by fetching the second page.
+			db, err := satellitedb.Open(ctx, zaptest.NewLogger(t), tempDB.ConnStr, satellitedb.Options{
+				ApplicationName:       "satellite-accounting-test",
+				ReadRollupBatchSize:   2,
+				StrictAccountingReads: true,
+			})
+			require.NoError(t, err)
+			defer ctx.Check(db.Close)
+			require.NoError(t, db.TestingMigrateToLatest(ctx))
+
+			now := time.Now().UTC().Truncate(time.Hour)
+			projectID := testrand.UUID()
+
+			// we need raw database access to store a corrupt project_id
+			rawdb := db.(migrationTestingAccess).MigrationTestingDefaultDB().TestDBAccess()
+			for _, table := range []string{"bucket_bandwidth_rollups", "bucket_bandwidth_rollup_archives"} {
+				for _, rollup := range []struct {
+					bucket    string
+					projectID []byte
+				}{
+					{"a", projectID[:]},
+					{"b", projectID[:]},
+					{"c", []byte{1, 2, 3}},
+					{"d", projectID[:]},
+				} {
+					_, err = rawdb.ExecContext(ctx, `
+						INSERT INTO `+table+` (
+							bucket_name, project_id, interval_start, interval_seconds,
+							action, inline, allocated, settled)
+						VALUES ($1, $2, $3, 3600, $4, 0, 0, 100)`,
+						[]byte(rollup.bucket), rollup.projectID, now, int32(pb.PieceAction_GET))
+					require.NoError(t, err)
+				}
+			}
+
+			errAbort := errs.New("abort")
+			for _, forEach := range []struct {
+				name string
+				fn   func(context.Context, time.Time, func(context.Context, orders.BucketBandwidthRollup) error) error
+			}{
+				{"rollups", db.ProjectAccounting().ForEachRollupSince},
+				{"archived", db.ProjectAccounting().ForEachArchivedRollupSince},
+			} {
+				forEach := forEach
+				t.Run(forEach.name, func(t *testing.T) {
+					var buckets []string
+					err := forEach.fn(ctx, now, func(ctx context.Context, rollup orders.BucketBandwidthRollup) error {
+						buckets = append(buckets, rollup.BucketName)
+						return nil
+					})
+					require.Error(t, err)
+					require.False(t, errors.Is(err, errAbort))
+					require.Equal(t, []string{"a", "b"}, buckets)
+
+					for _, abortAfter := range []int{1, 2} {
+						buckets = nil
+						err = forEach.fn(ctx, now, func(ctx context.Context, rollup orders.BucketBandwidthRollup) error {
+							buckets = append(buckets, rollup.BucketName)
+							if len(buckets) == abortAfter {
+								return errAbort
+							}
+							return nil
+						})
+						require.True(t, errors.Is(err, errAbort), err)
+						require.Len(t, buckets, abortAfter)
+					}
+				})
+			}
+		})
+	}
+}
+
+func TestAccountingScanLimits(t *testing.T) {
+	for _, dbInfo := range satellitedbtest.Databases() {
+		dbInfo := dbInfo
+		t.Run(dbInfo.Name, func(t *testing.T) {
+			if dbInfo.MasterDB.URL == "" {
+				t.Skipf("Database %s connection string not provided. %s", dbInfo.MasterDB.Name, dbInfo.MasterDB.Message)
+			}
+
+			ctx := testcontext.New(t)
+			defer ctx.Cleanup()
+
+			tempDB, err := tempdb.OpenUnique(ctx, dbInfo.MasterDB.URL, "scanlimits")
+			require.NoError(t, err)
+			defer ctx.Check(tempDB.Close)
+
+			db, err := satellitedb.Open(ctx, zaptest.NewLogger(t), tempDB.ConnStr, satellitedb.Options{
+				ApplicationName:          "satellite-accounting-test",
+				ReadRollupBatchSize:      2,
+				MaxAccountingScanResults: 3,
+			})
+			require.NoError(t, err)
+			defer ctx.Check(db.Close)
+			require.NoError(t, db.TestingMigrateToLatest(ctx))
+
+			now := time.Now().UTC().Truncate(time.Hour)
+			since, before := now.Add(-time.Hour), now
+			pdb := db.ProjectAccounting()
+
+			createBuckets := func(projectID uuid.UUID, count int) {
+				var rollups []orders.BucketBandwidthRollup
+				for i := 0; i < count; i++ {
+					bucketName := fmt.Sprintf("bucket%d", i)
+					err := pdb.CreateStorageTally(ctx, accounting.BucketStorageTally{
+						BucketName:    bucketName,
+						ProjectID:     projectID,
+						IntervalStart: since,
+						TotalBytes:    100,
+						ObjectCount:   1,
+					})
+					require.NoError(t, err)
+
+					rollups = append(rollups, orders.BucketBandwidthRollup{
+						ProjectID:  projectID,
+						BucketName: bucketName,
+						Action:     pb.PieceAction_GET,
+						Settled:    100,
+					})
+				}
+				require.NoError(t, pdb.CreateBandwidthRollups(ctx, rollups, since, 3600))
+			}
+
+			small, large := testrand.UUID(), testrand.UUID()
+			createBuckets(small, 2)
+			createBuckets(large, 4)
+
+			_, err = pdb.GetProjectTotal(ctx, small, since, before, 0)
+			require.NoError(t, err)
+			_, err = pdb.GetProjectTotal(ctx, large, since, before, 0)
+			require.True(t, accounting.ErrTooManyResults.Has(err), err)
+
+			rollups, err := pdb.GetBucketUsageRollups(ctx, small, since, before, 0)
+			require.NoError(t, err)
+			require.Len(t, rollups, 2)
+			_, err = pdb.GetBucketUsageRollups(ctx, large, since, before, 0)
+			require.True(t, accounting.ErrTooManyResults.Has(err), err)
+
+			// 6 rollups are stored in total.
+			_, err = pdb.GetRollupsSince(ctx, since)
+			require.True(t, accounting.ErrTooManyResults.Has(err), err)
+
+			t.Run("cancel iterating buckets", func(t *testing.T) {
+				cancelCtx, cancel := context.WithCancel(ctx)
+				defer cancel()
+
+				var calls int
+				err := pdb.IterateBucketUsageRollups(cancelCtx, large, since, before, 0, func(context.Context, accounting.BucketUsageRollup) error {
+					calls++
+					cancel()
+					return nil
+				})
+				require.True(t, errors.Is(err, context.Canceled), err)
+				require.Equal(t, 1, calls)
+			})
+
+			t.Run("cancel iterating rollups", func(t *testing.T) {
+				cancelCtx, cancel := context.WithCancel(ctx)
+				defer cancel()
+
+				// the rest of the page is processed, but the next page isn't read.
+				var calls int
+				err := pdb.ForEachRollupSince(cancelCtx, since, func(context.Context, orders.BucketBandwidthRollup) error {
+					calls++
+					cancel()
+					return nil
+				})
+				require.True(t, errors.Is(err, context.Canceled), err)
+				require.Equal(t, 2, calls)
+			})
+		})
+	}
+}
+
+func TestAccountingQueryShapeMetrics(t *testing.T) {
+	satellitedbtest.Run(t, func(ctx *testcontext.Context, t *testing.T, db satellite.DB) {
+		now := time.Now().UTC().Truncate(time.Hour)
+		since, before := now.Add(-time.Hour), now
+		projectID := testrand.UUID()
+		pdb := db.ProjectAccounting()
+
+		err := pdb.CreateStorageTally(ctx, accounting.BucketStorageTally{
+			BucketName:    "bucket",
+			ProjectID:     projectID,
+			IntervalStart: since,
+			TotalBytes:    100,
+		})
+		require.NoError(t, err)
+
+		_, err = pdb.GetProjectTotal(ctx, projectID, since, before, 0)
+		require.NoError(t, err)
+		_, err = pdb.GetBucketUsageRollups(ctx, projectID, since, before, 0)
+		require.NoError(t, err)
+		_, err = pdb.ArchiveRollupsBefore(ctx, since, 10)
+		require.NoError(t, err)
+
+		registered := make(map[string]bool)
+		monkit.Default.Stats(func(key monkit.SeriesKey, field string, val float64) {
+			if key.Tags.Get("scope") == "storj.io/storj/satellite/satellitedb" {
+				registered[key.Measurement] = true
+			}
+		})
+
+		// the names are locked in monkit.lock, renaming them breaks dashboards.
+		for _, name := range []string{
+			"project_total_buckets",
+			"project_total_tally_rows",
+			"project_total_rollup_rows",
+			"bucket_usage_rollups_buckets",
+			"bucket_usage_rollups_tally_rows",
+			"bucket_usage_rollups_rollup_rows",
+			"bucket_bandwidth_rollups_archived",
+		} {
+			require.True(t, registered[name], name)
+		}
+	})
+}
+
+func TestArchiveRollupsBeforeBatches(t *testing.T) {
+	for _, dbInfo := range satellitedbtest.Databases() {
+		dbInfo := dbInfo
+		t.Run(dbInfo.Name, func(t *testing.T) {
+			if dbInfo.MasterDB.URL == "" {
+				t.Skipf("Database %s connection string not provided. %s", dbInfo.MasterDB.Name, dbInfo.MasterDB.Message)
+			}
+
+			ctx := testcontext.New(t)
+			defer ctx.Cleanup()
+
+			tempDB, err := tempdb.OpenUnique(ctx, dbInfo.MasterDB.URL, "archivebatches")
+			require.NoError(t, err)
+			defer ctx.Check(tempDB.Close)
+
+			db, err := satellitedb.Open(ctx, zaptest.NewLogger(t), tempDB.ConnStr, satellitedb.Options{
+				ApplicationName: "satellite-accounting-test",
+			})
+			require.NoError(t, err)
+			defer ctx.Check(db.Close)
+			require.NoError(t, db.TestingMigrateToLatest(ctx))
+
+			const batchSize = 2
+			now := time.Now().UTC().Truncate(time.Hour)
+			projectID := testrand.UUID()
+			for i := 0; i < 3*batchSize; i++ {
+				interval := now.Add(-time.Duration(i+1) * time.Hour)
+				err := db.Orders().UpdateBucketBandwidthSettle(ctx, projectID, []byte("bucket"), pb.PieceAction_GET, 100, interval)
+				require.NoError(t, err)
+				err = db.Orders().UpdateStoragenodeBandwidthSettle(ctx, testrand.NodeID(), pb.PieceAction_GET, 100, interval)
+				require.NoError(t, err)
+			}
+			// rollups after the cutoff are kept.
+			err = db.Orders().UpdateBucketBandwidthSettle(ctx, projectID, []byte("bucket"), pb.PieceAction_GET, 100, now)
+			require.NoError(t, err)
+			err = db.Orders().UpdateStoragenodeBandwidthSettle(ctx, testrand.NodeID(), pb.PieceAction_GET, 100, now)
+			require.NoError(t, err)
+
+			archived, err := db.ProjectAccounting().ArchiveRollupsBefore(ctx, now.Add(-time.Minute), 0)
+			require.NoError(t, err)
+			require.Zero(t, archived)
+
+			archived, err = db.ProjectAccounting().ArchiveRollupsBefore(ctx, now.Add(-time.Minute), batchSize)
+			require.NoError(t, err)
+			require.Equal(t, 3*batchSize, archived)
+
+			archived, err = db.StoragenodeAccounting().ArchiveRollupsBefore(ctx, now.Add(-time.Minute), batchSize)
+			require.NoError(t, err)
+			require.Equal(t, 3*batchSize, archived)
+
+			// every batch is moved by a separate statement, hence in a separate transaction.
+			transaction := "xmin::text"
+			if dbInfo.Name == "Cockroach" {
+				transaction = "crdb_internal_mvcc_timestamp::text"
+			}
+			rawdb := db.(migrationTestingAccess).MigrationTestingDefaultDB().TestDBAccess()
+			for _, table := range []string{"bucket_bandwidth_rollup_archives", "storagenode_bandwidth_rollup_archives"} {
+				var transactions int
+				err = rawdb.QueryRowContext(ctx, `SELECT count(DISTINCT `+transaction+`) FROM `+table).Scan(&transactions)
+				require.NoError(t, err)
+				require.GreaterOrEqual(t, transactions, 3, table)
+			}
+
+			rollups, err := db.ProjectAccounting().GetRollupsSince(ctx, now.Add(-24*time.Hour))
+			require.NoError(t, err)
+			require.Len(t, rollups, 1)
+		})
+	}
+}
+
+func TestRestoreRollupsSince(t *testing.T) {
+	for _, dbInfo := range satellitedbtest.Databases() {
+		dbInfo := dbInfo
+		t.Run(dbInfo.Name, func(t *testing.T) {
+			if dbInfo.MasterDB.URL == "" {
+				t.Skipf("Database %s connection string not provided. %s", dbInfo.MasterDB.Name, dbInfo.MasterDB.Message)
+			}
+
+			ctx := testcontext.New(t)
+			defer ctx.Cleanup()
+
+			tempDB, err := tempdb.OpenUnique(ctx, dbInfo.MasterDB.URL, "restorerollups")
+			require.NoError(t, err)
+			defer ctx.Check(tempDB.Close)
+
+			// use small batches to restore in multiple statements.
+			db, err := satellitedb.Open(ctx, zaptest.NewLogger(t), tempDB.ConnStr, satellitedb.Options{
+				ApplicationName:     "satellite-accounting-test",
+				ReadRollupBatchSize: 2,
+			})
+			require.NoError(t, err)
+			defer ctx.Check(db.Close)
+			require.NoError(t, db.TestingMigrateToLatest(ctx))
+
+			now := time.Now().UTC().Truncate(time.Hour)
+			since := now.Add(-48 * time.Hour)
+			projectID := testrand.UUID()
+
+			for _, bucket := range []string{"a", "b", "c"} {
+				err := db.Orders().UpdateBucketBandwidthSettle(ctx, projectID, []byte(bucket), pb.PieceAction_GET, 100, now.Add(-24*time.Hour))
+				require.NoError(t, err)
+			}
+			// rollups before since stay archived.
+			err = db.Orders().UpdateBucketBandwidthSettle(ctx, projectID, []byte("old"), pb.PieceAction_GET, 100, since.Add(-time.Hour))
+			require.NoError(t, err)
+
+			archived, err := db.ProjectAccounting().ArchiveRollupsBefore(ctx, now, 100)
+			require.NoError(t, err)
+			require.Equal(t, 4, archived)
+
+			// bandwidth settled after archiving is added to the restored rollup.
+			err = db.Orders().UpdateBucketBandwidthSettle(ctx, projectID, []byte("a"), pb.PieceAction_GET, 50, now.Add(-24*time.Hour))
+			require.NoError(t, err)
+
+			restored, err := db.ProjectAccounting().RestoreRollupsSince(ctx, since)
+			require.NoError(t, err)
+			require.Equal(t, 3, restored)
+
+			rollups, err := db.ProjectAccounting().GetRollupsSince(ctx, since.Add(-24*time.Hour))
+			require.NoError(t, err)
+			settled := map[string]int64{}
+			for _, rollup := range rollups {
+				settled[rollup.BucketName] += rollup.Settled
+			}
+			require.Equal(t, map[string]int64{"a": 150, "b": 100, "c": 100}, settled)
+
+			archivedRollups, err := db.ProjectAccounting().GetArchivedRollupsSince(ctx, since.Add(-24*time.Hour))
+			require.NoError(t, err)
+			require.Len(t, archivedRollups, 1)
+			require.Equal(t, "old", archivedRollups[0].BucketName)
+
+			// restoring again doesn't count rollups twice.
+			restored, err = db.ProjectAccounting().RestoreRollupsSince(ctx, since)
+			require.NoError(t, err)
+			require.Zero(t, restored)
+
+			rollups, err = db.ProjectAccounting().GetRollupsSince(ctx, since.Add(-24*time.Hour))
+			require.NoError(t, err)
+			require.Len(t, rollups, 3)
+		})
+	}
+}
+
+func TestSaveTalliesBatches(t *testing.T) {
+	for _, dbInfo := range satellitedbtest.Databases() {
+		dbInfo := dbInfo
+		t.Run(dbInfo.Name, func(t *testing.T) {
+			if dbInfo.MasterDB.URL == "" {
+				t.Skipf("Database %s connection string not provided. %s", dbInfo.MasterDB.Name, dbInfo.MasterDB.Message)
+			}
+
+			ctx := testcontext.New(t)
+			defer ctx.Cleanup()
+
+			tempDB, err := tempdb.OpenUnique(ctx, dbInfo.MasterDB.URL, "savetalliesbatches")
+			require.NoError(t, err)
+			defer ctx.Check(tempDB.Close)
+
+			db, err := satellitedb.Open(ctx, zaptest.NewLogger(t), tempDB.ConnStr, satellitedb.Options{
+				ApplicationName:      "satellite-accounting-test",
+				SaveTalliesBatchSize: 3,
+			})
+			require.NoError(t, err)
+			defer ctx.Check(db.Close)
+			require.NoError(t, db.TestingMigrateToLatest(ctx))
+
+			projectID := testrand.UUID()
+			bucketTallies := make(map[metabase.BucketLocation]*accounting.BucketTally)
+			for i := 0; i < 10; i++ {
+				location := metabase.BucketLocation{ProjectID: projectID, BucketName: fmt.Sprintf("bucket%d", i)}
+				bucketTallies[location] = &accounting.BucketTally{
+					BucketLocation: location,
+					ObjectCount:    int64(i),
+					TotalSegments:  int64(i),
+					TotalBytes:     int64(i * 100),
+				}
+			}
+
+			_, _, err = db.ProjectAccounting().SaveTallies(ctx, time.Now(), bucketTallies)
+			require.NoError(t, err)
+
+			tallies, err := db.ProjectAccounting().GetTallies(ctx)
+			require.NoError(t, err)
+			require.Len(t, tallies, len(bucketTallies))
+			for _, tally := range tallies {
+				require.Equal(t, *bucketTallies[tally.BucketLocation], tally)
+			}
+		})
+	}
+}
+
+func TestDeleteProjectBandwidthBeforeBatches(t *testing.T) {
+	satellitedbtest.Run(t, func(ctx *testcontext.Context, t *testing.T, db satellite.DB) {
+		now := time.Now().UTC()
+		before := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+		projectID := testrand.UUID()
+		for day := 1; day <= 5; day++ {
+			interval := time.Date(now.Year(), now.Month()-1, day, 12, 0, 0, 0, time.UTC)
+			err := db.Orders().UpdateBucketBandwidthAllocation(ctx, projectID, []byte("bucket"), pb.PieceAction_GET, 100, interval)
+			require.NoError(t, err)
+		}
+		err := db.Orders().UpdateBucketBandwidthAllocation(ctx, projectID, []byte("bucket"), pb.PieceAction_GET, 100, before)
+		require.NoError(t, err)
+
+		deleted, err := db.ProjectAccounting().DeleteProjectBandwidthBefore(ctx, before, 2)
+		require.NoError(t, err)
+		require.EqualValues(t, 5, deleted)
+
+		// rollups from the current month are kept.
+		deleted, err = db.ProjectAccounting().DeleteProjectBandwidthBefore(ctx, before, 0)
+		require.NoError(t, err)
+		require.Zero(t, deleted)
+
+		allocated, _, err := db.ProjectAccounting().GetProjectDailyBandwidth(ctx, projectID, before.Year(), before.Month(), before.Day())
+		require.NoError(t, err)
+		require.EqualValues(t, 100, allocated)
+	})
+}
+
+func TestDeleteTalliesBefore(t *testing.T) {
+	satellitedbtest.Run(t, func(ctx *testcontext.Context, t *testing.T, db satellite.DB) {
+		now := time.Now().UTC().Truncate(time.Hour)
+		before := now.Add(-time.Hour)
+
+		projectID := testrand.UUID()
+		for i := 0; i < 5; i++ {
+			err := db.ProjectAccounting().CreateStorageTally(ctx, accounting.BucketStorageTally{
+				BucketName:    "bucket",
+				ProjectID:     projectID,
+				IntervalStart: before.Add(-time.Duration(i+1) * time.Hour),
+				TotalBytes:    100,
+			})
+			require.NoError(t, err)
+		}
+		for _, intervalStart := range []time.Time{before, now} {
+			err := db.ProjectAccounting().CreateStorageTally(ctx, accounting.BucketStorageTally{
+				BucketName:    "bucket",
+				ProjectID:     projectID,
+				IntervalStart: intervalStart,
+				TotalBytes:    200,
+			})
+			require.NoError(t, err)
+		}
+
+		deleted, err := db.ProjectAccounting().DeleteTalliesBefore(ctx, before, 2)
+		require.NoError(t, err)
+		require.EqualValues(t, 5, deleted)
+
+		deleted, err = db.ProjectAccounting().DeleteTalliesBefore(ctx, before, 0)
+		require.NoError(t, err)
+		require.Zero(t, deleted)
+
+		// tallies from the cutoff on are kept.
+		tallies, err := db.ProjectAccounting().GetTallies(ctx)
+		require.NoError(t, err)
+		require.Len(t, tallies, 2)
+		for _, tally := range tallies {
+			require.EqualValues(t, 200, tally.TotalBytes)
+		}
+	})
+}
+
+func TestDeleteProjectAccountingData(t *testing.T) {
+	satellitedbtest.Run(t, func(ctx *testcontext.Context, t *testing.T, db satellite.DB) {
+		now := time.Now().UTC().Truncate(time.Hour)
+		archiveBefore := now.Add(-36 * time.Hour)
+
+		deleted, other := testrand.UUID(), testrand.UUID()
+		for _, projectID := range []uuid.UUID{deleted, other} {
+			for _, interval := range []time.Time{now.Add(-48 * time.Hour), now.Add(-24 * time.Hour), now} {
+				err := db.ProjectAccounting().CreateStorageTally(ctx, accounting.BucketStorageTally{
+					BucketName:    "bucket",
+					ProjectID:     projectID,
+					IntervalStart: interval,
+					TotalBytes:    100,
+				})
+				require.NoError(t, err)
+				err = db.Orders().UpdateBucketBandwidthAllocation(ctx, projectID, []byte("bucket"), pb.PieceAction_GET, 100, interval)
+				require.NoError(t, err)
+			}
+		}
+
+		archived, err := db.ProjectAccounting().ArchiveStorageTalliesBefore(ctx, archiveBefore, 10)
+		require.NoError(t, err)
+		require.Equal(t, 2, archived)
+		archived, err = db.ProjectAccounting().ArchiveRollupsBefore(ctx, archiveBefore, 10)
+		require.NoError(t, err)
+		require.Equal(t, 2, archived)
+
+		deletions, err := db.ProjectAccounting().DeleteProjectAccountingData(ctx, deleted, 1)
+		require.NoError(t, err)
+		require.Equal(t, accounting.ProjectAccountingDeletions{
+			StorageTallies:          2,
+			StorageTallyArchives:    1,
+			BandwidthRollups:        2,
+			BandwidthRollupArchives: 1,
+			DailyBandwidthRollups:   3,
+		}, deletions)
+
+		// deleting again is a no-op.
+		deletions, err = db.ProjectAccounting().DeleteProjectAccountingData(ctx, deleted, 0)
+		require.NoError(t, err)
+		require.Zero(t, deletions)
+
+		// the other project is untouched.
+		tallies, err := db.ProjectAccounting().GetTallies(ctx)
+		require.NoError(t, err)
+		require.Len(t, tallies, 2)
+		for _, tally := range tallies {
+			require.Equal(t, other, tally.ProjectID)
+		}
+
+		archivedTallies, err := db.ProjectAccounting().GetArchivedTalliesSince(ctx, time.Time{})
+		require.NoError(t, err)
+		require.Len(t, archivedTallies, 1)
+		require.Equal(t, other, archivedTallies[0].ProjectID)
+
+		rollups, err := db.ProjectAccounting().GetRollupsSince(ctx, time.Time{})
+		require.NoError(t, err)
+		require.Len(t, rollups, 2)
+		for _, rollup := range rollups {
+			require.Equal(t, other, rollup.ProjectID)
+		}
+
+		archivedRollups, err := db.ProjectAccounting().GetArchivedRollupsSince(ctx, time.Time{})
+		require.NoError(t, err)
+		require.Len(t, archivedRollups, 1)
+		require.Equal(t, other, archivedRollups[0].ProjectID)
+
+		for _, projectID := range []uuid.UUID{deleted, other} {
+			allocated, _, err := db.ProjectAccounting().GetProjectDailyBandwidth(ctx, projectID, now.Year(), now.Month(), now.Day())
+			require.NoError(t, err)
+			if projectID == deleted {
+				require.Zero(t, allocated)
+			} else {
+				require.EqualValues(t, 100, allocated)
+			}
+		}
+	})
+}
+
+func TestVerifyProjectBandwidthConsistency(t *testing.T) {
+	for _, dbInfo := range satellitedbtest.Databases() {
+		dbInfo := dbInfo
+		t.Run(dbInfo.Name, func(t *testing.T) {
+			if dbInfo.MasterDB.URL == "" {
+				t.Skipf("Database %s connection string not provided. %s", dbInfo.MasterDB.Name, dbInfo.MasterDB.Message)
+			}
+
+			ctx := testcontext.New(t)
+			defer ctx.Cleanup()
+
+			tempDB, err := tempdb.OpenUnique(ctx, dbInfo.MasterDB.URL, "bandwidthconsistency")
+			require.NoError(t, err)
+			defer ctx.Check(tempDB.Close)
+
+			db, err := satellitedb.Open(ctx, zaptest.NewLogger(t), tempDB.ConnStr, satellitedb.Options{
+				ApplicationName: "satellite-accounting-test",
+			})
+			require.NoError(t, err)
+			defer ctx.Check(db.Close)
+			require.NoError(t, db.TestingMigrateToLatest(ctx))
+
+			// allocations of the previous month have expired, hence settled amounts are compared.
+			now := time.Now().UTC()
+			month := time.Date(now.Year(), now.Month()-1, 1, 0, 0, 0, 0, time.UTC)
+			day := func(n int) time.Time { return month.AddDate(0, 0, n-1) }
+
+			projectID := testrand.UUID()
+			for _, n := range []int{3, 5, 7} {
+				err := db.Orders().UpdateBucketBandwidthAllocation(ctx, projectID, []byte("bucket"), pb.PieceAction_GET, 1000, day(n).Add(12*time.Hour))
+				require.NoError(t, err)
+				err = db.Orders().UpdateBucketBandwidthSettle(ctx, projectID, []byte("bucket"), pb.PieceAction_GET, 800, day(n).Add(12*time.Hour))
+				require.NoError(t, err)
+			}
+
+			inconsistencies, err := db.ProjectAccounting().VerifyProjectBandwidthConsistency(ctx, projectID, month.Year(), month.Month(), 0)
+			require.NoError(t, err)
+			require.Empty(t, inconsistencies)
+
+			// we need raw database access to skew the rollups.
+			rawdb := db.(migrationTestingAccess).MigrationTestingDefaultDB().TestDBAccess()
+			for n, skew := range map[int]int64{5: 300, 7: 50} {
+				_, err = rawdb.ExecContext(ctx, `
+					UPDATE project_bandwidth_daily_rollups SET egress_settled = egress_settled + $1
+					WHERE project_id = $2 AND interval_day = $3`,
+					skew, projectID[:], day(n))
+				require.NoError(t, err)
+			}
+			_, err = rawdb.ExecContext(ctx, `
+				INSERT INTO bucket_bandwidth_rollup_archives (
+					bucket_name, project_id, interval_start, interval_seconds,
+					action, inline, allocated, settled)
+				VALUES ($1, $2, $3, 3600, $4, 0, 500, 500)`,
+				[]byte("bucket"), projectID[:], day(9).Add(time.Hour), int32(pb.PieceAction_GET))
+			require.NoError(t, err)
+
+			inconsistencies, err = db.ProjectAccounting().VerifyProjectBandwidthConsistency(ctx, projectID, month.Year(), month.Month(), 100)
+			require.NoError(t, err)
+			require.Equal(t, []accounting.BandwidthInconsistency{
+				{Day: day(5), DailyRollup: 1100, BucketRollups: 800},
+				{Day: day(9), DailyRollup: 0, BucketRollups: 500},
+			}, inconsistencies)
+
+			// other projects aren't affected.
+			inconsistencies, err = db.ProjectAccounting().VerifyProjectBandwidthConsistency(ctx, testrand.UUID(), month.Year(), month.Month(), 0)
+			require.NoError(t, err)
+			require.Empty(t, inconsistencies)
+		})
+	}
+}
+
+func TestProjectUsageIncludesArchivedRollups(t *testing.T) {
+	satellitedbtest.Run(t, func(ctx *testcontext.Context, t *testing.T, db satellite.DB) {
+		now := time.Now().UTC().Truncate(time.Hour)
+		since := now.Add(-72 * time.Hour)
+		projectID := testrand.UUID()
+
+		err := db.ProjectAccounting().CreateStorageTally(ctx, accounting.BucketStorageTally{
+			BucketName:    "bucket",
+			ProjectID:     projectID,
+			IntervalStart: since,
+			ObjectCount:   1,
+			TotalBytes:    1000,
+		})
+		require.NoError(t, err)
+
+		for _, interval := range []time.Time{now.Add(-48 * time.Hour), now.Add(-time.Hour)} {
+			err := db.Orders().UpdateBucketBandwidthSettle(ctx, projectID, []byte("bucket"), pb.PieceAction_GET, 1000, interval)
+			require.NoError(t, err)
+			err = db.Orders().UpdateBucketBandwidthSettle(ctx, projectID, []byte("bucket"), pb.PieceAction_GET_REPAIR, 100, interval)
+			require.NoError(t, err)
+		}
+
+		totalBefore, err := db.ProjectAccounting().GetProjectTotal(ctx, projectID, since, now, 0)
+		require.NoError(t, err)
+		require.EqualValues(t, 2000, totalBefore.Egress)
+		rollupsBefore, err := db.ProjectAccounting().GetBucketUsageRollups(ctx, projectID, since, now, 0)
+		require.NoError(t, err)
+		require.Len(t, rollupsBefore, 1)
+
+		archived, err := db.ProjectAccounting().ArchiveRollupsBefore(ctx, now.Add(-24*time.Hour), 100)
+		require.NoError(t, err)
+		require.Equal(t, 2, archived)
+
+		// bandwidth settled for an already archived interval is counted too.
+		err = db.Orders().UpdateBucketBandwidthSettle(ctx, projectID, []byte("bucket"), pb.PieceAction_GET, 500, now.Add(-48*time.Hour))
+		require.NoError(t, err)
+
+		totalAfter, err := db.ProjectAccounting().GetProjectTotal(ctx, projectID, since, now, 0)
+		require.NoError(t, err)
+		require.EqualValues(t, 2500, totalAfter.Egress)
+
+		rollupsAfter, err := db.ProjectAccounting().GetBucketUsageRollups(ctx, projectID, since, now, 0)
+		require.NoError(t, err)
+		require.Len(t, rollupsAfter, 1)
+		require.InDelta(t, rollupsBefore[0].GetEgress+memory.Size(500).GB(), rollupsAfter[0].GetEgress, 1e-12)
+		require.InDelta(t, rollupsBefore[0].RepairEgress, rollupsAfter[0].RepairEgress, 1e-12)
+	})
+}
+
+// createBucketsWithUsage creates buckets with a storage tally and a GET rollup each for the GetBucketTotals tests.
+func createBucketsWithUsage(ctx context.Context, t require.TestingT, db satellite.DB, projectID uuid.UUID, count int, interval time.Time) {
+	for i := 0; i < count; i++ {
+		name := fmt.Sprintf("bucket%03d", i)
+		_, err := db.Buckets().CreateBucket(ctx, storj.Bucket{
+			ID:        testrand.UUID(),
+			Name:      name,
+			ProjectID: projectID,
+		})
+		require.NoError(t, err)
+
+		err = db.ProjectAccounting().CreateStorageTally(ctx, accounting.BucketStorageTally{
+			BucketName:    name,
+			ProjectID:     projectID,
+			IntervalStart: interval,
+			ObjectCount:   int64(i),
+			TotalBytes:    int64(i) * memory.GB.Int64(),
+		})
+		require.NoError(t, err)
+
+		err = db.Orders().UpdateBucketBandwidthSettle(ctx, projectID, []byte(name), pb.PieceAction_GET, int64(i)*memory.GB.Int64(), interval)
+		require.NoError(t, err)
+	}
+}
+
+func TestGetBucketTotalsParallelism(t *testing.T) {
+	for _, dbInfo := range satellitedbtest.Databases() {
+		dbInfo := dbInfo
+		t.Run(dbInfo.Name, func(t *testing.T) {
+			if dbInfo.MasterDB.URL == "" {
+				t.Skipf("Database %s connection string not provided. %s", dbInfo.MasterDB.Name, dbInfo.MasterDB.Message)
+			}
+
+			ctx := testcontext.New(t)
+			defer ctx.Cleanup()
+
+			tempDB, err := tempdb.OpenUnique(ctx, dbInfo.MasterDB.URL, "buckettotals")
+			require.NoError(t, err)
+			defer ctx.Check(tempDB.Close)
+
+			open := func(parallelism int) satellite.DB {
+				db, err := satellitedb.Open(ctx, zaptest.NewLogger(t), tempDB.ConnStr, satellitedb.Options{
+					ApplicationName:         "satellite-accounting-test",
+					BucketTotalsParallelism: parallelism,
+				})
+				require.NoError(t, err)
+				return db
+			}
+			serialDB, parallelDB := open(1), open(3)
+			defer ctx.Check(serialDB.Close)
+			defer ctx.Check(parallelDB.Close)
+			require.NoError(t, serialDB.TestingMigrateToLatest(ctx))
+
+			now := time.Now().UTC()
+			projectID := testrand.UUID()
+			createBucketsWithUsage(ctx, t, serialDB, projectID, 10, now.Add(-time.Hour))
+
+			cursor := accounting.BucketUsageCursor{Limit: 50, Page: 1}
+			since, before := now.Add(-2*time.Hour), now
+
+			serial, err := serialDB.ProjectAccounting().GetBucketTotals(ctx, projectID, cursor, since, before, 0)
+			require.NoError(t, err)
+			require.Len(t, serial.BucketUsages, 10)
+
+			parallel, err := parallelDB.ProjectAccounting().GetBucketTotals(ctx, projectID, cursor, since, before, 0)
+			require.NoError(t, err)
+			require.Equal(t, serial, parallel)
+			for i, usage := range parallel.BucketUsages {
+				require.Equal(t, fmt.Sprintf("bucket%03d", i), usage.BucketName)
+				require.EqualValues(t, i, usage.ObjectCount)
+			}
+
+			canceledCtx, cancel := context.WithCancel(ctx)
+			cancel()
+			_, err = parallelDB.ProjectAccounting().GetBucketTotals(canceledCtx, projectID, cursor, since, before, 0)
+			require.Error(t, err)
+		})
+	}
+}
+
+// BenchmarkGetBucketTotals compares fetching the usage of a full page of buckets
+// sequentially and concurrently. The difference shows with a remote database,
+// where every query pays a network round trip.
+func BenchmarkGetBucketTotals(b *testing.B) {
+	for _, dbInfo := range satellitedbtest.Databases() {
+		dbInfo := dbInfo
+		b.Run(dbInfo.Name, func(b *testing.B) {
+			if dbInfo.MasterDB.URL == "" {
+				b.Skipf("Database %s connection string not provided. %s", dbInfo.MasterDB.Name, dbInfo.MasterDB.Message)
+			}
+
+			ctx := testcontext.New(b)
+			defer ctx.Cleanup()
+
+			tempDB, err := tempdb.OpenUnique(ctx, dbInfo.MasterDB.URL, "buckettotals")
+			require.NoError(b, err)
+			defer ctx.Check(tempDB.Close)
+
+			now := time.Now().UTC()
+			projectID := testrand.UUID()
+			cursor := accounting.BucketUsageCursor{Limit: 50, Page: 1}
+
+			for i, parallelism := range []int{1, 4, 8} {
+				db, err := satellitedb.Open(ctx, zaptest.NewLogger(b), tempDB.ConnStr, satellitedb.Options{
+					ApplicationName:         "satellite-accounting-bench",
+					BucketTotalsParallelism: parallelism,
+				})
+				require.NoError(b, err)
+				defer ctx.Check(db.Close)
+
+				if i == 0 {
+					require.NoError(b, db.TestingMigrateToLatest(ctx))
+					createBucketsWithUsage(ctx, b, db, projectID, int(cursor.Limit), now.Add(-time.Hour))
+				}
+
+				b.Run(fmt.Sprintf("parallelism=%d", parallelism), func(b *testing.B) {
+					for k := 0; k < b.N; k++ {
+						_, err := db.ProjectAccounting().GetBucketTotals(ctx, projectID, cursor, now.Add(-2*time.Hour), now, 0)
+						require.NoError(b, err)
+					}
+				})
+			}
+		})
+	}
+}
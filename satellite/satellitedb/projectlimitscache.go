@@ -0,0 +1,240 @@
+// Copyright (C) 2021 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package satellitedb
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/zeebo/errs"
+
+	"storj.io/common/memory"
+	"storj.io/common/uuid"
+	"storj.io/storj/private/lrucache"
+	"storj.io/storj/satellite/accounting"
+)
+
+// ensures that projectLimitsCache implements accounting.ProjectAccounting.
+var _ accounting.ProjectAccounting = (*projectLimitsCache)(nil)
+
+// projectLimitsCache is a read-through cache of project limits in front of ProjectAccounting.
+//
+// Limits are invalidated when they are updated through the cache, limits updated in any
+// other way are served stale until the cache entry expires.
+type projectLimitsCache struct {
+	// generation is incremented whenever limits are invalidated, so that limits which were
+	// read from the database concurrently with an update aren't kept in the cache.
+	generation uint64
+
+	accounting.ProjectAccounting
+	lru *lrucache.ExpiringLRU
+}
+
+// errProjectLimitsNotCached is used to look up limits without reading them from the database.
+var errProjectLimitsNotCached = errs.Class("project limits not cached")
+
+// GetProjectLimits returns current project limit for both storage and bandwidth.
+func (cache *projectLimitsCache) GetProjectLimits(ctx context.Context, projectID uuid.UUID) (_ accounting.ProjectLimits, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	return cache.get(projectID, atomic.LoadUint64(&cache.generation), func() (accounting.ProjectLimits, error) {
+		return cache.ProjectAccounting.GetProjectLimits(ctx, projectID)
+	})
+}
+
+// GetProjectLimitsBatch returns current storage and bandwidth limits of all given projects.
+// Only the limits which aren't cached are read from the database.
+func (cache *projectLimitsCache) GetProjectLimitsBatch(ctx context.Context, projectIDs []uuid.UUID) (_ map[uuid.UUID]accounting.ProjectLimits, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	limits := make(map[uuid.UUID]accounting.ProjectLimits, len(projectIDs))
+
+	var missing []uuid.UUID
+	for _, projectID := range projectIDs {
+		projectLimits, err := cache.get(projectID, atomic.LoadUint64(&cache.generation), func() (accounting.ProjectLimits, error) {
+			return accounting.ProjectLimits{}, errProjectLimitsNotCached.New("%s", projectID)
+		})
+		if err != nil {
+			if errProjectLimitsNotCached.Has(err) {
+				missing = append(missing, projectID)
+				continue
+			}
+			return nil, err
+		}
+		limits[projectID] = projectLimits
+	}
+	if len(missing) == 0 {
+		return limits, nil
+	}
+
+	generation := atomic.LoadUint64(&cache.generation)
+	loaded, err := cache.ProjectAccounting.GetProjectLimitsBatch(ctx, missing)
+	if err != nil {
+		return nil, err
+	}
+	for projectID, projectLimits := range loaded {
+		projectLimits := projectLimits
+		limits[projectID], err = cache.get(projectID, generation, func() (accounting.ProjectLimits, error) {
+			return projectLimits, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return limits, nil
+}
+
+// GetEffectiveProjectLimits returns current project limits for both storage and bandwidth,
+// using the given defaults for limits which aren't set.
+func (cache *projectLimitsCache) GetEffectiveProjectLimits(ctx context.Context, projectID uuid.UUID, defaults accounting.ProjectLimits) (_ accounting.EffectiveProjectLimits, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	if defaults.Usage == nil || defaults.Bandwidth == nil {
+		return accounting.EffectiveProjectLimits{}, Error.New("default limits must be set")
+	}
+
+	limits, err := cache.GetProjectLimits(ctx, projectID)
+	if err != nil {
+		return accounting.EffectiveProjectLimits{}, err
+	}
+
+	return effectiveProjectLimits(limits, defaults), nil
+}
+
+// GetProjectBandwidthUsageAndLimit returns project bandwidth usage the same way as GetProjectBandwidth
+// together with the cached project bandwidth limit, nil limit means the default limit is used.
+func (cache *projectLimitsCache) GetProjectBandwidthUsageAndLimit(ctx context.Context, projectID uuid.UUID, year int, month time.Month, day int, asOfSystemInterval time.Duration) (used int64, limit *int64, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	limit, err = cache.GetProjectBandwidthLimit(ctx, projectID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	used, err = cache.ProjectAccounting.GetProjectBandwidth(ctx, projectID, year, month, day, asOfSystemInterval)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return used, limit, nil
+}
+
+// get returns the cached limits of the project, calling load when they aren't cached.
+// Loaded limits are dropped from the cache when limits were invalidated since generation.
+func (cache *projectLimitsCache) get(projectID uuid.UUID, generation uint64, load func() (accounting.ProjectLimits, error)) (accounting.ProjectLimits, error) {
+	key := projectID.String()
+
+	loaded := false
+	value, err := cache.lru.Get(key, func() (interface{}, error) {
+		loaded = true
+		return load()
+	})
+	if err != nil {
+		return accounting.ProjectLimits{}, err
+	}
+	if loaded && atomic.LoadUint64(&cache.generation) != generation {
+		cache.lru.Delete(key)
+	}
+
+	limits, ok := value.(accounting.ProjectLimits)
+	if !ok {
+		return accounting.ProjectLimits{}, errs.New("cached project limits have unexpected type %T", value)
+	}
+
+	// the cached limits are shared, hence callers get their own copy.
+	return accounting.ProjectLimits{
+		Usage:     copyLimit(limits.Usage),
+		Bandwidth: copyLimit(limits.Bandwidth),
+	}, nil
+}
+
+// invalidate removes limits of the projects from the cache, it must be called
+// after the limits were updated in the database.
+func (cache *projectLimitsCache) invalidate(projectIDs ...uuid.UUID) {
+	atomic.AddUint64(&cache.generation, 1)
+	for _, projectID := range projectIDs {
+		cache.lru.Delete(projectID.String())
+	}
+}
+
+// GetProjectStorageLimit returns project storage usage limit.
+func (cache *projectLimitsCache) GetProjectStorageLimit(ctx context.Context, projectID uuid.UUID) (_ *int64, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	limits, err := cache.GetProjectLimits(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	return limits.Usage, nil
+}
+
+// GetProjectBandwidthLimit returns project bandwidth usage limit.
+func (cache *projectLimitsCache) GetProjectBandwidthLimit(ctx context.Context, projectID uuid.UUID) (_ *int64, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	limits, err := cache.GetProjectLimits(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	return limits.Bandwidth, nil
+}
+
+// UpdateProjectUsageLimit updates project usage limit.
+func (cache *projectLimitsCache) UpdateProjectUsageLimit(ctx context.Context, projectID uuid.UUID, limit memory.Size) (err error) {
+	defer mon.Task()(&ctx)(&err)
+	defer cache.invalidate(projectID)
+
+	return cache.ProjectAccounting.UpdateProjectUsageLimit(ctx, projectID, limit)
+}
+
+// UpdateProjectBandwidthLimit updates project bandwidth limit.
+func (cache *projectLimitsCache) UpdateProjectBandwidthLimit(ctx context.Context, projectID uuid.UUID, limit memory.Size) (err error) {
+	defer mon.Task()(&ctx)(&err)
+	defer cache.invalidate(projectID)
+
+	return cache.ProjectAccounting.UpdateProjectBandwidthLimit(ctx, projectID, limit)
+}
+
+// ClearProjectUsageLimit clears project usage limit.
+func (cache *projectLimitsCache) ClearProjectUsageLimit(ctx context.Context, projectID uuid.UUID) (err error) {
+	defer mon.Task()(&ctx)(&err)
+	defer cache.invalidate(projectID)
+
+	return cache.ProjectAccounting.ClearProjectUsageLimit(ctx, projectID)
+}
+
+// ClearProjectBandwidthLimit clears project bandwidth limit.
+func (cache *projectLimitsCache) ClearProjectBandwidthLimit(ctx context.Context, projectID uuid.UUID) (err error) {
+	defer mon.Task()(&ctx)(&err)
+	defer cache.invalidate(projectID)
+
+	return cache.ProjectAccounting.ClearProjectBandwidthLimit(ctx, projectID)
+}
+
+// UpdateProjectLimits updates project storage and bandwidth limits at once.
+func (cache *projectLimitsCache) UpdateProjectLimits(ctx context.Context, projectID uuid.UUID, limits accounting.ProjectLimits) (err error) {
+	defer mon.Task()(&ctx)(&err)
+	defer cache.invalidate(projectID)
+
+	return cache.ProjectAccounting.UpdateProjectLimits(ctx, projectID, limits)
+}
+
+// UpdateProjectLimitsBulk updates storage and bandwidth limits of all given projects at once.
+func (cache *projectLimitsCache) UpdateProjectLimitsBulk(ctx context.Context, projectIDs []uuid.UUID, limits accounting.ProjectLimits) (updated int64, err error) {
+	defer mon.Task()(&ctx)(&err)
+	defer cache.invalidate(projectIDs...)
+
+	return cache.ProjectAccounting.UpdateProjectLimitsBulk(ctx, projectIDs, limits)
+}
+
+// copyLimit returns a copy of limit, so that the cached value can't be modified.
+func copyLimit(limit *int64) *int64 {
+	if limit == nil {
+		return nil
+	}
+	value := *limit
+	return &value
+}
@@ -0,0 +1,89 @@
+// Copyright (C) 2021 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package satellitedb
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"storj.io/common/memory"
+	"storj.io/common/testcontext"
+	"storj.io/common/testrand"
+	"storj.io/common/uuid"
+	"storj.io/storj/private/lrucache"
+	"storj.io/storj/satellite/accounting"
+)
+
+// blockingLimitsDB returns the usage limit it has read only after unblock is closed.
+type blockingLimitsDB struct {
+	accounting.ProjectAccounting
+
+	mu      sync.Mutex
+	usage   int64
+	read    chan struct{}
+	unblock chan struct{}
+}
+
+func (db *blockingLimitsDB) GetProjectLimitsBatch(ctx context.Context, projectIDs []uuid.UUID) (map[uuid.UUID]accounting.ProjectLimits, error) {
+	db.mu.Lock()
+	usage := db.usage
+	read, unblock := db.read, db.unblock
+	db.mu.Unlock()
+
+	if read != nil {
+		close(read)
+		<-unblock
+	}
+	limits := make(map[uuid.UUID]accounting.ProjectLimits, len(projectIDs))
+	for _, projectID := range projectIDs {
+		limits[projectID] = accounting.ProjectLimits{Usage: &usage}
+	}
+	return limits, nil
+}
+
+func (db *blockingLimitsDB) UpdateProjectUsageLimit(ctx context.Context, projectID uuid.UUID, limit memory.Size) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.usage = limit.Int64()
+	return nil
+}
+
+func TestProjectLimitsCacheConcurrentUpdate(t *testing.T) {
+	ctx := testcontext.New(t)
+	defer ctx.Cleanup()
+
+	db := &blockingLimitsDB{
+		usage:   memory.GB.Int64(),
+		read:    make(chan struct{}),
+		unblock: make(chan struct{}),
+	}
+	cache := &projectLimitsCache{
+		ProjectAccounting: db,
+		lru:               lrucache.New(lrucache.Options{Capacity: 10}),
+	}
+	projectID := testrand.UUID()
+
+	// the limits are read before the update, but stored in the cache after it.
+	read, unblock := db.read, db.unblock
+	ctx.Go(func() error {
+		_, err := cache.GetProjectLimitsBatch(ctx, []uuid.UUID{projectID})
+		return err
+	})
+	<-read
+
+	db.mu.Lock()
+	db.read, db.unblock = nil, nil
+	db.mu.Unlock()
+
+	require.NoError(t, cache.UpdateProjectUsageLimit(ctx, projectID, 2*memory.GB))
+	close(unblock)
+	ctx.Wait()
+
+	limits, err := cache.GetProjectLimitsBatch(ctx, []uuid.UUID{projectID})
+	require.NoError(t, err)
+	require.EqualValues(t, 2*memory.GB, *limits[projectID].Usage)
+}
@@ -0,0 +1,167 @@
+// Copyright (C) 2021 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package satellitedb_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"storj.io/common/memory"
+	"storj.io/common/testcontext"
+	"storj.io/common/testrand"
+	"storj.io/common/uuid"
+	"storj.io/private/dbutil/tempdb"
+	"storj.io/storj/private/lrucache"
+	"storj.io/storj/satellite/accounting"
+	"storj.io/storj/satellite/console"
+	"storj.io/storj/satellite/satellitedb"
+	"storj.io/storj/satellite/satellitedb/satellitedbtest"
+)
+
+func TestProjectLimitsCache(t *testing.T) {
+	for _, dbInfo := range satellitedbtest.Databases() {
+		dbInfo := dbInfo
+		t.Run(dbInfo.Name, func(t *testing.T) {
+			if dbInfo.MasterDB.URL == "" {
+				t.Skipf("Database %s connection string not provided. %s", dbInfo.MasterDB.Name, dbInfo.MasterDB.Message)
+			}
+
+			ctx := testcontext.New(t)
+			defer ctx.Cleanup()
+
+			tempDB, err := tempdb.OpenUnique(ctx, dbInfo.MasterDB.URL, "projectlimitscache")
+			require.NoError(t, err)
+			defer ctx.Check(tempDB.Close)
+
+			options := func(expiration time.Duration) satellitedb.Options {
+				return satellitedb.Options{
+					ApplicationName: "satellite-accounting-test",
+					ProjectLimitsLRUOptions: lrucache.Options{
+						Expiration: expiration,
+						Capacity:   10,
+					},
+				}
+			}
+
+			db, err := satellitedb.Open(ctx, zaptest.NewLogger(t), tempDB.ConnStr, options(time.Hour))
+			require.NoError(t, err)
+			defer ctx.Check(db.Close)
+			require.NoError(t, db.TestingMigrateToLatest(ctx))
+
+			shortDB, err := satellitedb.Open(ctx, zaptest.NewLogger(t), tempDB.ConnStr, options(100*time.Millisecond))
+			require.NoError(t, err)
+			defer ctx.Check(shortDB.Close)
+
+			project, err := db.Console().Projects().Insert(ctx, &console.Project{Name: "test", OwnerID: testrand.UUID()})
+			require.NoError(t, err)
+
+			// we need raw database access to change the limits bypassing the cache.
+			rawdb := db.(migrationTestingAccess).MigrationTestingDefaultDB().TestDBAccess()
+			setUsageLimit := func(limit int64) {
+				_, err := rawdb.ExecContext(ctx, rawdb.Rebind(`UPDATE projects SET usage_limit = ? WHERE id = ?`), limit, project.ID[:])
+				require.NoError(t, err)
+			}
+
+			pdb := db.ProjectAccounting()
+			require.NoError(t, pdb.UpdateProjectUsageLimit(ctx, project.ID, memory.GB))
+
+			limit, err := pdb.GetProjectStorageLimit(ctx, project.ID)
+			require.NoError(t, err)
+			require.EqualValues(t, memory.GB, *limit)
+
+			// the cached limit isn't affected by changes bypassing the cache
+			// nor by changes of the returned value.
+			setUsageLimit(2 * memory.GB.Int64())
+			*limit = 0
+			limits, err := pdb.GetProjectLimits(ctx, project.ID)
+			require.NoError(t, err)
+			require.EqualValues(t, memory.GB, *limits.Usage)
+
+			// all limits reads go through the cache.
+			batch, err := pdb.GetProjectLimitsBatch(ctx, []uuid.UUID{project.ID, testrand.UUID()})
+			require.NoError(t, err)
+			require.Len(t, batch, 1)
+			require.EqualValues(t, memory.GB, *batch[project.ID].Usage)
+
+			effective, err := pdb.GetEffectiveProjectLimits(ctx, project.ID, accounting.ProjectLimits{
+				Usage:     &[]int64{10 * memory.GB.Int64()}[0],
+				Bandwidth: &[]int64{20 * memory.GB.Int64()}[0],
+			})
+			require.NoError(t, err)
+			require.Equal(t, accounting.EffectiveProjectLimits{
+				Usage:            memory.GB.Int64(),
+				Bandwidth:        20 * memory.GB.Int64(),
+				BandwidthDefault: true,
+			}, effective)
+
+			now := time.Now()
+			used, bandwidthLimit, err := pdb.GetProjectBandwidthUsageAndLimit(ctx, project.ID, now.Year(), now.Month(), now.Day(), 0)
+			require.NoError(t, err)
+			require.Zero(t, used)
+			require.Nil(t, bandwidthLimit)
+
+			// updates through any ProjectAccounting of the database invalidate the limits.
+			require.NoError(t, db.ProjectAccounting().UpdateProjectLimits(ctx, project.ID, accounting.ProjectLimits{
+				Bandwidth: &[]int64{3 * memory.GB.Int64()}[0],
+			}))
+			limits, err = pdb.GetProjectLimits(ctx, project.ID)
+			require.NoError(t, err)
+			require.EqualValues(t, 2*memory.GB, *limits.Usage)
+			require.EqualValues(t, 3*memory.GB, *limits.Bandwidth)
+
+			bandwidth, err := pdb.GetProjectBandwidthLimit(ctx, project.ID)
+			require.NoError(t, err)
+			require.EqualValues(t, 3*memory.GB, *bandwidth)
+
+			// limits read by batch are cached as well.
+			otherProject, err := db.Console().Projects().Insert(ctx, &console.Project{Name: "other", OwnerID: testrand.UUID()})
+			require.NoError(t, err)
+			batch, err = pdb.GetProjectLimitsBatch(ctx, []uuid.UUID{project.ID, otherProject.ID})
+			require.NoError(t, err)
+			require.Len(t, batch, 2)
+			require.Nil(t, batch[otherProject.ID].Usage)
+
+			_, err = rawdb.ExecContext(ctx, rawdb.Rebind(`UPDATE projects SET usage_limit = ? WHERE id = ?`), memory.GB.Int64(), otherProject.ID[:])
+			require.NoError(t, err)
+			limit, err = pdb.GetProjectStorageLimit(ctx, otherProject.ID)
+			require.NoError(t, err)
+			require.Nil(t, limit)
+
+			_, err = pdb.UpdateProjectLimitsBulk(ctx, []uuid.UUID{project.ID}, accounting.ProjectLimits{
+				Bandwidth: &[]int64{4 * memory.GB.Int64()}[0],
+			})
+			require.NoError(t, err)
+			bandwidth, err = pdb.GetProjectBandwidthLimit(ctx, project.ID)
+			require.NoError(t, err)
+			require.EqualValues(t, 4*memory.GB, *bandwidth)
+
+			// cleared limits are read again.
+			require.NoError(t, pdb.ClearProjectBandwidthLimit(ctx, project.ID))
+			bandwidth, err = pdb.GetProjectBandwidthLimit(ctx, project.ID)
+			require.NoError(t, err)
+			require.Nil(t, bandwidth)
+
+			require.NoError(t, pdb.ClearProjectUsageLimit(ctx, project.ID))
+			limit, err = pdb.GetProjectStorageLimit(ctx, project.ID)
+			require.NoError(t, err)
+			require.Nil(t, limit)
+
+			// expired limits are read again.
+			setUsageLimit(2 * memory.GB.Int64())
+			shortPDB := shortDB.ProjectAccounting()
+			limit, err = shortPDB.GetProjectStorageLimit(ctx, project.ID)
+			require.NoError(t, err)
+			require.EqualValues(t, 2*memory.GB, *limit)
+
+			setUsageLimit(5 * memory.GB.Int64())
+			require.Eventually(t, func() bool {
+				limit, err := shortPDB.GetProjectStorageLimit(ctx, project.ID)
+				return err == nil && *limit == 5*memory.GB.Int64()
+			}, 5*time.Second, 20*time.Millisecond)
+		})
+	}
+}
@@ -7,7 +7,9 @@ package satellitedbtest
 
 import (
 	"context"
+	"crypto/rand"
 	"fmt"
+	"os"
 	"strconv"
 	"strings"
 	"testing"
@@ -19,7 +21,6 @@ import (
 	"storj.io/common/testcontext"
 	"storj.io/private/dbutil"
 	"storj.io/private/dbutil/pgtest"
-	"storj.io/private/dbutil/pgutil"
 	"storj.io/private/dbutil/tempdb"
 	"storj.io/storj/satellite"
 	"storj.io/storj/satellite/metabase"
@@ -69,15 +70,26 @@ func Databases() []SatelliteDatabases {
 	return dbs
 }
 
-// SchemaSuffix returns a suffix for schemas.
+// schemaSuffixAlphabet contains the characters of random schema suffixes, schema names are lower cased.
+const schemaSuffixAlphabet = "abcdefghijklmnopqrstuvwxyz0123456789"
+
+// SchemaSuffix returns a suffix for schemas. It's unique across test processes started at the
+// same time, since it combines the process id with crypto random characters.
 func SchemaSuffix() string {
-	return pgutil.CreateRandomTestingSchemaName(6)
+	random := make([]byte, 6)
+	if _, err := rand.Read(random); err != nil {
+		panic(err)
+	}
+	for i, b := range random {
+		random[i] = schemaSuffixAlphabet[int(b)%len(schemaSuffixAlphabet)]
+	}
+	return strconv.FormatInt(int64(os.Getpid()), 36) + "-" + string(random)
 }
 
 // SchemaName returns a properly formatted schema string.
 func SchemaName(testname, category string, index int, schemaSuffix string) string {
 	// postgres has a maximum schema length of 64
-	// we need additional 6 bytes for the random suffix
+	// we need additional bytes for the random suffix
 	//    and 4 bytes for the satellite index "/S0/""
 
 	indexStr := strconv.Itoa(index)
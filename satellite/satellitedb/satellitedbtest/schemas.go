@@ -0,0 +1,125 @@
+// Copyright (C) 2021 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package satellitedbtest
+
+import (
+	"context"
+	"strings"
+
+	pgxerrcode "github.com/jackc/pgerrcode"
+	"github.com/zeebo/errs"
+
+	"storj.io/private/dbutil/pgutil"
+	"storj.io/private/dbutil/pgutil/pgerrcode"
+	"storj.io/private/tagsql"
+)
+
+// createUniqueSchemaAttempts is the number of names CreateUniqueSchema tries before giving up.
+const createUniqueSchemaAttempts = 5
+
+// dropSchemasBatchSize is the number of schemas DropSchemasMatching drops with a single statement.
+const dropSchemasBatchSize = 100
+
+// schemasQuery selects user schemas of the current database, both on Postgres and Cockroach.
+const schemasQuery = `
+	SELECT schema_name FROM information_schema.schemata
+	WHERE
+		schema_name NOT IN ('public', 'information_schema', 'crdb_internal', 'pg_extension') AND
+		schema_name NOT LIKE 'pg\_%'
+`
+
+// ListSchemas returns the names of the schemas of the current database, ordered by name.
+// System schemas and the public schema are not included.
+func ListSchemas(ctx context.Context, db tagsql.DB) (_ []string, err error) {
+	rows, err := db.QueryContext(ctx, schemasQuery+` ORDER BY schema_name`)
+	if err != nil {
+		return nil, errs.Wrap(err)
+	}
+	defer func() { err = errs.Combine(err, rows.Close()) }()
+
+	var schemas []string
+	for rows.Next() {
+		var schema string
+		if err := rows.Scan(&schema); err != nil {
+			return nil, errs.Wrap(err)
+		}
+		schemas = append(schemas, schema)
+	}
+	return schemas, errs.Wrap(rows.Err())
+}
+
+// SchemaExists returns whether a schema with the name exists in the current database.
+func SchemaExists(ctx context.Context, db tagsql.DB, name string) (exists bool, err error) {
+	err = db.QueryRowContext(ctx, `
+		SELECT EXISTS (SELECT 1 FROM information_schema.schemata WHERE schema_name = $1)
+	`, name).Scan(&exists)
+	return exists, errs.Wrap(err)
+}
+
+// CreateUniqueSchema creates a schema named prefix followed by a SchemaSuffix and returns its name.
+// A name that already exists, e.g. one created by another test process, is never shared, instead
+// a new suffix is generated.
+func CreateUniqueSchema(ctx context.Context, db tagsql.DB, prefix string) (string, error) {
+	for attempt := 0; attempt < createUniqueSchemaAttempts; attempt++ {
+		name := prefix + SchemaSuffix()
+
+		exists, err := SchemaExists(ctx, db, name)
+		if err != nil {
+			return "", err
+		}
+		if exists {
+			continue
+		}
+
+		// CREATE SCHEMA without IF NOT EXISTS, so a schema created in the meantime fails
+		// instead of being shared.
+		_, err = db.ExecContext(ctx, `CREATE SCHEMA `+pgutil.QuoteIdentifier(name))
+		if err != nil {
+			// concurrent creates may fail with a unique violation instead of a duplicate schema.
+			if code := pgerrcode.FromError(err); code == pgxerrcode.DuplicateSchema || code == pgxerrcode.UniqueViolation {
+				continue
+			}
+			return "", errs.Wrap(err)
+		}
+		return name, nil
+	}
+	return "", errs.New("unable to create a unique schema with prefix %q", prefix)
+}
+
+// DropSchemasMatching drops the schemas returned by ListSchemas whose names start with prefix,
+// including their tables, and returns the number of dropped schemas. It's meant for cleaning up
+// schemas leaked by crashed test runs, hence the prefix must not be empty.
+func DropSchemasMatching(ctx context.Context, db tagsql.DB, prefix string) (dropped int, err error) {
+	if prefix == "" {
+		return 0, errs.New("prefix must not be empty")
+	}
+
+	schemas, err := ListSchemas(ctx, db)
+	if err != nil {
+		return 0, err
+	}
+
+	var matching []string
+	for _, schema := range schemas {
+		if strings.HasPrefix(schema, prefix) {
+			matching = append(matching, pgutil.QuoteIdentifier(schema))
+		}
+	}
+
+	for len(matching) > 0 {
+		batch := matching
+		if len(batch) > dropSchemasBatchSize {
+			batch = batch[:dropSchemasBatchSize]
+		}
+		matching = matching[len(batch):]
+
+		_, err := db.ExecContext(ctx, `DROP SCHEMA IF EXISTS `+strings.Join(batch, ", ")+` CASCADE`)
+		if err != nil {
+			return dropped, errs.Wrap(err)
+		}
+		dropped += len(batch)
+	}
+
+	return dropped, nil
+}
@@ -0,0 +1,91 @@
+// Copyright (C) 2021 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package satellitedbtest_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"storj.io/common/testcontext"
+	"storj.io/private/dbutil/pgutil"
+	"storj.io/private/dbutil/tempdb"
+	"storj.io/storj/satellite/satellitedb/satellitedbtest"
+)
+
+func TestSchemaSuffix(t *testing.T) {
+	suffixes := map[string]bool{}
+	for i := 0; i < 1000; i++ {
+		suffix := satellitedbtest.SchemaSuffix()
+		require.Equal(t, strings.ToLower(suffix), suffix)
+		require.False(t, suffixes[suffix], "duplicate suffix %q", suffix)
+		suffixes[suffix] = true
+	}
+}
+
+func TestSchemas(t *testing.T) {
+	for _, dbInfo := range satellitedbtest.Databases() {
+		dbInfo := dbInfo
+		t.Run(dbInfo.Name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := testcontext.New(t)
+			defer ctx.Cleanup()
+
+			if dbInfo.MasterDB.URL == "" {
+				t.Skipf("Database %s connection string not provided. %s", dbInfo.MasterDB.Name, dbInfo.MasterDB.Message)
+			}
+
+			db, err := tempdb.OpenUnique(ctx, dbInfo.MasterDB.URL, "schemas")
+			require.NoError(t, err)
+			defer ctx.Check(db.Close)
+
+			prefix := "leaked/" + satellitedbtest.SchemaSuffix() + "/"
+			for i := 0; i < 3; i++ {
+				require.NoError(t, pgutil.CreateSchema(ctx, db, fmt.Sprintf("%s%d", prefix, i)))
+			}
+			other := "kept/" + satellitedbtest.SchemaSuffix()
+			require.NoError(t, pgutil.CreateSchema(ctx, db, other))
+
+			schemas, err := satellitedbtest.ListSchemas(ctx, db)
+			require.NoError(t, err)
+			require.Subset(t, schemas, []string{prefix + "0", prefix + "1", prefix + "2", other})
+			require.NotContains(t, schemas, "public")
+			require.NotContains(t, schemas, "pg_catalog")
+			require.NotContains(t, schemas, "information_schema")
+
+			exists, err := satellitedbtest.SchemaExists(ctx, db, prefix+"1")
+			require.NoError(t, err)
+			require.True(t, exists)
+
+			dropped, err := satellitedbtest.DropSchemasMatching(ctx, db, prefix)
+			require.NoError(t, err)
+			require.Equal(t, 3, dropped)
+
+			exists, err = satellitedbtest.SchemaExists(ctx, db, prefix+"1")
+			require.NoError(t, err)
+			require.False(t, exists)
+
+			exists, err = satellitedbtest.SchemaExists(ctx, db, other)
+			require.NoError(t, err)
+			require.True(t, exists)
+
+			_, err = satellitedbtest.DropSchemasMatching(ctx, db, "")
+			require.Error(t, err)
+
+			unique := "unique/" + satellitedbtest.SchemaSuffix() + "/"
+			first, err := satellitedbtest.CreateUniqueSchema(ctx, db, unique)
+			require.NoError(t, err)
+			second, err := satellitedbtest.CreateUniqueSchema(ctx, db, unique)
+			require.NoError(t, err)
+			require.NotEqual(t, first, second)
+
+			dropped, err = satellitedbtest.DropSchemasMatching(ctx, db, unique)
+			require.NoError(t, err)
+			require.Equal(t, 2, dropped)
+		})
+	}
+}
@@ -562,25 +562,59 @@ func (db *StoragenodeAccounting) ArchiveRollupsBefore(ctx context.Context, befor
 		return nodeRollupsDeleted, nil
 
 	case dbutil.Postgres:
-		storagenodeStatement := `
+		// Postgres doesn't support DELETE ... LIMIT, so the batch is selected by ctid.
+		for {
+			row := db.db.QueryRow(ctx, `
 			WITH rollups_to_move AS (
 				DELETE FROM storagenode_bandwidth_rollups
-				WHERE interval_start <= $1
+				WHERE ctid IN (
+					SELECT ctid FROM storagenode_bandwidth_rollups
+					WHERE interval_start <= $1
+					LIMIT $2
+				)
 				RETURNING *
 			), moved_rollups AS (
 				INSERT INTO storagenode_bandwidth_rollup_archives SELECT * FROM rollups_to_move RETURNING *
 			)
 			SELECT count(*) FROM moved_rollups
-		`
-		row := db.db.DB.QueryRow(ctx, storagenodeStatement, before)
-		err = row.Scan(&nodeRollupsDeleted)
-		return nodeRollupsDeleted, err
+			`, before, batchSize)
+
+			var rowCount int
+			err = row.Scan(&rowCount)
+			if err != nil {
+				return nodeRollupsDeleted, err
+			}
+			nodeRollupsDeleted += rowCount
+
+			if rowCount < batchSize {
+				break
+			}
+		}
+		return nodeRollupsDeleted, nil
 
 	default:
 		return 0, Error.New("unsupported database: %v", db.db.impl)
 	}
 }
 
+// ArchiveRollupsBeforeDryRun returns the number of rollups ArchiveRollupsBefore would archive,
+// without archiving them.
+func (db *StoragenodeAccounting) ArchiveRollupsBeforeDryRun(ctx context.Context, before time.Time, batchSize int) (count int, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	if batchSize <= 0 {
+		return 0, nil
+	}
+	if db.db.impl != dbutil.Cockroach && db.db.impl != dbutil.Postgres {
+		return 0, Error.New("unsupported database: %v", db.db.impl)
+	}
+
+	err = db.db.QueryRow(ctx, `
+		SELECT count(*) FROM storagenode_bandwidth_rollups WHERE interval_start <= $1
+	`, before).Scan(&count)
+	return count, Error.Wrap(err)
+}
+
 // GetRollupsSince retrieves all archived bandwidth rollup records since a given time.
 func (db *StoragenodeAccounting) GetRollupsSince(ctx context.Context, since time.Time) (bwRollups []accounting.StoragenodeBandwidthRollup, err error) {
 	defer mon.Task()(&ctx)(&err)
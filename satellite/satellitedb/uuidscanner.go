@@ -0,0 +1,62 @@
+// Copyright (C) 2021 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package satellitedb
+
+import (
+	"database/sql"
+	"database/sql/driver"
+
+	"storj.io/common/uuid"
+)
+
+// ensure that uuidScanner can be used with database/sql.
+var (
+	_ sql.Scanner   = (*uuidScanner)(nil)
+	_ driver.Valuer = uuidScanner{}
+)
+
+// uuidScanner scans a bytea column into a uuid.UUID. Unlike uuid.UUID it does
+// not fail when the stored bytes are not a valid UUID, instead it reports
+// that through Valid, so that bulk readers can decide what to do with the row.
+type uuidScanner struct {
+	UUID  uuid.UUID
+	Valid bool
+	// Raw contains the bytes as stored in the database.
+	Raw []byte
+}
+
+// Scan implements sql.Scanner.
+func (s *uuidScanner) Scan(value interface{}) error {
+	*s = uuidScanner{}
+
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []byte:
+		s.Raw = append([]byte(nil), v...)
+		id, err := uuid.FromBytes(v)
+		if err != nil {
+			return nil
+		}
+		s.UUID, s.Valid = id, true
+		return nil
+	default:
+		return Error.New("unable to scan %T into uuid", value)
+	}
+}
+
+// Value implements driver.Valuer.
+func (s uuidScanner) Value() (driver.Value, error) {
+	if !s.Valid {
+		return s.Raw, nil
+	}
+	return s.UUID[:], nil
+}
+
+// scanUUIDBytes parses bytes returned from dbx into an uuidScanner.
+func scanUUIDBytes(raw []byte) (s uuidScanner) {
+	// scanning []byte never fails.
+	_ = s.Scan(raw)
+	return s
+}